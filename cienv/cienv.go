@@ -3,6 +3,7 @@ package cienv
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"regexp"
 	"strconv"
@@ -104,14 +105,9 @@ func GetBuildInfo() (prInfo *BuildInfo, isPR bool, err error) {
 
 // GetGerritBuildInfo returns Gerrit specific build info
 func GetGerritBuildInfo() (*BuildInfo, error) {
-	changeID := os.Getenv("GERRIT_CHANGE_ID")
-	if changeID == "" {
-		return nil, errors.New("cannot get change id from environment variable. Set GERRIT_CHANGE_ID ?")
-	}
-
-	revisionID := os.Getenv("GERRIT_REVISION_ID")
-	if revisionID == "" {
-		return nil, errors.New("cannot get revision id from environment variable. Set GERRIT_REVISION_ID ?")
+	changeID, revisionID, err := GetGerritChange()
+	if err != nil {
+		return nil, err
 	}
 
 	branch := os.Getenv("GERRIT_BRANCH")
@@ -126,6 +122,34 @@ func GetGerritBuildInfo() (*BuildInfo, error) {
 	}, nil
 }
 
+// GetGerritChange returns the Gerrit change ID and revision ID from
+// GERRIT_CHANGE_ID and GERRIT_REVISION_ID. GERRIT_PATCHSET_REVISION, which
+// Gerrit Trigger plugin of Jenkins sets, is used if GERRIT_REVISION_ID is not
+// set.
+func GetGerritChange() (changeID, revisionID string, err error) {
+	changeID = os.Getenv("GERRIT_CHANGE_ID")
+	if changeID == "" {
+		return "", "", errors.New("cannot get change id from environment variable. Set GERRIT_CHANGE_ID ?")
+	}
+	if strings.ContainsAny(changeID, " \t\r\n") {
+		return "", "", fmt.Errorf("invalid change id in GERRIT_CHANGE_ID: %q", changeID)
+	}
+
+	revisionEnv := "GERRIT_REVISION_ID"
+	revisionID = os.Getenv(revisionEnv)
+	if revisionID == "" {
+		revisionEnv = "GERRIT_PATCHSET_REVISION"
+		revisionID = os.Getenv(revisionEnv)
+	}
+	if revisionID == "" {
+		return "", "", errors.New("cannot get revision id from environment variable. Set GERRIT_REVISION_ID or GERRIT_PATCHSET_REVISION ?")
+	}
+	if strings.ContainsAny(revisionID, " \t\r\n") {
+		return "", "", fmt.Errorf("invalid revision id in %s: %q", revisionEnv, revisionID)
+	}
+	return changeID, revisionID, nil
+}
+
 func getPullRequestNum() int {
 	envs := []string{
 		// Common.
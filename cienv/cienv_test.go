@@ -36,6 +36,7 @@ func setupEnvs() (cleanup func()) {
 		"GITHUB_ACTIONS",
 		"GERRIT_CHANGE_ID",
 		"GERRIT_REVISION_ID",
+		"GERRIT_PATCHSET_REVISION",
 		"GERRIT_BRANCH",
 	}
 	saveEnvs := make(map[string]string)
@@ -300,3 +301,32 @@ func TestGetGerritBuildInfo(t *testing.T) {
 		t.Error("nil expected but got err")
 	}
 }
+
+func TestGetGerritChange(t *testing.T) {
+	cleanup := setupEnvs()
+	defer cleanup()
+
+	os.Setenv("GERRIT_CHANGE_ID", "myproject~master~I1293efab014de2")
+	if _, _, err := GetGerritChange(); err == nil {
+		t.Error("error expected without revision id but got nil")
+	}
+
+	os.Setenv("GERRIT_PATCHSET_REVISION", "ed318bf9a3c")
+	changeID, revisionID, err := GetGerritChange()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changeID != "myproject~master~I1293efab014de2" || revisionID != "ed318bf9a3c" {
+		t.Errorf("got (%q, %q)", changeID, revisionID)
+	}
+
+	os.Setenv("GERRIT_REVISION_ID", "current")
+	if _, revisionID, _ := GetGerritChange(); revisionID != "current" {
+		t.Errorf("GERRIT_REVISION_ID should take precedence; got %q", revisionID)
+	}
+
+	os.Setenv("GERRIT_REVISION_ID", "ed318bf9a3c\n")
+	if _, _, err := GetGerritChange(); err == nil {
+		t.Error("error expected for invalid revision id but got nil")
+	}
+}
@@ -13,7 +13,8 @@ type GitHubEvent struct {
 		Owner struct {
 			Login string `json:"login"`
 		} `json:"owner"`
-		Name string `json:"name"`
+		Name          string `json:"name"`
+		DefaultBranch string `json:"default_branch"`
 	} `json:"repository"`
 	CheckSuite struct {
 		After        string              `json:"after"`
@@ -22,6 +23,8 @@ type GitHubEvent struct {
 	HeadCommit struct {
 		ID string `json:"id"`
 	} `json:"head_commit"`
+	// Before is the commit SHA before the push of push events.
+	Before     string `json:"before"`
 	ActionName string `json:"-"` // this is defined as env GITHUB_EVENT_NAME
 }
 
@@ -0,0 +1,37 @@
+package cienv
+
+import (
+	"os"
+	"strings"
+)
+
+// PushInfo represents information about the push which triggered the build.
+type PushInfo struct {
+	// Before is the commit SHA before the push. It's empty if unknown or the
+	// push created the branch.
+	Before string
+
+	// DefaultBranch is the default branch of the repository. Optional.
+	DefaultBranch string
+}
+
+// GetPushInfo returns PushInfo from the push event of GitHub Actions or
+// environment variables of GitLab CI (CI_COMMIT_BEFORE_SHA and
+// CI_DEFAULT_BRANCH). Fields are empty if they are not available.
+func GetPushInfo() *PushInfo {
+	info := &PushInfo{}
+	if IsInGitHubAction() {
+		if event, err := LoadGitHubEvent(); err == nil {
+			info.Before = event.Before
+			info.DefaultBranch = event.Repository.DefaultBranch
+		}
+	} else {
+		info.Before = os.Getenv("CI_COMMIT_BEFORE_SHA")
+		info.DefaultBranch = os.Getenv("CI_DEFAULT_BRANCH")
+	}
+	// The before SHA of pushes which create branches is all zeros.
+	if strings.Trim(info.Before, "0") == "" {
+		info.Before = ""
+	}
+	return info
+}
@@ -0,0 +1,49 @@
+package cienv
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetPushInfo_githubActions(t *testing.T) {
+	defer setupEnvs()()
+	defer setEnv("GITHUB_EVENT_PATH", "_testdata/github_event_push.json")()
+	os.Setenv("GITHUB_ACTIONS", "true")
+
+	want := &PushInfo{Before: "7a17ca64b1febe0a4479161cbe72978d6666f424", DefaultBranch: "master"}
+	if diff := cmp.Diff(GetPushInfo(), want); diff != "" {
+		t.Errorf("result has diff:\n%s", diff)
+	}
+}
+
+func TestGetPushInfo_gitlab(t *testing.T) {
+	defer setupEnvs()()
+	defer setEnv("CI_DEFAULT_BRANCH", "main")()
+
+	defer setEnv("CI_COMMIT_BEFORE_SHA", "7a17ca64b1febe0a4479161cbe72978d6666f424")()
+	want := &PushInfo{Before: "7a17ca64b1febe0a4479161cbe72978d6666f424", DefaultBranch: "main"}
+	if diff := cmp.Diff(GetPushInfo(), want); diff != "" {
+		t.Errorf("result has diff:\n%s", diff)
+	}
+
+	// Pushes which create branches.
+	os.Setenv("CI_COMMIT_BEFORE_SHA", "0000000000000000000000000000000000000000")
+	want = &PushInfo{DefaultBranch: "main"}
+	if diff := cmp.Diff(GetPushInfo(), want); diff != "" {
+		t.Errorf("result has diff:\n%s", diff)
+	}
+}
+
+func setEnv(key, value string) (restore func()) {
+	old, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	return func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -18,13 +21,14 @@ import (
 	"github.com/reviewdog/reviewdog/doghouse"
 	"github.com/reviewdog/reviewdog/doghouse/client"
 	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/metrics"
 	"github.com/reviewdog/reviewdog/project"
 	"github.com/reviewdog/reviewdog/proto/rdf"
 	"github.com/reviewdog/reviewdog/service/github/githubutils"
 	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
-func runDoghouse(ctx context.Context, r io.Reader, w io.Writer, opt *option, isProject bool, forPr bool) error {
+func runDoghouse(ctx context.Context, r io.Reader, w io.Writer, opt *option, isProject bool, forPr bool, sink metrics.Sink) error {
 	ghInfo, isPr, err := cienv.GetBuildInfo()
 	if err != nil {
 		return err
@@ -37,21 +41,40 @@ func runDoghouse(ctx context.Context, r io.Reader, w io.Writer, opt *option, isP
 	if err != nil {
 		return err
 	}
-	cli, err := newDoghouseCli(ctx)
+	cli, err := newDoghouseCli(ctx, sink)
 	if err != nil {
 		return err
 	}
-	filteredResultSet, err := postResultSet(ctx, resultSet, ghInfo, cli, opt)
+	dr := &doghouseRun{}
+	dr.baseline, dr.baselineRecorder, err = loadBaseline(opt)
 	if err != nil {
 		return err
 	}
-	if foundResultShouldReport := reportResults(w, filteredResultSet); foundResultShouldReport {
-		return errors.New("found at least one result in diff")
+	if opt.summaryFile != "" {
+		dr.summary = reviewdog.NewRunSummary()
 	}
-	return nil
+	filteredResultSet, err := postResultSet(ctx, resultSet, ghInfo, cli, opt, dr)
+	if err == nil {
+		if foundResultShouldReport := reportResults(w, filteredResultSet); foundResultShouldReport {
+			err = errors.New("found at least one result in diff")
+		}
+	}
+	err = writeSummary(opt.summaryFile, dr.summary, w, err)
+	return writeBaseline(opt.baseline, dr.baselineRecorder, err)
 }
 
-func newDoghouseCli(ctx context.Context) (client.DogHouseClientInterface, error) {
+// doghouseRun has states of a doghouse run shared by results of all tools.
+type doghouseRun struct {
+	// baseline has known results to drop. baselineRecorder records results to
+	// generate a baseline.
+	baseline         *filter.Baseline
+	baselineRecorder *filter.Baseline
+
+	// summary accumulates results in diff if not nil.
+	summary *reviewdog.RunSummary
+}
+
+func newDoghouseCli(ctx context.Context, sink metrics.Sink) (client.DogHouseClientInterface, error) {
 	// If skipDoghouseServer is true, run doghouse code directly instead of talking to
 	// the doghouse server because provided GitHub API Token has Check API scope.
 	// You can force skipping the doghouse server if you are generating your own application API token.
@@ -61,7 +84,7 @@ func newDoghouseCli(ctx context.Context) (client.DogHouseClientInterface, error)
 		if err != nil {
 			return nil, err
 		}
-		ghcli, err := githubClient(ctx, token)
+		ghcli, err := githubClient(ctx, token, sink)
 		if err != nil {
 			return nil, err
 		}
@@ -111,8 +134,27 @@ func checkResultSet(ctx context.Context, r io.Reader, opt *option, isProject boo
 	return resultSet, nil
 }
 
+// checkIdempotencyKey returns the idempotency key of the check request. It's
+// the same for re-runs of the same GitHub Actions workflow run and job with
+// the same request, so that they reuse the check run left by a failed attempt.
+// It returns empty string outside GitHub Actions.
+func checkIdempotencyKey(req *doghouse.CheckRequest) string {
+	runID := os.Getenv("GITHUB_RUN_ID")
+	if runID == "" {
+		return ""
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00", runID, os.Getenv("GITHUB_WORKFLOW"), os.Getenv("GITHUB_JOB"))
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func postResultSet(ctx context.Context, resultSet *reviewdog.ResultMap,
-	ghInfo *cienv.BuildInfo, cli client.DogHouseClientInterface, opt *option) (*reviewdog.FilteredResultMap, error) {
+	ghInfo *cienv.BuildInfo, cli client.DogHouseClientInterface, opt *option, dr *doghouseRun) (*reviewdog.FilteredResultMap, error) {
 	var g errgroup.Group
 	wd, _ := os.Getwd()
 	gitRelWd, err := serviceutil.GitRelWorkdir()
@@ -121,7 +163,27 @@ func postResultSet(ctx context.Context, resultSet *reviewdog.ResultMap,
 	}
 	filteredResultSet := new(reviewdog.FilteredResultMap)
 	resultSet.Range(func(name string, result *reviewdog.Result) {
+		filter.RewritePaths(result.Diagnostics, opt.pathRewrites)
 		diagnostics := result.Diagnostics
+		if !opt.keepEmptyMessages {
+			n := len(diagnostics)
+			diagnostics = filter.FilterEmptyMessages(diagnostics)
+			if dropped := n - len(diagnostics); dropped > 0 {
+				log.Printf("reviewdog: [%s] dropped %d results with empty messages", name, dropped)
+			}
+		}
+		diagnostics = filter.FilterSeverity(diagnostics, opt.filterSeverity)
+		diagnostics = filter.FilterTools(diagnostics, name, opt.includeTools, opt.excludeTools)
+		diagnostics = filter.FilterFiles(diagnostics, wd, opt.includeFiles, opt.excludeFiles)
+		if opt.ignoreAnnotations {
+			diagnostics = filter.FilterIgnoreAnnotations(diagnostics)
+		}
+		if dr.baselineRecorder != nil {
+			for _, d := range diagnostics {
+				dr.baselineRecorder.Add(d, name, wd)
+			}
+		}
+		diagnostics = filter.FilterBaseline(diagnostics, name, wd, dr.baseline)
 		as := make([]*doghouse.Annotation, 0, len(diagnostics))
 		for _, d := range diagnostics {
 			as = append(as, checkResultToAnnotation(d, wd, gitRelWd))
@@ -137,6 +199,7 @@ func postResultSet(ctx context.Context, resultSet *reviewdog.ResultMap,
 			Level:       result.Level,
 			FilterMode:  opt.filterMode,
 		}
+		req.IdempotencyKey = checkIdempotencyKey(req)
 		g.Go(func() error {
 			if err := result.CheckUnexpectedFailure(); err != nil {
 				return err
@@ -162,14 +225,17 @@ func postResultSet(ctx context.Context, resultSet *reviewdog.ResultMap,
 			if res.ReportURL == "" && res.CheckedResults == nil {
 				return fmt.Errorf("[%s] no result found", name)
 			}
+			if dr.summary != nil {
+				addCheckSummary(dr.summary, name, res.CheckedResults, opt)
+			}
 			// If failOnError is on, return error when at least one report
-			// returns failure conclusion (status). Users can check this
-			// reviewdoc run status (#446) to merge PRs for example.
+			// violates the fail policy. Users can check this reviewdoc run status
+			// (#446) to merge PRs for example.
 			//
 			// Also, the individual report conclusions are associated to random check
 			// suite due to the GitHub bug (#403), so actually users cannot depends
 			// on each report as of writing.
-			if opt.failOnError && (res.Conclusion == "failure") {
+			if failOnError(opt) && checkFailed(res, opt) {
 				return fmt.Errorf("[%s] Check conclusion is %q", name, res.Conclusion)
 			}
 			return nil
@@ -178,6 +244,42 @@ func postResultSet(ctx context.Context, resultSet *reviewdog.ResultMap,
 	return filteredResultSet, g.Wait()
 }
 
+// checkFailed returns true if the check result violates the fail policy. It
+// counts checked results in diff with -fail-on-severity and -fail-threshold
+// like the other reporters. Without them, or if the response has no checked
+// results (e.g. old doghouse server), it fails on the failure conclusion.
+func checkFailed(res *doghouse.CheckResponse, opt *option) bool {
+	if res.CheckedResults == nil || (opt.failOnSeverity == filter.SeverityLevelAny && opt.failThreshold == 0) {
+		return res.Conclusion == "failure"
+	}
+	return countFailResults(res.CheckedResults, opt) > opt.failThreshold
+}
+
+// countFailResults returns the number of checked results in diff whose
+// severity is counted for -fail-on-severity.
+func countFailResults(checks []*filter.FilteredDiagnostic, opt *option) int {
+	counted := 0
+	for _, c := range checks {
+		if c.ShouldReport && opt.failOnSeverity.Match(c.Diagnostic.GetSeverity()) {
+			counted++
+		}
+	}
+	return counted
+}
+
+// addCheckSummary adds the checked results in diff of the tool to the
+// summary.
+func addCheckSummary(summary *reviewdog.RunSummary, name string, checks []*filter.FilteredDiagnostic, opt *option) {
+	for _, c := range checks {
+		if c.ShouldReport {
+			summary.AddCheck(name, c)
+		}
+	}
+	if countFailResults(checks, opt) > opt.failThreshold {
+		summary.SetFailLevelExceeded()
+	}
+}
+
 func checkResultToAnnotation(d *rdf.Diagnostic, wd, gitRelWd string) *doghouse.Annotation {
 	d.GetLocation().Path = filter.NormalizePath(d.GetLocation().GetPath(), wd, gitRelWd)
 	return &doghouse.Annotation{
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -46,7 +48,7 @@ func TestNewDoghouseCli_returnGitHubClient(t *testing.T) {
 		"REVIEWDOG_GITHUB_API_TOKEN": "xxx",
 	})
 	defer cleanup()
-	cli, err := newDoghouseCli(context.Background())
+	cli, err := newDoghouseCli(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("failed to create new client: %v", err)
 	}
@@ -62,7 +64,7 @@ func TestNewDoghouseCli_returnErrorForGitHubClient(t *testing.T) {
 		"REVIEWDOG_GITHUB_API_TOKEN": "", // missing
 	})
 	defer cleanup()
-	if _, err := newDoghouseCli(context.Background()); err == nil {
+	if _, err := newDoghouseCli(context.Background(), nil); err == nil {
 		t.Error("got no error but want REVIEWDOG_GITHUB_API_TOKEN missing error")
 	}
 }
@@ -74,7 +76,7 @@ func TestNewDoghouseCli_returnDogHouseClientWithReviewdogToken(t *testing.T) {
 		"REVIEWDOG_GITHUB_API_TOKEN": "xxx",
 	})
 	defer cleanup()
-	cli, err := newDoghouseCli(context.Background())
+	cli, err := newDoghouseCli(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("failed to create new client: %v", err)
 	}
@@ -90,7 +92,7 @@ func TestNewDoghouseCli_returnDogHouseClient(t *testing.T) {
 		"REVIEWDOG_GITHUB_API_TOKEN": "",
 	})
 	defer cleanup()
-	cli, err := newDoghouseCli(context.Background())
+	cli, err := newDoghouseCli(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("failed to create new client: %v", err)
 	}
@@ -313,7 +315,7 @@ func TestPostResultSet_withReportURL(t *testing.T) {
 	}
 
 	opt := &option{filterMode: filter.ModeAdded}
-	if _, err := postResultSet(context.Background(), &resultSet, ghInfo, fakeCli, opt); err != nil {
+	if _, err := postResultSet(context.Background(), &resultSet, ghInfo, fakeCli, opt, &doghouseRun{}); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -338,7 +340,7 @@ func TestPostResultSet_withoutReportURL(t *testing.T) {
 	ghInfo := &cienv.BuildInfo{Owner: owner, Repo: repo, PullRequest: prNum, SHA: sha}
 
 	opt := &option{filterMode: filter.ModeAdded}
-	resp, err := postResultSet(context.Background(), &resultSet, ghInfo, fakeCli, opt)
+	resp, err := postResultSet(context.Background(), &resultSet, ghInfo, fakeCli, opt, &doghouseRun{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -386,7 +388,7 @@ func TestPostResultSet_conclusion(t *testing.T) {
 		}
 		opt := &option{filterMode: filter.ModeAdded, failOnError: tt.failOnError}
 		id := fmt.Sprintf("[conclusion=%s, failOnError=%v]", tt.conclusion, tt.failOnError)
-		_, err := postResultSet(context.Background(), &resultSet, ghInfo, fakeCli, opt)
+		_, err := postResultSet(context.Background(), &resultSet, ghInfo, fakeCli, opt, &doghouseRun{})
 		if tt.wantErr && err == nil {
 			t.Errorf("[%s] want err, but got nil.", id)
 		} else if !tt.wantErr && err != nil {
@@ -395,6 +397,256 @@ func TestPostResultSet_conclusion(t *testing.T) {
 	}
 }
 
+func TestPostResultSet_failPolicy(t *testing.T) {
+	fakeCli := &fakeDoghouseServerCli{}
+	fakeCli.FakeCheck = func(ctx context.Context, req *doghouse.CheckRequest) (*doghouse.CheckResponse, error) {
+		return &doghouse.CheckResponse{
+			ReportURL:  "xxx",
+			Conclusion: "neutral",
+			CheckedResults: []*filter.FilteredDiagnostic{
+				{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_WARNING}, ShouldReport: true},
+				{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_INFO}, ShouldReport: true},
+				{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_ERROR}, ShouldReport: false},
+			},
+		}, nil
+	}
+	var resultSet reviewdog.ResultMap
+	resultSet.Store("name1", &reviewdog.Result{Level: "warning", Diagnostics: []*rdf.Diagnostic{}})
+	ghInfo := &cienv.BuildInfo{Owner: "haya14busa", Repo: "reviewdog", PullRequest: 14, SHA: "1414"}
+
+	tests := []struct {
+		failOnSeverity filter.SeverityLevel
+		failThreshold  int
+		wantErr        bool
+	}{
+		{failOnSeverity: filter.SeverityLevelAny, failThreshold: 0, wantErr: false}, // -fail-on-error isn't set.
+		{failOnSeverity: filter.SeverityLevelWarning, failThreshold: 0, wantErr: true},
+		{failOnSeverity: filter.SeverityLevelError, failThreshold: 0, wantErr: false},
+		{failOnSeverity: filter.SeverityLevelAny, failThreshold: 1, wantErr: true},
+		{failOnSeverity: filter.SeverityLevelAny, failThreshold: 2, wantErr: false},
+	}
+	for _, tt := range tests {
+		opt := &option{filterMode: filter.ModeAdded, failOnSeverity: tt.failOnSeverity, failThreshold: tt.failThreshold}
+		_, err := postResultSet(context.Background(), &resultSet, ghInfo, fakeCli, opt, &doghouseRun{})
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("[-fail-on-severity=%s -fail-threshold=%d] got error %v, want error: %v",
+				&tt.failOnSeverity, tt.failThreshold, err, tt.wantErr)
+		}
+	}
+}
+
+// postedMessages posts the results with opt and dr and returns messages of
+// posted annotations.
+func postedMessages(t *testing.T, resultSet *reviewdog.ResultMap, opt *option, dr *doghouseRun) []string {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		messages []string
+	)
+	fakeCli := &fakeDoghouseServerCli{}
+	fakeCli.FakeCheck = func(ctx context.Context, req *doghouse.CheckRequest) (*doghouse.CheckResponse, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, a := range req.Annotations {
+			messages = append(messages, a.Diagnostic.GetMessage())
+		}
+		return &doghouse.CheckResponse{ReportURL: "xxx"}, nil
+	}
+	ghInfo := &cienv.BuildInfo{Owner: "haya14busa", Repo: "reviewdog", PullRequest: 14, SHA: "1414"}
+	if _, err := postResultSet(context.Background(), resultSet, ghInfo, fakeCli, opt, dr); err != nil {
+		t.Fatal(err)
+	}
+	return messages
+}
+
+func TestPostResultSet_ignoreAnnotations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "src.go")
+	if err := os.WriteFile(path, []byte("a := 1\nb := 2 // reviewdog:ignore\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	newResultSet := func() *reviewdog.ResultMap {
+		var resultSet reviewdog.ResultMap
+		resultSet.Store("name1", &reviewdog.Result{Diagnostics: []*rdf.Diagnostic{
+			{Message: "line 1", Location: &rdf.Location{Path: path, Range: &rdf.Range{Start: &rdf.Position{Line: 1}}}},
+			{Message: "line 2", Location: &rdf.Location{Path: path, Range: &rdf.Range{Start: &rdf.Position{Line: 2}}}},
+		}})
+		return &resultSet
+	}
+
+	got := postedMessages(t, newResultSet(), &option{filterMode: filter.ModeAdded, ignoreAnnotations: true}, &doghouseRun{})
+	if diff := cmp.Diff([]string{"line 1"}, got); diff != "" {
+		t.Errorf("posted annotations with -ignore-annotations have diff:\n%s", diff)
+	}
+	got = postedMessages(t, newResultSet(), &option{filterMode: filter.ModeAdded}, &doghouseRun{})
+	if diff := cmp.Diff([]string{"line 1", "line 2"}, got); diff != "" {
+		t.Errorf("posted annotations have diff:\n%s", diff)
+	}
+}
+
+func TestPostResultSet_toolFilter(t *testing.T) {
+	var resultSet reviewdog.ResultMap
+	for _, name := range []string{"golint", "govet", "staticcheck"} {
+		resultSet.Store(name, &reviewdog.Result{Diagnostics: []*rdf.Diagnostic{
+			{Message: name, Location: &rdf.Location{Path: "reviewdog.go"}},
+		}})
+	}
+	opt := &option{
+		filterMode:   filter.ModeAdded,
+		includeTools: filter.ToolPatterns{"go*"},
+		excludeTools: filter.ToolPatterns{"govet"},
+	}
+	got := postedMessages(t, &resultSet, opt, &doghouseRun{})
+	if diff := cmp.Diff([]string{"golint"}, got); diff != "" {
+		t.Errorf("posted annotations have diff:\n%s", diff)
+	}
+}
+
+func TestPostResultSet_fileFilter(t *testing.T) {
+	var resultSet reviewdog.ResultMap
+	resultSet.Store("name1", &reviewdog.Result{Diagnostics: []*rdf.Diagnostic{
+		{Message: "main.go", Location: &rdf.Location{Path: "main.go"}},
+		{Message: "main_test.go", Location: &rdf.Location{Path: "main_test.go"}},
+		{Message: "testdata", Location: &rdf.Location{Path: "testdata/a.go"}},
+	}})
+	opt := &option{filterMode: filter.ModeAdded}
+	if err := opt.includeFiles.Set("*.go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := opt.excludeFiles.Set("*_test.go"); err != nil {
+		t.Fatal(err)
+	}
+	got := postedMessages(t, &resultSet, opt, &doghouseRun{})
+	if diff := cmp.Diff([]string{"main.go", "testdata"}, got); diff != "" {
+		t.Errorf("posted annotations have diff:\n%s", diff)
+	}
+}
+
+func TestPostResultSet_baseline(t *testing.T) {
+	newResultSet := func() *reviewdog.ResultMap {
+		var resultSet reviewdog.ResultMap
+		resultSet.Store("name1", &reviewdog.Result{Diagnostics: []*rdf.Diagnostic{
+			{Message: "known", Location: &rdf.Location{Path: "reviewdog.go"}},
+		}})
+		return &resultSet
+	}
+	opt := &option{filterMode: filter.ModeAdded}
+
+	recorder := filter.NewBaseline()
+	got := postedMessages(t, newResultSet(), opt, &doghouseRun{baselineRecorder: recorder})
+	if diff := cmp.Diff([]string{"known"}, got); diff != "" {
+		t.Errorf("posted annotations with baseline recorder have diff:\n%s", diff)
+	}
+	if recorder.Len() != 1 {
+		t.Fatalf("recorded %d results, want 1", recorder.Len())
+	}
+
+	resultSet := newResultSet()
+	resultSet.Store("name2", &reviewdog.Result{Diagnostics: []*rdf.Diagnostic{
+		{Message: "known", Location: &rdf.Location{Path: "reviewdog.go"}},
+	}})
+	resultSet.Store("name1", &reviewdog.Result{Diagnostics: []*rdf.Diagnostic{
+		{Message: "known", Location: &rdf.Location{Path: "reviewdog.go"}},
+		{Message: "new", Location: &rdf.Location{Path: "reviewdog.go"}},
+	}})
+	got = postedMessages(t, resultSet, opt, &doghouseRun{baseline: recorder})
+	sort.Strings(got)
+	if diff := cmp.Diff([]string{"known", "new"}, got); diff != "" {
+		t.Errorf("posted annotations with baseline have diff:\n%s", diff)
+	}
+}
+
+func TestPostResultSet_pathRewrites(t *testing.T) {
+	var paths []string
+	fakeCli := &fakeDoghouseServerCli{}
+	fakeCli.FakeCheck = func(ctx context.Context, req *doghouse.CheckRequest) (*doghouse.CheckResponse, error) {
+		for _, a := range req.Annotations {
+			paths = append(paths, a.Diagnostic.GetLocation().GetPath())
+		}
+		return &doghouse.CheckResponse{ReportURL: "xxx"}, nil
+	}
+
+	// It assumes the current dir is ./cmd/reviewdog/
+	var resultSet reviewdog.ResultMap
+	resultSet.Store("name1", &reviewdog.Result{Diagnostics: []*rdf.Diagnostic{
+		{Message: "rewritten", Location: &rdf.Location{Path: "sub/doghouse.go"}},
+		{Message: "as is", Location: &rdf.Location{Path: "main.go"}},
+	}})
+	var rules filter.PathRewrites
+	if err := rules.Set("^sub/="); err != nil {
+		t.Fatal(err)
+	}
+	ghInfo := &cienv.BuildInfo{Owner: "haya14busa", Repo: "reviewdog", PullRequest: 14, SHA: "1414"}
+	opt := &option{filterMode: filter.ModeAdded, pathRewrites: rules}
+	if _, err := postResultSet(context.Background(), &resultSet, ghInfo, fakeCli, opt, &doghouseRun{}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"cmd/reviewdog/doghouse.go", "cmd/reviewdog/main.go"}, paths); diff != "" {
+		t.Errorf("annotation paths have diff:\n%s", diff)
+	}
+}
+
+func TestPostResultSet_summary(t *testing.T) {
+	fakeCli := &fakeDoghouseServerCli{}
+	fakeCli.FakeCheck = func(ctx context.Context, req *doghouse.CheckRequest) (*doghouse.CheckResponse, error) {
+		return &doghouse.CheckResponse{
+			ReportURL: "xxx",
+			CheckedResults: []*filter.FilteredDiagnostic{
+				{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_ERROR}, ShouldReport: true},
+				{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_WARNING}, ShouldReport: true},
+				{Diagnostic: &rdf.Diagnostic{Severity: rdf.Severity_ERROR}, ShouldReport: false},
+			},
+		}, nil
+	}
+	var resultSet reviewdog.ResultMap
+	resultSet.Store("name1", &reviewdog.Result{Diagnostics: []*rdf.Diagnostic{}})
+	ghInfo := &cienv.BuildInfo{Owner: "haya14busa", Repo: "reviewdog", PullRequest: 14, SHA: "1414"}
+
+	dr := &doghouseRun{summary: reviewdog.NewRunSummary()}
+	opt := &option{filterMode: filter.ModeAdded, failOnSeverity: filter.SeverityLevelError, failThreshold: 1}
+	if _, err := postResultSet(context.Background(), &resultSet, ghInfo, fakeCli, opt, dr); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := dr.summary.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "total": 2,
+  "severities": {
+    "error": 1,
+    "warning": 1
+  },
+  "tools": {
+    "name1": 2
+  },
+  "fail_level_exceeded": false
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("summary (-want +got):\n%s", diff)
+	}
+}
+
+func TestPostResultSet_emptyMessages(t *testing.T) {
+	newResultSet := func() *reviewdog.ResultMap {
+		var resultSet reviewdog.ResultMap
+		resultSet.Store("name1", &reviewdog.Result{Diagnostics: []*rdf.Diagnostic{
+			{Message: "message", Location: &rdf.Location{Path: "reviewdog.go"}},
+			{Message: " \n", Location: &rdf.Location{Path: "reviewdog.go"}},
+		}})
+		return &resultSet
+	}
+
+	got := postedMessages(t, newResultSet(), &option{filterMode: filter.ModeAdded}, &doghouseRun{})
+	if diff := cmp.Diff([]string{"message"}, got); diff != "" {
+		t.Errorf("posted annotations have diff:\n%s", diff)
+	}
+	got = postedMessages(t, newResultSet(), &option{filterMode: filter.ModeAdded, keepEmptyMessages: true}, &doghouseRun{})
+	if diff := cmp.Diff([]string{"message", " \n"}, got); diff != "" {
+		t.Errorf("posted annotations with -keep-empty-messages have diff:\n%s", diff)
+	}
+}
+
 func TestPostResultSet_withEmptyResponse(t *testing.T) {
 	const (
 		owner = "haya14busa"
@@ -414,7 +666,7 @@ func TestPostResultSet_withEmptyResponse(t *testing.T) {
 	ghInfo := &cienv.BuildInfo{Owner: owner, Repo: repo, PullRequest: prNum, SHA: sha}
 
 	opt := &option{filterMode: filter.ModeAdded}
-	if _, err := postResultSet(context.Background(), &resultSet, ghInfo, fakeCli, opt); err == nil {
+	if _, err := postResultSet(context.Background(), &resultSet, ghInfo, fakeCli, opt, &doghouseRun{}); err == nil {
 		t.Error("got no error but want report missing error")
 	}
 }
@@ -549,3 +801,42 @@ func absPath(t *testing.T, path string) string {
 	}
 	return p
 }
+
+func TestCheckIdempotencyKey(t *testing.T) {
+	newReq := func(msg string) *doghouse.CheckRequest {
+		return &doghouse.CheckRequest{
+			Name: "linter",
+			SHA:  "1414",
+			Annotations: []*doghouse.Annotation{
+				{Diagnostic: &rdf.Diagnostic{Message: msg, Location: &rdf.Location{Path: "a.go"}}},
+			},
+		}
+	}
+	cleanup := setupEnvs(map[string]string{
+		"GITHUB_RUN_ID":   "",
+		"GITHUB_WORKFLOW": "",
+		"GITHUB_JOB":      "",
+	})
+	defer cleanup()
+	if got := checkIdempotencyKey(newReq("m")); got != "" {
+		t.Errorf("got key %q outside GitHub Actions, want empty", got)
+	}
+
+	os.Setenv("GITHUB_RUN_ID", "1")
+	os.Setenv("GITHUB_WORKFLOW", "reviewdog")
+	os.Setenv("GITHUB_JOB", "lint")
+	key := checkIdempotencyKey(newReq("m"))
+	if key == "" {
+		t.Fatal("got empty key in GitHub Actions")
+	}
+	if got := checkIdempotencyKey(newReq("m")); got != key {
+		t.Errorf("got key %q for the retry, want %q", got, key)
+	}
+	if got := checkIdempotencyKey(newReq("other")); got == key {
+		t.Error("got the same key for different annotations")
+	}
+	os.Setenv("GITHUB_RUN_ID", "2")
+	if got := checkIdempotencyKey(newReq("m")); got == key {
+		t.Error("got the same key for another workflow run")
+	}
+}
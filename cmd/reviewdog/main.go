@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"flag"
 	"fmt"
@@ -13,8 +14,10 @@ import (
 	"os"
 	"os/exec"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"golang.org/x/build/gerrit"
 	"golang.org/x/oauth2"
@@ -28,13 +31,17 @@ import (
 	"github.com/reviewdog/reviewdog/cienv"
 	"github.com/reviewdog/reviewdog/commands"
 	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/metrics"
 	"github.com/reviewdog/reviewdog/parser"
 	"github.com/reviewdog/reviewdog/project"
+	azureservice "github.com/reviewdog/reviewdog/service/azuredevops"
 	bbservice "github.com/reviewdog/reviewdog/service/bitbucket"
+	"github.com/reviewdog/reviewdog/service/commentutil"
 	gerritservice "github.com/reviewdog/reviewdog/service/gerrit"
 	githubservice "github.com/reviewdog/reviewdog/service/github"
 	"github.com/reviewdog/reviewdog/service/github/githubutils"
 	gitlabservice "github.com/reviewdog/reviewdog/service/gitlab"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 const usageMessage = "" +
@@ -47,7 +54,10 @@ type option struct {
 	version          bool
 	diffCmd          string
 	diffStrip        int
+	diffFile         string
+	diffBase         string
 	efms             strslice
+	inputFiles       strslice
 	f                string // format name
 	fDiffStrip       int
 	list             bool   // list supported errorformat name
@@ -60,20 +70,70 @@ type option struct {
 	tee              bool
 	filterMode       filter.Mode
 	failOnError      bool
+	filterSeverity   filter.SeverityLevel
+	failOnSeverity   filter.SeverityLevel
+	failThreshold    int
+	validateOnly     bool
+	selfTest         bool
+
+	suggestionConflict filter.SuggestionConflictMode
+
+	commentTemplate          string
+	commentTemplateFile      string
+	commentSnippetLines      int
+	commentSuggestionPreview int
+	commentMaxLength         int
+	commentSeverity          string
+
+	sarifFile   string
+	junitFile   string
+	execCmd     string
+	summaryFile string
+	metrics     string
+
+	commentMode commentutil.CommentMode
+	fingerprint bool
+
+	reportNoResults bool
+
+	tabWidth int
+
+	ignoreAnnotations bool
+	keepEmptyMessages bool
+	includeTools      filter.ToolPatterns
+	excludeTools      filter.ToolPatterns
+	includeFiles      filter.FilePatterns
+	excludeFiles      filter.FilePatterns
+	baseline          string
+	updateBaseline    bool
+	labels            strslice
+
+	diffContextExpansion int
+	collapseDuplicates   int
+	maxComments          int
+	skipEmptyDiff        bool
+	pathRewrites         filter.PathRewrites
+	severityMap          parser.SeverityMap
+
+	timeout time.Duration
+
+	logLevel serviceutil.LogLevel
 }
 
 const (
 	diffCmdDoc    = `diff command (e.g. "git diff") for local reporter. Do not use --relative flag for git command.`
 	diffStripDoc  = "strip NUM leading components from diff file names (equivalent to 'patch -p') (default is 1 for git diff)"
-	efmsDoc       = `list of supported machine-readable format and errorformat (https://github.com/reviewdog/errorformat)`
-	fDoc          = `format name (run -list to see supported format name) for input. It's also used as tool name in review comment if -name is empty`
+	diffFileDoc   = `unified diff file path to filter results with instead of -diff or the diff of reporters (e.g. a diff artifact of CI). It doesn't need git. Use -strip to set strip level`
+	diffBaseDoc   = `git revision to diff HEAD against to filter results with instead of -diff or the diff of reporters (e.g. for push builds without Pull Requests). The diff is taken from the merge-base of the revision and HEAD. "push" means the commit before the push in GitHub Actions and GitLab CI, and the merge-base with the default branch is used if it's not found (e.g. force-pushes or new branches)`
+	efmsDoc       = `list of supported machine-readable format and errorformat (https://github.com/reviewdog/errorformat). "--" separates errorformats into fallback sets: lines which no errorformat of a set matches are parsed with the next set`
+	fDoc          = `format name (run -list to see supported format name) for input. It's also used as tool name in review comment if -name is empty. Comma separated format names with "##reviewdog -f=<format name>" delimiter lines in input can be used to merge multiple formats`
 	fDiffStripDoc = `option for -f=diff: strip NUM leading components from diff file names (equivalent to 'patch -p') (default is 1 for git diff)`
 	listDoc       = `list supported pre-defined format names which can be used as -f arg`
 	nameDoc       = `tool name in review comment. -f is used as tool name if -name is empty`
 
 	confDoc             = `config file path`
 	runnersDoc          = `comma separated runners name to run in config file. default: run all runners`
-	levelDoc            = `report level currently used for github-pr-check reporter ("info","warning","error").`
+	levelDoc            = `report level currently used for github-pr-check and github-annotations reporters ("info","warning","error").`
 	guessPullRequestDoc = `guess Pull Request ID by branch name and commit SHA`
 	teeDoc              = `enable "tee"-like mode which outputs tools's output as is while reporting results to -reporter. Useful for debugging as well.`
 	filterModeDoc       = `how to filter checks results. [added, diff_context, file, nofilter].
@@ -87,10 +147,40 @@ const (
 		"nofilter"
 			Do not filter any results.
 `
-	reporterDoc = `reporter of reviewdog results. (local, github-check, github-pr-check, github-pr-review, gitlab-mr-discussion, gitlab-mr-commit)
+	reporterDoc = `reporter of reviewdog results. (local, teamcity, sarif, junit, github-annotations, suggestion-diff, exec, github-check, github-pr-check, github-pr-review, gitlab-mr-discussion, gitlab-mr-commit)
 	"local" (default)
 		Report results to stdout.
 
+	"teamcity"
+		Report results to stdout as TeamCity inspection service messages.
+
+	"sarif"
+		Write results to -sarif-file as a SARIF 2.1.0 log (e.g. for GitHub code
+		scanning).
+
+	"junit"
+		Write results to -junit-file as a JUnit XML report, so that CI services
+		show them in test reports. It writes one testsuite per tool and one failed
+		testcase per result.
+
+	"github-annotations"
+		Report results to stdout as GitHub Actions workflow commands
+		(::error, ::warning and ::notice) to create annotations. It doesn't need
+		Pull Requests nor tokens, so it works for push events too. Results
+		without severity are reported with -level.
+
+	"suggestion-diff"
+		Write one unified diff of suggested fixes of results (suggestions of
+		rdjson/rdjsonl or diff input) to stdout instead of results, e.g. to
+		apply them locally with ` + "`git apply`" + `. Overlapping suggestions are
+		skipped.
+
+	"exec"
+		Pipe results to -exec-cmd as rdjsonl (one JSON of Diagnostic message per
+		line) on stdin, so that you can deliver results anywhere. Results
+		without source get -name as source.name. reviewdog fails if the command
+		exits with non-zero code.
+
 	"github-check"
 		Report results to GitHub Check. It works both for Pull Requests and commits.
 		For Pull Request, you can see report results in GitHub PullRequest Check
@@ -125,6 +215,19 @@ const (
 
 		For GitHub Enterprise:
 			$ export GITHUB_API="https://example.githubenterprise.com/api/v3"
+		The upload API URL is derived from it (/api/uploads).
+
+		It waits for GitHub rate limits up to 1m per API call by default.
+		Set REVIEWDOG_GITHUB_RATE_LIMIT_MAX_WAIT (e.g. 5m) to change it.
+
+		Reviews are submitted with COMMENT event by default. Set
+		REVIEWDOG_GITHUB_REVIEW_REQUEST_CHANGES to a severity level [any, info,
+		warning, error] to request changes if results of the level or higher are
+		found, and REVIEWDOG_GITHUB_REVIEW_APPROVE=true to approve Pull
+		Requests without results.
+
+		Set REVIEWDOG_GITHUB_REVIEW_THREAD_BY_CODE=true to post results with
+		the same code as replies to one review comment.
 
 	"gitlab-mr-discussion"
 		Report results to GitLab MergeRequest discussion.
@@ -136,10 +239,27 @@ const (
 		Alternatively, GITLAB_API can also be defined, and it will take precedence over the former:
 			$ export GITLAB_API="https://example.gitlab.com/api/v4"
 
+		Set REVIEWDOG_GITLAB_RESOLVE_STALE_DISCUSSIONS=true to resolve
+		discussions previously created by reviewdog whose results are not
+		reported anymore.
+
+		Set REVIEWDOG_GITLAB_APPROVE=true to approve the MergeRequest as the
+		token user if results don't exceed -fail-on-severity and
+		-fail-threshold, or revoke the approval otherwise.
+
 	"gitlab-mr-commit"
 		Same as gitlab-mr-discussion, but report results to GitLab comments for
 		each commits in Merge Requests.
 
+	"gitlab-commit-status"
+		Set GitLab commit status of the commit to failed if results exceed
+		-fail-on-severity and -fail-threshold, or success otherwise, with a
+		description of result counts. It works without Merge Requests.
+
+		Set REVIEWDOG_GITLAB_API_TOKEN as gitlab-mr-discussion does.
+		REVIEWDOG_GITLAB_COMMIT_STATUS_NAME sets the name of the status
+		(default: reviewdog).
+
 	"gerrit-change-review"
 		Report results to Gerrit Change comments.
 
@@ -152,6 +272,46 @@ const (
 			$ export GERRIT_REVISION_ID=ed318bf9a3c
 			$ export GERRIT_BRANCH=master
 			$ export GERRIT_ADDRESS=http://localhost:8080
+		GERRIT_PATCHSET_REVISION (set by Gerrit Trigger plugin of Jenkins) is
+		used if GERRIT_REVISION_ID is not set.
+
+		Comments are posted in batches of 500 comments per request by default.
+		Set GERRIT_REVIEWDOG_BATCH_SIZE to change the batch size.
+
+		Set GERRIT_REVIEWDOG_DRY_RUN=true to print review requests to stdout
+		instead of posting them.
+
+		Set GERRIT_REVIEWDOG_LABEL to vote on the label with the review. It votes
+		GERRIT_REVIEWDOG_LABEL_FAIL (default: -1) if any results are reported,
+		otherwise GERRIT_REVIEWDOG_LABEL_PASS (default: 1).
+			$ export GERRIT_REVIEWDOG_LABEL=Verified
+
+		Set GERRIT_REVIEWDOG_REPORT_OUTSIDE_DIFF=true to list results outside
+		the diff in the review message (e.g. with -filter-mode=nofilter).
+
+		Transient failures (network errors and 5xx responses) are retried
+		GERRIT_REVIEWDOG_RETRY_COUNT (default: 3) times with exponential backoff
+		starting from GERRIT_REVIEWDOG_RETRY_DELAY (default: 1s).
+
+		The diff is taken against the merge-base of GERRIT_BRANCH and the
+		current revision. Set GERRIT_REVIEWDOG_DIFF_BASE to diff against another
+		revision instead, and GERRIT_REVIEWDOG_DIFF_TARGET to diff another
+		revision than the current one. Both accept commits and patchset numbers
+		(e.g. GERRIT_REVIEWDOG_DIFF_BASE=2 to review changes since patchset 2).
+
+		The same results reported by the same tool at the same line are posted
+		only once. Set GERRIT_REVIEWDOG_NO_DEDUP=true to post all of them.
+
+		Set GERRIT_REVIEWDOG_HASHTAG (e.g. reviewdog) to add the hashtag to
+		changes which receive comments. Failures to add it are only logged.
+
+		Paths of results are relative to the current directory. Set
+		GERRIT_REVIEWDOG_WORKDIR to use another directory in the repository.
+
+		Requests to Gerrit go through proxies set by HTTPS_PROXY, HTTP_PROXY
+		and NO_PROXY. Set GERRIT_REVIEWDOG_CA_FILE to trust a PEM CA bundle in
+		addition to system certificates, and GERRIT_REVIEWDOG_HTTP_TIMEOUT
+		(e.g. 30s) to time out requests.
 	
 	"bitbucket-code-report"
 		Create Bitbucket Code Report via Code Insights
@@ -167,6 +327,20 @@ const (
 		
 		To post results to Bitbucket Server specify BITBUCKET_SERVER_URL.
 
+	"bitbucket-server-code-report"
+		Same as bitbucket-code-report, but always post results to Bitbucket
+		Server (Data Center) Code Insights API (/rest/insights/1.0/...).
+		BITBUCKET_SERVER_URL is required.
+
+	"azure-devops-pr-thread"
+		Report results to Azure DevOps pull request threads.
+
+		In Azure Pipelines, set AZURE_DEVOPS_TOKEN to a personal access token
+		(PAT) or $(System.AccessToken). Other data are taken from predefined
+		variables. Outside of Azure Pipelines, also set AZURE_DEVOPS_URL (e.g.
+		https://dev.azure.com/myorg), AZURE_DEVOPS_PROJECT, AZURE_DEVOPS_REPO and
+		AZURE_DEVOPS_PULL_REQUEST_ID.
+
 	For GitHub Enterprise and self hosted GitLab, set
 	REVIEWDOG_INSECURE_SKIP_VERIFY to skip verifying SSL (please use this at your own risk)
 		$ export REVIEWDOG_INSECURE_SKIP_VERIFY=true
@@ -181,7 +355,61 @@ const (
 		$ export CI_REPO_OWNER="haya14busa" # repository owner
 		$ export CI_REPO_NAME="reviewdog" # repository name
 `
-	failOnErrorDoc = `Returns 1 as exit code if any errors/warnings found in input`
+	failOnErrorDoc    = `Returns 1 as exit code if any errors/warnings found in input`
+	filterSeverityDoc = `lowest severity of results to report. [any, info, warning, error] (default: any)
+		Results without severity are always reported.`
+	failOnSeverityDoc = `lowest severity of reported results counted to fail with exit code 1. [any, info, warning, error] (default: any)
+		Results without severity are always counted. It implies -fail-on-error.`
+	failThresholdDoc      = `max number of reported results counted by -fail-on-severity which doesn't fail. 0 means failing on any counted results. It implies -fail-on-error.`
+	suggestionConflictDoc = `how to resolve overlapping suggestions proposed by different results. [keep-first, drop-all] (default: keep-first)
+	"keep-first"
+		Keep the first suggestion and drop later ones which overlap it.
+	"drop-all"
+		Drop all overlapping suggestions.
+	Results themselves are reported either way.`
+	commentTemplateDoc = `Go text/template for comment bodies of github-pr-review, gitlab-mr-discussion, gitlab-mr-commit, gerrit-change-review and azure-devops-pr-thread reporters.
+	Available fields: .ToolName, .Severity, .Message, .Path, .Line, .Code, .CodeURL, .BodyPrefix, .Labels (see -label), .Diagnostic and .Comment.
+	e.g. -comment-template='**[{{.ToolName}}]** {{.BodyPrefix}}{{.Message}}'`
+	commentTemplateFileDoc      = `file path of comment template. See -comment-template`
+	commentSnippetLinesDoc      = `max number of source lines of results included in comments of github-pr-review, gitlab-mr-discussion, gitlab-mr-commit and gerrit-change-review reporters as a code block. 0 means no snippets`
+	commentSuggestionPreviewDoc = `max number of lines of each side of a diff of suggestions (source lines and their replacements) included in comments of github-pr-review, gitlab-mr-discussion, gitlab-mr-commit, gerrit-change-review and azure-devops-pr-thread reporters, in addition to native suggestions. 0 means no diffs`
+	commentSeverityDoc          = `prefix of result messages in comments per severity. "emoji" (❌, ⚠️ and ℹ️), "text" ([ERROR], [WARNING] and [INFO]) or comma separated severity=marker pairs of error, warning, info and other (e.g. error=[E],warning=[W]). It replaces the default severity emojis of comments. default: no prefix`
+	commentMaxLengthDoc         = `max length of result messages in comments in bytes. Longer messages are truncated with a note. 0 means the default limit of each reporter (github-pr-review: 60000, gitlab-mr-discussion and gitlab-mr-commit: 900000, gerrit-change-review: 15000, azure-devops-pr-thread: 140000)`
+	sarifFileDoc                = `output file path of sarif reporter`
+	junitFileDoc                = `output file path of junit reporter`
+	execCmdDoc                  = `command of exec reporter which reads results as rdjsonl from stdin (e.g. "./post-results.sh --channel lint")`
+	metricsDoc                  = `where to send metrics of the run such as durations of parse, diff, filter and report stages, the number of API calls and results. "-" writes JSON lines to stdout, "statsd://HOST:PORT" sends them to statsd, and other values are output file paths of JSON lines. default: no metrics`
+	summaryFileDoc              = `output file path of a JSON summary of the run (total results, results per severity and per tool, and whether results of -fail-on-severity exceed -fail-threshold), which is written after all reporters flush. "-" writes it to stdout. default: no summary`
+	commentModeDoc              = `how github-pr-review, gitlab-mr-discussion and gitlab-mr-commit reporters post results. [inline, summary, both] (default: inline)
+	"inline"
+		Post results as inline comments.
+	"summary"
+		Post one summary comment which groups results by file and severity instead of inline comments.
+	"both"
+		Post both inline comments and a summary comment.`
+	reportNoResultsDoc      = `post a message which tells no results are found when a run reports none, so that reviewers can tell reviewdog ran. It's for github-pr-review, gitlab-mr-discussion, gitlab-mr-commit and gerrit-change-review reporters. github-check, github-pr-check and gitlab-commit-status always report passing statuses`
+	fingerprintDoc          = `embed a fingerprint (hash of path, message and code) of each result in comments of github-pr-review, gitlab-mr-discussion and gitlab-mr-commit reporters, and skip results whose fingerprint is found in existing comments even if they moved to other lines`
+	diffContextExpansionDoc = `number of lines around changed lines whose results are also reported with -filter-mode=diff_context even if they are outside diff hunks. 0 means no expansion`
+	ignoreAnnotationsDoc    = `drop results whose line has a "reviewdog:ignore" annotation comment. "reviewdog:ignore code1,code2" drops results with the codes only`
+	keepEmptyMessagesDoc    = `report results whose message is empty or whitespace only. They are dropped by default`
+	includeToolsDoc         = `comma separated glob patterns of tool names whose results are reported. Tool names are source names of results (source.name of rdjson) or -name. default: all tools`
+	excludeToolsDoc         = `comma separated glob patterns of tool names whose results are dropped. See -include-tools`
+	includeFilesDoc         = `comma separated gitignore-style patterns of file paths whose results are reported (e.g. "src/**,*.go"). Paths are relative to the working directory. default: all files`
+	excludeFilesDoc         = `comma separated gitignore-style patterns of file paths whose results are dropped (e.g. "vendor/,*.pb.go"). See -include-files`
+	baselineDoc             = `baseline file path of known results. Results in the baseline are not reported, so only new results are reported. Results are identified by fingerprints of the tool name, path, message and code, which don't change when results move to other lines`
+	updateBaselineDoc       = `write all the current results, which are not filtered by diff, to the -baseline file instead of reading it. Results are reported as usual. The file is not written if the run fails (e.g. with -fail-on-error)`
+	labelDoc                = `label of results in key=value format (e.g. -label=team=backend), which comment templates can refer to as {{.Labels.team}}. Can be specified multiple times. Labels of runners in the project config override it`
+	collapseDuplicatesDoc   = `report results with the same tool, code and message as one comment listing all the locations if there are more than N of them. Only comment reporters (e.g. github-pr-review) collapse them and the other reporters report each result. 0 reports each result separately`
+	maxCommentsDoc          = `max number of results posted as individual comments per tool. Results with higher severity are posted first and the others are summarized in one comment. Only comment reporters (e.g. github-pr-review) apply it and the other reporters report all the results. 0 means no limit`
+	pathRewriteDoc          = `rewrite paths of results in REGEXP=REPLACEMENT format before filtering them, e.g. -path-rewrite='^=sub/' adds the prefix, -path-rewrite='^sub/=' strips it and -path-rewrite='^old/=new/' replaces it. Rewritten paths should be relative to the current directory. Can be specified multiple times and the first matching rule is applied`
+	severityMapDoc          = `map a tool-specific severity level of -f or -efm input to error, warning or info in LEVEL=SEVERITY format (e.g. -severity-map=blocker=error). Levels are matched case-insensitively. Can be specified multiple times. Unmapped levels use the built-in mapping (e.g. fatal to error, note and style to info)`
+	skipEmptyDiffDoc        = `skip reporting and exit with 0 when the diff is empty, so that reporters don't update stale comments or statuses. It doesn't affect -filter-mode=nofilter`
+	timeoutDoc              = `timeout of the whole run such as parsing input, getting diff and posting results (e.g. 10m). The run is cancelled and fails when it's exceeded. 0 means no timeout`
+	logLevelDoc             = `log level of github-pr-review, gitlab-mr-discussion, gitlab-mr-commit and gerrit-change-review reporters and the number of input lines which -efm doesn't match (info). [debug, info, warn, none] (default: warn)`
+	tabWidthDoc             = `tab width to translate result columns, which count a tab as one column, into rendered columns before posting. 0 means no translation`
+	inputFileDoc            = `input file path of -f or -efm format to read instead of stdin. Can be specified multiple times to parse the files in sequence with the same parser and report the merged results`
+	validateOnlyDoc         = `parse and validate input with -f or -efm without reporting results`
+	selfTestDoc             = `check that reviewdog can access the service of -reporter with the configured credentials by reading the target (e.g. the PullRequest or the Gerrit change) without reading input or posting anything`
 )
 
 var opt = &option{}
@@ -190,9 +418,12 @@ func init() {
 	flag.BoolVar(&opt.version, "version", false, "print version")
 	flag.StringVar(&opt.diffCmd, "diff", "", diffCmdDoc)
 	flag.IntVar(&opt.diffStrip, "strip", 1, diffStripDoc)
+	flag.StringVar(&opt.diffFile, "diff-file", "", diffFileDoc)
+	flag.StringVar(&opt.diffBase, "diff-base", "", diffBaseDoc)
 	flag.Var(&opt.efms, "efm", efmsDoc)
 	flag.StringVar(&opt.f, "f", "", fDoc)
 	flag.IntVar(&opt.fDiffStrip, "f.diff.strip", 1, fDiffStripDoc)
+	flag.Var(&opt.inputFiles, "input-file", inputFileDoc)
 	flag.BoolVar(&opt.list, "list", false, listDoc)
 	flag.StringVar(&opt.name, "name", "", nameDoc)
 	flag.StringVar(&opt.conf, "conf", "", confDoc)
@@ -203,6 +434,44 @@ func init() {
 	flag.BoolVar(&opt.tee, "tee", false, teeDoc)
 	flag.Var(&opt.filterMode, "filter-mode", filterModeDoc)
 	flag.BoolVar(&opt.failOnError, "fail-on-error", false, failOnErrorDoc)
+	flag.Var(&opt.filterSeverity, "filter-severity", filterSeverityDoc)
+	flag.Var(&opt.failOnSeverity, "fail-on-severity", failOnSeverityDoc)
+	flag.IntVar(&opt.failThreshold, "fail-threshold", 0, failThresholdDoc)
+	flag.BoolVar(&opt.validateOnly, "validate-only", false, validateOnlyDoc)
+	flag.BoolVar(&opt.selfTest, "self-test", false, selfTestDoc)
+	flag.Var(&opt.suggestionConflict, "suggestion-conflict", suggestionConflictDoc)
+	flag.StringVar(&opt.commentTemplate, "comment-template", "", commentTemplateDoc)
+	flag.StringVar(&opt.commentTemplateFile, "comment-template-file", "", commentTemplateFileDoc)
+	flag.IntVar(&opt.commentSnippetLines, "comment-snippet-lines", 0, commentSnippetLinesDoc)
+	flag.IntVar(&opt.commentSuggestionPreview, "comment-suggestion-preview", 0, commentSuggestionPreviewDoc)
+	flag.IntVar(&opt.commentMaxLength, "comment-max-length", 0, commentMaxLengthDoc)
+	flag.StringVar(&opt.commentSeverity, "comment-severity-prefix", "", commentSeverityDoc)
+	flag.StringVar(&opt.sarifFile, "sarif-file", "reviewdog.sarif", sarifFileDoc)
+	flag.StringVar(&opt.junitFile, "junit-file", "reviewdog-junit.xml", junitFileDoc)
+	flag.StringVar(&opt.execCmd, "exec-cmd", "", execCmdDoc)
+	flag.StringVar(&opt.summaryFile, "summary-file", "", summaryFileDoc)
+	flag.StringVar(&opt.metrics, "metrics", "", metricsDoc)
+	flag.Var(&opt.commentMode, "comment-mode", commentModeDoc)
+	flag.BoolVar(&opt.fingerprint, "fingerprint", false, fingerprintDoc)
+	flag.BoolVar(&opt.reportNoResults, "report-no-results", false, reportNoResultsDoc)
+	flag.IntVar(&opt.tabWidth, "tab-width", 0, tabWidthDoc)
+	flag.BoolVar(&opt.ignoreAnnotations, "ignore-annotations", false, ignoreAnnotationsDoc)
+	flag.BoolVar(&opt.keepEmptyMessages, "keep-empty-messages", false, keepEmptyMessagesDoc)
+	flag.Var(&opt.includeTools, "include-tools", includeToolsDoc)
+	flag.Var(&opt.excludeTools, "exclude-tools", excludeToolsDoc)
+	flag.Var(&opt.includeFiles, "include-files", includeFilesDoc)
+	flag.Var(&opt.excludeFiles, "exclude-files", excludeFilesDoc)
+	flag.StringVar(&opt.baseline, "baseline", "", baselineDoc)
+	flag.BoolVar(&opt.updateBaseline, "update-baseline", false, updateBaselineDoc)
+	flag.Var(&opt.labels, "label", labelDoc)
+	flag.IntVar(&opt.diffContextExpansion, "diff-context-expansion", 0, diffContextExpansionDoc)
+	flag.IntVar(&opt.collapseDuplicates, "collapse-duplicates", 0, collapseDuplicatesDoc)
+	flag.IntVar(&opt.maxComments, "max-comments", 0, maxCommentsDoc)
+	flag.BoolVar(&opt.skipEmptyDiff, "skip-empty-diff", false, skipEmptyDiffDoc)
+	flag.Var(&opt.pathRewrites, "path-rewrite", pathRewriteDoc)
+	flag.Var(&opt.severityMap, "severity-map", severityMapDoc)
+	flag.DurationVar(&opt.timeout, "timeout", 0, timeoutDoc)
+	flag.Var(&opt.logLevel, "log-level", logLevelDoc)
 }
 
 func usage() {
@@ -225,6 +494,11 @@ func main() {
 
 func run(r io.Reader, w io.Writer, opt *option) error {
 	ctx := context.Background()
+	if opt.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.timeout)
+		defer cancel()
+	}
 
 	if opt.version {
 		fmt.Fprintln(w, commands.Version)
@@ -235,6 +509,10 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 		return runList(w)
 	}
 
+	if opt.selfTest {
+		return runSelfTest(ctx, w, opt)
+	}
+
 	if opt.tee {
 		r = io.TeeReader(r, w)
 	}
@@ -243,6 +521,66 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 	isProject := len(opt.efms) == 0 && opt.f == ""
 	var projectConf *project.Config
 
+	if len(opt.inputFiles) > 0 {
+		if isProject {
+			return errors.New("-input-file needs -f or -efm")
+		}
+		// Check files before getting diff or posting anything.
+		for _, path := range opt.inputFiles {
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("input file of -input-file is not readable: %w", err)
+			}
+		}
+	}
+
+	if opt.validateOnly {
+		if isProject {
+			return errors.New("-validate-only needs -f or -efm")
+		}
+		return runValidate(r, w, opt)
+	}
+
+	tmpl, err := commentTemplate(opt)
+	if err != nil {
+		return err
+	}
+
+	if opt.diffCmd != "" && opt.diffFile != "" {
+		return errors.New("you cannot specify both -diff and -diff-file")
+	}
+	if opt.diffBase != "" && (opt.diffCmd != "" || opt.diffFile != "") {
+		return errors.New("you cannot specify -diff-base with -diff or -diff-file")
+	}
+
+	if opt.updateBaseline && opt.baseline == "" {
+		return errors.New("-update-baseline needs -baseline")
+	}
+
+	if opt.failThreshold < 0 {
+		return errors.New("-fail-threshold must not be negative")
+	}
+	if opt.tabWidth < 0 {
+		return errors.New("-tab-width must not be negative")
+	}
+	if opt.diffContextExpansion < 0 {
+		return errors.New("-diff-context-expansion must not be negative")
+	}
+	if opt.collapseDuplicates < 0 {
+		return errors.New("-collapse-duplicates must not be negative")
+	}
+	if opt.maxComments < 0 {
+		return errors.New("-max-comments must not be negative")
+	}
+	if opt.timeout < 0 {
+		return errors.New("-timeout must not be negative")
+	}
+
+	sink, closeSink, err := metricsSink(opt.metrics, w)
+	if err != nil {
+		return err
+	}
+	defer closeSink()
+
 	var cs reviewdog.CommentService
 	var ds reviewdog.DiffService
 
@@ -262,11 +600,11 @@ func run(r io.Reader, w io.Writer, opt *option) error {
 	default:
 		return fmt.Errorf("unknown -reporter: %s", opt.reporter)
 	case "github-check":
-		return runDoghouse(ctx, r, w, opt, isProject, false)
+		return runDoghouse(ctx, r, w, opt, isProject, false, sink)
 	case "github-pr-check":
-		return runDoghouse(ctx, r, w, opt, isProject, true)
+		return runDoghouse(ctx, r, w, opt, isProject, true, sink)
 	case "github-pr-review":
-		gs, isPR, err := githubService(ctx, opt)
+		gs, isPR, err := githubService(ctx, opt, tmpl, sink)
 		if err != nil {
 			return err
 		}
@@ -291,7 +629,7 @@ github-pr-check reporter as a fallback.
 		}
 		ds = gs
 	case "gitlab-mr-discussion":
-		build, cli, err := gitlabBuildWithClient()
+		build, cli, err := gitlabBuildWithClient(sink)
 		if err != nil {
 			return err
 		}
@@ -300,18 +638,42 @@ github-pr-check reporter as a fallback.
 			return nil
 		}
 
-		gc, err := gitlabservice.NewGitLabMergeRequestDiscussionCommenter(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
+		gopts := []gitlabservice.MergeRequestDiscussionCommenterOption{
+			gitlabservice.WithDiscussionCommentTemplate(tmpl),
+			gitlabservice.WithDiscussionCommentMode(opt.commentMode),
+			gitlabservice.WithDiscussionLogger(serviceLogger(opt)),
+		}
+		if os.Getenv("REVIEWDOG_GITLAB_RESOLVE_STALE_DISCUSSIONS") == "true" {
+			gopts = append(gopts, gitlabservice.WithResolveStaleDiscussions())
+			if isProject {
+				// Runners flush the commenter one by one.
+				gopts = append(gopts, gitlabservice.WithDeferredStaleResolution())
+			}
+		}
+		if opt.fingerprint {
+			gopts = append(gopts, gitlabservice.WithDiscussionFingerprint())
+		}
+		if opt.reportNoResults {
+			gopts = append(gopts, gitlabservice.WithDiscussionReportNoResults())
+		}
+		if opt.commentMaxLength > 0 {
+			gopts = append(gopts, gitlabservice.WithDiscussionMaxMessageLength(opt.commentMaxLength))
+		}
+		gc, err := gitlabservice.NewGitLabMergeRequestDiscussionCommenter(cli, build.Owner, build.Repo, build.PullRequest, build.SHA, gopts...)
 		if err != nil {
 			return err
 		}
 
 		cs = reviewdog.MultiCommentService(gc, cs)
+		if os.Getenv("REVIEWDOG_GITLAB_APPROVE") == "true" {
+			cs = reviewdog.MultiCommentService(gitlabApprover(cli, build, opt), cs)
+		}
 		ds, err = gitlabservice.NewGitLabMergeRequestDiff(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
 		if err != nil {
 			return err
 		}
 	case "gitlab-mr-commit":
-		build, cli, err := gitlabBuildWithClient()
+		build, cli, err := gitlabBuildWithClient(sink)
 		if err != nil {
 			return err
 		}
@@ -320,33 +682,117 @@ github-pr-check reporter as a fallback.
 			return nil
 		}
 
-		gc, err := gitlabservice.NewGitLabMergeRequestCommitCommenter(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
+		gopts := []gitlabservice.MergeRequestCommitCommenterOption{
+			gitlabservice.WithCommitCommentTemplate(tmpl),
+			gitlabservice.WithCommitCommentMode(opt.commentMode),
+			gitlabservice.WithCommitLogger(serviceLogger(opt)),
+		}
+		if opt.fingerprint {
+			gopts = append(gopts, gitlabservice.WithCommitFingerprint())
+		}
+		if opt.reportNoResults {
+			gopts = append(gopts, gitlabservice.WithCommitReportNoResults())
+		}
+		if opt.commentMaxLength > 0 {
+			gopts = append(gopts, gitlabservice.WithCommitMaxMessageLength(opt.commentMaxLength))
+		}
+		gc, err := gitlabservice.NewGitLabMergeRequestCommitCommenter(cli, build.Owner, build.Repo, build.PullRequest, build.SHA, gopts...)
 		if err != nil {
 			return err
 		}
 
 		cs = reviewdog.MultiCommentService(gc, cs)
+		if os.Getenv("REVIEWDOG_GITLAB_APPROVE") == "true" {
+			cs = reviewdog.MultiCommentService(gitlabApprover(cli, build, opt), cs)
+		}
 		ds, err = gitlabservice.NewGitLabMergeRequestDiff(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
 		if err != nil {
 			return err
 		}
+	case "gitlab-commit-status":
+		build, cli, err := gitlabBuildWithClient(sink)
+		if err != nil {
+			return err
+		}
+		sopts := []gitlabservice.CommitStatusReporterOption{
+			gitlabservice.WithCommitStatusFailPolicy(opt.failOnSeverity, opt.failThreshold),
+			gitlabservice.WithCommitStatusLogger(serviceLogger(opt)),
+		}
+		if name := os.Getenv("REVIEWDOG_GITLAB_COMMIT_STATUS_NAME"); name != "" {
+			sopts = append(sopts, gitlabservice.WithCommitStatusName(name))
+		}
+		cs = reviewdog.MultiCommentService(gitlabservice.NewGitLabCommitStatusReporter(cli, build.Owner, build.Repo, build.SHA, sopts...), cs)
+		if build.PullRequest != 0 {
+			ds, err = gitlabservice.NewGitLabMergeRequestDiff(cli, build.Owner, build.Repo, build.PullRequest, build.SHA)
+			if err != nil {
+				return err
+			}
+		} else {
+			// There is no diff to filter results with outside Merge Requests.
+			if !(opt.filterMode == filter.ModeDefault || opt.filterMode == filter.ModeNoFilter) {
+				log.Printf("reviewdog: [gitlab-commit-status] uses filter.ModeNoFilter outside Merge Requests")
+			}
+			opt.filterMode = filter.ModeNoFilter
+			ds = &reviewdog.EmptyDiff{}
+		}
 	case "gerrit-change-review":
-		b, cli, err := gerritBuildWithClient()
+		b, cli, err := gerritBuildWithClient(sink)
 		if err != nil {
 			return err
 		}
-		gc, err := gerritservice.NewChangeReviewCommenter(cli, b.GerritChangeID, b.GerritRevisionID)
+		gopts, err := gerritCommenterOptions(w)
+		if err != nil {
+			return err
+		}
+		gopts = append(gopts, gerritservice.WithCommentTemplate(tmpl), gerritservice.WithLogger(serviceLogger(opt)))
+		if opt.reportNoResults {
+			gopts = append(gopts, gerritservice.WithReportNoResults())
+		}
+		if opt.commentMaxLength > 0 {
+			gopts = append(gopts, gerritservice.WithMaxMessageLength(opt.commentMaxLength))
+		}
+		gc, err := gerritservice.NewChangeReviewCommenter(cli, b.GerritChangeID, b.GerritRevisionID, gopts...)
 		if err != nil {
 			return err
 		}
 		cs = gc
 
-		d, err := gerritservice.NewChangeDiff(cli, b.Branch, b.GerritChangeID)
+		var dopts []gerritservice.ChangeDiffOption
+		if base := os.Getenv("GERRIT_REVIEWDOG_DIFF_BASE"); base != "" {
+			dopts = append(dopts, gerritservice.WithBaseRevision(base))
+		}
+		if target := os.Getenv("GERRIT_REVIEWDOG_DIFF_TARGET"); target != "" {
+			dopts = append(dopts, gerritservice.WithTargetRevision(target))
+		}
+		if dir := os.Getenv("GERRIT_REVIEWDOG_WORKDIR"); dir != "" {
+			dopts = append(dopts, gerritservice.WithDiffWorkdir(dir))
+		}
+		d, err := gerritservice.NewChangeDiff(cli, b.Branch, b.GerritChangeID, dopts...)
 		if err != nil {
 			return err
 		}
 		ds = d
-	case "bitbucket-code-report":
+	case "azure-devops-pr-thread":
+		b, cli, err := azureDevOpsBuildWithClient(sink)
+		if err != nil {
+			return err
+		}
+		aopts := []azureservice.PullRequestThreadCommenterOption{
+			azureservice.WithThreadCommentTemplate(tmpl),
+		}
+		if opt.commentMaxLength > 0 {
+			aopts = append(aopts, azureservice.WithThreadMaxMessageLength(opt.commentMaxLength))
+		}
+		ac, err := azureservice.NewPullRequestThreadCommenter(cli, b.project, b.repo, b.pr, aopts...)
+		if err != nil {
+			return err
+		}
+		cs = reviewdog.MultiCommentService(ac, cs)
+		ds = azureservice.NewPullRequestDiff(cli, b.project, b.repo, b.pr)
+	case "bitbucket-code-report", "bitbucket-server-code-report":
+		if opt.reporter == "bitbucket-server-code-report" && os.Getenv("BITBUCKET_SERVER_URL") == "" {
+			return errors.New("bitbucket-server-code-report reporter needs BITBUCKET_SERVER_URL")
+		}
 		build, client, ct, err := bitbucketBuildWithClient(ctx)
 		if err != nil {
 			return err
@@ -368,11 +814,29 @@ github-pr-check reporter as a fallback.
 		}
 		opt.filterMode = filter.ModeNoFilter
 		ds = &reviewdog.EmptyDiff{}
-	case "local":
+	case "local", "teamcity", "sarif", "junit", "github-annotations", "suggestion-diff", "exec":
+		switch opt.reporter {
+		case "teamcity":
+			cs = reviewdog.NewTeamCityCommentWriter(w)
+		case "github-annotations":
+			cs = githubutils.NewGitHubAnnotationWriter(w, opt.level)
+		case "sarif":
+			cs = reviewdog.NewSARIFCommentWriter(opt.sarifFile)
+		case "junit":
+			cs = reviewdog.NewJUnitCommentWriter(opt.junitFile)
+		case "suggestion-diff":
+			cs = reviewdog.NewSuggestionDiffWriter(w)
+		case "exec":
+			ec, err := execCommentWriter(opt.execCmd, w)
+			if err != nil {
+				return err
+			}
+			cs = ec
+		}
 		if opt.diffCmd == "" && opt.filterMode == filter.ModeNoFilter {
 			ds = &reviewdog.EmptyDiff{}
-		} else {
-			d, err := diffService(opt.diffCmd, opt.diffStrip)
+		} else if opt.diffFile == "" && opt.diffBase == "" {
+			d, err := diffService(ctx, opt.diffCmd, opt.diffStrip)
 			if err != nil {
 				return err
 			}
@@ -380,8 +844,44 @@ github-pr-check reporter as a fallback.
 		}
 	}
 
+	if opt.diffFile != "" {
+		ds = reviewdog.NewDiffFile(opt.diffFile, opt.diffStrip)
+	}
+	if opt.diffBase != "" {
+		ds = gitDiffService(opt.diffBase)
+	}
+
+	ropts := reviewdogOptions(opt)
+	knownBaseline, baseline, err := loadBaseline(opt)
+	if err != nil {
+		return err
+	}
+	if knownBaseline != nil {
+		ropts = append(ropts, reviewdog.WithBaseline(knownBaseline))
+	}
+	if baseline != nil {
+		ropts = append(ropts, reviewdog.WithBaselineRecorder(baseline))
+	}
+	labels, err := filter.ParseLabels(opt.labels)
+	if err != nil {
+		return err
+	}
+	if len(labels) > 0 {
+		ropts = append(ropts, reviewdog.WithLabeler(filter.StaticLabels(labels)))
+	}
+	var summary *reviewdog.RunSummary
+	if opt.summaryFile != "" {
+		summary = reviewdog.NewRunSummary()
+		ropts = append(ropts, reviewdog.WithRunSummary(summary))
+	}
+	if sink != nil {
+		ropts = append(ropts, reviewdog.WithMetrics(sink))
+	}
+
 	if isProject {
-		return project.Run(ctx, projectConf, buildRunnersMap(opt.runners), cs, ds, opt.tee, opt.filterMode, opt.failOnError)
+		err := project.Run(ctx, projectConf, buildRunnersMap(opt.runners), cs, ds, opt.tee, opt.filterMode, failOnError(opt), ropts...)
+		err = writeSummary(opt.summaryFile, summary, w, writeSuggestionDiff(cs, err))
+		return writeBaseline(opt.baseline, baseline, err)
 	}
 
 	p, err := newParserFromOpt(opt)
@@ -389,8 +889,187 @@ github-pr-check reporter as a fallback.
 		return err
 	}
 
-	app := reviewdog.NewReviewdog(toolName(opt), p, cs, ds, opt.filterMode, opt.failOnError)
-	return app.Run(ctx, r)
+	app := reviewdog.NewReviewdog(toolName(opt), p, cs, ds, opt.filterMode, failOnError(opt), ropts...)
+	runErr := app.Run(ctx, r)
+	if c, ok := p.(parser.UnmatchedCounter); ok && c.Unmatched() > 0 {
+		serviceLogger(opt).Infof("reviewdog: %d line(s) of input matched no errorformat pattern", c.Unmatched())
+	}
+	runErr = writeSummary(opt.summaryFile, summary, w, writeSuggestionDiff(cs, runErr))
+	return writeBaseline(opt.baseline, baseline, runErr)
+}
+
+// writeSummary writes the JSON summary to the file ("-" means w) if any and
+// returns runErr. The summary is written even if the run fails so that the
+// failure can be explained.
+func writeSummary(path string, s *reviewdog.RunSummary, w io.Writer, runErr error) error {
+	if s == nil {
+		return runErr
+	}
+	if path == "-" {
+		if err := s.WriteJSON(w); err != nil {
+			return fmt.Errorf("failed to write summary: %w", err)
+		}
+		return runErr
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	if err := s.WriteJSON(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return runErr
+}
+
+// writeSuggestionDiff writes the diff of suggestions if cs is the
+// suggestion-diff reporter and returns runErr. The diff is written even if
+// the run fails with -fail-on-error so that the fixes can be applied.
+func writeSuggestionDiff(cs reviewdog.CommentService, runErr error) error {
+	sw, ok := cs.(*reviewdog.SuggestionDiffWriter)
+	if !ok {
+		return runErr
+	}
+	if err := sw.WriteDiff(); err != nil {
+		return err
+	}
+	return runErr
+}
+
+// loadBaseline returns the baseline of known results to drop with -baseline,
+// or the recorder of the results to write to the baseline file with
+// -update-baseline. Both are nil without -baseline.
+func loadBaseline(opt *option) (known, recorder *filter.Baseline, err error) {
+	if opt.baseline == "" {
+		return nil, nil, nil
+	}
+	if opt.updateBaseline {
+		return nil, filter.NewBaseline(), nil
+	}
+	known, err = filter.LoadBaseline(opt.baseline)
+	if err != nil {
+		return nil, nil, err
+	}
+	return known, nil, nil
+}
+
+// writeBaseline writes the recorded baseline to the file if any and returns
+// runErr. The file is kept as is if the run fails so that a broken run
+// doesn't clear the baseline.
+func writeBaseline(path string, b *filter.Baseline, runErr error) error {
+	if b == nil || runErr != nil {
+		return runErr
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	if _, err := b.WriteTo(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	log.Printf("reviewdog: wrote %d result(s) to baseline %s", b.Len(), path)
+	return runErr
+}
+
+func reviewdogOptions(opt *option) []reviewdog.Option {
+	return []reviewdog.Option{
+		reviewdog.WithSeverityLevel(opt.filterSeverity),
+		reviewdog.WithSuggestionConflictMode(opt.suggestionConflict),
+		reviewdog.WithFailPolicy(opt.failOnSeverity, opt.failThreshold),
+		reviewdog.WithTabWidth(opt.tabWidth),
+		reviewdog.WithDiffContextExpansion(opt.diffContextExpansion),
+		reviewdog.WithIgnoreAnnotations(opt.ignoreAnnotations),
+		reviewdog.WithKeepEmptyMessages(opt.keepEmptyMessages),
+		reviewdog.WithToolFilter(opt.includeTools, opt.excludeTools),
+		reviewdog.WithFileFilter(opt.includeFiles, opt.excludeFiles),
+		reviewdog.WithDuplicateCollapse(opt.collapseDuplicates),
+		reviewdog.WithMaxComments(opt.maxComments),
+		reviewdog.WithSkipEmptyDiff(opt.skipEmptyDiff),
+		reviewdog.WithPathRewrites(opt.pathRewrites),
+	}
+}
+
+// serviceLogger returns a logger for services with -log-level.
+func serviceLogger(opt *option) serviceutil.Logger {
+	return serviceutil.NewLogger(os.Stderr, opt.logLevel)
+}
+
+// failOnError returns true if reviewdog should fail with reported results.
+// -fail-on-severity and -fail-threshold imply -fail-on-error.
+func failOnError(opt *option) bool {
+	return opt.failOnError || opt.failOnSeverity != filter.SeverityLevelAny || opt.failThreshold > 0
+}
+
+// commentTemplate returns the comment template from -comment-template or
+// -comment-template-file with -comment-snippet-lines,
+// -comment-suggestion-preview and -comment-severity-prefix. It returns nil if
+// none of them is specified.
+func commentTemplate(opt *option) (*commentutil.Template, error) {
+	if opt.commentSnippetLines < 0 {
+		return nil, errors.New("-comment-snippet-lines must not be negative")
+	}
+	if opt.commentSuggestionPreview < 0 {
+		return nil, errors.New("-comment-suggestion-preview must not be negative")
+	}
+	if opt.commentMaxLength < 0 {
+		return nil, errors.New("-comment-max-length must not be negative")
+	}
+	prefixes, err := commentutil.ParseSeverityPrefixes(opt.commentSeverity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -comment-severity-prefix: %w", err)
+	}
+	tmpl, err := parseCommentTemplate(opt)
+	if err != nil {
+		return nil, err
+	}
+	if opt.commentSnippetLines > 0 {
+		tmpl = tmpl.WithSnippet(opt.commentSnippetLines)
+	}
+	if opt.commentSuggestionPreview > 0 {
+		tmpl = tmpl.WithSuggestionPreview(opt.commentSuggestionPreview)
+	}
+	if prefixes != nil {
+		tmpl = tmpl.WithSeverityPrefixes(prefixes)
+	}
+	return tmpl, nil
+}
+
+func parseCommentTemplate(opt *option) (*commentutil.Template, error) {
+	text := opt.commentTemplate
+	if opt.commentTemplateFile != "" {
+		if text != "" {
+			return nil, errors.New("you cannot specify both -comment-template and -comment-template-file")
+		}
+		b, err := os.ReadFile(opt.commentTemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read comment template: %w", err)
+		}
+		text = string(b)
+	}
+	if text == "" {
+		return nil, nil
+	}
+	return commentutil.ParseTemplate(text)
+}
+
+func runValidate(r io.Reader, w io.Writer, opt *option) error {
+	p, err := newParserFromOpt(opt)
+	if err != nil {
+		return err
+	}
+	diagnostics, err := p.Parse(r)
+	if err != nil {
+		return fmt.Errorf("invalid input: %w", err)
+	}
+	fmt.Fprintf(w, "reviewdog: %d valid results found\n", len(diagnostics))
+	return nil
 }
 
 func runList(w io.Writer) error {
@@ -399,6 +1078,9 @@ func runList(w io.Writer) error {
 	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "rdjsonl", "Reviewdog Diagnostic JSONL Format (JSONL of Diagnostic message)", "https://github.com/reviewdog/reviewdog")
 	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "diff", "Unified Diff Format", "https://en.wikipedia.org/wiki/Diff#Unified_format")
 	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "checkstyle", "checkstyle XML format", "http://checkstyle.sourceforge.net/")
+	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "golangci-lint-json", "golangci-lint JSON format (golangci-lint run --out-format json)", "https://golangci-lint.run/")
+	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "lsp", "LSP diagnostics JSON format (PublishDiagnosticsParams)", "https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_publishDiagnostics")
+	fmt.Fprintf(tabw, "%s\t%s\t- %s\n", "clang-tidy-fixes", "clang-tidy fixes YAML format (clang-tidy -export-fixes)", "https://clang.llvm.org/extra/clang-tidy/")
 	for _, f := range sortedFmts(fmts.DefinedFmts()) {
 		fmt.Fprintf(tabw, "%s\t%s\t- %s\n", f.Name, f.Description, f.URL)
 	}
@@ -420,7 +1102,7 @@ func sortedFmts(fs fmts.Fmts) []*fmts.Fmt {
 	return r
 }
 
-func diffService(s string, strip int) (reviewdog.DiffService, error) {
+func diffService(ctx context.Context, s string, strip int) (reviewdog.DiffService, error) {
 	cmds, err := shellwords.Parse(s)
 	if err != nil {
 		return nil, err
@@ -428,24 +1110,74 @@ func diffService(s string, strip int) (reviewdog.DiffService, error) {
 	if len(cmds) < 1 {
 		return nil, errors.New("diff command is empty")
 	}
-	cmd := exec.Command(cmds[0], cmds[1:]...)
+	cmd := exec.CommandContext(ctx, cmds[0], cmds[1:]...)
 	d := reviewdog.NewDiffCmd(cmd, strip)
 	return d, nil
 }
 
-func newHTTPClient() *http.Client {
+// execCommentWriter returns a comment writer of exec reporter for -exec-cmd.
+func execCommentWriter(s string, w io.Writer) (*reviewdog.ExecCommentWriter, error) {
+	cmds, err := shellwords.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -exec-cmd: %w", err)
+	}
+	if len(cmds) < 1 {
+		return nil, errors.New("exec reporter needs -exec-cmd")
+	}
+	return reviewdog.NewExecCommentWriter(w, os.Stderr, cmds[0], cmds[1:]...), nil
+}
+
+// gitDiffService returns a diff service of -diff-base.
+func gitDiffService(base string) *reviewdog.GitDiff {
+	if base != "push" {
+		return reviewdog.NewGitDiff(base, "")
+	}
+	push := cienv.GetPushInfo()
+	fallback := ""
+	if push.DefaultBranch != "" {
+		fallback = "origin/" + push.DefaultBranch
+	}
+	return reviewdog.NewGitDiff(push.Before, fallback)
+}
+
+// newHTTPClient returns an HTTP client for reporters. API calls are counted
+// with sink if not nil.
+func newHTTPClient(sink metrics.Sink) *http.Client {
 	tr := &http.Transport{
 		Proxy:           http.ProxyFromEnvironment,
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify()},
 	}
-	return &http.Client{Transport: tr}
+	return &http.Client{Transport: metrics.Transport(tr, sink)}
+}
+
+// metricsSink returns the metrics sink of -metrics and a func to close it. It
+// returns nil sink if dest is empty.
+func metricsSink(dest string, w io.Writer) (metrics.Sink, func(), error) {
+	nop := func() {}
+	switch {
+	case dest == "":
+		return nil, nop, nil
+	case dest == "-":
+		return metrics.NewJSONSink(w), nop, nil
+	case strings.HasPrefix(dest, "statsd://"):
+		s, err := metrics.NewStatsdSink(strings.TrimPrefix(dest, "statsd://"), "reviewdog")
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid -metrics: %w", err)
+		}
+		return s, func() { s.Close() }, nil
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create -metrics file: %w", err)
+	}
+	return metrics.NewJSONSink(f), func() { f.Close() }, nil
 }
 
 func insecureSkipVerify() bool {
 	return os.Getenv("REVIEWDOG_INSECURE_SKIP_VERIFY") == "true"
 }
 
-func githubService(ctx context.Context, opt *option) (gs *githubservice.PullRequest, isPR bool, err error) {
+func githubService(ctx context.Context, opt *option, tmpl *commentutil.Template, sink metrics.Sink) (gs *githubservice.PullRequest, isPR bool, err error) {
 	token, err := nonEmptyEnv("REVIEWDOG_GITHUB_API_TOKEN")
 	if err != nil {
 		return nil, isPR, err
@@ -455,7 +1187,7 @@ func githubService(ctx context.Context, opt *option) (gs *githubservice.PullRequ
 		return nil, isPR, err
 	}
 
-	client, err := githubClient(ctx, token)
+	client, err := githubClient(ctx, token, sink)
 	if err != nil {
 		return nil, isPR, err
 	}
@@ -477,7 +1209,41 @@ func githubService(ctx context.Context, opt *option) (gs *githubservice.PullRequ
 		g.PullRequest = prID
 	}
 
-	gs, err = githubservice.NewGitHubPullRequest(client, g.Owner, g.Repo, g.PullRequest, g.SHA)
+	gopts := []githubservice.PullRequestOption{
+		githubservice.WithCommentTemplate(tmpl),
+		githubservice.WithCommentMode(opt.commentMode),
+		githubservice.WithLogger(serviceLogger(opt)),
+	}
+	if opt.fingerprint {
+		gopts = append(gopts, githubservice.WithFingerprint())
+	}
+	if opt.reportNoResults {
+		gopts = append(gopts, githubservice.WithReportNoResults())
+	}
+	if opt.commentMaxLength > 0 {
+		gopts = append(gopts, githubservice.WithMaxMessageLength(opt.commentMaxLength))
+	}
+	if v := os.Getenv("REVIEWDOG_GITHUB_RATE_LIMIT_MAX_WAIT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, false, fmt.Errorf("REVIEWDOG_GITHUB_RATE_LIMIT_MAX_WAIT must be a duration (e.g. 5m): %w", err)
+		}
+		gopts = append(gopts, githubservice.WithRateLimitMaxWait(d))
+	}
+	if v := os.Getenv("REVIEWDOG_GITHUB_REVIEW_REQUEST_CHANGES"); v != "" {
+		var level filter.SeverityLevel
+		if err := level.Set(v); err != nil {
+			return nil, false, fmt.Errorf("REVIEWDOG_GITHUB_REVIEW_REQUEST_CHANGES: %w", err)
+		}
+		gopts = append(gopts, githubservice.WithRequestChanges(level))
+	}
+	if os.Getenv("REVIEWDOG_GITHUB_REVIEW_APPROVE") == "true" {
+		gopts = append(gopts, githubservice.WithApprove())
+	}
+	if os.Getenv("REVIEWDOG_GITHUB_REVIEW_THREAD_BY_CODE") == "true" {
+		gopts = append(gopts, githubservice.WithThreadByCode())
+	}
+	gs, err = githubservice.NewGitHubPullRequest(client, g.Owner, g.Repo, g.PullRequest, g.SHA, gopts...)
 	if err != nil {
 		return nil, false, err
 	}
@@ -515,45 +1281,71 @@ func getPullRequestIDByBranchOrCommit(ctx context.Context, client *github.Client
 	return *pullRequests.Issues[0].Number, nil
 }
 
-func githubClient(ctx context.Context, token string) (*github.Client, error) {
-	ctx = context.WithValue(ctx, oauth2.HTTPClient, newHTTPClient())
+func githubClient(ctx context.Context, token string, sink metrics.Sink) (*github.Client, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, newHTTPClient(sink))
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
 	client := github.NewClient(tc)
-	var err error
-	client.BaseURL, err = githubBaseURL()
-	return client, err
+	baseURL, err := githubBaseURL()
+	if err != nil {
+		return nil, err
+	}
+	client.BaseURL = baseURL
+	client.UploadURL = githubUploadURL(baseURL)
+	return client, nil
 }
 
 const defaultGitHubAPI = "https://api.github.com/"
 
+// githubBaseURL returns the GitHub REST API base URL from GITHUB_API or
+// GITHUB_API_URL (GitHub Actions' default environment variable) so that
+// reporters work with GitHub Enterprise Server (e.g.
+// https://github.example.com/api/v3/).
 func githubBaseURL() (*url.URL, error) {
 	if baseURL := os.Getenv("GITHUB_API"); baseURL != "" {
-		u, err := url.Parse(baseURL)
-		if err != nil {
-			return nil, fmt.Errorf("GitHub base URL from GITHUB_API is invalid: %v, %w", baseURL, err)
-		}
-		return u, nil
+		return parseGitHubBaseURL("GITHUB_API", baseURL)
 	}
 	// get GitHub base URL from GitHub Actions' default environment variable GITHUB_API_URL
 	// ref: https://docs.github.com/en/actions/reference/environment-variables#default-environment-variables
 	if baseURL := os.Getenv("GITHUB_API_URL"); baseURL != "" {
-		u, err := url.Parse(baseURL + "/")
-		if err != nil {
-			return nil, fmt.Errorf("GitHub base URL from GITHUB_API_URL is invalid: %v, %w", baseURL, err)
-		}
-		return u, nil
+		return parseGitHubBaseURL("GITHUB_API_URL", baseURL)
 	}
-	u, err := url.Parse(defaultGitHubAPI)
+	return parseGitHubBaseURL("reviewdog default", defaultGitHubAPI)
+}
+
+// parseGitHubBaseURL parses and validates the base URL from the source. It
+// adds a trailing slash which go-github requires.
+func parseGitHubBaseURL(source, baseURL string) (*url.URL, error) {
+	u, err := url.Parse(baseURL)
 	if err != nil {
-		return nil, fmt.Errorf("GitHub base URL from reviewdog default is invalid: %v, %w", defaultGitHubAPI, err)
+		return nil, fmt.Errorf("GitHub base URL from %s is invalid: %v, %w", source, baseURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("GitHub base URL from %s is invalid: %v, want absolute http(s) URL (e.g. https://github.example.com/api/v3)", source, baseURL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
 	}
 	return u, nil
 }
 
-func gitlabBuildWithClient() (*cienv.BuildInfo, *gitlab.Client, error) {
+// githubUploadURL returns the upload API URL for the base URL. GitHub
+// Enterprise Server serves it at /api/uploads/ instead of /api/v3/.
+func githubUploadURL(baseURL *url.URL) *url.URL {
+	u := *baseURL
+	if strings.HasSuffix(u.Path, "/api/v3/") {
+		u.Path = strings.TrimSuffix(u.Path, "/api/v3/") + "/api/uploads/"
+		return &u
+	}
+	if u.Host == "api.github.com" {
+		u.Host = "uploads.github.com"
+	}
+	return &u
+}
+
+func gitlabBuildWithClient(sink metrics.Sink) (*cienv.BuildInfo, *gitlab.Client, error) {
 	token, err := nonEmptyEnv("REVIEWDOG_GITLAB_API_TOKEN")
 	if err != nil {
 		return nil, nil, err
@@ -564,7 +1356,7 @@ func gitlabBuildWithClient() (*cienv.BuildInfo, *gitlab.Client, error) {
 		return nil, nil, err
 	}
 
-	client, err := gitlabClient(token)
+	client, err := gitlabClient(token, sink)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -582,7 +1374,7 @@ func gitlabBuildWithClient() (*cienv.BuildInfo, *gitlab.Client, error) {
 	return g, client, err
 }
 
-func gerritBuildWithClient() (*cienv.BuildInfo, *gerrit.Client, error) {
+func gerritBuildWithClient(sink metrics.Sink) (*cienv.BuildInfo, *gerrit.Client, error) {
 	buildInfo, err := cienv.GetGerritBuildInfo()
 	if err != nil {
 		return nil, nil, err
@@ -593,22 +1385,112 @@ func gerritBuildWithClient() (*cienv.BuildInfo, *gerrit.Client, error) {
 		return nil, nil, errors.New("cannot get gerrit host address from environment variable. Set GERRIT_ADDRESS ?")
 	}
 
+	httpClient, err := gerritHTTPClient(sink)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	username := os.Getenv("GERRIT_USERNAME")
 	password := os.Getenv("GERRIT_PASSWORD")
 	if username != "" && password != "" {
-		client := gerrit.NewClient(gerritAddr, gerrit.BasicAuth(username, password))
+		client := gerritservice.NewClient(gerritAddr, gerrit.BasicAuth(username, password), httpClient)
 		return buildInfo, client, nil
 	}
 
 	if useGitCookiePath := os.Getenv("GERRIT_GIT_COOKIE_PATH"); useGitCookiePath != "" {
-		client := gerrit.NewClient(gerritAddr, gerrit.GitCookieFileAuth(useGitCookiePath))
+		client := gerritservice.NewClient(gerritAddr, gerrit.GitCookieFileAuth(useGitCookiePath), httpClient)
 		return buildInfo, client, nil
 	}
 
-	client := gerrit.NewClient(gerritAddr, gerrit.NoAuth)
+	client := gerritservice.NewClient(gerritAddr, gerrit.NoAuth, httpClient)
 	return buildInfo, client, nil
 }
 
+// gerritHTTPClient returns an HTTP client for Gerrit. It uses proxies of
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY, trusts certificates in
+// GERRIT_REVIEWDOG_CA_FILE in addition to system ones, and times out requests
+// after GERRIT_REVIEWDOG_HTTP_TIMEOUT. API calls are counted with sink if not
+// nil.
+func gerritHTTPClient(sink metrics.Sink) (*http.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureSkipVerify()}
+	if caFile := os.Getenv("GERRIT_REVIEWDOG_CA_FILE"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GERRIT_REVIEWDOG_CA_FILE: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("GERRIT_REVIEWDOG_CA_FILE has no PEM certificates: %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	client := &http.Client{Transport: metrics.Transport(&http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}, sink)}
+	if v := os.Getenv("GERRIT_REVIEWDOG_HTTP_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("GERRIT_REVIEWDOG_HTTP_TIMEOUT must be a duration (e.g. 30s): %w", err)
+		}
+		client.Timeout = timeout
+	}
+	return client, nil
+}
+
+func gerritCommenterOptions(w io.Writer) ([]gerritservice.ChangeReviewCommenterOption, error) {
+	var opts []gerritservice.ChangeReviewCommenterOption
+	if os.Getenv("GERRIT_REVIEWDOG_DRY_RUN") == "true" {
+		opts = append(opts, gerritservice.WithDryRunWriter(w))
+	}
+	if os.Getenv("GERRIT_REVIEWDOG_REPORT_OUTSIDE_DIFF") == "true" {
+		opts = append(opts, gerritservice.WithOutsideDiffSummary())
+	}
+	if os.Getenv("GERRIT_REVIEWDOG_NO_DEDUP") == "true" {
+		opts = append(opts, gerritservice.WithoutDeduplication())
+	}
+	if tag := os.Getenv("GERRIT_REVIEWDOG_HASHTAG"); tag != "" {
+		opts = append(opts, gerritservice.WithHashtag(tag))
+	}
+	if dir := os.Getenv("GERRIT_REVIEWDOG_WORKDIR"); dir != "" {
+		opts = append(opts, gerritservice.WithWorkdir(dir))
+	}
+	if v := os.Getenv("GERRIT_REVIEWDOG_BATCH_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("GERRIT_REVIEWDOG_BATCH_SIZE must be a positive integer: %q", v)
+		}
+		opts = append(opts, gerritservice.WithBatchSize(n))
+	}
+	if label := os.Getenv("GERRIT_REVIEWDOG_LABEL"); label != "" {
+		pass, err := intEnv("GERRIT_REVIEWDOG_LABEL_PASS", 1)
+		if err != nil {
+			return nil, err
+		}
+		fail, err := intEnv("GERRIT_REVIEWDOG_LABEL_FAIL", -1)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, gerritservice.WithLabel(label, pass, fail))
+	}
+	retryCount, err := intEnv("GERRIT_REVIEWDOG_RETRY_COUNT", gerritservice.DefaultRetryCount)
+	if err != nil {
+		return nil, err
+	}
+	retryDelay := gerritservice.DefaultRetryDelay
+	if v := os.Getenv("GERRIT_REVIEWDOG_RETRY_DELAY"); v != "" {
+		retryDelay, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("GERRIT_REVIEWDOG_RETRY_DELAY must be a duration (e.g. 1s): %w", err)
+		}
+	}
+	opts = append(opts, gerritservice.WithRetry(retryCount, retryDelay))
+	return opts, nil
+}
+
 func bitbucketBuildWithClient(ctx context.Context) (*cienv.BuildInfo, bbservice.APIClient, context.Context, error) {
 	build, _, err := cienv.GetBuildInfo()
 	if err != nil {
@@ -635,6 +1517,53 @@ func bitbucketBuildWithClient(ctx context.Context) (*cienv.BuildInfo, bbservice.
 	return build, client, ctx, nil
 }
 
+// azureDevOpsBuild is the Azure DevOps pull request to report results to.
+type azureDevOpsBuild struct {
+	project string
+	repo    string
+	pr      int
+}
+
+// azureDevOpsBuildWithClient returns the pull request and the client from
+// AZURE_DEVOPS_* environment variables or predefined variables of Azure
+// Pipelines.
+func azureDevOpsBuildWithClient(sink metrics.Sink) (*azureDevOpsBuild, *azureservice.Client, error) {
+	token, err := nonEmptyEnv("AZURE_DEVOPS_TOKEN")
+	if err != nil {
+		return nil, nil, err
+	}
+	// https://learn.microsoft.com/en-us/azure/devops/pipelines/build/variables
+	baseURL := firstEnv("AZURE_DEVOPS_URL", "SYSTEM_COLLECTIONURI")
+	if baseURL == "" {
+		return nil, nil, errors.New("cannot get Azure DevOps organization URL. Set AZURE_DEVOPS_URL?")
+	}
+	b := &azureDevOpsBuild{
+		project: firstEnv("AZURE_DEVOPS_PROJECT", "SYSTEM_TEAMPROJECT"),
+		repo:    firstEnv("AZURE_DEVOPS_REPO", "BUILD_REPOSITORY_NAME"),
+	}
+	if b.project == "" || b.repo == "" {
+		return nil, nil, errors.New("cannot get Azure DevOps project and repository. Set AZURE_DEVOPS_PROJECT and AZURE_DEVOPS_REPO?")
+	}
+	pr := firstEnv("AZURE_DEVOPS_PULL_REQUEST_ID", "SYSTEM_PULLREQUEST_PULLREQUESTID")
+	if b.pr, err = strconv.Atoi(pr); err != nil || b.pr <= 0 {
+		return nil, nil, fmt.Errorf("cannot get Azure DevOps pull request ID. Set AZURE_DEVOPS_PULL_REQUEST_ID?: %q", pr)
+	}
+	cli, err := azureservice.NewClient(newHTTPClient(sink), baseURL, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, cli, nil
+}
+
+// gitlabApprover returns the approver of the MergeRequest which uses the
+// same fail policy as gitlab-commit-status reporter.
+func gitlabApprover(cli *gitlab.Client, build *cienv.BuildInfo, opt *option) *gitlabservice.MergeRequestApprover {
+	return gitlabservice.NewGitLabMergeRequestApprover(cli, build.Owner, build.Repo, build.PullRequest, build.SHA,
+		gitlabservice.WithApprovalFailPolicy(opt.failOnSeverity, opt.failThreshold),
+		gitlabservice.WithApprovalLogger(serviceLogger(opt)),
+	)
+}
+
 func fetchMergeRequestIDFromCommit(cli *gitlab.Client, projectID, sha string) (id int, err error) {
 	// https://docs.gitlab.com/ce/api/merge_requests.html#list-project-merge-requests
 	opt := &gitlab.ListProjectMergeRequestsOptions{
@@ -653,12 +1582,12 @@ func fetchMergeRequestIDFromCommit(cli *gitlab.Client, projectID, sha string) (i
 	return 0, nil
 }
 
-func gitlabClient(token string) (*gitlab.Client, error) {
+func gitlabClient(token string, sink metrics.Sink) (*gitlab.Client, error) {
 	baseURL, err := gitlabBaseURL()
 	if err != nil {
 		return nil, err
 	}
-	client, err := gitlab.NewClient(token, gitlab.WithHTTPClient(newHTTPClient()), gitlab.WithBaseURL(baseURL.String()))
+	client, err := gitlab.NewClient(token, gitlab.WithHTTPClient(newHTTPClient(sink)), gitlab.WithBaseURL(baseURL.String()))
 	if err != nil {
 		return nil, err
 	}
@@ -695,6 +1624,30 @@ func nonEmptyEnv(env string) (string, error) {
 	return v, nil
 }
 
+// firstEnv returns the first non-empty value of the environment variables.
+func firstEnv(envs ...string) string {
+	for _, env := range envs {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// intEnv returns integer value of the environment variable, or def if it's
+// not set.
+func intEnv(env string, def int) (int, error) {
+	v := os.Getenv(env)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("environment variable $%v must be an integer: %q", env, v)
+	}
+	return n, nil
+}
+
 type strslice []string
 
 func (ss *strslice) String() string {
@@ -744,10 +1697,14 @@ func newParserFromOpt(opt *option) (parser.Parser, error) {
 		FormatName:  opt.f,
 		DiffStrip:   opt.fDiffStrip,
 		Errorformat: opt.efms,
+		SeverityMap: opt.severityMap,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("fail to create parser. use either -f or -efm: %w", err)
 	}
+	if len(opt.inputFiles) > 0 {
+		return parser.NewFilesParser(p, opt.inputFiles...), nil
+	}
 	return p, err
 }
 
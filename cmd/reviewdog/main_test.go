@@ -2,14 +2,20 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/reviewdog/reviewdog/commands"
 	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/metrics"
 )
 
 func TestRun_local(t *testing.T) {
@@ -216,3 +222,293 @@ func TestRun_version(t *testing.T) {
 		t.Errorf("version = %v, want %v", got, commands.Version)
 	}
 }
+
+func TestRun_validateOnly(t *testing.T) {
+	stdout := new(bytes.Buffer)
+	in := strings.NewReader(`{"diagnostics": [{"message": "msg", "location": {"path": "a.go"}}]}`)
+	if err := run(in, stdout, &option{f: "rdjson", validateOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "reviewdog: 1 valid results found\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	in = strings.NewReader(`{"diagnostics": [{"location": {"path": "a.go"}}]}`)
+	if err := run(in, new(bytes.Buffer), &option{f: "rdjson", validateOnly: true}); err == nil {
+		t.Error("got no error for invalid input")
+	}
+}
+
+func TestRun_bitbucketServerCodeReport_noServerURL(t *testing.T) {
+	t.Setenv("BITBUCKET_SERVER_URL", "")
+	opt := &option{f: "golint", reporter: "bitbucket-server-code-report"}
+	if err := run(strings.NewReader(""), new(bytes.Buffer), opt); err == nil {
+		t.Error("got no error without BITBUCKET_SERVER_URL")
+	}
+}
+
+func TestRun_exec(t *testing.T) {
+	opt := &option{
+		efms:       strslice([]string{`%f:%l: %m`}),
+		reporter:   "exec",
+		execCmd:    `sh -c 'wc -l | tr -d " "'`,
+		filterMode: filter.ModeNoFilter,
+	}
+	stdout := new(bytes.Buffer)
+	if err := run(strings.NewReader("a.go:1: m1\nb.go:2: m2\n"), stdout, opt); err != nil {
+		t.Fatal(err)
+	}
+	if got := stdout.String(); got != "2\n" {
+		t.Errorf("got %q, want 2 lines of results piped to the command", got)
+	}
+
+	opt.execCmd = ""
+	if err := run(strings.NewReader(""), new(bytes.Buffer), opt); err == nil {
+		t.Error("got no error without -exec-cmd")
+	}
+}
+
+func TestRun_invalidCommentTemplate(t *testing.T) {
+	opt := &option{f: "golint", commentTemplate: "{{.Message"}
+	if err := run(strings.NewReader(""), new(bytes.Buffer), opt); err == nil {
+		t.Error("got no error for invalid comment template")
+	}
+}
+
+func TestRun_local_diffFile(t *testing.T) {
+	// Paths in diff are relative to the repository root.
+	const difftext = `--- a/cmd/reviewdog/sample.txt
++++ b/cmd/reviewdog/sample.txt
+@@ -1,3 +1,3 @@
+ line1
+-line2
++line2 changed
+ line3
+`
+	diffFile := filepath.Join(t.TempDir(), "changes.diff")
+	if err := os.WriteFile(diffFile, []byte(difftext), 0600); err != nil {
+		t.Fatal(err)
+	}
+	opt := &option{
+		diffFile:  diffFile,
+		efms:      strslice([]string{`%f(%l,%c): %m`}),
+		diffStrip: 1,
+		reporter:  "local",
+	}
+	stdin := "sample.txt(2,1): message1\nsample.txt(3,1): message2"
+	stdout := new(bytes.Buffer)
+	if err := run(strings.NewReader(stdin), stdout, opt); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Trim(stdout.String(), "\n"), "sample.txt(2,1): message1"; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRun_negativeFailThreshold(t *testing.T) {
+	opt := &option{f: "golint", failThreshold: -1}
+	if err := run(strings.NewReader(""), new(bytes.Buffer), opt); err == nil {
+		t.Error("got no error for negative -fail-threshold")
+	}
+}
+
+func TestRun_sarif(t *testing.T) {
+	sarifFile := filepath.Join(t.TempDir(), "reviewdog.sarif")
+	opt := &option{
+		efms:       strslice([]string{`%f:%l:%c: %m`}),
+		reporter:   "sarif",
+		filterMode: filter.ModeNoFilter,
+		sarifFile:  sarifFile,
+		name:       "tool",
+	}
+	if err := run(strings.NewReader("a.go:2:1: message"), new(bytes.Buffer), opt); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(sarifFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"version": "2.1.0"`, `"name": "tool"`, `"text": "message"`, `"uri": "a.go"`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("SARIF log doesn't contain %s:\n%s", want, b)
+		}
+	}
+}
+
+func TestGitHubClient_baseURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		githubAPI  string
+		apiURL     string
+		wantBase   string
+		wantUpload string
+	}{
+		{
+			name:       "default",
+			wantBase:   "https://api.github.com/",
+			wantUpload: "https://uploads.github.com/",
+		},
+		{
+			name:       "enterprise",
+			githubAPI:  "https://github.example.com/api/v3",
+			wantBase:   "https://github.example.com/api/v3/",
+			wantUpload: "https://github.example.com/api/uploads/",
+		},
+		{
+			name:       "GitHub Actions",
+			apiURL:     "https://github.example.com/api/v3",
+			wantBase:   "https://github.example.com/api/v3/",
+			wantUpload: "https://github.example.com/api/uploads/",
+		},
+		{
+			name:       "GITHUB_API over GITHUB_API_URL",
+			githubAPI:  "http://localhost:8080/",
+			apiURL:     "https://github.example.com/api/v3",
+			wantBase:   "http://localhost:8080/",
+			wantUpload: "http://localhost:8080/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_API", tt.githubAPI)
+			t.Setenv("GITHUB_API_URL", tt.apiURL)
+			cli, err := githubClient(context.Background(), "token", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cli.BaseURL.String(); got != tt.wantBase {
+				t.Errorf("BaseURL = %q, want %q", got, tt.wantBase)
+			}
+			if got := cli.UploadURL.String(); got != tt.wantUpload {
+				t.Errorf("UploadURL = %q, want %q", got, tt.wantUpload)
+			}
+		})
+	}
+}
+
+func TestGitHubClient_invalidBaseURL(t *testing.T) {
+	for _, u := range []string{"github.example.com/api/v3", "ftp://github.example.com", "https://"} {
+		t.Setenv("GITHUB_API", u)
+		if _, err := githubClient(context.Background(), "token", nil); err == nil {
+			t.Errorf("got no error for GITHUB_API=%q", u)
+		}
+	}
+}
+
+func TestRun_summaryFile(t *testing.T) {
+	summaryFile := filepath.Join(t.TempDir(), "summary.json")
+	opt := &option{
+		efms:        strslice([]string{`%f:%l:%c: %m`}),
+		reporter:    "local",
+		filterMode:  filter.ModeNoFilter,
+		failOnError: true,
+		summaryFile: summaryFile,
+		name:        "tool",
+	}
+	if err := run(strings.NewReader("a.go:2:1: message"), new(bytes.Buffer), opt); err == nil {
+		t.Fatal("got no error with -fail-on-error")
+	}
+	b, err := os.ReadFile(summaryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"total": 1`, `"tool": 1`, `"fail_level_exceeded": true`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("summary doesn't contain %s:\n%s", want, b)
+		}
+	}
+}
+
+func TestRun_inputFile(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	if err := os.WriteFile(a, []byte("a.go:1: m1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("b.go:2: m2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opt := &option{
+		efms:       strslice([]string{`%f:%l: %m`}),
+		inputFiles: strslice([]string{a, b}),
+		reporter:   "local",
+		filterMode: filter.ModeNoFilter,
+	}
+	stdout := new(bytes.Buffer)
+	if err := run(strings.NewReader("stdin.go:3: ignored\n"), stdout, opt); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "a.go:1: m1\nb.go:2: m2\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	opt.inputFiles = strslice([]string{a, filepath.Join(dir, "missing.txt")})
+	err := run(strings.NewReader(""), new(bytes.Buffer), opt)
+	if err == nil || !strings.Contains(err.Error(), "missing.txt") {
+		t.Errorf("got %v, want an error about the missing file", err)
+	}
+}
+
+type countingSink struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (s *countingSink) Timing(string, time.Duration, metrics.Tags) {}
+
+func (s *countingSink) Count(name string, n int, _ metrics.Tags) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[name] += n
+}
+
+func TestNewHTTPClient_metrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	sink := &countingSink{counts: map[string]int{}}
+	gerritCli, err := gerritHTTPClient(sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cli := range []*http.Client{newHTTPClient(sink), gerritCli, newHTTPClient(nil)} {
+		resp, err := cli.Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if got := sink.counts["api.calls"]; got != 2 {
+		t.Errorf("got %d API calls, want 2 of the clients with the sink", got)
+	}
+}
+
+func TestRun_metrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.jsonl")
+	opt := &option{
+		efms:       strslice([]string{`%f:%l: %m`}),
+		reporter:   "local",
+		name:       "lint",
+		filterMode: filter.ModeNoFilter,
+		metrics:    path,
+	}
+	if err := run(strings.NewReader("a.go:1: m1\nb.go:2: m2\n"), new(bytes.Buffer), opt); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`{"name":"parse","type":"timing"`,
+		`{"name":"results.parsed","type":"count","value":2,"tags":{"tool":"lint"}}`,
+		`{"name":"diff","type":"timing"`,
+		`{"name":"filter","type":"timing"`,
+		`{"name":"report","type":"timing"`,
+		`{"name":"results.reported","type":"count","value":2,"tags":{"tool":"lint"}}`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("metrics don't have %s:\n%s", want, b)
+		}
+	}
+}
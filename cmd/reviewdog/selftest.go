@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/reviewdog/reviewdog/cienv"
+)
+
+// runSelfTest checks that reviewdog can access the service of the reporter
+// with the configured credentials by a minimal authenticated read, without
+// posting anything.
+func runSelfTest(ctx context.Context, w io.Writer, opt *option) error {
+	target, err := selfTest(ctx, opt.reporter)
+	if err != nil {
+		return fmt.Errorf("self-test of %s failed: %w", opt.reporter, err)
+	}
+	fmt.Fprintf(w, "reviewdog: self-test of %s succeeded: %s\n", opt.reporter, target)
+	return nil
+}
+
+// selfTest reads the target of the reporter and returns its description.
+func selfTest(ctx context.Context, reporter string) (string, error) {
+	switch reporter {
+	default:
+		return "", fmt.Errorf("unknown -reporter: %s", reporter)
+	case "local", "teamcity", "sarif", "junit", "github-annotations", "suggestion-diff", "exec":
+		return "no remote service to check", nil
+	case "github-check", "github-pr-check":
+		// Same condition as newDoghouseCli.
+		skipDoghouseServer := (os.Getenv("REVIEWDOG_SKIP_DOGHOUSE") == "true" || cienv.IsInGitHubAction()) && os.Getenv("REVIEWDOG_TOKEN") == ""
+		if !skipDoghouseServer {
+			return "", errors.New("the reviewdog server cannot be checked without posting results. Set REVIEWDOG_SKIP_DOGHOUSE=true to check REVIEWDOG_GITHUB_API_TOKEN")
+		}
+		return selfTestGitHub(ctx, false)
+	case "github-pr-review":
+		return selfTestGitHub(ctx, true)
+	case "gitlab-mr-discussion", "gitlab-mr-commit":
+		build, cli, err := gitlabBuildWithClient(nil)
+		if err != nil {
+			return "", err
+		}
+		if build.PullRequest == 0 {
+			return "", errors.New("this is not MergeRequest build")
+		}
+		mr, _, err := cli.MergeRequests.GetMergeRequest(build.Owner+"/"+build.Repo, build.PullRequest, nil, gitlab.WithContext(ctx))
+		if err != nil {
+			return "", fmt.Errorf("failed to get MergeRequest: %w", err)
+		}
+		return fmt.Sprintf("MergeRequest !%d of %s/%s (%s)", mr.IID, build.Owner, build.Repo, mr.Title), nil
+	case "gitlab-commit-status":
+		build, cli, err := gitlabBuildWithClient(nil)
+		if err != nil {
+			return "", err
+		}
+		p, _, err := cli.Projects.GetProject(build.Owner+"/"+build.Repo, nil, gitlab.WithContext(ctx))
+		if err != nil {
+			return "", fmt.Errorf("failed to get project: %w", err)
+		}
+		return fmt.Sprintf("project %s", p.PathWithNamespace), nil
+	case "gerrit-change-review":
+		build, cli, err := gerritBuildWithClient(nil)
+		if err != nil {
+			return "", err
+		}
+		change, err := cli.GetChangeDetail(ctx, build.GerritChangeID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get change: %w", err)
+		}
+		return fmt.Sprintf("change %d of %s (%s)", change.ChangeNumber, change.Project, change.Subject), nil
+	case "azure-devops-pr-thread":
+		build, cli, err := azureDevOpsBuildWithClient(nil)
+		if err != nil {
+			return "", err
+		}
+		if _, err := cli.GetPullRequest(ctx, build.project, build.repo, build.pr); err != nil {
+			return "", fmt.Errorf("failed to get pull request: %w", err)
+		}
+		return fmt.Sprintf("pull request %d of %s/%s", build.pr, build.project, build.repo), nil
+	case "bitbucket-code-report", "bitbucket-server-code-report":
+		return "", errors.New("Bitbucket cannot be checked without posting results")
+	}
+}
+
+// selfTestGitHub reads the pull request if it's a PullRequest build, or the
+// repository otherwise. needPR makes it fail if it's not a PullRequest build.
+func selfTestGitHub(ctx context.Context, needPR bool) (string, error) {
+	token, err := nonEmptyEnv("REVIEWDOG_GITHUB_API_TOKEN")
+	if err != nil {
+		return "", err
+	}
+	g, isPR, err := cienv.GetBuildInfo()
+	if err != nil {
+		return "", err
+	}
+	client, err := githubClient(ctx, token, nil)
+	if err != nil {
+		return "", err
+	}
+	if isPR {
+		pr, _, err := client.PullRequests.Get(ctx, g.Owner, g.Repo, g.PullRequest)
+		if err != nil {
+			return "", fmt.Errorf("failed to get PullRequest: %w", err)
+		}
+		return fmt.Sprintf("PullRequest #%d of %s/%s (%s)", pr.GetNumber(), g.Owner, g.Repo, pr.GetTitle()), nil
+	}
+	if needPR {
+		return "", errors.New("this is not PullRequest build")
+	}
+	repo, _, err := client.Repositories.Get(ctx, g.Owner, g.Repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository: %w", err)
+	}
+	return fmt.Sprintf("repository %s", repo.GetFullName()), nil
+}
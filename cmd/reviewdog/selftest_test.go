@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRun_selfTest_local(t *testing.T) {
+	stdout := new(bytes.Buffer)
+	if err := run(strings.NewReader(""), stdout, &option{reporter: "local", selfTest: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "reviewdog: self-test of local succeeded: no remote service to check\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRun_selfTest_gerrit(t *testing.T) {
+	var posted bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/changes/changeID/detail", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			posted = true
+		}
+		fmt.Fprint(w, `)]}'
+{"project": "proj", "_number": 123, "subject": "Fix bug"}`)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	t.Setenv("GERRIT_ADDRESS", ts.URL)
+	t.Setenv("GERRIT_CHANGE_ID", "changeID")
+	t.Setenv("GERRIT_REVISION_ID", "revisionID")
+	t.Setenv("GERRIT_BRANCH", "master")
+	t.Setenv("GERRIT_USERNAME", "")
+	t.Setenv("GERRIT_GIT_COOKIE_PATH", "")
+
+	opt := &option{reporter: "gerrit-change-review", selfTest: true}
+	stdout := new(bytes.Buffer)
+	if err := run(strings.NewReader(""), stdout, opt); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "reviewdog: self-test of gerrit-change-review succeeded: change 123 of proj (Fix bug)\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if posted {
+		t.Error("self-test posted to Gerrit")
+	}
+
+	t.Setenv("GERRIT_CHANGE_ID", "unknown")
+	if err := run(strings.NewReader(""), new(bytes.Buffer), opt); err == nil {
+		t.Error("got no error for an unknown change")
+	}
+}
+
+func TestRun_selfTest_missingCredentials(t *testing.T) {
+	t.Setenv("REVIEWDOG_GITHUB_API_TOKEN", "")
+	opt := &option{reporter: "github-pr-review", selfTest: true}
+	err := run(strings.NewReader(""), new(bytes.Buffer), opt)
+	if err == nil || !strings.Contains(err.Error(), "REVIEWDOG_GITHUB_API_TOKEN") {
+		t.Errorf("got %v, want an error about REVIEWDOG_GITHUB_API_TOKEN", err)
+	}
+}
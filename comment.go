@@ -3,6 +3,8 @@ package reviewdog
 import "context"
 
 var _ BulkCommentService = &multiCommentService{}
+var _ GroupingCommentService = &multiCommentService{}
+var _ FinishingCommentService = &multiCommentService{}
 
 type multiCommentService struct {
 	services []CommentService
@@ -10,13 +12,17 @@ type multiCommentService struct {
 
 func (m *multiCommentService) Post(ctx context.Context, c *Comment) error {
 	for _, cs := range m.services {
-		if err := cs.Post(ctx, c); err != nil {
+		if err := postComment(ctx, cs, c); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// GroupsResults implements GroupingCommentService. It posts grouped results
+// to each service depending on whether the service groups them.
+func (m *multiCommentService) GroupsResults() {}
+
 func (m *multiCommentService) Flush(ctx context.Context) error {
 	for _, cs := range m.services {
 		if bulk, ok := cs.(BulkCommentService); ok {
@@ -28,6 +34,17 @@ func (m *multiCommentService) Flush(ctx context.Context) error {
 	return nil
 }
 
+func (m *multiCommentService) Finish(ctx context.Context) error {
+	for _, cs := range m.services {
+		if fc, ok := cs.(FinishingCommentService); ok {
+			if err := fc.Finish(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // MultiCommentService creates a comment service that duplicates its post to
 // all the provided comment services.
 func MultiCommentService(services ...CommentService) CommentService {
@@ -35,3 +52,17 @@ func MultiCommentService(services ...CommentService) CommentService {
 	copy(s, services)
 	return &multiCommentService{services: s}
 }
+
+// postComment posts c to cs. If cs isn't GroupingCommentService, grouped
+// results of c are posted as separate comments instead.
+func postComment(ctx context.Context, cs CommentService, c *Comment) error {
+	if _, ok := cs.(GroupingCommentService); ok || (len(c.Result.Duplicates) == 0 && len(c.Result.Omitted) == 0) {
+		return cs.Post(ctx, c)
+	}
+	for _, fd := range c.Result.ReportedChecks() {
+		if err := cs.Post(ctx, &Comment{Result: fd, ToolName: c.ToolName}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package reviewdog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ BulkCommentService = &ExecCommentWriter{}
+
+// ExecCommentWriter is comment writer which pipes results to an external
+// command on Flush, so that users can deliver results to any services.
+//
+// The command gets results posted since the last Flush on stdin as rdjsonl,
+// i.e. one JSON of rdf.Diagnostic per line, which is the same format as the
+// rdjsonl input. Results without source get the tool name as source.name. The
+// command runs even if there are no results, with empty stdin. Stdout and
+// stderr of the command are passed through, and Flush fails if the command
+// exits with non-zero code.
+type ExecCommentWriter struct {
+	name string
+	args []string
+
+	stdout io.Writer
+	stderr io.Writer
+
+	mu       sync.Mutex
+	comments []*Comment
+}
+
+// NewExecCommentWriter returns a new ExecCommentWriter which runs the command
+// name with args. Output of the command is written to stdout and stderr.
+func NewExecCommentWriter(stdout, stderr io.Writer, name string, args ...string) *ExecCommentWriter {
+	return &ExecCommentWriter{name: name, args: args, stdout: stdout, stderr: stderr}
+}
+
+func (e *ExecCommentWriter) Post(_ context.Context, c *Comment) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.comments = append(e.comments, c)
+	return nil
+}
+
+// Flush runs the command with the posted comments.
+func (e *ExecCommentWriter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var stdin bytes.Buffer
+	for _, c := range e.comments {
+		b, err := protojson.Marshal(execDiagnostic(c))
+		if err != nil {
+			return fmt.Errorf("exec reporter: failed to marshal result: %w", err)
+		}
+		stdin.Write(b)
+		stdin.WriteByte('\n')
+	}
+	e.comments = nil
+
+	cmd := exec.CommandContext(ctx, e.name, e.args...)
+	cmd.Stdin = &stdin
+	cmd.Stdout = e.stdout
+	cmd.Stderr = e.stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("exec reporter: %s exited with code %d", e.name, exitErr.ExitCode())
+		}
+		return fmt.Errorf("exec reporter: failed to run %s: %w", e.name, err)
+	}
+	return nil
+}
+
+// execDiagnostic returns the diagnostic of the comment with the tool name as
+// its source if it has no source.
+func execDiagnostic(c *Comment) *rdf.Diagnostic {
+	d := c.Result.Diagnostic
+	if d.GetSource().GetName() != "" || c.ToolName == "" {
+		return d
+	}
+	d = proto.Clone(d).(*rdf.Diagnostic)
+	d.Source = &rdf.Source{Name: c.ToolName}
+	return d
+}
@@ -0,0 +1,99 @@
+package reviewdog
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/parser"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestExecCommentWriter_Flush(t *testing.T) {
+	comments := []*Comment{
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "a.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 1, Column: 2}},
+					},
+					Message:  "error",
+					Severity: rdf.Severity_ERROR,
+					Source:   &rdf.Source{Name: "linter"},
+				},
+			},
+			ToolName: "golangci",
+		},
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{Path: "b.go"},
+					Message:  "no source",
+				},
+			},
+			ToolName: "golangci",
+		},
+	}
+	var stdout bytes.Buffer
+	w := NewExecCommentWriter(&stdout, &stdout, "cat")
+	for _, c := range comments {
+		if err := w.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := parser.NewRDJSONLParser().Parse(&stdout)
+	if err != nil {
+		t.Fatalf("command input must be rdjsonl: %v", err)
+	}
+	want := []*rdf.Diagnostic{
+		comments[0].Result.Diagnostic,
+		{
+			Location: &rdf.Location{Path: "b.go"},
+			Message:  "no source",
+			Source:   &rdf.Source{Name: "golangci"},
+		},
+	}
+	// The rdjsonl parser stores input lines as original output.
+	ignore := protocmp.IgnoreFields(&rdf.Diagnostic{}, "original_output")
+	if diff := cmp.Diff(want, got, protocmp.Transform(), ignore); diff != "" {
+		t.Errorf("diff (-want +got):\n%s", diff)
+	}
+	if comments[1].Result.Diagnostic.GetSource() != nil {
+		t.Error("posted result must not be modified")
+	}
+
+	// The next Flush only gets comments posted after the last one.
+	stdout.Reset()
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("got %q, want empty input", stdout.String())
+	}
+}
+
+func TestExecCommentWriter_Flush_fail(t *testing.T) {
+	var stderr bytes.Buffer
+	w := NewExecCommentWriter(&stderr, &stderr, "sh", "-c", "echo failed >&2; exit 3")
+	err := w.Flush(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "exited with code 3") {
+		t.Errorf("got error %v, want exit code 3", err)
+	}
+	if stderr.String() != "failed\n" {
+		t.Errorf("got stderr %q, want the command output", stderr.String())
+	}
+
+	w = NewExecCommentWriter(&stderr, &stderr, "reviewdog-no-such-command")
+	if err := w.Flush(context.Background()); err == nil {
+		t.Error("Flush must fail for missing commands")
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
+	"sync"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
 )
 
 var _ CommentService = &RawCommentWriter{}
@@ -55,3 +59,78 @@ func (mc *UnifiedCommentWriter) Post(_ context.Context, c *Comment) error {
 	_, err := fmt.Fprintln(mc.w, s)
 	return err
 }
+
+var _ CommentService = &TeamCityCommentWriter{}
+
+// TeamCityCommentWriter is comment writer which writes results to given
+// writer as TeamCity inspection service messages. Inspection types are
+// registered once per tool and rule before their first inspection.
+//
+// Format:
+//   - ##teamcity[inspectionType id='<id>' name='<id>' category='<tool name>' description='<tool name>']
+//   - ##teamcity[inspection typeId='<id>' message='<message>' file='<file>' line='<lnum>' SEVERITY='<severity>']
+//
+// where <id> is the rule code if any, otherwise the tool name.
+//
+// https://www.jetbrains.com/help/teamcity/service-messages.html#Reporting+Inspections
+type TeamCityCommentWriter struct {
+	w io.Writer
+
+	mu         sync.Mutex
+	registered map[string]bool
+}
+
+func NewTeamCityCommentWriter(w io.Writer) *TeamCityCommentWriter {
+	return &TeamCityCommentWriter{w: w, registered: make(map[string]bool)}
+}
+
+func (tc *TeamCityCommentWriter) Post(_ context.Context, c *Comment) error {
+	d := c.Result.Diagnostic
+	typeID := c.ToolName
+	if code := d.GetCode().GetValue(); code != "" {
+		typeID = code
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if !tc.registered[typeID] {
+		if _, err := fmt.Fprintf(tc.w, "##teamcity[inspectionType id='%s' name='%s' category='%s' description='%s']\n",
+			teamCityEscape(typeID), teamCityEscape(typeID), teamCityEscape(c.ToolName), teamCityEscape(c.ToolName)); err != nil {
+			return err
+		}
+		tc.registered[typeID] = true
+	}
+
+	s := fmt.Sprintf("##teamcity[inspection typeId='%s' message='%s' file='%s'",
+		teamCityEscape(typeID), teamCityEscape(d.GetMessage()), teamCityEscape(d.GetLocation().GetPath()))
+	if lnum := d.GetLocation().GetRange().GetStart().GetLine(); lnum > 0 {
+		s += fmt.Sprintf(" line='%d'", lnum)
+	}
+	switch d.GetSeverity() {
+	case rdf.Severity_ERROR:
+		s += " SEVERITY='ERROR'"
+	case rdf.Severity_WARNING:
+		s += " SEVERITY='WARNING'"
+	case rdf.Severity_INFO:
+		s += " SEVERITY='INFO'"
+	}
+	_, err := fmt.Fprintln(tc.w, s+"]")
+	return err
+}
+
+var teamCityEscaper = strings.NewReplacer(
+	"|", "||",
+	"'", "|'",
+	"\n", "|n",
+	"\r", "|r",
+	"[", "|[",
+	"]", "|]",
+	"\u0085", "|x",
+	"\u2028", "|l",
+	"\u2029", "|p",
+)
+
+// teamCityEscape escapes the value of TeamCity service message attributes.
+func teamCityEscape(s string) string {
+	return teamCityEscaper.Replace(s)
+}
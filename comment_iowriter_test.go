@@ -93,3 +93,60 @@ line2`,
 		}
 	}
 }
+
+func TestTeamCityCommentWriter_Post(t *testing.T) {
+	comments := []*Comment{
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "path/to/file",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+					},
+					Message:  "it's [wrong]\n|here|",
+					Severity: rdf.Severity_ERROR,
+				},
+			},
+			ToolName: "tool",
+		},
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{Path: "path/to/file"},
+					Message:  "message",
+				},
+			},
+			ToolName: "tool",
+		},
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "path/to/file2",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+					},
+					Message:  "message",
+					Severity: rdf.Severity_WARNING,
+					Code:     &rdf.Code{Value: "SA1000"},
+				},
+			},
+			ToolName: "tool",
+		},
+	}
+	want := `##teamcity[inspectionType id='tool' name='tool' category='tool' description='tool']
+##teamcity[inspection typeId='tool' message='it|'s |[wrong|]|n||here||' file='path/to/file' line='14' SEVERITY='ERROR']
+##teamcity[inspection typeId='tool' message='message' file='path/to/file']
+##teamcity[inspectionType id='SA1000' name='SA1000' category='tool' description='tool']
+##teamcity[inspection typeId='SA1000' message='message' file='path/to/file2' line='1' SEVERITY='WARNING']
+`
+	buf := new(bytes.Buffer)
+	tc := NewTeamCityCommentWriter(buf)
+	for _, c := range comments {
+		if err := tc.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
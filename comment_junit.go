@@ -0,0 +1,148 @@
+package reviewdog
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ BulkCommentService = &JUnitCommentWriter{}
+
+// JUnitCommentWriter is comment writer which writes results to given file as
+// a JUnit XML report when Flush is called, so that CI services show them in
+// test reports. It writes one testsuite per tool and one failed testcase per
+// result whose classname is the file path. The file is rewritten with all the
+// posted results on each Flush, so it works with multiple tools in project
+// mode.
+type JUnitCommentWriter struct {
+	path string
+
+	mu       sync.Mutex
+	comments []*Comment
+}
+
+func NewJUnitCommentWriter(path string) *JUnitCommentWriter {
+	return &JUnitCommentWriter{path: path}
+}
+
+func (j *JUnitCommentWriter) Post(_ context.Context, c *Comment) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.comments = append(j.comments, c)
+	return nil
+}
+
+// Flush writes all the posted comments as a JUnit XML report.
+func (j *JUnitCommentWriter) Flush(_ context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	b, err := xml.MarshalIndent(buildJUnit(j.comments), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+	b = append([]byte(xml.Header), append(b, '\n')...)
+	if err := os.WriteFile(j.path, b, 0o644); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string       `xml:"name,attr"`
+	ClassName string       `xml:"classname,attr"`
+	Failure   junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+func buildJUnit(comments []*Comment) *junitTestSuites {
+	report := &junitTestSuites{Name: "reviewdog"}
+	suiteIndex := make(map[string]int)
+	for _, c := range comments {
+		i, ok := suiteIndex[c.ToolName]
+		if !ok {
+			i = len(report.Suites)
+			suiteIndex[c.ToolName] = i
+			report.Suites = append(report.Suites, junitTestSuite{Name: c.ToolName})
+		}
+		suite := &report.Suites[i]
+		d := c.Result.Diagnostic
+		pos := junitPosition(d)
+		name := pos
+		if code := d.GetCode().GetValue(); code != "" {
+			name += " (" + code + ")"
+		}
+		text := d.GetMessage()
+		if pos != "" {
+			text = pos + ": " + text
+		}
+		if url := d.GetCode().GetUrl(); url != "" {
+			text += "\n" + url
+		}
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      name,
+			ClassName: d.GetLocation().GetPath(),
+			Failure: junitFailure{
+				Message: d.GetMessage(),
+				Type:    junitFailureType(d.GetSeverity()),
+				Text:    text,
+			},
+		})
+		suite.Tests++
+		suite.Failures++
+		report.Tests++
+		report.Failures++
+	}
+	return report
+}
+
+// junitPosition returns the position of the diagnostic. e.g. "a.go:14:3".
+func junitPosition(d *rdf.Diagnostic) string {
+	pos := []string{d.GetLocation().GetPath()}
+	start := d.GetLocation().GetRange().GetStart()
+	if start.GetLine() > 0 {
+		pos = append(pos, fmt.Sprint(start.GetLine()))
+		if start.GetColumn() > 0 {
+			pos = append(pos, fmt.Sprint(start.GetColumn()))
+		}
+	}
+	return strings.Join(pos, ":")
+}
+
+// junitFailureType returns the failure type of the severity. It returns empty
+// for unknown severity.
+func junitFailureType(s rdf.Severity) string {
+	switch s {
+	case rdf.Severity_ERROR:
+		return "error"
+	case rdf.Severity_WARNING:
+		return "warning"
+	case rdf.Severity_INFO:
+		return "info"
+	}
+	return ""
+}
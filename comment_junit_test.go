@@ -0,0 +1,107 @@
+package reviewdog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestJUnitCommentWriter_Flush(t *testing.T) {
+	comments := []*Comment{
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "a.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 1, Column: 2}},
+					},
+					Message:  "error <message>",
+					Severity: rdf.Severity_ERROR,
+					Code:     &rdf.Code{Value: "E1", Url: "https://example.com/E1"},
+				},
+			},
+			ToolName: "linter",
+		},
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{Path: "b.go"},
+					Message:  "no severity",
+				},
+			},
+			ToolName: "other",
+		},
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "c.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 5}},
+					},
+					Message:  "warning message",
+					Severity: rdf.Severity_WARNING,
+				},
+			},
+			ToolName: "linter",
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "reviewdog.xml")
+	w := NewJUnitCommentWriter(path)
+	for _, c := range comments {
+		if err := w.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="reviewdog" tests="3" failures="3">
+  <testsuite name="linter" tests="2" failures="2">
+    <testcase name="a.go:1:2 (E1)" classname="a.go">
+      <failure message="error &lt;message&gt;" type="error">a.go:1:2: error &lt;message&gt;&#xA;https://example.com/E1</failure>
+    </testcase>
+    <testcase name="c.go:5" classname="c.go">
+      <failure message="warning message" type="warning">c.go:5: warning message</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="other" tests="1" failures="1">
+    <testcase name="b.go" classname="b.go">
+      <failure message="no severity">b.go: no severity</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("JUnit report diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestJUnitCommentWriter_Flush_empty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviewdog.xml")
+	if err := NewJUnitCommentWriter(path).Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="reviewdog" tests="0" failures="0"></testsuites>
+`
+	if got := string(b); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,189 @@
+package reviewdog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ BulkCommentService = &SARIFCommentWriter{}
+
+// SARIFCommentWriter is comment writer which writes results to given file
+// as a SARIF 2.1.0 log when Flush is called. It writes one run per tool and
+// rules from diagnostic codes. The file is rewritten with all the posted
+// results on each Flush, so it works with multiple tools in project mode.
+//
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type SARIFCommentWriter struct {
+	path string
+
+	mu       sync.Mutex
+	comments []*Comment
+}
+
+func NewSARIFCommentWriter(path string) *SARIFCommentWriter {
+	return &SARIFCommentWriter{path: path}
+}
+
+func (s *SARIFCommentWriter) Post(_ context.Context, c *Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.comments = append(s.comments, c)
+	return nil
+}
+
+// Flush writes all the posted comments as a SARIF log.
+func (s *SARIFCommentWriter) Flush(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := json.MarshalIndent(buildSARIF(s.comments), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF log: %w", err)
+	}
+	if err := os.WriteFile(s.path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write SARIF log: %w", err)
+	}
+	return nil
+}
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID      string `json:"id"`
+	HelpURI string `json:"helpUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	RuleIndex *int            `json:"ruleIndex,omitempty"`
+	Level     string          `json:"level,omitempty"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int32 `json:"startLine,omitempty"`
+	StartColumn int32 `json:"startColumn,omitempty"`
+	EndLine     int32 `json:"endLine,omitempty"`
+	EndColumn   int32 `json:"endColumn,omitempty"`
+}
+
+func buildSARIF(comments []*Comment) *sarifLog {
+	log := &sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{}}
+	runIndex := make(map[string]int)
+	ruleIndex := make(map[string]map[string]int)
+	for _, c := range comments {
+		i, ok := runIndex[c.ToolName]
+		if !ok {
+			i = len(log.Runs)
+			runIndex[c.ToolName] = i
+			ruleIndex[c.ToolName] = make(map[string]int)
+			log.Runs = append(log.Runs, sarifRun{
+				Tool:    sarifTool{Driver: sarifDriver{Name: c.ToolName}},
+				Results: []sarifResult{},
+			})
+		}
+		run := &log.Runs[i]
+		d := c.Result.Diagnostic
+		if run.Tool.Driver.InformationURI == "" {
+			run.Tool.Driver.InformationURI = d.GetSource().GetUrl()
+		}
+		r := sarifResult{
+			Level:   sarifLevel(d.GetSeverity()),
+			Message: sarifMessage{Text: d.GetMessage()},
+		}
+		if code := d.GetCode().GetValue(); code != "" {
+			ri, ok := ruleIndex[c.ToolName][code]
+			if !ok {
+				ri = len(run.Tool.Driver.Rules)
+				ruleIndex[c.ToolName][code] = ri
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: code, HelpURI: d.GetCode().GetUrl()})
+			}
+			r.RuleID = code
+			r.RuleIndex = &ri
+		}
+		if path := d.GetLocation().GetPath(); path != "" {
+			r.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: path},
+				Region:           sarifRegionFromRange(d.GetLocation().GetRange()),
+			}}}
+		}
+		run.Results = append(run.Results, r)
+	}
+	return log
+}
+
+// sarifLevel returns SARIF result level. It returns empty for unknown
+// severity, and then SARIF consumers use the default level "warning".
+func sarifLevel(s rdf.Severity) string {
+	switch s {
+	case rdf.Severity_ERROR:
+		return "error"
+	case rdf.Severity_WARNING:
+		return "warning"
+	case rdf.Severity_INFO:
+		return "note"
+	}
+	return ""
+}
+
+func sarifRegionFromRange(r *rdf.Range) *sarifRegion {
+	start := r.GetStart()
+	if start.GetLine() == 0 {
+		return nil
+	}
+	region := &sarifRegion{
+		StartLine:   start.GetLine(),
+		StartColumn: start.GetColumn(),
+	}
+	if end := r.GetEnd(); end.GetLine() > 0 {
+		region.EndLine = end.GetLine()
+		region.EndColumn = end.GetColumn()
+	}
+	return region
+}
@@ -0,0 +1,150 @@
+package reviewdog
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestSARIFCommentWriter_Flush(t *testing.T) {
+	comments := []*Comment{
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path: "a.go",
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: 1, Column: 2},
+							End:   &rdf.Position{Line: 3, Column: 4},
+						},
+					},
+					Message:  "error message",
+					Severity: rdf.Severity_ERROR,
+					Code:     &rdf.Code{Value: "E1", Url: "https://example.com/E1"},
+					Source:   &rdf.Source{Name: "linter", Url: "https://example.com/linter"},
+				},
+			},
+			ToolName: "linter",
+		},
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "b.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 5}},
+					},
+					Message:  "info message",
+					Severity: rdf.Severity_INFO,
+					Code:     &rdf.Code{Value: "E1"},
+				},
+			},
+			ToolName: "linter",
+		},
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{Path: "c.go"},
+					Message:  "no severity",
+				},
+			},
+			ToolName: "other",
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "reviewdog.sarif")
+	w := NewSARIFCommentWriter(path)
+	for _, c := range comments {
+		if err := w.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got sarifLog
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v\n%s", err, b)
+	}
+	zero := 0
+	want := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{Driver: sarifDriver{
+					Name:           "linter",
+					InformationURI: "https://example.com/linter",
+					Rules:          []sarifRule{{ID: "E1", HelpURI: "https://example.com/E1"}},
+				}},
+				Results: []sarifResult{
+					{
+						RuleID:    "E1",
+						RuleIndex: &zero,
+						Level:     "error",
+						Message:   sarifMessage{Text: "error message"},
+						Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+							ArtifactLocation: sarifArtifactLocation{URI: "a.go"},
+							Region:           &sarifRegion{StartLine: 1, StartColumn: 2, EndLine: 3, EndColumn: 4},
+						}}},
+					},
+					{
+						RuleID:    "E1",
+						RuleIndex: &zero,
+						Level:     "note",
+						Message:   sarifMessage{Text: "info message"},
+						Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+							ArtifactLocation: sarifArtifactLocation{URI: "b.go"},
+							Region:           &sarifRegion{StartLine: 5},
+						}}},
+					},
+				},
+			},
+			{
+				Tool: sarifTool{Driver: sarifDriver{Name: "other"}},
+				Results: []sarifResult{
+					{
+						Message: sarifMessage{Text: "no severity"},
+						Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+							ArtifactLocation: sarifArtifactLocation{URI: "c.go"},
+						}}},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SARIF log diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSARIFCommentWriter_Flush_empty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviewdog.sarif")
+	if err := NewSARIFCommentWriter(path).Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": []
+}
+`
+	if got := string(b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,329 @@
+package reviewdog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ CommentService = &SuggestionDiffWriter{}
+
+// suggestionDiffContext is the number of context lines in hunks.
+const suggestionDiffContext = 3
+
+// SuggestionDiffWriter collects suggestions of posted comments and writes a
+// unified diff which applies all of them to local files (e.g. with `git
+// apply`). Call WriteDiff after all the comments are posted, so that
+// suggestions of multiple tools are written as one diff.
+type SuggestionDiffWriter struct {
+	w io.Writer
+
+	mu          sync.Mutex
+	suggestions map[string][]*rdf.Suggestion
+}
+
+// NewSuggestionDiffWriter returns a new SuggestionDiffWriter which writes the
+// diff to w.
+func NewSuggestionDiffWriter(w io.Writer) *SuggestionDiffWriter {
+	return &SuggestionDiffWriter{w: w, suggestions: make(map[string][]*rdf.Suggestion)}
+}
+
+func (s *SuggestionDiffWriter) Post(_ context.Context, c *Comment) error {
+	d := c.Result.Diagnostic
+	if len(d.GetSuggestions()) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := d.GetLocation().GetPath()
+	s.suggestions[path] = append(s.suggestions[path], d.GetSuggestions()...)
+	return nil
+}
+
+// WriteDiff applies the collected suggestions to copies of the files and
+// writes the changes as a unified diff sorted by path. Suggestions which
+// overlap already applied ones or have invalid ranges are skipped with a log.
+func (s *SuggestionDiffWriter) WriteDiff() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.suggestions))
+	for path := range s.suggestions {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s for suggestions: %w", path, err)
+		}
+		fd := newSuggestionFileDiff(path, string(content), s.suggestions[path])
+		if _, err := io.WriteString(s.w, fd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// textEdit replaces content[start:end] with text.
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+// lineIndex holds the start offsets of lines of a file content.
+type lineIndex struct {
+	content string
+	starts  []int
+}
+
+func newLineIndex(content string) *lineIndex {
+	starts := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' && i+1 < len(content) {
+			starts = append(starts, i+1)
+		}
+	}
+	if content == "" {
+		starts = nil
+	}
+	return &lineIndex{content: content, starts: starts}
+}
+
+func (li *lineIndex) lineCount() int {
+	return len(li.starts)
+}
+
+// lineStart returns the offset of the 1-based line. The line after the last
+// line starts at the end of the content.
+func (li *lineIndex) lineStart(lnum int) int {
+	if lnum > len(li.starts) {
+		return len(li.content)
+	}
+	return li.starts[lnum-1]
+}
+
+// line returns the content of the line without the line break.
+func (li *lineIndex) line(lnum int) string {
+	return strings.TrimSuffix(li.content[li.lineStart(lnum):li.lineStart(lnum+1)], "\n")
+}
+
+// lineOf returns the 1-based line which contains the offset.
+func (li *lineIndex) lineOf(offset int) int {
+	return sort.Search(len(li.starts), func(i int) bool { return li.starts[i] > offset })
+}
+
+// suggestionEdit converts the suggestion into an edit of the content. Ranges
+// without columns replace whole lines and ranges with columns replace bytes
+// from the start column to the end column (exclusive) as GitHub suggestions
+// do.
+func suggestionEdit(li *lineIndex, s *rdf.Suggestion) (textEdit, bool) {
+	start, end := s.GetRange().GetStart(), s.GetRange().GetEnd()
+	startLine, endLine := int(start.GetLine()), int(end.GetLine())
+	if endLine < startLine {
+		endLine = startLine
+	}
+	if startLine <= 0 || endLine > li.lineCount() {
+		return textEdit{}, false
+	}
+	if start.GetColumn() <= 0 && end.GetColumn() <= 0 {
+		e := textEdit{start: li.lineStart(startLine), end: li.lineStart(endLine + 1), text: s.GetText()}
+		// Keep the line break of the last replaced line unless the lines are
+		// deleted.
+		if e.text != "" && strings.HasSuffix(li.content[e.start:e.end], "\n") {
+			e.text += "\n"
+		}
+		return e, true
+	}
+	startCol, endCol := int(start.GetColumn())-1, int(end.GetColumn())-1
+	if startCol < 0 {
+		startCol = 0
+	}
+	if endCol < 0 {
+		endCol = 0
+	}
+	if startCol > len(li.line(startLine)) || endCol > len(li.line(endLine)) {
+		return textEdit{}, false
+	}
+	e := textEdit{start: li.lineStart(startLine) + startCol, end: li.lineStart(endLine) + endCol, text: s.GetText()}
+	if e.start > e.end {
+		return textEdit{}, false
+	}
+	return e, true
+}
+
+// lineChange replaces old lines [first, last] of a file with newLines.
+type lineChange struct {
+	first, last int
+	newLines    []string
+}
+
+// newSuggestionFileDiff returns the unified diff of the file which applies
+// the suggestions, or empty string if nothing changes.
+func newSuggestionFileDiff(path, content string, suggestions []*rdf.Suggestion) string {
+	li := newLineIndex(content)
+	var edits []textEdit
+	for _, s := range suggestions {
+		e, ok := suggestionEdit(li, s)
+		if !ok {
+			log.Printf("reviewdog: skipped suggestion with invalid range at %s:%d", path, s.GetRange().GetStart().GetLine())
+			continue
+		}
+		edits = append(edits, e)
+	}
+	// Apply edits bottom-up so that offsets of the remaining edits don't move,
+	// and skip edits which overlap already applied ones.
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	var applied []textEdit
+	for _, e := range edits {
+		if len(applied) > 0 && e.end > applied[len(applied)-1].start {
+			log.Printf("reviewdog: skipped overlapping suggestion at %s:%d", path, li.lineOf(e.start))
+			continue
+		}
+		applied = append(applied, e)
+	}
+	changes := lineChanges(li, applied)
+	if len(changes) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", path, path)
+	writeHunks(&sb, li, changes)
+	return sb.String()
+}
+
+// editLines returns the first and last old lines which the edit touches. It
+// includes the next line if the edit joins it with the last replaced line.
+func editLines(li *lineIndex, e textEdit) (first, last int) {
+	first = li.lineOf(e.start)
+	if e.end <= e.start {
+		return first, first
+	}
+	last = li.lineOf(e.end - 1)
+	if e.end < len(li.content) && li.content[e.end-1] == '\n' {
+		joined := !strings.HasSuffix(e.text, "\n")
+		if e.text == "" {
+			joined = e.start > 0 && li.content[e.start-1] != '\n'
+		}
+		if joined {
+			last = li.lineOf(e.end)
+		}
+	}
+	return first, last
+}
+
+// lineChanges groups the edits, which are sorted bottom-up, by the lines they
+// touch and returns the line changes in top-down order.
+func lineChanges(li *lineIndex, edits []textEdit) []lineChange {
+	var changes []lineChange
+	for i := len(edits) - 1; i >= 0; {
+		first, last := editLines(li, edits[i])
+		// Collect the following edits which touch the same lines.
+		j := i - 1
+		for ; j >= 0 && li.lineOf(edits[j].start) <= last; j-- {
+			if _, l := editLines(li, edits[j]); l > last {
+				last = l
+			}
+		}
+		segStart, segEnd := li.lineStart(first), li.lineStart(last+1)
+		newText := li.content[segStart:segEnd]
+		// Apply edits of the group bottom-up.
+		for k := j + 1; k <= i; k++ {
+			e := edits[k]
+			newText = newText[:e.start-segStart] + e.text + newText[e.end-segStart:]
+		}
+		if newText != li.content[segStart:segEnd] {
+			changes = append(changes, lineChange{first: first, last: last, newLines: splitLines(newText)})
+		}
+		i = j
+	}
+	return changes
+}
+
+// splitLines splits text into lines keeping line breaks.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// writeHunks writes hunks of the changes with context lines. Changes close to
+// each other are written in the same hunk.
+func writeHunks(sb *strings.Builder, li *lineIndex, changes []lineChange) {
+	oldLine := func(lnum int) string {
+		return li.content[li.lineStart(lnum):li.lineStart(lnum+1)]
+	}
+	delta := 0 // The number of new lines minus old lines before the hunk.
+	for i := 0; i < len(changes); {
+		j := i + 1
+		for j < len(changes) && changes[j].first-changes[j-1].last-1 <= 2*suggestionDiffContext {
+			j++
+		}
+		first := changes[i].first - suggestionDiffContext
+		if first < 1 {
+			first = 1
+		}
+		last := changes[j-1].last + suggestionDiffContext
+		if last > li.lineCount() {
+			last = li.lineCount()
+		}
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		lnum := first
+		for _, c := range changes[i:j] {
+			for ; lnum < c.first; lnum++ {
+				writeDiffLine(&body, " ", oldLine(lnum))
+				oldCount++
+				newCount++
+			}
+			for ; lnum <= c.last; lnum++ {
+				writeDiffLine(&body, "-", oldLine(lnum))
+				oldCount++
+			}
+			for _, l := range c.newLines {
+				writeDiffLine(&body, "+", l)
+				newCount++
+			}
+		}
+		for ; lnum <= last; lnum++ {
+			writeDiffLine(&body, " ", oldLine(lnum))
+			oldCount++
+			newCount++
+		}
+		fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(first, oldCount), hunkRange(first+delta, newCount))
+		sb.WriteString(body.String())
+		delta += newCount - oldCount
+		i = j
+	}
+}
+
+func writeDiffLine(sb *strings.Builder, prefix, line string) {
+	sb.WriteString(prefix)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// hunkRange returns the range of a hunk header. Empty ranges start at the line
+// before the hunk.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,160 @@
+package reviewdog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestSuggestionDiffWriter(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"a.go":     "line1\nline2\nline3\nline4\nline5\nline6\nline7\nline8\nline9\nx := foo(bar)\nline11\n",
+		"b.txt":    "first\nlast",
+		"c.txt":    "unchanged\n",
+		"join.go":  "a(\nb)\nc\n",
+		"long.txt": "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := func(start, end int32) *rdf.Range {
+		return &rdf.Range{Start: &rdf.Position{Line: start}, End: &rdf.Position{Line: end}}
+	}
+	cols := func(startLine, startCol, endLine, endCol int32) *rdf.Range {
+		return &rdf.Range{
+			Start: &rdf.Position{Line: startLine, Column: startCol},
+			End:   &rdf.Position{Line: endLine, Column: endCol},
+		}
+	}
+	newComment := func(path string, suggestions ...*rdf.Suggestion) *Comment {
+		return &Comment{Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+			Location:    &rdf.Location{Path: path},
+			Suggestions: suggestions,
+		}}}
+	}
+	w := new(bytes.Buffer)
+	sw := NewSuggestionDiffWriter(w)
+	for _, c := range []*Comment{
+		newComment("a.go",
+			&rdf.Suggestion{Range: lines(2, 2), Text: "LINE2\nLINE2.5"},
+			&rdf.Suggestion{Range: lines(6, 6)}, // delete the line
+		),
+		newComment("a.go",
+			&rdf.Suggestion{Range: cols(2, 1, 2, 3), Text: "overlap"}, // skipped
+			&rdf.Suggestion{Range: cols(10, 6, 10, 9), Text: "baz"},
+			&rdf.Suggestion{Range: cols(10, 10, 10, 13), Text: "qux"},
+			&rdf.Suggestion{Range: lines(100, 100), Text: "invalid"}, // skipped
+		),
+		newComment("b.txt", &rdf.Suggestion{Range: lines(2, 2), Text: "LAST"}),
+		newComment("c.txt", &rdf.Suggestion{Range: lines(1, 1), Text: "unchanged"}),
+		newComment("join.go", &rdf.Suggestion{Range: cols(1, 3, 2, 1), Text: ""}),
+		newComment("long.txt",
+			&rdf.Suggestion{Range: lines(1, 1), Text: "1\n1.5"},
+			&rdf.Suggestion{Range: lines(11, 12), Text: "11-12"},
+		),
+		newComment("c.txt"), // no suggestions
+	} {
+		if err := sw.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sw.WriteDiff(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `--- a/a.go
++++ b/a.go
+@@ -1,11 +1,11 @@
+ line1
+-line2
++LINE2
++LINE2.5
+ line3
+ line4
+ line5
+-line6
+ line7
+ line8
+ line9
+-x := foo(bar)
++x := baz(qux)
+ line11
+--- a/b.txt
++++ b/b.txt
+@@ -1,2 +1,2 @@
+ first
+-last
+\ No newline at end of file
++LAST
+\ No newline at end of file
+--- a/join.go
++++ b/join.go
+@@ -1,3 +1,2 @@
+-a(
+-b)
++a(b)
+ c
+--- a/long.txt
++++ b/long.txt
+@@ -1,4 +1,5 @@
+-1
++1
++1.5
+ 2
+ 3
+ 4
+@@ -8,5 +9,4 @@
+ 8
+ 9
+ 10
+-11
+-12
++11-12
+`
+	if diff := cmp.Diff(want, w.String()); diff != "" {
+		t.Fatalf("diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	cmd := exec.Command("git", "apply", "-")
+	cmd.Stdin = bytes.NewReader(w.Bytes())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git apply: %v\n%s", err, out)
+	}
+	wantFiles := map[string]string{
+		"a.go":     "line1\nLINE2\nLINE2.5\nline3\nline4\nline5\nline7\nline8\nline9\nx := baz(qux)\nline11\n",
+		"b.txt":    "first\nLAST",
+		"c.txt":    "unchanged\n",
+		"join.go":  "a(b)\nc\n",
+		"long.txt": "1\n1.5\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11-12\n",
+	}
+	for path, want := range wantFiles {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
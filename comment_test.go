@@ -35,6 +35,40 @@ func TestMultiCommentService_Post(t *testing.T) {
 	}
 }
 
+type fakeGroupingCommentService struct {
+	posted []*Comment
+}
+
+func (f *fakeGroupingCommentService) Post(_ context.Context, c *Comment) error {
+	f.posted = append(f.posted, c)
+	return nil
+}
+
+func (f *fakeGroupingCommentService) GroupsResults() {}
+
+func TestMultiCommentService_Post_duplicates(t *testing.T) {
+	newResult := func(output string) *filter.FilteredDiagnostic {
+		return &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{OriginalOutput: output}, ShouldReport: true}
+	}
+	c := &Comment{Result: newResult("a")}
+	dup := newResult("b")
+	dup.ShouldReport = false
+	c.Result.Duplicates = []*filter.FilteredDiagnostic{dup}
+
+	buf := new(bytes.Buffer)
+	g := &fakeGroupingCommentService{}
+	w := MultiCommentService(g, NewRawCommentWriter(buf))
+	if err := w.Post(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.posted) != 1 || g.posted[0] != c {
+		t.Errorf("grouping service got %d comments, want the grouped comment", len(g.posted))
+	}
+	if got, want := buf.String(), "a\nb\n"; got != want {
+		t.Errorf("raw writer got %q, want each result %q", got, want)
+	}
+}
+
 type fakeBulkCommentService struct {
 	BulkCommentService
 	calledFlush bool
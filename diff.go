@@ -1,22 +1,41 @@
 package reviewdog
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
 	"os/exec"
+	"strings"
 	"sync"
+
+	"github.com/reviewdog/reviewdog/diff"
 )
 
 var _ DiffService = &DiffString{}
 
+// DiffString is an in-memory DiffService which returns the given diff as is.
+// It needs neither git nor network, so it's useful for tests and for library
+// users who already have the diff.
 type DiffString struct {
 	b     []byte
 	strip int
 }
 
+// NewDiffString returns a new DiffString which returns diff with the strip
+// level (e.g. 1 for `git diff` output).
 func NewDiffString(diff string, strip int) DiffService {
 	return &DiffString{b: []byte(diff), strip: strip}
 }
 
+// NewDiffBytes is the same as NewDiffString but takes the diff as bytes,
+// which are returned without copying.
+func NewDiffBytes(diff []byte, strip int) DiffService {
+	return &DiffString{b: diff, strip: strip}
+}
+
 func (d *DiffString) Diff(_ context.Context) ([]byte, error) {
 	return d.b, nil
 }
@@ -61,6 +80,109 @@ func (d *DiffCmd) Strip() int {
 	return d.strip
 }
 
+var _ DiffService = &DiffFile{}
+
+// DiffFile is a DiffService which reads a unified diff from a file, e.g. a
+// diff artifact of CI. It doesn't need git.
+type DiffFile struct {
+	path  string
+	strip int
+}
+
+func NewDiffFile(path string, strip int) *DiffFile {
+	return &DiffFile{path: path, strip: strip}
+}
+
+// Diff reads the diff file and returns an error if it's not a unified diff.
+// An empty file is treated as an empty diff.
+func (d *DiffFile) Diff(_ context.Context) ([]byte, error) {
+	b, err := os.ReadFile(d.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read diff file: %w", err)
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return b, nil
+	}
+	filediffs, err := diff.ParseMultiFile(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse diff file %s: %w", d.path, err)
+	}
+	for _, fd := range filediffs {
+		if fd.PathOld == "" && fd.PathNew == "" {
+			return nil, fmt.Errorf("invalid diff file %s: file header (---/+++) not found", d.path)
+		}
+	}
+	if len(filediffs) == 0 {
+		return nil, fmt.Errorf("invalid diff file %s: no unified diff found", d.path)
+	}
+	return b, nil
+}
+
+func (d *DiffFile) Strip() int {
+	return d.strip
+}
+
+var _ DiffService = &GitDiff{}
+
+// GitDiff is a DiffService which diffs HEAD against a base revision with git,
+// e.g. the commit before the push of push builds without Pull Requests.
+type GitDiff struct {
+	base     string
+	fallback string
+}
+
+// NewGitDiff returns a new GitDiff. The diff is taken from the merge-base of
+// base and HEAD, so it works even if base is not an ancestor of HEAD (e.g.
+// after force-pushes). If base is empty or doesn't exist in the repository,
+// the merge-base of fallback and HEAD is used instead if fallback is not
+// empty.
+func NewGitDiff(base, fallback string) *GitDiff {
+	return &GitDiff{base: base, fallback: fallback}
+}
+
+// Diff returns the diff between the merge-base and HEAD.
+func (d *GitDiff) Diff(ctx context.Context) ([]byte, error) {
+	mergeBase, err := d.mergeBase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out, err := exec.CommandContext(ctx, "git", "diff", "--find-renames", mergeBase, "HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git diff: %w", err)
+	}
+	return out, nil
+}
+
+func (d *GitDiff) mergeBase(ctx context.Context) (string, error) {
+	base := d.base
+	if base == "" || !gitCommitExists(ctx, base) {
+		if d.fallback == "" && base == "" {
+			return "", errors.New("base revision is not available")
+		}
+		if d.fallback == "" {
+			return "", fmt.Errorf("base revision %q not found", base)
+		}
+		if base != "" {
+			log.Printf("reviewdog: base revision %q not found. Use merge-base with %q instead", base, d.fallback)
+		}
+		base = d.fallback
+	}
+	out, err := exec.CommandContext(ctx, "git", "merge-base", base, "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get merge-base of %q and HEAD: %w", base, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func gitCommitExists(ctx context.Context, rev string) bool {
+	return exec.CommandContext(ctx, "git", "rev-parse", "--quiet", "--verify", rev+"^{commit}").Run() == nil
+}
+
+// Strip returns 1 as a strip of git diff.
+func (d *GitDiff) Strip() int {
+	return 1
+}
+
 // EmptyDiff service return empty diff.
 type EmptyDiff struct{}
 
@@ -2,6 +2,8 @@
 // https://en.wikipedia.org/wiki/Diff_utility#Unified_format
 package diff
 
+import "strings"
+
 // FileDiff represents a unified diff for a single file.
 //
 // Example:
@@ -27,6 +29,30 @@ type FileDiff struct {
 	// old and new file.
 }
 
+// IsBinary returns true if the diff is for a binary file, which doesn't have
+// hunks.
+func (fd *FileDiff) IsBinary() bool {
+	for _, line := range fd.Extended {
+		if strings.HasPrefix(line, tokenBinaryFiles) || line == "GIT binary patch" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDeleted returns true if the diff deletes the file.
+func (fd *FileDiff) IsDeleted() bool {
+	if fd.PathNew == "/dev/null" {
+		return true
+	}
+	for _, line := range fd.Extended {
+		if strings.HasPrefix(line, "deleted file mode ") {
+			return true
+		}
+	}
+	return false
+}
+
 // Hunk represents change hunks that contain the line differences in the file.
 //
 // Example:
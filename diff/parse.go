@@ -19,6 +19,8 @@ const (
 	tokenAddedLine      = "+"    // +added line
 	tokenDeletedLine    = "-"    // -deleted line
 	tokenNoNewlineAtEOF = `\`    // \ No newline at end of file
+
+	tokenBinaryFiles = "Binary files " // Binary files a/img.png and b/img.png differ
 )
 
 var (
@@ -71,6 +73,20 @@ type fileParser struct {
 func (p *fileParser) Parse() (*FileDiff, error) {
 	fd := &FileDiff{}
 	fd.Extended = parseExtendedHeader(p.r)
+	if len(fd.Extended) == 0 {
+		// `diff -r` reports binary files without other headers.
+		if b, err := p.r.Peek(len(tokenBinaryFiles)); err == nil && bytes.HasPrefix(b, []byte(tokenBinaryFiles)) {
+			line, _ := readHeaderLine(p.r)
+			fd.Extended = []string{line}
+			fd.PathOld, fd.PathNew = parseBinaryFilesLine(line)
+			return fd, nil
+		}
+	}
+	for _, line := range fd.Extended {
+		if strings.HasPrefix(line, tokenBinaryFiles) {
+			fd.PathOld, fd.PathNew = parseBinaryFilesLine(line)
+		}
+	}
 	b, err := p.r.Peek(len(tokenOldFile))
 	if err != nil {
 		if err == io.EOF && len(fd.Extended) > 0 {
@@ -80,13 +96,13 @@ func (p *fileParser) Parse() (*FileDiff, error) {
 	}
 	if bytes.HasPrefix(b, []byte(tokenOldFile)) {
 		// parse `--- sample.old.txt	2016-10-13 05:09:35.820791185 +0900`
-		oldline, _ := readline(p.r) // ignore err because we know it can read something
+		oldline, _ := readHeaderLine(p.r) // ignore err because we know it can read something
 		fd.PathOld, fd.TimeOld = parseFileHeader(oldline)
 		// parse `+++ sample.new.txt	2016-10-13 05:09:35.820791185 +0900`
 		if b, err := p.r.Peek(len(tokenNewFile)); err != nil || !bytes.HasPrefix(b, []byte(tokenNewFile)) {
 			return nil, ErrNoNewFile
 		}
-		newline, _ := readline(p.r) // ignore err because we know it can read something
+		newline, _ := readHeaderLine(p.r) // ignore err because we know it can read something
 		fd.PathNew, fd.TimeNew = parseFileHeader(newline)
 	}
 	// parse hunks
@@ -129,6 +145,18 @@ func (p *fileParser) parseHunks() ([]*Hunk, error) {
 	return hunks, nil
 }
 
+// parseBinaryFilesLine parses `Binary files a/img.png and b/img.png differ`
+// line and returns the old and new paths. It returns empty paths if paths are
+// ambiguous.
+func parseBinaryFilesLine(line string) (oldpath, newpath string) {
+	ss := strings.TrimSuffix(strings.TrimPrefix(line, tokenBinaryFiles), " differ")
+	paths := strings.Split(ss, " and ")
+	if len(paths) != 2 {
+		return "", ""
+	}
+	return unquoteCStyle(paths[0]), unquoteCStyle(paths[1])
+}
+
 // parseFileHeader parses file header line and returns filename and timestamp.
 // timestamp may be empty.
 func parseFileHeader(line string) (filename, timestamp string) {
@@ -217,14 +245,14 @@ func parseExtendedHeader(r *bufio.Reader) []string {
 	}
 	// if starts with 'diff', parse extended header
 	if bytes.HasPrefix(b, []byte(tokenDiff)) {
-		diffgitline, _ := readline(r) // ignore err because we know it can read something
+		diffgitline, _ := readHeaderLine(r) // ignore err because we know it can read something
 		es = append(es, diffgitline)
 		for {
 			b, err := r.Peek(len(tokenDiff))
 			if err != nil || bytes.HasPrefix(b, []byte(tokenOldFile)) || bytes.HasPrefix(b, []byte(tokenDiff)) {
 				break
 			}
-			line, _ := readline(r)
+			line, _ := readHeaderLine(r)
 			es = append(es, line)
 		}
 	}
@@ -240,7 +268,7 @@ func (p *hunkParser) Parse() (*Hunk, error) {
 	if b, err := p.r.Peek(len(tokenStartHunk)); err != nil || !bytes.HasPrefix(b, []byte(tokenStartHunk)) {
 		return nil, nil
 	}
-	rangeline, _ := readline(p.r)
+	rangeline, _ := readHeaderLine(p.r)
 	hr, err := parseHunkRange(rangeline)
 	if err != nil {
 		return nil, err
@@ -385,3 +413,12 @@ func readline(r *bufio.Reader) (string, error) {
 	}
 	return string(line), nil
 }
+
+// readHeaderLine reads a whole line of headers. ReadLine strips "\r\n" as well
+// as "\n", and it also strips remaining "\r" so that diffs saved with CRLF
+// line endings (e.g. "\r\r\n" on Windows) have the same paths as LF ones.
+// Lines of hunks are read with readline to keep their contents as they are.
+func readHeaderLine(r *bufio.Reader) (string, error) {
+	line, err := readline(r)
+	return strings.TrimRight(line, "\r"), err
+}
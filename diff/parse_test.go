@@ -141,6 +141,100 @@ func TestParseMultiFile_sample(t *testing.T) {
 	}
 }
 
+func TestParseMultiFile_CRLF(t *testing.T) {
+	in := strings.Join([]string{
+		"diff --git a/a.txt b/a.txt",
+		"--- a/a.txt",
+		"+++ b/a.txt",
+		"@@ -1 +1,2 @@",
+		" line1",
+		"+line2",
+		"",
+	}, "\r\r\n")
+	difffiles, err := ParseMultiFile(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(difffiles) != 1 {
+		t.Fatalf("got %d file diffs, want 1", len(difffiles))
+	}
+	fd := difffiles[0]
+	if fd.PathOld != "a/a.txt" || fd.PathNew != "b/a.txt" {
+		t.Errorf("got paths %q and %q, want %q and %q", fd.PathOld, fd.PathNew, "a/a.txt", "b/a.txt")
+	}
+	if want := []string{"diff --git a/a.txt b/a.txt"}; !reflect.DeepEqual(fd.Extended, want) {
+		t.Errorf("got extended headers %q, want %q", fd.Extended, want)
+	}
+	// Contents are kept as they are.
+	var contents []string
+	for _, l := range fd.Hunks[0].Lines {
+		contents = append(contents, l.Content)
+	}
+	if want := []string{"line1\r", "line2\r"}; !reflect.DeepEqual(contents, want) {
+		t.Errorf("got contents %q, want %q", contents, want)
+	}
+}
+
+func TestParseMultiFile_binaryAndDeleted(t *testing.T) {
+	tests := []struct {
+		file        string
+		wantPaths   []string
+		wantBinary  []bool
+		wantDeleted []bool
+	}{
+		{
+			file:        "testdata/binary.diff",
+			wantPaths:   []string{"b/binary.new.bin", "b/sample.new.txt"},
+			wantBinary:  []bool{true, false},
+			wantDeleted: []bool{false, false},
+		},
+		{
+			file:        "testdata/binary_plain.diff",
+			wantPaths:   []string{"binary.new.bin", "sample.new.txt"},
+			wantBinary:  []bool{true, false},
+			wantDeleted: []bool{false, false},
+		},
+		{
+			file:        "testdata/deleted.diff",
+			wantPaths:   []string{"/dev/null", "b/golint.new.go"},
+			wantBinary:  []bool{false, false},
+			wantDeleted: []bool{true, false},
+		},
+		{
+			file:        "testdata/empty_deleted.diff",
+			wantPaths:   []string{""},
+			wantBinary:  []bool{false},
+			wantDeleted: []bool{true},
+		},
+	}
+	for _, tt := range tests {
+		f, err := os.Open(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fds, err := ParseMultiFile(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fds) != len(tt.wantPaths) {
+			t.Errorf("%s: got %d file diffs, want %d", tt.file, len(fds), len(tt.wantPaths))
+			continue
+		}
+		for i, fd := range fds {
+			if fd.PathNew != tt.wantPaths[i] {
+				t.Errorf("%s: [%d].PathNew = %q, want %q", tt.file, i, fd.PathNew, tt.wantPaths[i])
+			}
+			if got := fd.IsBinary(); got != tt.wantBinary[i] {
+				t.Errorf("%s: [%d].IsBinary() = %v, want %v", tt.file, i, got, tt.wantBinary[i])
+			}
+			if got := fd.IsDeleted(); got != tt.wantDeleted[i] {
+				t.Errorf("%s: [%d].IsDeleted() = %v, want %v", tt.file, i, got, tt.wantDeleted[i])
+			}
+		}
+	}
+}
+
 func TestFileParser_Parse(t *testing.T) {
 	tests := []struct {
 		in   string
@@ -452,6 +546,10 @@ line2
 line3`,
 			out: []string{longLine, "line2", "line3"},
 		},
+		{
+			in:  "line1\r\nline2\r\r\nline3\r",
+			out: []string{"line1", "line2\r", "line3\r"},
+		},
 	}
 	for _, tt := range tests {
 		r := bufio.NewReader(strings.NewReader(tt.in))
@@ -13,3 +13,9 @@ git diff --no-index empty.txt /dev/null > newline_and_empty_deleted.diff
 git diff --no-index golint.{old,new}.go >> newline_and_empty_deleted.diff
 git -c core.quotepath=true diff --no-index 日本語.{old,new}.txt > 日本語.diff
 gofmt -d gofmt.go > gofmt.diff
+git diff --no-index binary.{old,new}.bin > binary.diff
+git diff --no-index sample.{old,new}.txt >> binary.diff
+diff -u binary.{old,new}.bin > binary_plain.diff
+diff -u sample.{old,new}.txt >> binary_plain.diff
+git diff --no-index sample.old.txt /dev/null > deleted.diff
+git diff --no-index golint.{old,new}.go >> deleted.diff
@@ -39,6 +39,26 @@ index 34cacb9..a727dd3 100644
 	}
 }
 
+func TestDiffBytes(t *testing.T) {
+	difftext := []byte(`--- golint.old.go
++++ golint.new.go
+@@ -1 +1,2 @@
+ package test
++var V int
+`)
+	d := NewDiffBytes(difftext, 0)
+	b, err := d.Diff(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != string(difftext) {
+		t.Errorf("got:\n%s\nwant:\n%s", b, difftext)
+	}
+	if d.Strip() != 0 {
+		t.Errorf("Strip() = %d, want 0", d.Strip())
+	}
+}
+
 func TestDiffCmd(t *testing.T) {
 	wantb, err := os.ReadFile("./diff/testdata/golint.diff")
 	if err != nil {
@@ -65,3 +85,107 @@ func TestDiffCmd(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestDiffFile(t *testing.T) {
+	wantb, err := os.ReadFile("./diff/testdata/golint.diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDiffFile("./diff/testdata/golint.diff", 1)
+	b, err := d.Diff(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != string(wantb) {
+		t.Errorf("got:\n%v\nwant:\n%v", got, string(wantb))
+	}
+	if got := d.Strip(); got != 1 {
+		t.Errorf("Strip() = %d, want 1", got)
+	}
+}
+
+func TestDiffFile_invalid(t *testing.T) {
+	dir := t.TempDir()
+	for _, content := range []string{
+		"this is not a diff\n",
+		"@@ -1,3 +1,4 @@\n line\n+added\n",
+	} {
+		path := dir + "/invalid.diff"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewDiffFile(path, 1).Diff(context.Background()); err == nil {
+			t.Errorf("got no error for %q", content)
+		}
+	}
+	if _, err := NewDiffFile(dir+"/not-found.diff", 1).Diff(context.Background()); err == nil {
+		t.Error("got no error for non-existent file")
+	}
+}
+
+func TestGitDiff(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(content string) string {
+		t.Helper()
+		if err := os.WriteFile("a.txt", []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "a.txt")
+		git("commit", "-q", "-m", content)
+		return git("rev-parse", "HEAD")
+	}
+	git("init", "-q")
+	base := commit("a\n")
+	pushed := commit("a\nb\n")
+	// Force-push another commit on the base.
+	git("reset", "-q", "--hard", base)
+	commit("a\nc\n")
+
+	tests := []struct {
+		name     string
+		base     string
+		fallback string
+		want     string // the added line
+		wantErr  bool
+	}{
+		{name: "base", base: base, want: "+c"},
+		{name: "force-pushed", base: pushed, want: "+c"},
+		{name: "missing base", base: "deadbeef", fallback: base, want: "+c"},
+		{name: "no base", fallback: base, want: "+c"},
+		{name: "missing base without fallback", base: "deadbeef", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewGitDiff(tt.base, tt.fallback)
+			b, err := d.Diff(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got no error, diff:\n%s", b)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(b), "\n"+tt.want+"\n") || strings.Contains(string(b), "+b") {
+				t.Errorf("unexpected diff:\n%s", b)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/go-github/v39/github"
+	"github.com/vvakame/sdlog/aelog"
 
 	"github.com/reviewdog/reviewdog/diff"
 	"github.com/reviewdog/reviewdog/doghouse"
@@ -72,18 +73,24 @@ func (ch *Checker) Check(ctx context.Context) (*doghouse.CheckResponse, error) {
 		return nil, fmt.Errorf("failed to create check: %w", err)
 	}
 
-	checkRun, conclusion, err := ch.postCheck(ctx, check.GetID(), filtered)
+	// Annotations of a reused check run are posted by the failed attempt in
+	// order, so skip them not to post them twice.
+	posted := check.GetOutput().GetAnnotationsCount()
+	checkRun, conclusion, err := ch.postCheck(ctx, check.GetID(), filtered, posted)
 	if err != nil {
 		return nil, fmt.Errorf("failed to post result: %w", err)
 	}
 	res := &doghouse.CheckResponse{
-		ReportURL:  checkRun.GetHTMLURL(),
-		Conclusion: conclusion,
+		ReportURL:      checkRun.GetHTMLURL(),
+		CheckedResults: filtered,
+		Conclusion:     conclusion,
 	}
 	return res, nil
 }
 
-func (ch *Checker) postCheck(ctx context.Context, checkID int64, checks []*filter.FilteredDiagnostic) (*github.CheckRun, string, error) {
+// postCheck posts annotations of the checks except for the first posted ones
+// and completes the check run.
+func (ch *Checker) postCheck(ctx context.Context, checkID int64, checks []*filter.FilteredDiagnostic, posted int) (*github.CheckRun, string, error) {
 	var annotations []*github.CheckRunAnnotation
 	for _, c := range checks {
 		if !c.ShouldReport {
@@ -91,24 +98,31 @@ func (ch *Checker) postCheck(ctx context.Context, checkID int64, checks []*filte
 		}
 		annotations = append(annotations, ch.toCheckRunAnnotation(c))
 	}
+	conclusion := "success"
 	if len(annotations) > 0 {
-		if err := ch.postAnnotations(ctx, checkID, annotations); err != nil {
+		conclusion = ch.conclusion()
+	}
+	annotations = annotations[min(posted, len(annotations)):]
+	// Post all but the last batch of annotations first. The last batch is
+	// posted together with the conclusion to complete the check run.
+	lastBatch := annotations
+	if len(annotations) > maxAnnotationsPerRequest {
+		n := (len(annotations) - 1) / maxAnnotationsPerRequest * maxAnnotationsPerRequest
+		if err := ch.postAnnotations(ctx, checkID, annotations[:n]); err != nil {
 			return nil, "", fmt.Errorf("failed to post annotations: %w", err)
 		}
+		lastBatch = annotations[n:]
 	}
 
-	conclusion := "success"
-	if len(annotations) > 0 {
-		conclusion = ch.conclusion()
-	}
 	opt := github.UpdateCheckRunOptions{
 		Name:        ch.checkName(),
 		Status:      github.String("completed"),
 		Conclusion:  github.String(conclusion),
 		CompletedAt: &github.Timestamp{Time: time.Now()},
 		Output: &github.CheckRunOutput{
-			Title:   github.String(ch.checkTitle()),
-			Summary: github.String(ch.summary(checks)),
+			Title:       github.String(ch.checkTitle()),
+			Summary:     github.String(ch.summary(checks)),
+			Annotations: lastBatch,
 		},
 	}
 	checkRun, err := ch.gh.UpdateCheckRun(ctx, ch.req.Owner, ch.req.Repo, checkID, opt)
@@ -118,15 +132,52 @@ func (ch *Checker) postCheck(ctx context.Context, checkID int64, checks []*filte
 	return checkRun, conclusion, nil
 }
 
+// createCheck creates a new in-progress check run, or returns an existing
+// in-progress one created by a previous attempt of the same request (see
+// CheckRequest.IdempotencyKey) which failed before completing it. Reusing it
+// makes retries idempotent instead of leaving duplicate check runs.
 func (ch *Checker) createCheck(ctx context.Context) (*github.CheckRun, error) {
+	if checkRun := ch.inProgressCheck(ctx); checkRun != nil {
+		return checkRun, nil
+	}
 	opt := github.CreateCheckRunOptions{
 		Name:    ch.checkName(),
 		HeadSHA: ch.req.SHA,
 		Status:  github.String("in_progress"),
 	}
+	if key := ch.req.IdempotencyKey; key != "" {
+		opt.ExternalID = github.String(key)
+	}
 	return ch.gh.CreateCheckRun(ctx, ch.req.Owner, ch.req.Repo, opt)
 }
 
+// inProgressCheck returns an in-progress check run with the same name, head
+// SHA and idempotency key if any. Check runs of other requests (e.g.
+// concurrent runs on the same SHA) are never reused. It returns nil if the
+// request has no idempotency key or the lookup fails so that a new check run
+// is created as before.
+func (ch *Checker) inProgressCheck(ctx context.Context) *github.CheckRun {
+	if ch.req.IdempotencyKey == "" {
+		return nil
+	}
+	opt := &github.ListCheckRunsOptions{
+		CheckName: github.String(ch.checkName()),
+		Status:    github.String("in_progress"),
+	}
+	checkRuns, err := ch.gh.ListCheckRunsForRef(ctx, ch.req.Owner, ch.req.Repo, ch.req.SHA, opt)
+	if err != nil {
+		aelog.Warningf(ctx, "failed to list check runs to reuse: %v", err)
+		return nil
+	}
+	for _, checkRun := range checkRuns {
+		if checkRun.GetName() == ch.checkName() && checkRun.GetHeadSHA() == ch.req.SHA &&
+			checkRun.GetStatus() == "in_progress" && checkRun.GetExternalID() == ch.req.IdempotencyKey {
+			return checkRun
+		}
+	}
+	return nil
+}
+
 func (ch *Checker) postAnnotations(ctx context.Context, checkID int64, annotations []*github.CheckRunAnnotation) error {
 	opt := github.UpdateCheckRunOptions{
 		Name: ch.checkName(),
@@ -168,6 +219,9 @@ func (ch *Checker) conclusion() string {
 	return "failure"
 }
 
+// annotationLevel maps the diagnostic severity to annotation_level. Results
+// without severity are "notice" regardless of the requested level.
+//
 // https://developer.github.com/v3/checks/runs/#annotations-object
 func (ch *Checker) annotationLevel(s rdf.Severity) string {
 	switch s {
@@ -175,23 +229,9 @@ func (ch *Checker) annotationLevel(s rdf.Severity) string {
 		return "failure"
 	case rdf.Severity_WARNING:
 		return "warning"
-	case rdf.Severity_INFO:
-		return "notice"
 	default:
-		return ch.reqAnnotationLevel()
-	}
-}
-
-func (ch *Checker) reqAnnotationLevel() string {
-	switch strings.ToLower(ch.req.Level) {
-	case "info":
 		return "notice"
-	case "warning":
-		return "warning"
-	case "failure":
-		return "failure"
 	}
-	return "failure"
 }
 
 func (ch *Checker) summary(checks []*filter.FilteredDiagnostic) string {
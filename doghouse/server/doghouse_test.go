@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -19,6 +20,7 @@ type fakeCheckerGitHubCli struct {
 	FakeGetPullRequestDiff func(ctx context.Context, owner, repo string, number int) ([]byte, error)
 	FakeCreateCheckRun     func(ctx context.Context, owner, repo string, opt github.CreateCheckRunOptions) (*github.CheckRun, error)
 	FakeUpdateCheckRun     func(ctx context.Context, owner, repo string, checkID int64, opt github.UpdateCheckRunOptions) (*github.CheckRun, error)
+	FakeListCheckRuns      func(ctx context.Context, owner, repo, ref string, opt *github.ListCheckRunsOptions) ([]*github.CheckRun, error)
 }
 
 func (f *fakeCheckerGitHubCli) GetPullRequestDiff(ctx context.Context, owner, repo string, number int) ([]byte, error) {
@@ -33,6 +35,13 @@ func (f *fakeCheckerGitHubCli) UpdateCheckRun(ctx context.Context, owner, repo s
 	return f.FakeUpdateCheckRun(ctx, owner, repo, checkID, opt)
 }
 
+func (f *fakeCheckerGitHubCli) ListCheckRunsForRef(ctx context.Context, owner, repo, ref string, opt *github.ListCheckRunsOptions) ([]*github.CheckRun, error) {
+	if f.FakeListCheckRuns == nil {
+		return nil, nil
+	}
+	return f.FakeListCheckRuns(ctx, owner, repo, ref, opt)
+}
+
 const sampleDiff = `--- a/sample.old.txt	2016-10-13 05:09:35.820791185 +0900
 +++ b/sample.new.txt	2016-10-13 05:15:26.839245048 +0900
 @@ -1,3 +1,4 @@
@@ -210,17 +219,16 @@ func TestCheck_OK(t *testing.T) {
 			t.Errorf("UpdateCheckRunOptions.Name = %q, want %q", opt.Name, name)
 		}
 		annotations := opt.Output.Annotations
-		if len(annotations) == 0 {
-			if *opt.Conclusion != conclusion {
-				t.Errorf("UpdateCheckRunOptions.Conclusion = %q, want %q", *opt.Conclusion, conclusion)
-			}
-		} else {
+		if opt.Conclusion != nil && *opt.Conclusion != conclusion {
+			t.Errorf("UpdateCheckRunOptions.Conclusion = %q, want %q", *opt.Conclusion, conclusion)
+		}
+		if len(annotations) > 0 {
 			wantAnnotations := []*github.CheckRunAnnotation{
 				{
 					Path:            github.String("sample.new.txt"),
 					StartLine:       github.Int(2),
 					EndLine:         github.Int(2),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("test message"),
 					Title:           github.String("[haya14busa-linter] sample.new.txt#L2"),
 					RawDetails:      github.String("raw test message"),
@@ -229,7 +237,7 @@ func TestCheck_OK(t *testing.T) {
 					Path:            github.String("sample.new.txt"),
 					StartLine:       github.Int(2),
 					EndLine:         github.Int(3),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("test multiline"),
 					Title:           github.String("[haya14busa-linter] sample.new.txt#L2-L3"),
 				},
@@ -237,7 +245,7 @@ func TestCheck_OK(t *testing.T) {
 					Path:            github.String("sample.new.txt"),
 					StartLine:       github.Int(2),
 					EndLine:         github.Int(3),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("test multiline with column"),
 					Title:           github.String("[haya14busa-linter] sample.new.txt#L2-L3"),
 				},
@@ -247,7 +255,7 @@ func TestCheck_OK(t *testing.T) {
 					EndLine:         github.Int(2),
 					StartColumn:     github.Int(1),
 					EndColumn:       github.Int(5),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("test range comment"),
 					Title:           github.String("[haya14busa-linter] sample.new.txt#L2"),
 				},
@@ -263,7 +271,7 @@ func TestCheck_OK(t *testing.T) {
 					Path:            github.String("sample.new.txt"),
 					StartLine:       github.Int(2),
 					EndLine:         github.Int(2),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("source test"),
 					Title:           github.String("[awesome-linter] sample.new.txt#L2"),
 				},
@@ -271,7 +279,7 @@ func TestCheck_OK(t *testing.T) {
 					Path:            github.String("sample.new.txt"),
 					StartLine:       github.Int(2),
 					EndLine:         github.Int(2),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("code test w/o URL"),
 					Title:           github.String("[haya14busa-linter] sample.new.txt#L2 <CODE14>"),
 				},
@@ -279,7 +287,7 @@ func TestCheck_OK(t *testing.T) {
 					Path:            github.String("sample.new.txt"),
 					StartLine:       github.Int(2),
 					EndLine:         github.Int(2),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("code test w/ URL"),
 					Title:           github.String("[haya14busa-linter] sample.new.txt#L2 <CODE14>(https://github.com/reviewdog#CODE14)"),
 				},
@@ -287,7 +295,7 @@ func TestCheck_OK(t *testing.T) {
 					Path:            github.String("sample.new.txt"),
 					StartLine:       github.Int(2),
 					EndLine:         github.Int(2),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("request from old clients"),
 					Title:           github.String("[haya14busa-linter] sample.new.txt#L2"),
 					RawDetails:      github.String("raw message from old clients"),
@@ -368,17 +376,16 @@ func testOutsideDiff(t *testing.T, outsideDiff bool, filterMode filter.Mode) {
 	}
 	cli.FakeUpdateCheckRun = func(ctx context.Context, owner, repo string, checkID int64, opt github.UpdateCheckRunOptions) (*github.CheckRun, error) {
 		annotations := opt.Output.Annotations
-		if len(annotations) == 0 {
-			if *opt.Conclusion != conclusion {
-				t.Errorf("UpdateCheckRunOptions.Conclusion = %q, want %q", *opt.Conclusion, conclusion)
-			}
-		} else {
+		if opt.Conclusion != nil && *opt.Conclusion != conclusion {
+			t.Errorf("UpdateCheckRunOptions.Conclusion = %q, want %q", *opt.Conclusion, conclusion)
+		}
+		if len(annotations) > 0 {
 			wantAnnotations := []*github.CheckRunAnnotation{
 				{
 					Path:            github.String("sample.new.txt"),
 					StartLine:       github.Int(2),
 					EndLine:         github.Int(2),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("test message"),
 					Title:           github.String("[haya14busa-linter] sample.new.txt#L2"),
 					RawDetails:      github.String("raw test message"),
@@ -387,7 +394,7 @@ func testOutsideDiff(t *testing.T, outsideDiff bool, filterMode filter.Mode) {
 					Path:            github.String("sample.new.txt"),
 					StartLine:       github.Int(14),
 					EndLine:         github.Int(14),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("test message outside diff"),
 					Title:           github.String("[haya14busa-linter] sample.new.txt#L14"),
 					RawDetails:      github.String("raw test message outside diff"),
@@ -424,6 +431,9 @@ func TestCheck_OK_multiple_update_runs(t *testing.T) {
 		reportURL   = "http://example.com/report_url"
 		conclusion  = "neutral"
 		wantCheckID = 1414
+
+		numAnnotations   = 120
+		wantUpdateCalled = 3
 	)
 
 	req := &doghouse.CheckRequest{
@@ -434,7 +444,7 @@ func TestCheck_OK_multiple_update_runs(t *testing.T) {
 		SHA:         sha,
 		Level:       "warning",
 	}
-	for i := 0; i < 101; i++ {
+	for i := 0; i < numAnnotations; i++ {
 		req.Annotations = append(req.Annotations, &doghouse.Annotation{
 			Diagnostic: &rdf.Diagnostic{
 				Message: "test message",
@@ -462,19 +472,27 @@ func TestCheck_OK_multiple_update_runs(t *testing.T) {
 		}
 		return &github.CheckRun{ID: github.Int64(wantCheckID)}, nil
 	}
+	updateCalled := 0
 	cli.FakeUpdateCheckRun = func(ctx context.Context, owner, repo string, checkID int64, opt github.UpdateCheckRunOptions) (*github.CheckRun, error) {
 		if checkID != wantCheckID {
 			t.Errorf("UpdateCheckRun: checkID = %d, want %d", checkID, wantCheckID)
 		}
+		updateCalled++
 		annotations := opt.Output.Annotations
-		switch len(annotations) {
-		case 0:
-			if *opt.Conclusion != conclusion {
-				t.Errorf("UpdateCheckRunOptions.Conclusion = %q, want %q", *opt.Conclusion, conclusion)
+		if updateCalled < wantUpdateCalled {
+			if opt.Conclusion != nil {
+				t.Errorf("UpdateCheckRun: conclusion %q is set before the last batch", *opt.Conclusion)
+			}
+			if len(annotations) != maxAnnotationsPerRequest {
+				t.Errorf("UpdateCheckRun: len(annotations) = %d, want %d", len(annotations), maxAnnotationsPerRequest)
+			}
+		} else {
+			if opt.Conclusion == nil || *opt.Conclusion != conclusion {
+				t.Errorf("UpdateCheckRunOptions.Conclusion = %v, want %q", opt.Conclusion, conclusion)
+			}
+			if want := numAnnotations % maxAnnotationsPerRequest; len(annotations) != want {
+				t.Errorf("UpdateCheckRun: len(annotations) = %d, want %d", len(annotations), want)
 			}
-		case maxAnnotationsPerRequest, 1: // Expected
-		default:
-			t.Errorf("UpdateCheckRun: len(annotations) = %d, but it's unexpected", len(annotations))
 		}
 		return &github.CheckRun{HTMLURL: github.String(reportURL)}, nil
 	}
@@ -482,6 +500,9 @@ func TestCheck_OK_multiple_update_runs(t *testing.T) {
 	if _, err := checker.Check(context.Background()); err != nil {
 		t.Fatal(err)
 	}
+	if updateCalled != wantUpdateCalled {
+		t.Errorf("UpdateCheckRun called %d times, want %d", updateCalled, wantUpdateCalled)
+	}
 }
 
 func TestCheck_OK_nonPullRequests(t *testing.T) {
@@ -543,17 +564,16 @@ func TestCheck_OK_nonPullRequests(t *testing.T) {
 			t.Errorf("UpdateCheckRun: checkID = %d, want %d", checkID, wantCheckID)
 		}
 		annotations := opt.Output.Annotations
-		if len(annotations) == 0 {
-			if *opt.Conclusion != conclusion {
-				t.Errorf("UpdateCheckRunOptions.Conclusion = %q, want %q", *opt.Conclusion, conclusion)
-			}
-		} else {
+		if opt.Conclusion != nil && *opt.Conclusion != conclusion {
+			t.Errorf("UpdateCheckRunOptions.Conclusion = %q, want %q", *opt.Conclusion, conclusion)
+		}
+		if len(annotations) > 0 {
 			wantAnnotations := []*github.CheckRunAnnotation{
 				{
 					Path:            github.String("sample.new.txt"),
 					StartLine:       github.Int(2),
 					EndLine:         github.Int(2),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("test message"),
 					Title:           github.String("[haya14busa-linter] sample.new.txt#L2"),
 					RawDetails:      github.String("raw test message"),
@@ -562,7 +582,7 @@ func TestCheck_OK_nonPullRequests(t *testing.T) {
 					Path:            github.String("sample.new.txt"),
 					StartLine:       github.Int(14),
 					EndLine:         github.Int(14),
-					AnnotationLevel: github.String("warning"),
+					AnnotationLevel: github.String("notice"),
 					Message:         github.String("test message2"),
 					Title:           github.String("[haya14busa-linter] sample.new.txt#L14"),
 					RawDetails:      github.String("raw test message2"),
@@ -663,3 +683,156 @@ func TestCheck_fail_check_with_403(t *testing.T) {
 		t.Error("resp.CheckedResults should not be nil")
 	}
 }
+
+func TestCheck_annotationLevel(t *testing.T) {
+	tests := []struct {
+		level    string
+		severity rdf.Severity
+		want     string
+	}{
+		{severity: rdf.Severity_ERROR, want: "failure"},
+		{severity: rdf.Severity_WARNING, want: "warning"},
+		{severity: rdf.Severity_INFO, want: "notice"},
+		{severity: rdf.Severity_UNKNOWN_SEVERITY, want: "notice"},
+		{level: "info", severity: rdf.Severity_ERROR, want: "failure"},
+		{level: "info", severity: rdf.Severity_UNKNOWN_SEVERITY, want: "notice"},
+		{level: "warning", severity: rdf.Severity_UNKNOWN_SEVERITY, want: "notice"},
+		{level: "error", severity: rdf.Severity_UNKNOWN_SEVERITY, want: "notice"},
+		{level: "error", severity: rdf.Severity_INFO, want: "notice"},
+	}
+	for _, tt := range tests {
+		ch := &Checker{req: &doghouse.CheckRequest{Level: tt.level}}
+		if got := ch.annotationLevel(tt.severity); got != tt.want {
+			t.Errorf("annotationLevel(%v) with level %q = %q, want %q", tt.severity, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestCheck_reuseInProgressCheckRun(t *testing.T) {
+	const (
+		name          = "haya14busa-linter"
+		sha           = "1414"
+		key           = "key"
+		existingID    = int64(1)
+		otherSHACheck = int64(2)
+		otherKeyCheck = int64(3)
+	)
+	annotation := func(line int) *doghouse.Annotation {
+		return &doghouse.Annotation{
+			Diagnostic: &rdf.Diagnostic{
+				Message:  fmt.Sprintf("test message %d", line),
+				Location: &rdf.Location{Path: "sample.new.txt", Range: &rdf.Range{Start: &rdf.Position{Line: int32(line)}}},
+			},
+		}
+	}
+	tests := []struct {
+		name            string
+		key             string
+		checkRuns       []*github.CheckRun
+		listErr         error
+		wantList        bool
+		wantCreated     bool
+		wantCheckID     int64
+		wantAnnotations int
+	}{
+		{
+			name: "reuse in-progress check run of the same key",
+			key:  key,
+			checkRuns: []*github.CheckRun{
+				{ID: github.Int64(otherSHACheck), Name: github.String(name), HeadSHA: github.String("other"), Status: github.String("in_progress"), ExternalID: github.String(key)},
+				{ID: github.Int64(existingID), Name: github.String(name), HeadSHA: github.String(sha), Status: github.String("in_progress"), ExternalID: github.String(key),
+					Output: &github.CheckRunOutput{AnnotationsCount: github.Int(1)}},
+			},
+			wantList:        true,
+			wantCheckID:     existingID,
+			wantAnnotations: 1, // The first one is posted by the failed attempt.
+		},
+		{
+			name: "in-progress check run of another request",
+			key:  key,
+			checkRuns: []*github.CheckRun{
+				{ID: github.Int64(otherKeyCheck), Name: github.String(name), HeadSHA: github.String(sha), Status: github.String("in_progress"), ExternalID: github.String("other")},
+			},
+			wantList:        true,
+			wantCreated:     true,
+			wantCheckID:     14,
+			wantAnnotations: 2,
+		},
+		{
+			name:            "no check runs",
+			key:             key,
+			wantList:        true,
+			wantCreated:     true,
+			wantCheckID:     14,
+			wantAnnotations: 2,
+		},
+		{
+			name:            "list error",
+			key:             key,
+			listErr:         errors.New("list failed"),
+			wantList:        true,
+			wantCreated:     true,
+			wantCheckID:     14,
+			wantAnnotations: 2,
+		},
+		{
+			name: "no idempotency key",
+			checkRuns: []*github.CheckRun{
+				{ID: github.Int64(existingID), Name: github.String(name), HeadSHA: github.String(sha), Status: github.String("in_progress")},
+			},
+			wantCreated:     true,
+			wantCheckID:     14,
+			wantAnnotations: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &doghouse.CheckRequest{
+				Name:           name,
+				Owner:          "haya14busa",
+				Repo:           "reviewdog",
+				SHA:            sha,
+				Annotations:    []*doghouse.Annotation{annotation(1), annotation(2)},
+				IdempotencyKey: tt.key,
+			}
+			created, listed := false, false
+			cli := &fakeCheckerGitHubCli{}
+			cli.FakeListCheckRuns = func(ctx context.Context, owner, repo, ref string, opt *github.ListCheckRunsOptions) ([]*github.CheckRun, error) {
+				listed = true
+				if ref != sha || opt.GetCheckName() != name || opt.GetStatus() != "in_progress" {
+					t.Errorf("unexpected list request: ref=%q, name=%q, status=%q", ref, opt.GetCheckName(), opt.GetStatus())
+				}
+				return tt.checkRuns, tt.listErr
+			}
+			cli.FakeCreateCheckRun = func(ctx context.Context, owner, repo string, opt github.CreateCheckRunOptions) (*github.CheckRun, error) {
+				created = true
+				if opt.GetExternalID() != tt.key {
+					t.Errorf("CreateCheckRun: external ID = %q, want %q", opt.GetExternalID(), tt.key)
+				}
+				return &github.CheckRun{ID: github.Int64(14)}, nil
+			}
+			cli.FakeUpdateCheckRun = func(ctx context.Context, owner, repo string, checkID int64, opt github.UpdateCheckRunOptions) (*github.CheckRun, error) {
+				if checkID != tt.wantCheckID {
+					t.Errorf("UpdateCheckRun: checkID = %d, want %d", checkID, tt.wantCheckID)
+				}
+				if got := len(opt.GetOutput().Annotations); got != tt.wantAnnotations {
+					t.Errorf("UpdateCheckRun: got %d annotations, want %d", got, tt.wantAnnotations)
+				}
+				if opt.GetConclusion() != "failure" {
+					t.Errorf("UpdateCheckRun: conclusion = %q, want failure", opt.GetConclusion())
+				}
+				return &github.CheckRun{}, nil
+			}
+			checker := &Checker{req: req, gh: cli}
+			if _, err := checker.Check(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if listed != tt.wantList {
+				t.Errorf("listed = %v, want %v", listed, tt.wantList)
+			}
+			if created != tt.wantCreated {
+				t.Errorf("created = %v, want %v", created, tt.wantCreated)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ type checkerGitHubClientInterface interface {
 	GetPullRequestDiff(ctx context.Context, owner, repo string, number int) ([]byte, error)
 	CreateCheckRun(ctx context.Context, owner, repo string, opt github.CreateCheckRunOptions) (*github.CheckRun, error)
 	UpdateCheckRun(ctx context.Context, owner, repo string, checkID int64, opt github.UpdateCheckRunOptions) (*github.CheckRun, error)
+	ListCheckRunsForRef(ctx context.Context, owner, repo, ref string, opt *github.ListCheckRunsOptions) ([]*github.CheckRun, error)
 }
 
 type checkerGitHubClient struct {
@@ -30,6 +31,14 @@ func (c *checkerGitHubClient) CreateCheckRun(ctx context.Context, owner, repo st
 	return checkRun, err
 }
 
+func (c *checkerGitHubClient) ListCheckRunsForRef(ctx context.Context, owner, repo, ref string, opt *github.ListCheckRunsOptions) ([]*github.CheckRun, error) {
+	res, _, err := c.Checks.ListCheckRunsForRef(ctx, owner, repo, ref, opt)
+	if err != nil {
+		return nil, err
+	}
+	return res.CheckRuns, nil
+}
+
 func (c *checkerGitHubClient) UpdateCheckRun(ctx context.Context, owner, repo string, checkID int64, opt github.UpdateCheckRunOptions) (*github.CheckRun, error) {
 	// Retry requests because GitHub API somehow returns 401 Bad credentials from
 	// time to time...
@@ -49,6 +49,13 @@ type CheckRequest struct {
 	// FilterMode represents a way to filter checks results
 	// Optional.
 	FilterMode filter.Mode `json:"filter_mode"`
+
+	// IdempotencyKey identifies retries of the same request. It's set to the
+	// external ID of the created check run, and an in-progress check run with
+	// the same key (e.g. the one left by a failed attempt) is reused instead of
+	// creating a new one. Retries must have the same annotations.
+	// Optional. Check runs are not reused if it's empty.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 // CheckResponse represents doghouse GitHub check response.
@@ -58,9 +65,10 @@ type CheckResponse struct {
 	ReportURL string `json:"report_url,omitempty"`
 
 	// CheckedResults is checked annotations result.
-	// This field is expected to be filled for GitHub Actions integration and
-	// filled when ReportURL is not available. i.e. reviewdog doesn't have write
-	// permission to Check API.
+	// This field is expected to be filled for GitHub Actions integration.
+	// Results are reported with it when ReportURL is not available. i.e.
+	// reviewdog doesn't have write permission to Check API. Otherwise, it's
+	// used to apply the fail policy of the CLI.
 	// It's also not expected to be passed over network via JSON.
 	// TODO(haya14busa): Consider to move this type to this package to avoid
 	// (cyclic) import.
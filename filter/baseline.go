@@ -0,0 +1,148 @@
+package filter
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// Baseline is a set of fingerprints of known results. Results in the baseline
+// are dropped by FilterBaseline so that only new results are reported.
+//
+// The baseline file has one fingerprint per line followed by the path and
+// the message of the result for readability. Lines starting with "#" are
+// comments.
+type Baseline struct {
+	mu sync.Mutex
+	// counts is the number of known results per fingerprint.
+	counts map[string]int
+	// descs are descriptions of results per fingerprint.
+	descs map[string]string
+}
+
+// NewBaseline returns an empty Baseline.
+func NewBaseline() *Baseline {
+	return &Baseline{counts: make(map[string]int), descs: make(map[string]string)}
+}
+
+// LoadBaseline reads the baseline file.
+func LoadBaseline(path string) (*Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	defer f.Close()
+	b := NewBaseline()
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		fp := fields[0]
+		b.counts[fp]++
+		if len(fields) == 2 {
+			b.descs[fp] = fields[1]
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	return b, nil
+}
+
+// Add adds the result reported by the tool to the baseline. workdir is used to
+// make absolute paths relative.
+func (b *Baseline) Add(d *rdf.Diagnostic, toolName, workdir string) {
+	fp := BaselineFingerprint(d, toolName, workdir)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.counts[fp]++
+	// Line numbers are not written to keep the file stable.
+	msg := strings.SplitN(d.GetMessage(), "\n", 2)[0]
+	b.descs[fp] = NormalizePath(d.GetLocation().GetPath(), workdir, "") + ": " + msg
+}
+
+// Len returns the number of results in the baseline.
+func (b *Baseline) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, c := range b.counts {
+		n += c
+	}
+	return n
+}
+
+// WriteTo writes the baseline file sorted by fingerprints.
+func (b *Baseline) WriteTo(w io.Writer) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fps := make([]string, 0, len(b.counts))
+	for fp := range b.counts {
+		fps = append(fps, fp)
+	}
+	sort.Strings(fps)
+	var sb strings.Builder
+	sb.WriteString("# reviewdog baseline. Results with these fingerprints are not reported.\n")
+	for _, fp := range fps {
+		for i := 0; i < b.counts[fp]; i++ {
+			sb.WriteString(strings.TrimSpace(fp + " " + b.descs[fp]))
+			sb.WriteString("\n")
+		}
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// BaselineFingerprint returns a stable fingerprint of the result. It's
+// calculated from the tool name, the path relative to workdir, the message
+// and the code of the result, so it doesn't change even if the result moves
+// to another line. The tool name is the source name of the result, or
+// toolName if it has no source.
+func BaselineFingerprint(d *rdf.Diagnostic, toolName, workdir string) string {
+	if name := d.GetSource().GetName(); name != "" {
+		toolName = name
+	}
+	h := sha256.New()
+	for _, s := range []string{toolName, NormalizePath(d.GetLocation().GetPath(), workdir, ""), d.GetMessage(), d.GetCode().GetValue()} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// FilterBaseline returns results which are not in the baseline. If the
+// baseline has n results with the same fingerprint, the first n results with
+// the fingerprint are dropped and the rest are reported as new results.
+func FilterBaseline(results []*rdf.Diagnostic, toolName, workdir string, b *Baseline) []*rdf.Diagnostic {
+	if b == nil {
+		return results
+	}
+	b.mu.Lock()
+	remaining := make(map[string]int, len(b.counts))
+	for fp, c := range b.counts {
+		remaining[fp] = c
+	}
+	b.mu.Unlock()
+	filtered := make([]*rdf.Diagnostic, 0, len(results))
+	for _, d := range results {
+		fp := BaselineFingerprint(d, toolName, workdir)
+		if remaining[fp] > 0 {
+			remaining[fp]--
+			continue
+		}
+		filtered = append(filtered, d)
+	}
+	return filtered
+}
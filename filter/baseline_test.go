@@ -0,0 +1,97 @@
+package filter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestBaselineFingerprint(t *testing.T) {
+	newResult := func(path string, line int32, msg string) *rdf.Diagnostic {
+		return &rdf.Diagnostic{
+			Location: &rdf.Location{
+				Path:  path,
+				Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+			},
+			Message: msg,
+		}
+	}
+	fp := BaselineFingerprint(newResult("a.go", 1, "msg"), "tool", "/src")
+	if got := BaselineFingerprint(newResult("a.go", 10, "msg"), "tool", "/src"); got != fp {
+		t.Errorf("fingerprint changed by the line: %s, %s", fp, got)
+	}
+	if got := BaselineFingerprint(newResult("/src/a.go", 1, "msg"), "tool", "/src"); got != fp {
+		t.Errorf("fingerprint changed by the absolute path: %s, %s", fp, got)
+	}
+	if got := BaselineFingerprint(newResult("a.go", 1, "msg"), "other", "/src"); got == fp {
+		t.Error("fingerprint should change by the tool")
+	}
+	if got := BaselineFingerprint(newResult("a.go", 1, "msg2"), "tool", "/src"); got == fp {
+		t.Error("fingerprint should change by the message")
+	}
+}
+
+func TestFilterBaseline(t *testing.T) {
+	newResult := func(line int32, msg string) *rdf.Diagnostic {
+		return &rdf.Diagnostic{
+			Location: &rdf.Location{
+				Path:  "a.go",
+				Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+			},
+			Message: msg,
+		}
+	}
+	b := NewBaseline()
+	b.Add(newResult(1, "old"), "tool", "")
+	b.Add(newResult(2, "dup"), "tool", "")
+
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "baseline")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != 2 {
+		t.Errorf("loaded %d results, want 2:\n%s", loaded.Len(), buf.String())
+	}
+	if !strings.Contains(buf.String(), " a.go: old\n") {
+		t.Errorf("baseline doesn't describe the result:\n%s", buf.String())
+	}
+
+	results := []*rdf.Diagnostic{
+		newResult(10, "old"), // moved
+		newResult(11, "dup"),
+		newResult(12, "dup"), // another occurrence of the known result
+		newResult(13, "new"),
+	}
+	got := FilterBaseline(results, "tool", "", loaded)
+	want := []*rdf.Diagnostic{results[2], results[3]}
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	// The baseline is not consumed by filtering.
+	if got := FilterBaseline(results, "tool", "", loaded); len(got) != 2 {
+		t.Errorf("got %d results by the second filter, want 2", len(got))
+	}
+}
+
+func TestLoadBaseline_notFound(t *testing.T) {
+	if _, err := LoadBaseline(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("got no error for a missing baseline")
+	}
+}
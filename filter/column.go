@@ -0,0 +1,80 @@
+package filter
+
+import (
+	"bufio"
+	"os"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// ExpandTabColumn translates 1-based column of the line which counts a tab as
+// one byte into the column rendered with tabs expanded to the next multiple of
+// tabWidth. It returns col as is if tabWidth is not positive.
+func ExpandTabColumn(line string, col, tabWidth int) int {
+	if tabWidth <= 0 || col <= 1 {
+		return col
+	}
+	visual := 0
+	for i := 0; i < col-1; i++ {
+		if i < len(line) && line[i] == '\t' {
+			visual += tabWidth - visual%tabWidth
+			continue
+		}
+		visual++
+	}
+	return visual + 1
+}
+
+// ExpandTabColumns translates columns of the locations of reported checks with
+// ExpandTabColumn. Source lines are read from SourceLines or the files. It
+// doesn't touch suggestion ranges as they are applied to bytes of the lines.
+func ExpandTabColumns(checks []*FilteredDiagnostic, tabWidth int) {
+	if tabWidth <= 0 {
+		return
+	}
+	files := make(map[string]map[int]string)
+	lineContent := func(check *FilteredDiagnostic, lnum int) (string, bool) {
+		if l, ok := check.SourceLines[lnum]; ok {
+			return l, true
+		}
+		path := check.Diagnostic.GetLocation().GetPath()
+		lines, ok := files[path]
+		if !ok {
+			lines = readLines(path)
+			files[path] = lines
+		}
+		l, ok := lines[lnum]
+		return l, ok
+	}
+	for _, check := range checks {
+		if !check.ShouldReport {
+			continue
+		}
+		rng := check.Diagnostic.GetLocation().GetRange()
+		for _, pos := range []*rdf.Position{rng.GetStart(), rng.GetEnd()} {
+			if pos.GetColumn() <= 1 {
+				continue
+			}
+			if l, ok := lineContent(check, int(pos.GetLine())); ok {
+				pos.Column = int32(ExpandTabColumn(l, int(pos.GetColumn()), tabWidth))
+			}
+		}
+	}
+}
+
+// readLines reads lines of the file. It returns empty map if it fails to read
+// the file.
+func readLines(path string) map[int]string {
+	lines := make(map[int]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return lines
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1024*1024)
+	for lnum := 1; s.Scan(); lnum++ {
+		lines[lnum] = s.Text()
+	}
+	return lines
+}
@@ -0,0 +1,88 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestExpandTabColumn(t *testing.T) {
+	tests := []struct {
+		line     string
+		col      int
+		tabWidth int
+		want     int
+	}{
+		{line: "\tx := 1", col: 2, tabWidth: 0, want: 2},
+		{line: "\tx := 1", col: 2, tabWidth: 4, want: 5},
+		{line: "\tx := 1", col: 2, tabWidth: 8, want: 9},
+		{line: "\t\tx", col: 3, tabWidth: 4, want: 9},
+		{line: "ab\tx", col: 4, tabWidth: 4, want: 5},
+		{line: "abcd\tx", col: 6, tabWidth: 4, want: 9},
+		{line: "no tab", col: 4, tabWidth: 4, want: 4},
+		{line: "\tx", col: 1, tabWidth: 4, want: 1},
+		// Column after the end of line.
+		{line: "\t", col: 4, tabWidth: 4, want: 7},
+	}
+	for _, tt := range tests {
+		if got := ExpandTabColumn(tt.line, tt.col, tt.tabWidth); got != tt.want {
+			t.Errorf("ExpandTabColumn(%q, %d, %d) = %d, want %d", tt.line, tt.col, tt.tabWidth, got, tt.want)
+		}
+	}
+}
+
+func TestExpandTabColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.go")
+	if err := os.WriteFile(path, []byte("package x\n\tfunc()\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	newCheck := func(line, col int32, sourceLines map[int]string) *FilteredDiagnostic {
+		return &FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path: path,
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: line, Column: col},
+						End:   &rdf.Position{Line: line, Column: col + 1},
+					},
+				},
+				Suggestions: []*rdf.Suggestion{{
+					Range: &rdf.Range{Start: &rdf.Position{Line: line, Column: col}},
+				}},
+			},
+			ShouldReport: true,
+			SourceLines:  sourceLines,
+		}
+	}
+	fromFile := newCheck(2, 2, nil)
+	fromSourceLines := newCheck(1, 3, map[int]string{1: "\t\tx"})
+	notReported := newCheck(2, 2, nil)
+	notReported.ShouldReport = false
+
+	ExpandTabColumns([]*FilteredDiagnostic{fromFile, fromSourceLines, notReported}, 4)
+
+	tests := []struct {
+		name                string
+		check               *FilteredDiagnostic
+		wantStart, wantEnd  int32
+		wantSuggestionStart int32
+	}{
+		{name: "from file", check: fromFile, wantStart: 5, wantEnd: 6, wantSuggestionStart: 2},
+		{name: "from source lines", check: fromSourceLines, wantStart: 9, wantEnd: 10, wantSuggestionStart: 3},
+		{name: "not reported", check: notReported, wantStart: 2, wantEnd: 3, wantSuggestionStart: 2},
+	}
+	for _, tt := range tests {
+		rng := tt.check.Diagnostic.GetLocation().GetRange()
+		if got := rng.GetStart().GetColumn(); got != tt.wantStart {
+			t.Errorf("%s: got start column %d, want %d", tt.name, got, tt.wantStart)
+		}
+		if got := rng.GetEnd().GetColumn(); got != tt.wantEnd {
+			t.Errorf("%s: got end column %d, want %d", tt.name, got, tt.wantEnd)
+		}
+		if got := tt.check.Diagnostic.GetSuggestions()[0].GetRange().GetStart().GetColumn(); got != tt.wantSuggestionStart {
+			t.Errorf("%s: got suggestion start column %d, want %d", tt.name, got, tt.wantSuggestionStart)
+		}
+	}
+}
@@ -73,11 +73,23 @@ type DiffFilter struct {
 	// It can be empty if it doesn't find any project root directory.
 	projectRelPath string
 
+	// gitRoots are the root directory of the git repository of cwd and its
+	// path with symlinks resolved, if it's different. It's empty if cwd is not
+	// in a git repository.
+	gitRoots []string
+
 	strip int
 	mode  Mode
 
+	// contextExpansion is the number of lines around changed lines which are
+	// also reported in ModeDiffContext.
+	contextExpansion int
+
 	difflines difflines
 	difffiles difffiles
+
+	// renames is a hash table of old paths to new paths of renamed files.
+	renames map[normalizedPath]normalizedPath
 }
 
 // difflines is a hash table of normalizedPath to line number to *diff.Line.
@@ -86,18 +98,39 @@ type difflines map[normalizedPath]map[int]*diff.Line
 // difffiles is a hash table of normalizedPath to *diff.FileDiff.
 type difffiles map[normalizedPath]*diff.FileDiff
 
+// DiffFilterOption is an option for NewDiffFilter.
+type DiffFilterOption func(*DiffFilter)
+
+// WithContextExpansion makes DiffFilter in ModeDiffContext also report lines
+// within n lines of changed lines even if they are outside diff hunks.
+func WithContextExpansion(n int) DiffFilterOption {
+	return func(df *DiffFilter) {
+		df.contextExpansion = n
+	}
+}
+
 // NewDiffFilter creates a new DiffFilter.
-func NewDiffFilter(diff []*diff.FileDiff, strip int, cwd string, mode Mode) *DiffFilter {
+func NewDiffFilter(diff []*diff.FileDiff, strip int, cwd string, mode Mode, opts ...DiffFilterOption) *DiffFilter {
 	df := &DiffFilter{
 		strip:     strip,
 		cwd:       cwd,
 		mode:      mode,
 		difflines: make(difflines),
 		difffiles: make(difffiles),
+		renames:   make(map[normalizedPath]normalizedPath),
+	}
+	for _, opt := range opts {
+		opt(df)
 	}
 	// If cwd is empty, projectRelPath should not have any meaningful data too.
 	if cwd != "" {
 		df.projectRelPath, _ = serviceutil.GitRelWorkdir()
+		if root, err := serviceutil.GitRoot(cwd); err == nil {
+			df.gitRoots = append(df.gitRoots, root)
+			if resolved, err := filepath.EvalSymlinks(root); err == nil && resolved != root {
+				df.gitRoots = append(df.gitRoots, resolved)
+			}
+		}
 	}
 	df.addDiff(diff)
 	return df
@@ -106,6 +139,17 @@ func NewDiffFilter(diff []*diff.FileDiff, strip int, cwd string, mode Mode) *Dif
 func (df *DiffFilter) addDiff(filediffs []*diff.FileDiff) {
 	for _, filediff := range filediffs {
 		path := df.normalizeDiffPath(filediff)
+		if from, to, ok := renamePaths(filediff); ok {
+			if filediff.PathNew == "" {
+				// Pure renames don't have file headers.
+				path = normalizedPath{p: to}
+			}
+			df.renames[normalizedPath{p: from}] = path
+		}
+		if filediff.IsBinary() || filediff.IsDeleted() {
+			// No lines to comment on.
+			continue
+		}
 		df.difffiles[path] = filediff
 		lines, ok := df.difflines[path]
 		if !ok {
@@ -133,11 +177,61 @@ func (df *DiffFilter) ShouldReport(path string, lnum int) (bool, *diff.FileDiff,
 	}
 	line, ok := lines[lnum]
 	if !ok {
-		return df.mode == ModeNoFilter || df.mode == ModeFile, file, nil
+		return df.mode == ModeNoFilter || df.mode == ModeFile || df.nearChangedLine(lines, lnum), file, nil
 	}
 	return df.isSignificantLine(line), file, line
 }
 
+// nearChangedLine returns true if lnum is within contextExpansion lines of
+// changed lines in ModeDiffContext.
+func (df *DiffFilter) nearChangedLine(lines map[int]*diff.Line, lnum int) bool {
+	if df.mode != ModeDiffContext || df.contextExpansion <= 0 || lnum <= 0 {
+		return false
+	}
+	for l := lnum - df.contextExpansion; l <= lnum+df.contextExpansion; l++ {
+		if line, ok := lines[l]; ok && line.Type == diff.LineAdded {
+			return true
+		}
+	}
+	return false
+}
+
+// RenamedPath returns the new path of the given path if the file is renamed
+// in the diff. The new path is relative to the current workdir as well as the
+// given path.
+func (df *DiffFilter) RenamedPath(path string) (string, bool) {
+	npath := df.normalizePath(path)
+	if _, ok := df.difffiles[npath]; ok {
+		// The old path is used by another file.
+		return "", false
+	}
+	newPath, ok := df.renames[npath]
+	if !ok {
+		return "", false
+	}
+	if df.projectRelPath == "" {
+		return newPath.p, true
+	}
+	rel, err := filepath.Rel(df.projectRelPath, newPath.p)
+	if err != nil {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// renamePaths returns the old and new paths of a renamed file from git
+// extended header lines ("rename from <path>" and "rename to <path>").
+func renamePaths(filediff *diff.FileDiff) (from, to string, ok bool) {
+	for _, line := range filediff.Extended {
+		if p := strings.TrimPrefix(line, "rename from "); p != line {
+			from = filepath.ToSlash(filepath.Clean(p))
+		} else if p := strings.TrimPrefix(line, "rename to "); p != line {
+			to = filepath.ToSlash(filepath.Clean(p))
+		}
+	}
+	return from, to, from != "" && to != ""
+}
+
 // DiffLine returns diff data from given new path and lnum. Returns nil if not
 // found.
 func (df *DiffFilter) DiffLine(path string, lnum int) *diff.Line {
@@ -167,6 +261,38 @@ func (df *DiffFilter) isSignificantLine(line *diff.Line) bool {
 // to current dir if project root not found.
 type normalizedPath struct{ p string }
 
+// relPath returns the normalized path of a result relative to cwd. Absolute
+// paths in the git repository are converted even if they are outside cwd (e.g.
+// "../other/a.go") or have symlinks resolved, so that they match paths in the
+// diff, which are relative to the git root. Absolute paths outside the
+// repository are returned as they are, and they are not in the diff.
+func (df *DiffFilter) relPath(path string) string {
+	path = NormalizePath(path, df.cwd, "")
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	p := filepath.FromSlash(path)
+	for _, root := range df.gitRoots {
+		if !contains(p, root) {
+			continue
+		}
+		repoRel, err := filepath.Rel(root, p)
+		if err != nil {
+			continue
+		}
+		base := df.projectRelPath
+		if base == "" {
+			base = "."
+		}
+		rel, err := filepath.Rel(base, repoRel)
+		if err != nil {
+			continue
+		}
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
 func (df *DiffFilter) normalizePath(path string) normalizedPath {
 	return normalizedPath{p: NormalizePath(path, df.cwd, df.projectRelPath)}
 }
@@ -220,6 +220,38 @@ func TestDiffFilter_root(t *testing.T) {
 	}
 }
 
+func TestDiffFilter_contextExpansion(t *testing.T) {
+	defer cd("..")()
+	// Lines 2 and 3 of sample.new.txt are added and the hunk ends at line 4.
+	files := getDiff(t, sampleDiffRoot)
+	tests := []struct {
+		lnum      int
+		mode      Mode
+		expansion int
+		want      bool
+	}{
+		{lnum: 5, mode: ModeDiffContext, expansion: 0, want: false},
+		{lnum: 5, mode: ModeDiffContext, expansion: 1, want: false},
+		{lnum: 5, mode: ModeDiffContext, expansion: 2, want: true},
+		{lnum: 6, mode: ModeDiffContext, expansion: 2, want: false},
+		{lnum: 6, mode: ModeDiffContext, expansion: 3, want: true},
+		{lnum: 4, mode: ModeDiffContext, expansion: 0, want: true},
+		{lnum: 0, mode: ModeDiffContext, expansion: 3, want: false},
+		// Only diff_context mode is expanded.
+		{lnum: 5, mode: ModeAdded, expansion: 2, want: false},
+	}
+	for _, tt := range tests {
+		df := NewDiffFilter(files, 1, getCwd(), tt.mode, WithContextExpansion(tt.expansion))
+		got, _, gotLine := df.ShouldReport("sample.new.txt", tt.lnum)
+		if got != tt.want {
+			t.Errorf("[%s, expansion=%d] ShouldReport(%d) = %v, want %v", tt.mode.String(), tt.expansion, tt.lnum, got, tt.want)
+		}
+		if tt.lnum > 4 && gotLine != nil {
+			t.Errorf("[%s, expansion=%d] ShouldReport(%d) returns diff line outside hunks", tt.mode.String(), tt.expansion, tt.lnum)
+		}
+	}
+}
+
 func TestDiffFilter_subdir(t *testing.T) {
 	// git diff (including diff from GitHub) returns path relative to a project
 	// root directory (See sampleDiffSubDir), but given path from linters can be
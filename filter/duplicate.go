@@ -0,0 +1,53 @@
+package filter
+
+type duplicateKey struct {
+	tool, code, message string
+}
+
+// CollapseDuplicates collapses reported checks with the same tool, code and
+// message into one check if more than threshold checks share them, so that
+// reporters post one comment listing all the locations instead of many
+// identical comments. The check in diff context which comes first is kept and
+// the others are added to its Duplicates and not reported. toolName is used
+// for diagnostics without source names. threshold <= 0 disables it.
+func CollapseDuplicates(checks []*FilteredDiagnostic, toolName string, threshold int) {
+	if threshold <= 0 {
+		return
+	}
+	groups := make(map[duplicateKey][]*FilteredDiagnostic)
+	var keys []duplicateKey
+	for _, check := range checks {
+		if !check.ShouldReport {
+			continue
+		}
+		d := check.Diagnostic
+		key := duplicateKey{tool: toolName, code: d.GetCode().GetValue(), message: d.GetMessage()}
+		if name := d.GetSource().GetName(); name != "" {
+			key.tool = name
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], check)
+	}
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) <= threshold {
+			continue
+		}
+		first := group[0]
+		for _, check := range group {
+			if check.InDiffContext {
+				first = check
+				break
+			}
+		}
+		for _, check := range group {
+			if check == first {
+				continue
+			}
+			check.ShouldReport = false
+			first.Duplicates = append(first.Duplicates, check)
+		}
+	}
+}
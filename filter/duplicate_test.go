@@ -0,0 +1,64 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestCollapseDuplicates(t *testing.T) {
+	newCheck := func(path, code, msg string, inDiffContext bool) *FilteredDiagnostic {
+		return &FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: path},
+				Code:     &rdf.Code{Value: code},
+				Message:  msg,
+			},
+			ShouldReport:  true,
+			InDiffContext: inDiffContext,
+		}
+	}
+	checks := []*FilteredDiagnostic{
+		newCheck("a.go", "E1", "msg", false),
+		newCheck("b.go", "E1", "msg", true),
+		newCheck("c.go", "E1", "msg", true),
+		newCheck("d.go", "E2", "msg", true),
+		newCheck("e.go", "E2", "msg", true),
+		newCheck("f.go", "E1", "other msg", true),
+	}
+	notReported := newCheck("g.go", "E1", "msg", true)
+	notReported.ShouldReport = false
+	checks = append(checks, notReported)
+
+	CollapseDuplicates(checks, "tool", 2)
+
+	// E1 "msg" has 3 results and they are collapsed into the first one in diff
+	// context. E2 has only 2 results.
+	wantReport := []bool{false, true, false, true, true, true, false}
+	for i, check := range checks {
+		if check.ShouldReport != wantReport[i] {
+			t.Errorf("checks[%d].ShouldReport = %v, want %v", i, check.ShouldReport, wantReport[i])
+		}
+	}
+	if got := checks[1].Duplicates; len(got) != 2 || got[0] != checks[0] || got[1] != checks[2] {
+		t.Errorf("checks[1].Duplicates = %v, want checks[0] and checks[2]", got)
+	}
+	for _, i := range []int{0, 2, 3, 4, 5, 6} {
+		if len(checks[i].Duplicates) != 0 {
+			t.Errorf("checks[%d].Duplicates = %v, want none", i, checks[i].Duplicates)
+		}
+	}
+}
+
+func TestCollapseDuplicates_disabled(t *testing.T) {
+	checks := []*FilteredDiagnostic{
+		{Diagnostic: &rdf.Diagnostic{Message: "msg"}, ShouldReport: true},
+		{Diagnostic: &rdf.Diagnostic{Message: "msg"}, ShouldReport: true},
+	}
+	CollapseDuplicates(checks, "tool", 0)
+	for i, check := range checks {
+		if !check.ShouldReport || len(check.Duplicates) != 0 {
+			t.Errorf("checks[%d] is collapsed: %+v", i, check)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package filter
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// FilePatterns is a list of gitignore-style glob patterns of file paths.
+//
+//   - `*` matches any characters except `/` and `?` matches one of them.
+//   - `**` matches any directories, e.g. `src/**/*.go` or `**/testdata`.
+//   - Patterns without `/` except a trailing one match the base name or a
+//     directory name at any depth, e.g. `*.pb.go` or `vendor/`.
+//   - Other patterns are relative to the working directory. A leading `/` is
+//     allowed, e.g. `/src/**`.
+//   - Patterns matching a directory match all files under it and a trailing
+//     `/` matches only directories.
+type FilePatterns []*FilePattern
+
+// FilePattern is a compiled pattern of FilePatterns.
+type FilePattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// String implements the flag.Value interface
+func (ps *FilePatterns) String() string {
+	ss := make([]string, 0, len(*ps))
+	for _, p := range *ps {
+		ss = append(ss, p.pattern)
+	}
+	return strings.Join(ss, ",")
+}
+
+// Set implements the flag.Value interface. It accepts comma separated
+// patterns and can be called multiple times.
+func (ps *FilePatterns) Set(value string) error {
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		re, err := filePatternRegexp(p)
+		if err != nil {
+			return fmt.Errorf("invalid file pattern %q: %w", p, err)
+		}
+		*ps = append(*ps, &FilePattern{pattern: p, re: re})
+	}
+	return nil
+}
+
+// Match returns true if the slash separated relative path matches any of the
+// patterns.
+func (ps FilePatterns) Match(path string) bool {
+	for _, p := range ps {
+		if p.re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// filePatternRegexp compiles the gitignore-style pattern to a regexp matching
+// whole paths.
+func filePatternRegexp(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(p[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class := p[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(p):
+			sb.WriteString(regexp.QuoteMeta(p[i+1 : i+2]))
+			i++
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if dirOnly {
+		sb.WriteString("/.*$")
+	} else {
+		sb.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(sb.String())
+}
+
+// FilterFiles returns results whose path matches include patterns and doesn't
+// match exclude patterns. Empty include patterns match any files. Paths are
+// matched relative to wd and results without path are kept.
+func FilterFiles(results []*rdf.Diagnostic, wd string, include, exclude FilePatterns) []*rdf.Diagnostic {
+	if len(include) == 0 && len(exclude) == 0 {
+		return results
+	}
+	filtered := make([]*rdf.Diagnostic, 0, len(results))
+	for _, d := range results {
+		path := d.GetLocation().GetPath()
+		if path == "" {
+			filtered = append(filtered, d)
+			continue
+		}
+		path = relSlashPath(path, wd)
+		if len(include) > 0 && !include.Match(path) {
+			continue
+		}
+		if exclude.Match(path) {
+			continue
+		}
+		filtered = append(filtered, d)
+	}
+	return filtered
+}
+
+// relSlashPath returns the slash separated path relative to wd. Absolute paths
+// outside wd are returned as they are.
+func relSlashPath(path, wd string) string {
+	path = filepath.Clean(path)
+	if filepath.IsAbs(path) && wd != "" {
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
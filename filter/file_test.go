@@ -0,0 +1,113 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestFilePatterns_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "*.go", path: "a.go", want: true},
+		{pattern: "*.go", path: "pkg/a.go", want: true},
+		{pattern: "*.go", path: "a.gox", want: false},
+		{pattern: "*.pb.go", path: "api/v1/a.pb.go", want: true},
+		{pattern: "vendor", path: "vendor/a/b.go", want: true},
+		{pattern: "vendor", path: "pkg/vendor/b.go", want: true},
+		{pattern: "vendor", path: "vendors/b.go", want: false},
+		{pattern: "vendor/", path: "vendor/b.go", want: true},
+		{pattern: "vendor/", path: "vendor", want: false},
+		{pattern: "src/**", path: "src/a/b.go", want: true},
+		{pattern: "src/**", path: "pkg/src/a.go", want: false},
+		{pattern: "/src", path: "src/a.go", want: true},
+		{pattern: "/src", path: "pkg/src/a.go", want: false},
+		{pattern: "src/*.go", path: "src/a.go", want: true},
+		{pattern: "src/*.go", path: "src/a/b.go", want: false},
+		{pattern: "src/**/*.go", path: "src/a.go", want: true},
+		{pattern: "src/**/*.go", path: "src/a/b/c.go", want: true},
+		{pattern: "**/testdata", path: "a/testdata/x.txt", want: true},
+		{pattern: "a?.go", path: "ab.go", want: true},
+		{pattern: "[ab].go", path: "b.go", want: true},
+		{pattern: "[!ab].go", path: "b.go", want: false},
+		{pattern: "a+b.go", path: "a+b.go", want: true},
+	}
+	for _, tt := range tests {
+		var ps FilePatterns
+		if err := ps.Set(tt.pattern); err != nil {
+			t.Fatal(err)
+		}
+		if got := ps.Match(tt.path); got != tt.want {
+			t.Errorf("%q.Match(%q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFilePatterns_Set_invalid(t *testing.T) {
+	for _, v := range []string{"[a.go", "/"} {
+		var ps FilePatterns
+		if err := ps.Set(v); err == nil {
+			t.Errorf("Set(%q) should fail", v)
+		}
+	}
+}
+
+func TestFilterFiles(t *testing.T) {
+	results := []*rdf.Diagnostic{
+		{Message: "src", Location: &rdf.Location{Path: "src/a.go"}},
+		{Message: "vendor", Location: &rdf.Location{Path: "src/vendor/b.go"}},
+		{Message: "abs", Location: &rdf.Location{Path: "/root/repo/src/c.go"}},
+		{Message: "docs", Location: &rdf.Location{Path: "docs/a.md"}},
+		{Message: "no path"},
+	}
+	tests := []struct {
+		name    string
+		include string
+		exclude string
+		want    []string
+	}{
+		{
+			name: "no patterns",
+			want: []string{"src", "vendor", "abs", "docs", "no path"},
+		},
+		{
+			name:    "include only",
+			include: "src/**",
+			want:    []string{"src", "vendor", "abs", "no path"},
+		},
+		{
+			name:    "exclude only",
+			exclude: "vendor/,*.md",
+			want:    []string{"src", "abs", "no path"},
+		},
+		{
+			name:    "include and exclude",
+			include: "src/**",
+			exclude: "vendor/",
+			want:    []string{"src", "abs", "no path"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var include, exclude FilePatterns
+			if err := include.Set(tt.include); err != nil {
+				t.Fatal(err)
+			}
+			if err := exclude.Set(tt.exclude); err != nil {
+				t.Fatal(err)
+			}
+			got := FilterFiles(results, "/root/repo", include, exclude)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FilterFiles() = %v, want %v", got, tt.want)
+			}
+			for i, d := range got {
+				if d.GetMessage() != tt.want[i] {
+					t.Errorf("FilterFiles()[%d] = %q, want %q", i, d.GetMessage(), tt.want[i])
+				}
+			}
+		})
+	}
+}
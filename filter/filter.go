@@ -2,6 +2,7 @@ package filter
 
 import (
 	"path/filepath"
+	"strings"
 
 	"github.com/reviewdog/reviewdog/diff"
 	"github.com/reviewdog/reviewdog/proto/rdf"
@@ -31,18 +32,50 @@ type FilteredDiagnostic struct {
 
 	OldPath string
 	OldLine int
+
+	// Labels are arbitrary metadata of the diagnostic (e.g. team=backend) set
+	// by Labelers. Optional.
+	Labels map[string]string
+
+	// Duplicates are other checks with the same tool, code and message which
+	// are collapsed into this check by CollapseDuplicates. They are not
+	// reported by themselves.
+	Duplicates []*FilteredDiagnostic
+
+	// Omitted are checks which are not reported due to LimitChecks and are
+	// summarized by this check instead. Diagnostic of a check with Omitted is
+	// the summary and not a result by itself.
+	Omitted []*FilteredDiagnostic
+}
+
+// ReportedChecks returns the results which are reported by the check: the
+// check and its duplicates, or the omitted checks and their duplicates if the
+// check summarizes omitted checks.
+func (fd *FilteredDiagnostic) ReportedChecks() []*FilteredDiagnostic {
+	if len(fd.Omitted) > 0 {
+		var checks []*FilteredDiagnostic
+		for _, o := range fd.Omitted {
+			checks = append(checks, o.ReportedChecks()...)
+		}
+		return checks
+	}
+	return append([]*FilteredDiagnostic{fd}, fd.Duplicates...)
 }
 
 // FilterCheck filters check results by diff. It doesn't drop check which
 // is not in diff but set FilteredDiagnostic.ShouldReport field false.
 func FilterCheck(results []*rdf.Diagnostic, diff []*diff.FileDiff, strip int,
-	cwd string, mode Mode) []*FilteredDiagnostic {
+	cwd string, mode Mode, opts ...DiffFilterOption) []*FilteredDiagnostic {
 	checks := make([]*FilteredDiagnostic, 0, len(results))
-	df := NewDiffFilter(diff, strip, cwd, mode)
+	df := NewDiffFilter(diff, strip, cwd, mode, opts...)
 	for _, result := range results {
 		check := &FilteredDiagnostic{Diagnostic: result, SourceLines: make(map[int]string)}
 		loc := result.GetLocation()
-		loc.Path = NormalizePath(loc.GetPath(), cwd, "")
+		loc.Path = df.relPath(loc.GetPath())
+		// Tools may report results on renamed files with the old path.
+		if newPath, ok := df.RenamedPath(loc.GetPath()); ok {
+			loc.Path = newPath
+		}
 		startLine := int(loc.GetRange().GetStart().GetLine())
 		endLine := int(loc.GetRange().GetEnd().GetLine())
 		if endLine == 0 {
@@ -89,7 +122,8 @@ func FilterCheck(results []*rdf.Diagnostic, diff []*diff.FileDiff, strip int,
 // NormalizePath return normalized path with workdir and relative path to
 // project.
 func NormalizePath(path, workdir, projectRelPath string) string {
-	path = filepath.Clean(path)
+	// Tools may output CRLF line endings on Windows.
+	path = filepath.Clean(strings.TrimRight(path, "\r"))
 	if path == "." {
 		return ""
 	}
@@ -1,6 +1,8 @@
 package filter
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -163,6 +165,41 @@ func TestFilterCheckByAddedLines(t *testing.T) {
 	}
 }
 
+func TestFilterCheck_CRLF(t *testing.T) {
+	newResults := func(path string) []*rdf.Diagnostic {
+		return []*rdf.Diagnostic{
+			{
+				Location: &rdf.Location{
+					Path:  path,
+					Range: &rdf.Range{Start: &rdf.Position{Line: 2, Column: 14}},
+				},
+			},
+		}
+	}
+	lfdiffs, _ := diff.ParseMultiFile(strings.NewReader(diffContent))
+	want := FilterCheck(newResults("sample.new.txt"), lfdiffs, 0, "", ModeAdded)
+	if !want[0].ShouldReport {
+		t.Fatal("LF result must be reported")
+	}
+
+	for _, tt := range []struct {
+		eol string
+		// wantSourceLine is the source line kept as it is in the diff except for
+		// the line ending stripped by bufio.Reader.ReadLine.
+		wantSourceLine string
+	}{
+		{eol: "\r\n", wantSourceLine: "added line"},
+		{eol: "\r\r\n", wantSourceLine: "added line\r"},
+	} {
+		filediffs, _ := diff.ParseMultiFile(strings.NewReader(strings.ReplaceAll(diffContent, "\n", tt.eol)))
+		got := FilterCheck(newResults("sample.new.txt\r"), filediffs, 0, "", ModeAdded)
+		want[0].SourceLines = map[int]string{2: tt.wantSourceLine}
+		if value := cmp.Diff(got, want, protocmp.Transform()); value != "" {
+			t.Errorf("%q: %s", tt.eol, value)
+		}
+	}
+}
+
 // All lines that are in diff are taken into account
 func TestFilterCheckByDiffContext(t *testing.T) {
 	results := []*rdf.Diagnostic{
@@ -277,6 +314,122 @@ func TestFilterCheckByDiffContext(t *testing.T) {
 	}
 }
 
+const diffContentRenamed = `diff --git a/old/renamed.go b/new/renamed.go
+similarity index 80%
+rename from old/renamed.go
+rename to new/renamed.go
+index 1111111..2222222 100644
+--- a/old/renamed.go
++++ b/new/renamed.go
+@@ -1,3 +1,3 @@
+ package renamed
+-var x = 1
++var x = 2
+ // end
+diff --git a/old/pure.go b/new/pure.go
+similarity index 100%
+rename from old/pure.go
+rename to new/pure.go
+`
+
+func TestFilterCheck_renamed(t *testing.T) {
+	newResult := func(path string, line int32) *rdf.Diagnostic {
+		return &rdf.Diagnostic{
+			Location: &rdf.Location{
+				Path:  path,
+				Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+			},
+		}
+	}
+	results := []*rdf.Diagnostic{
+		newResult("new/renamed.go", 2),
+		newResult("old/renamed.go", 2), // Reported with the old path.
+		newResult("old/pure.go", 1),
+		newResult("old/unknown.go", 1),
+	}
+	filediffs, err := diff.ParseMultiFile(strings.NewReader(diffContentRenamed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := FilterCheck(results, filediffs, 1, "", ModeFile)
+	tests := []struct {
+		wantPath          string
+		wantInDiffContext bool
+		wantOldPath       string
+	}{
+		{wantPath: "new/renamed.go", wantInDiffContext: true, wantOldPath: "old/renamed.go"},
+		{wantPath: "new/renamed.go", wantInDiffContext: true, wantOldPath: "old/renamed.go"},
+		{wantPath: "new/pure.go"},
+		{wantPath: "old/unknown.go"},
+	}
+	for i, tt := range tests {
+		check := got[i]
+		if p := check.Diagnostic.GetLocation().GetPath(); p != tt.wantPath {
+			t.Errorf("[%d] got path %q, want %q", i, p, tt.wantPath)
+		}
+		wantInDiffFile := tt.wantPath != "old/unknown.go"
+		if check.InDiffFile != wantInDiffFile || check.ShouldReport != wantInDiffFile {
+			t.Errorf("[%d] got InDiffFile=%v ShouldReport=%v, want %v", i, check.InDiffFile, check.ShouldReport, wantInDiffFile)
+		}
+		if check.InDiffContext != tt.wantInDiffContext {
+			t.Errorf("[%d] got InDiffContext=%v, want %v", i, check.InDiffContext, tt.wantInDiffContext)
+		}
+		if check.OldPath != tt.wantOldPath {
+			t.Errorf("[%d] got OldPath %q, want %q", i, check.OldPath, tt.wantOldPath)
+		}
+	}
+}
+
+const diffContentBinaryAndDeleted = `diff --git a/img.png b/img.png
+index beb58e6..aa9c9d1 100644
+Binary files a/img.png and b/img.png differ
+diff --git a/deleted.go b/deleted.go
+deleted file mode 100644
+index a949a96..0000000
+--- a/deleted.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package main
+-var x = 1
+diff --git a/changed.go b/changed.go
+index a949a96..769bdae 100644
+--- a/changed.go
++++ b/changed.go
+@@ -1,1 +1,2 @@
+ package main
++var y = 2
+`
+
+func TestFilterCheck_binaryAndDeleted(t *testing.T) {
+	newResult := func(path string, line int32) *rdf.Diagnostic {
+		return &rdf.Diagnostic{
+			Location: &rdf.Location{
+				Path:  path,
+				Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+			},
+		}
+	}
+	results := []*rdf.Diagnostic{
+		newResult("img.png", 1),
+		newResult("deleted.go", 2),
+		newResult("changed.go", 2),
+	}
+	filediffs, err := diff.ParseMultiFile(strings.NewReader(diffContentBinaryAndDeleted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filediffs) != 3 {
+		t.Fatalf("got %d file diffs, want 3", len(filediffs))
+	}
+	got := FilterCheck(results, filediffs, 1, "", ModeFile)
+	for i, want := range []bool{false, false, true} {
+		if got[i].ShouldReport != want || got[i].InDiffFile != want {
+			t.Errorf("[%d] %s: got ShouldReport=%v InDiffFile=%v, want %v",
+				i, got[i].Diagnostic.GetLocation().GetPath(), got[i].ShouldReport, got[i].InDiffFile, want)
+		}
+	}
+}
+
 func findFileDiff(filediffs []*diff.FileDiff, path string, strip int) *diff.FileDiff {
 	for _, file := range filediffs {
 		if NormalizeDiffPath(file.PathNew, strip) == path {
@@ -286,6 +439,76 @@ func findFileDiff(filediffs []*diff.FileDiff, path string, strip int) *diff.File
 	return nil
 }
 
+const diffContentAbsolutePath = `diff --git a/other/x.go b/other/x.go
+index a949a96..769bdae 100644
+--- a/other/x.go
++++ b/other/x.go
+@@ -1,1 +1,2 @@
+ package other
++var x = 1
+diff --git a/sub/a.go b/sub/a.go
+index a949a96..769bdae 100644
+--- a/sub/a.go
++++ b/sub/a.go
+@@ -1,1 +1,2 @@
+ package sub
++var a = 1
+`
+
+func TestFilterCheck_absolutePath(t *testing.T) {
+	tmp := t.TempDir()
+	root := filepath.Join(tmp, "repo")
+	for _, dir := range []string{".git", "sub", "other"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(tmp, "link")
+	if err := os.Symlink(root, link); err != nil {
+		t.Fatal(err)
+	}
+	defer cd(filepath.Join(root, "sub"))()
+
+	newResult := func(path string) *rdf.Diagnostic {
+		return &rdf.Diagnostic{
+			Location: &rdf.Location{
+				Path:  path,
+				Range: &rdf.Range{Start: &rdf.Position{Line: 2}},
+			},
+		}
+	}
+	outside := filepath.Join(tmp, "outside", "x.go")
+	tests := []struct {
+		name       string
+		cwd        string
+		path       string
+		wantPath   string
+		wantInDiff bool
+	}{
+		{name: "under cwd", cwd: filepath.Join(root, "sub"), path: filepath.Join(root, "sub", "a.go"), wantPath: "a.go", wantInDiff: true},
+		{name: "outside cwd", cwd: filepath.Join(root, "sub"), path: filepath.Join(root, "other", "x.go"), wantPath: "../other/x.go", wantInDiff: true},
+		// e.g. /var and /private/var on macOS.
+		{name: "symlinked cwd", cwd: filepath.Join(link, "sub"), path: filepath.Join(root, "sub", "a.go"), wantPath: "a.go", wantInDiff: true},
+		{name: "outside repo", cwd: filepath.Join(root, "sub"), path: outside, wantPath: filepath.ToSlash(outside)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filediffs, err := diff.ParseMultiFile(strings.NewReader(diffContentAbsolutePath))
+			if err != nil {
+				t.Fatal(err)
+			}
+			check := FilterCheck([]*rdf.Diagnostic{newResult(tt.path)}, filediffs, 1, tt.cwd, ModeAdded)[0]
+			if got := check.Diagnostic.GetLocation().GetPath(); got != tt.wantPath {
+				t.Errorf("got path %q, want %q", got, tt.wantPath)
+			}
+			if check.InDiffFile != tt.wantInDiff || check.InDiffContext != tt.wantInDiff || check.ShouldReport != tt.wantInDiff {
+				t.Errorf("got InDiffFile=%v InDiffContext=%v ShouldReport=%v, want %v",
+					check.InDiffFile, check.InDiffContext, check.ShouldReport, tt.wantInDiff)
+			}
+		})
+	}
+}
+
 func TestGetOldPosition(t *testing.T) {
 	const strip = 0
 	filediffs, _ := diff.ParseMultiFile(strings.NewReader(diffContent))
@@ -0,0 +1,77 @@
+package filter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// ignoreAnnotationRe matches ignore annotations in source lines. e.g.
+// `// reviewdog:ignore` ignores all results on the line and
+// `# reviewdog:ignore errcheck,unused` ignores results with the codes.
+// Any comment syntax works as the annotation is searched in the whole line.
+var ignoreAnnotationRe = regexp.MustCompile(`reviewdog:ignore\b(?:[ \t]+([A-Za-z0-9_][\w.\-/@:]*(?:,[A-Za-z0-9_][\w.\-/@:]*)*))?`)
+
+// ignoreAnnotation is an ignore annotation found in a source line.
+type ignoreAnnotation struct {
+	// codes are the result codes to ignore. Empty means all codes.
+	codes []string
+}
+
+// parseIgnoreAnnotation returns the ignore annotation in the line. It returns
+// false if the line doesn't have one.
+func parseIgnoreAnnotation(line string) (*ignoreAnnotation, bool) {
+	m := ignoreAnnotationRe.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false
+	}
+	a := &ignoreAnnotation{}
+	if m[1] != "" {
+		a.codes = strings.Split(m[1], ",")
+	}
+	return a, true
+}
+
+// match returns true if the annotation ignores the result.
+func (a *ignoreAnnotation) match(d *rdf.Diagnostic) bool {
+	if len(a.codes) == 0 {
+		return true
+	}
+	code := d.GetCode().GetValue()
+	for _, c := range a.codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterIgnoreAnnotations returns results except ones whose start line has an
+// ignore annotation matching them. Source lines are read from the files and
+// results whose file can't be read are kept.
+func FilterIgnoreAnnotations(results []*rdf.Diagnostic) []*rdf.Diagnostic {
+	files := make(map[string]map[int]string)
+	filtered := make([]*rdf.Diagnostic, 0, len(results))
+	for _, d := range results {
+		if !isIgnored(d, files) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+func isIgnored(d *rdf.Diagnostic, files map[string]map[int]string) bool {
+	path := d.GetLocation().GetPath()
+	lnum := int(d.GetLocation().GetRange().GetStart().GetLine())
+	if path == "" || lnum == 0 {
+		return false
+	}
+	lines, ok := files[path]
+	if !ok {
+		lines = readLines(path)
+		files[path] = lines
+	}
+	a, ok := parseIgnoreAnnotation(lines[lnum])
+	return ok && a.match(d)
+}
@@ -0,0 +1,89 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestParseIgnoreAnnotation(t *testing.T) {
+	tests := []struct {
+		line  string
+		ok    bool
+		codes []string
+	}{
+		{line: "x := 1", ok: false},
+		{line: "x := 1 // reviewdog:ignore", ok: true},
+		{line: "x = 1  # reviewdog:ignore errcheck", ok: true, codes: []string{"errcheck"}},
+		{line: "x = 1 -- reviewdog:ignore SC2086,E501", ok: true, codes: []string{"SC2086", "E501"}},
+		{line: "/* reviewdog:ignore */ x", ok: true},
+		{line: "<!-- reviewdog:ignore MD013 -->", ok: true, codes: []string{"MD013"}},
+		{line: "x // reviewdog:ignored", ok: false},
+	}
+	for _, tt := range tests {
+		a, ok := parseIgnoreAnnotation(tt.line)
+		if ok != tt.ok {
+			t.Errorf("parseIgnoreAnnotation(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if len(a.codes) != len(tt.codes) {
+			t.Errorf("parseIgnoreAnnotation(%q) codes = %v, want %v", tt.line, a.codes, tt.codes)
+			continue
+		}
+		for i := range a.codes {
+			if a.codes[i] != tt.codes[i] {
+				t.Errorf("parseIgnoreAnnotation(%q) codes = %v, want %v", tt.line, a.codes, tt.codes)
+			}
+		}
+	}
+}
+
+func TestFilterIgnoreAnnotations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	content := `package main
+
+func main() {
+	f() // reviewdog:ignore
+	g() // reviewdog:ignore errcheck
+	h()
+}
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	newResult := func(path string, line int32, code string) *rdf.Diagnostic {
+		d := &rdf.Diagnostic{
+			Location: &rdf.Location{
+				Path:  path,
+				Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+			},
+		}
+		if code != "" {
+			d.Code = &rdf.Code{Value: code}
+		}
+		return d
+	}
+	results := []*rdf.Diagnostic{
+		newResult(path, 4, "errcheck"),   // ignored by the bare annotation
+		newResult(path, 5, "errcheck"),   // ignored by the code
+		newResult(path, 5, "unused"),     // another code
+		newResult(path, 6, "errcheck"),   // no annotation
+		newResult("missing.go", 4, ""),   // unreadable file
+		newResult(path, 0, "whole-file"), // no line
+	}
+	got := FilterIgnoreAnnotations(results)
+	want := []*rdf.Diagnostic{results[2], results[3], results[4], results[5]}
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// Labeler returns labels (e.g. team=backend) of a diagnostic. Labels are
+// arbitrary metadata which reporters and comment templates can refer to
+// through FilteredDiagnostic.Labels.
+type Labeler func(d *rdf.Diagnostic) map[string]string
+
+// StaticLabels returns a Labeler which returns the same labels for all
+// diagnostics.
+func StaticLabels(labels map[string]string) Labeler {
+	return func(*rdf.Diagnostic) map[string]string {
+		return labels
+	}
+}
+
+// SetLabels sets labels returned by labelers to the checks. Labels of later
+// labelers override the ones of earlier labelers with the same keys. Checks
+// without any labels keep nil Labels.
+func SetLabels(checks []*FilteredDiagnostic, labelers ...Labeler) {
+	if len(labelers) == 0 {
+		return
+	}
+	for _, check := range checks {
+		for _, l := range labelers {
+			for k, v := range l(check.Diagnostic) {
+				if check.Labels == nil {
+					check.Labels = make(map[string]string)
+				}
+				check.Labels[k] = v
+			}
+		}
+	}
+}
+
+// ParseLabels parses labels in "key=value" format.
+func ParseLabels(kvs []string) (map[string]string, error) {
+	if len(kvs) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid label %q: want key=value", kv)
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels, nil
+}
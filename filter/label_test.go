@@ -0,0 +1,54 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestSetLabels(t *testing.T) {
+	checks := []*FilteredDiagnostic{
+		{Diagnostic: &rdf.Diagnostic{Location: &rdf.Location{Path: "api/a.go"}}},
+		{Diagnostic: &rdf.Diagnostic{Location: &rdf.Location{Path: "web/b.go"}}},
+	}
+	byPath := func(d *rdf.Diagnostic) map[string]string {
+		if d.GetLocation().GetPath() == "api/a.go" {
+			return map[string]string{"team": "api"}
+		}
+		return nil
+	}
+	SetLabels(checks, StaticLabels(map[string]string{"team": "core", "env": "ci"}), byPath)
+	want := []map[string]string{
+		{"team": "api", "env": "ci"},
+		{"team": "core", "env": "ci"},
+	}
+	for i, check := range checks {
+		if diff := cmp.Diff(want[i], check.Labels); diff != "" {
+			t.Errorf("checks[%d].Labels diff (-want +got):\n%s", i, diff)
+		}
+	}
+
+	unlabeled := []*FilteredDiagnostic{{Diagnostic: &rdf.Diagnostic{}}}
+	SetLabels(unlabeled, byPath)
+	if unlabeled[0].Labels != nil {
+		t.Errorf("Labels = %v, want nil", unlabeled[0].Labels)
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	got, err := ParseLabels([]string{"team=api", "query=a=b", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"team": "api", "query": "a=b", "empty": ""}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseLabels() diff (-want +got):\n%s", diff)
+	}
+	for _, kv := range []string{"team", "=api"} {
+		if _, err := ParseLabels([]string{kv}); err == nil {
+			t.Errorf("ParseLabels(%q) should fail", kv)
+		}
+	}
+}
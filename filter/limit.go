@@ -0,0 +1,103 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// maxOmittedListed is the max number of omitted checks listed in the message
+// of the check returned by LimitChecks.
+const maxOmittedListed = 50
+
+// LimitChecks keeps at most max reported checks and stops reporting the
+// others, so that a run doesn't overwhelm reviews with comments. Checks with
+// higher severity are kept first and checks with the same severity are kept
+// in order. It returns a check which summarizes the omitted checks in one
+// comment, or nil if nothing is omitted. The returned check is located at the
+// first omitted check in diff context (or the first omitted check if none is)
+// with the highest severity and has the omitted checks in Omitted. max <= 0
+// disables it.
+func LimitChecks(checks []*FilteredDiagnostic, max int) *FilteredDiagnostic {
+	if max <= 0 {
+		return nil
+	}
+	var reported []*FilteredDiagnostic
+	for _, check := range checks {
+		if check.ShouldReport {
+			reported = append(reported, check)
+		}
+	}
+	if len(reported) <= max {
+		return nil
+	}
+	sort.SliceStable(reported, func(i, j int) bool {
+		return severityRank(reported[i].Diagnostic.GetSeverity()) > severityRank(reported[j].Diagnostic.GetSeverity())
+	})
+	omitted := reported[max:]
+	for _, check := range omitted {
+		check.ShouldReport = false
+	}
+	first := omitted[0]
+	for _, check := range omitted {
+		if check.InDiffContext {
+			first = check
+			break
+		}
+	}
+	loc, _ := proto.Clone(first.Diagnostic.GetLocation()).(*rdf.Location)
+	return &FilteredDiagnostic{
+		Diagnostic: &rdf.Diagnostic{
+			Message:  omittedMessage(omitted),
+			Location: loc,
+			Severity: first.Diagnostic.GetSeverity(),
+			Source:   first.Diagnostic.GetSource(),
+		},
+		ShouldReport:  true,
+		InDiffFile:    first.InDiffFile,
+		InDiffContext: first.InDiffContext,
+		OldPath:       first.OldPath,
+		OldLine:       first.OldLine,
+		Omitted:       omitted,
+	}
+}
+
+// severityRank returns the rank of the severity to prioritize checks. Higher
+// is more severe and unknown severity is the lowest.
+func severityRank(s rdf.Severity) int {
+	switch s {
+	case rdf.Severity_ERROR:
+		return 3
+	case rdf.Severity_WARNING:
+		return 2
+	case rdf.Severity_INFO:
+		return 1
+	}
+	return 0
+}
+
+func omittedMessage(omitted []*FilteredDiagnostic) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d more issue(s) are not posted as individual comments to limit the number of comments:\n", len(omitted))
+	for i, check := range omitted {
+		if i == maxOmittedListed {
+			fmt.Fprintf(&sb, "\n- and %d more", len(omitted)-i)
+			break
+		}
+		loc := check.Diagnostic.GetLocation()
+		pos := loc.GetPath()
+		if line := loc.GetRange().GetStart().GetLine(); line > 0 {
+			pos = fmt.Sprintf("%s:%d", pos, line)
+		}
+		msg := strings.TrimSpace(check.Diagnostic.GetMessage())
+		if i := strings.IndexByte(msg, '\n'); i >= 0 {
+			msg = msg[:i]
+		}
+		fmt.Fprintf(&sb, "\n- `%s`: %s", pos, msg)
+	}
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestLimitChecks(t *testing.T) {
+	newCheck := func(path string, line int32, severity rdf.Severity) *FilteredDiagnostic {
+		return &FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: path, Range: &rdf.Range{Start: &rdf.Position{Line: line}}},
+				Message:  "msg " + path,
+				Severity: severity,
+			},
+			ShouldReport:  true,
+			InDiffFile:    true,
+			InDiffContext: true,
+		}
+	}
+	checks := []*FilteredDiagnostic{
+		newCheck("a.go", 1, rdf.Severity_INFO),
+		newCheck("b.go", 2, rdf.Severity_ERROR),
+		newCheck("c.go", 3, rdf.Severity_UNKNOWN_SEVERITY),
+		newCheck("d.go", 4, rdf.Severity_WARNING),
+		newCheck("e.go", 5, rdf.Severity_ERROR),
+		newCheck("f.go", 6, rdf.Severity_WARNING),
+	}
+	notReported := newCheck("g.go", 7, rdf.Severity_ERROR)
+	notReported.ShouldReport = false
+	checks = append(checks, notReported)
+
+	got := LimitChecks(checks, 3)
+
+	// Errors take the slots first, then the first warning.
+	wantReport := []bool{false, true, false, true, true, false, false}
+	for i, check := range checks {
+		if check.ShouldReport != wantReport[i] {
+			t.Errorf("checks[%d].ShouldReport = %v, want %v", i, check.ShouldReport, wantReport[i])
+		}
+	}
+	if got == nil {
+		t.Fatal("LimitChecks() = nil, want a check of omitted checks")
+	}
+	if o := got.Omitted; len(o) != 3 || o[0] != checks[5] || o[1] != checks[0] || o[2] != checks[2] {
+		t.Errorf("Omitted = %v, want checks[5], checks[0] and checks[2]", o)
+	}
+	if !got.ShouldReport || got.Diagnostic.GetLocation().GetPath() != "f.go" || got.Diagnostic.GetSeverity() != rdf.Severity_WARNING {
+		t.Errorf("got check at %v with %v, want reported check at f.go with WARNING", got.Diagnostic.GetLocation(), got.Diagnostic.GetSeverity())
+	}
+	wantMsg := "3 more issue(s) are not posted as individual comments to limit the number of comments:\n" +
+		"\n- `f.go:6`: msg f.go" +
+		"\n- `a.go:1`: msg a.go" +
+		"\n- `c.go:3`: msg c.go"
+	if msg := got.Diagnostic.GetMessage(); msg != wantMsg {
+		t.Errorf("got message:\n%s\nwant:\n%s", msg, wantMsg)
+	}
+	if n := len(got.ReportedChecks()); n != 3 {
+		t.Errorf("len(ReportedChecks()) = %d, want 3", n)
+	}
+}
+
+func TestLimitChecks_anchor(t *testing.T) {
+	newCheck := func(path string, inDiffContext bool) *FilteredDiagnostic {
+		return &FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: path},
+				Message:  "msg " + path,
+				Severity: rdf.Severity_ERROR,
+			},
+			ShouldReport:  true,
+			InDiffFile:    true,
+			InDiffContext: inDiffContext,
+		}
+	}
+	checks := []*FilteredDiagnostic{
+		newCheck("a.go", true),
+		newCheck("b.go", false),
+		newCheck("c.go", true),
+	}
+	got := LimitChecks(checks, 1)
+	if got == nil {
+		t.Fatal("LimitChecks() = nil, want a check of omitted checks")
+	}
+	// The summary is located at the first omitted check in diff context.
+	if path := got.Diagnostic.GetLocation().GetPath(); path != "c.go" || !got.InDiffContext {
+		t.Errorf("got summary at %q (InDiffContext=%v), want c.go in diff context", path, got.InDiffContext)
+	}
+	// Services may rewrite the path of the summary in place.
+	got.Diagnostic.GetLocation().Path = "sub/c.go"
+	if path := checks[2].Diagnostic.GetLocation().GetPath(); path != "c.go" {
+		t.Errorf("path of the omitted check is changed to %q with the summary", path)
+	}
+}
+
+func TestLimitChecks_underLimit(t *testing.T) {
+	checks := []*FilteredDiagnostic{
+		{Diagnostic: &rdf.Diagnostic{Message: "msg"}, ShouldReport: true},
+		{Diagnostic: &rdf.Diagnostic{Message: "msg"}, ShouldReport: true},
+	}
+	for _, max := range []int{0, 2} {
+		if got := LimitChecks(checks, max); got != nil {
+			t.Errorf("LimitChecks(checks, %d) = %v, want nil", max, got)
+		}
+		for i, check := range checks {
+			if !check.ShouldReport {
+				t.Errorf("LimitChecks(checks, %d): checks[%d] is not reported", max, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// FilterEmptyMessages returns results except ones whose message is empty or
+// consists of whitespace only, which would be posted as blank comments.
+func FilterEmptyMessages(results []*rdf.Diagnostic) []*rdf.Diagnostic {
+	filtered := make([]*rdf.Diagnostic, 0, len(results))
+	for _, d := range results {
+		if strings.TrimSpace(d.GetMessage()) != "" {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
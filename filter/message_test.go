@@ -0,0 +1,25 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestFilterEmptyMessages(t *testing.T) {
+	results := []*rdf.Diagnostic{
+		{Message: "msg1", Location: &rdf.Location{Path: "a.go"}},
+		{Location: &rdf.Location{Path: "b.go"}},
+		{Message: " \t\n", Location: &rdf.Location{Path: "c.go"}},
+		{Message: "\n msg2 ", Location: &rdf.Location{Path: "d.go"}},
+	}
+	var got []string
+	for _, d := range FilterEmptyMessages(results) {
+		got = append(got, d.GetLocation().GetPath())
+	}
+	if diff := cmp.Diff([]string{"a.go", "d.go"}, got); diff != "" {
+		t.Errorf("FilterEmptyMessages() diff: (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,70 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// PathRewrite rewrites the part of a path which matches Pattern with
+// Replacement (regexp.ReplaceAllString syntax, e.g. "$1").
+type PathRewrite struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// PathRewrites is a list of path rewrite rules. The first rule whose pattern
+// matches a path rewrites it and the rest are ignored.
+type PathRewrites []PathRewrite
+
+// String implements the flag.Value interface
+func (rs *PathRewrites) String() string {
+	ss := make([]string, 0, len(*rs))
+	for _, r := range *rs {
+		ss = append(ss, r.Pattern.String()+"="+r.Replacement)
+	}
+	return strings.Join(ss, ",")
+}
+
+// Set implements the flag.Value interface. It accepts a rule in
+// "REGEXP=REPLACEMENT" format and can be called multiple times. e.g.
+// "^=sub/" adds the prefix, "^sub/=" strips it and "^old/=new/" replaces it.
+func (rs *PathRewrites) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid path rewrite %q: want REGEXP=REPLACEMENT", value)
+	}
+	re, err := regexp.Compile(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid path rewrite pattern %q: %w", parts[0], err)
+	}
+	*rs = append(*rs, PathRewrite{Pattern: re, Replacement: parts[1]})
+	return nil
+}
+
+// Rewrite returns the path rewritten by the first matching rule, or the path
+// as it is if no rules match.
+func (rs PathRewrites) Rewrite(path string) string {
+	for _, r := range rs {
+		if r.Pattern.MatchString(path) {
+			return r.Pattern.ReplaceAllString(path, r.Replacement)
+		}
+	}
+	return path
+}
+
+// RewritePaths rewrites paths of the results with the rules in place. It's
+// applied before filtering, so rules should rewrite paths to the ones relative
+// to the current directory (or absolute ones) which reviewdog expects.
+func RewritePaths(results []*rdf.Diagnostic, rules PathRewrites) {
+	if len(rules) == 0 {
+		return
+	}
+	for _, d := range results {
+		if loc := d.GetLocation(); loc != nil && loc.GetPath() != "" {
+			loc.Path = rules.Rewrite(loc.GetPath())
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestPathRewrites_Rewrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []string
+		path  string
+		want  string
+	}{
+		{
+			name: "no rules",
+			path: "a.go",
+			want: "a.go",
+		},
+		{
+			name:  "add prefix",
+			rules: []string{"^=sub/"},
+			path:  "pkg/a.go",
+			want:  "sub/pkg/a.go",
+		},
+		{
+			name:  "strip prefix",
+			rules: []string{"^/src/repo/="},
+			path:  "/src/repo/pkg/a.go",
+			want:  "pkg/a.go",
+		},
+		{
+			name:  "replace prefix",
+			rules: []string{"^old/=new/"},
+			path:  "old/a.go",
+			want:  "new/a.go",
+		},
+		{
+			name:  "replace with groups",
+			rules: []string{`^build/([^/]+)/gen/=src/$1/`},
+			path:  "build/api/gen/a.go",
+			want:  "src/api/a.go",
+		},
+		{
+			name:  "not matched",
+			rules: []string{"^old/=new/"},
+			path:  "other/old/a.go",
+			want:  "other/old/a.go",
+		},
+		{
+			name:  "first matching rule",
+			rules: []string{"^x/=", "^a=b", "^=c/"},
+			path:  "a.go",
+			want:  "b.go",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules PathRewrites
+			for _, r := range tt.rules {
+				if err := rules.Set(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := rules.Rewrite(tt.path); got != tt.want {
+				t.Errorf("Rewrite(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathRewrites_Set_invalid(t *testing.T) {
+	for _, v := range []string{"no-separator", "(=x"} {
+		var rules PathRewrites
+		if err := rules.Set(v); err == nil {
+			t.Errorf("Set(%q) should fail", v)
+		}
+	}
+}
+
+func TestRewritePaths(t *testing.T) {
+	var rules PathRewrites
+	if err := rules.Set("^=sub/"); err != nil {
+		t.Fatal(err)
+	}
+	results := []*rdf.Diagnostic{
+		{Location: &rdf.Location{Path: "a.go"}},
+		{Location: &rdf.Location{}},
+		{},
+	}
+	RewritePaths(results, rules)
+	if got := results[0].GetLocation().GetPath(); got != "sub/a.go" {
+		t.Errorf("path = %q, want sub/a.go", got)
+	}
+	if got := results[1].GetLocation().GetPath(); got != "" {
+		t.Errorf("empty path = %q, want empty", got)
+	}
+}
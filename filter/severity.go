@@ -0,0 +1,82 @@
+package filter
+
+import (
+	"fmt"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// SeverityLevel represents the lowest severity of results to report.
+type SeverityLevel int
+
+const (
+	// SeverityLevelAny reports results regardless of severity.
+	SeverityLevelAny SeverityLevel = iota
+	// SeverityLevelInfo reports info, warning and error results.
+	SeverityLevelInfo
+	// SeverityLevelWarning reports warning and error results.
+	SeverityLevelWarning
+	// SeverityLevelError reports error results only.
+	SeverityLevelError
+)
+
+// String implements the flag.Value interface
+func (level *SeverityLevel) String() string {
+	names := [...]string{
+		"any",
+		"info",
+		"warning",
+		"error",
+	}
+	if *level < SeverityLevelAny || *level > SeverityLevelError {
+		return "Unknown severity level"
+	}
+
+	return names[*level]
+}
+
+// Set implements the flag.Value interface
+func (level *SeverityLevel) Set(value string) error {
+	switch value {
+	case "any", "":
+		*level = SeverityLevelAny
+	case "info":
+		*level = SeverityLevelInfo
+	case "warning":
+		*level = SeverityLevelWarning
+	case "error":
+		*level = SeverityLevelError
+	default:
+		return fmt.Errorf("invalid severity level: %s", value)
+	}
+	return nil
+}
+
+// Match returns true if the given severity is the same or higher than the
+// level. Unknown severity always matches because tools which don't report
+// severity should not be hidden.
+func (level SeverityLevel) Match(s rdf.Severity) bool {
+	switch s {
+	case rdf.Severity_ERROR:
+		return level <= SeverityLevelError
+	case rdf.Severity_WARNING:
+		return level <= SeverityLevelWarning
+	case rdf.Severity_INFO:
+		return level <= SeverityLevelInfo
+	}
+	return true
+}
+
+// FilterSeverity returns results whose severity matches the level.
+func FilterSeverity(results []*rdf.Diagnostic, level SeverityLevel) []*rdf.Diagnostic {
+	if level == SeverityLevelAny {
+		return results
+	}
+	filtered := make([]*rdf.Diagnostic, 0, len(results))
+	for _, d := range results {
+		if level.Match(d.GetSeverity()) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,57 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestSeverityLevel_Match(t *testing.T) {
+	severities := []rdf.Severity{
+		rdf.Severity_UNKNOWN_SEVERITY,
+		rdf.Severity_INFO,
+		rdf.Severity_WARNING,
+		rdf.Severity_ERROR,
+	}
+	tests := []struct {
+		level SeverityLevel
+		want  []bool // in the order of severities
+	}{
+		{level: SeverityLevelAny, want: []bool{true, true, true, true}},
+		{level: SeverityLevelInfo, want: []bool{true, true, true, true}},
+		{level: SeverityLevelWarning, want: []bool{true, false, true, true}},
+		{level: SeverityLevelError, want: []bool{true, false, false, true}},
+	}
+	for _, tt := range tests {
+		for i, s := range severities {
+			if got := tt.level.Match(s); got != tt.want[i] {
+				t.Errorf("%s.Match(%s) = %v, want %v", tt.level.String(), s, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestFilterSeverity(t *testing.T) {
+	results := []*rdf.Diagnostic{
+		{Message: "error", Severity: rdf.Severity_ERROR},
+		{Message: "warning", Severity: rdf.Severity_WARNING},
+		{Message: "unknown"},
+	}
+	got := FilterSeverity(results, SeverityLevelError)
+	if len(got) != 2 || got[0].GetMessage() != "error" || got[1].GetMessage() != "unknown" {
+		t.Errorf("FilterSeverity() = %v, want error and unknown results", got)
+	}
+}
+
+func TestSeverityLevel_Set(t *testing.T) {
+	var level SeverityLevel
+	if err := level.Set("warning"); err != nil {
+		t.Fatal(err)
+	}
+	if level != SeverityLevelWarning {
+		t.Errorf("got %v, want %v", level, SeverityLevelWarning)
+	}
+	if err := level.Set("fatal"); err == nil {
+		t.Error("got nil error for invalid level")
+	}
+}
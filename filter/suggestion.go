@@ -0,0 +1,185 @@
+package filter
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// SuggestionConflictMode represents how to resolve overlapping suggestions
+// proposed by different diagnostics.
+type SuggestionConflictMode int
+
+const (
+	// SuggestionConflictKeepFirst keeps the first suggestion and drops later
+	// suggestions which overlap it.
+	SuggestionConflictKeepFirst SuggestionConflictMode = iota
+	// SuggestionConflictDropAll drops all overlapping suggestions.
+	SuggestionConflictDropAll
+)
+
+// String implements the flag.Value interface
+func (mode *SuggestionConflictMode) String() string {
+	names := [...]string{
+		"keep-first",
+		"drop-all",
+	}
+	if *mode < SuggestionConflictKeepFirst || *mode > SuggestionConflictDropAll {
+		return "Unknown suggestion conflict mode"
+	}
+
+	return names[*mode]
+}
+
+// Set implements the flag.Value interface
+func (mode *SuggestionConflictMode) Set(value string) error {
+	switch value {
+	case "keep-first", "":
+		*mode = SuggestionConflictKeepFirst
+	case "drop-all":
+		*mode = SuggestionConflictDropAll
+	default:
+		return fmt.Errorf("invalid suggestion conflict mode: %s", value)
+	}
+	return nil
+}
+
+type suggestionRef struct {
+	diagnostic int // index of diagnostic in results
+	suggestion *rdf.Suggestion
+}
+
+// ResolveSuggestionConflicts drops suggestions whose line ranges overlap
+// suggestions of other diagnostics in the same file, according to mode.
+// Diagnostics themselves are always kept. It logs a warning for each dropped
+// suggestion.
+func ResolveSuggestionConflicts(results []*rdf.Diagnostic, mode SuggestionConflictMode) []*rdf.Diagnostic {
+	byPath := make(map[string][]suggestionRef)
+	for i, d := range results {
+		path := d.GetLocation().GetPath()
+		for _, s := range d.GetSuggestions() {
+			byPath[path] = append(byPath[path], suggestionRef{diagnostic: i, suggestion: s})
+		}
+	}
+
+	drop := make(map[*rdf.Suggestion]bool)
+	for path, refs := range byPath {
+		for i, a := range refs {
+			for _, b := range refs[:i] {
+				if a.diagnostic == b.diagnostic || !overlapSuggestions(a.suggestion, b.suggestion) {
+					continue
+				}
+				if mode == SuggestionConflictKeepFirst && drop[b.suggestion] {
+					// b is already dropped, so it doesn't conflict with a.
+					continue
+				}
+				log.Printf("reviewdog: dropped conflicting suggestion at %s:%d (%s)",
+					path, a.suggestion.GetRange().GetStart().GetLine(), mode.String())
+				drop[a.suggestion] = true
+				if mode == SuggestionConflictDropAll {
+					drop[b.suggestion] = true
+				}
+			}
+		}
+	}
+	if len(drop) == 0 {
+		return results
+	}
+
+	for _, d := range results {
+		var kept []*rdf.Suggestion
+		for _, s := range d.GetSuggestions() {
+			if !drop[s] {
+				kept = append(kept, s)
+			}
+		}
+		d.Suggestions = kept
+	}
+	return results
+}
+
+// overlapSuggestions returns true if line ranges of the suggestions overlap.
+func overlapSuggestions(a, b *rdf.Suggestion) bool {
+	aStart, aEnd := suggestionLines(a)
+	bStart, bEnd := suggestionLines(b)
+	return aStart <= bEnd && bStart <= aEnd
+}
+
+func suggestionLines(s *rdf.Suggestion) (start, end int32) {
+	start = s.GetRange().GetStart().GetLine()
+	end = s.GetRange().GetEnd().GetLine()
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// DropAppliedSuggestions drops suggestions whose text is the same as the
+// current content of the files at their ranges, e.g. because a later commit
+// already applied them. Suggestions are kept if the files or lines cannot be
+// read. Diagnostics themselves are always kept.
+func DropAppliedSuggestions(results []*rdf.Diagnostic) []*rdf.Diagnostic {
+	files := make(map[string]map[int]string)
+	for _, d := range results {
+		if len(d.GetSuggestions()) == 0 {
+			continue
+		}
+		path := d.GetLocation().GetPath()
+		lines, ok := files[path]
+		if !ok {
+			lines = readLines(path)
+			files[path] = lines
+		}
+		var kept []*rdf.Suggestion
+		for _, s := range d.GetSuggestions() {
+			if !suggestionApplied(s, lines) {
+				kept = append(kept, s)
+			}
+		}
+		if len(kept) != len(d.GetSuggestions()) {
+			d.Suggestions = kept
+		}
+	}
+	return results
+}
+
+// suggestionApplied returns true if the text of the suggestion is the same as
+// the content of lines at the suggestion range. Ranges without columns
+// replace whole lines, and ranges with columns replace bytes from the start
+// column to the end column (exclusive) as GitHub suggestions do.
+func suggestionApplied(s *rdf.Suggestion, lines map[int]string) bool {
+	start, end := s.GetRange().GetStart(), s.GetRange().GetEnd()
+	startLine, endLine := suggestionLines(s)
+	if startLine <= 0 {
+		return false
+	}
+	current := make([]string, 0, endLine-startLine+1)
+	for lnum := startLine; lnum <= endLine; lnum++ {
+		l, ok := lines[int(lnum)]
+		if !ok {
+			return false
+		}
+		current = append(current, l)
+	}
+	if start.GetColumn() <= 0 && end.GetColumn() <= 0 {
+		// Empty text deletes the lines, so it's never applied.
+		return s.GetText() != "" && s.GetText() == strings.Join(current, "\n")
+	}
+	startCol := int(start.GetColumn()) - 1
+	if startCol < 0 {
+		startCol = 0
+	}
+	endCol := int(end.GetColumn()) - 1
+	if endCol < 0 {
+		endCol = 0
+	}
+	last := len(current) - 1
+	if startCol > len(current[0]) || endCol > len(current[last]) || (last == 0 && startCol > endCol) {
+		return false
+	}
+	current[last] = current[last][:endCol]
+	current[0] = current[0][startCol:]
+	return s.GetText() == strings.Join(current, "\n")
+}
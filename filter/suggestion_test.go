@@ -0,0 +1,98 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestResolveSuggestionConflicts(t *testing.T) {
+	suggestion := func(start, end int32) *rdf.Suggestion {
+		return &rdf.Suggestion{Range: &rdf.Range{
+			Start: &rdf.Position{Line: start},
+			End:   &rdf.Position{Line: end},
+		}}
+	}
+	newResults := func() []*rdf.Diagnostic {
+		return []*rdf.Diagnostic{
+			{Location: &rdf.Location{Path: "a.go"}, Suggestions: []*rdf.Suggestion{suggestion(1, 3)}},
+			{Location: &rdf.Location{Path: "a.go"}, Suggestions: []*rdf.Suggestion{suggestion(3, 4)}},
+			{Location: &rdf.Location{Path: "a.go"}, Suggestions: []*rdf.Suggestion{suggestion(5, 5), suggestion(5, 6)}},
+			{Location: &rdf.Location{Path: "b.go"}, Suggestions: []*rdf.Suggestion{suggestion(1, 3)}},
+		}
+	}
+	tests := []struct {
+		mode SuggestionConflictMode
+		want []int
+	}{
+		{mode: SuggestionConflictKeepFirst, want: []int{1, 0, 2, 1}},
+		{mode: SuggestionConflictDropAll, want: []int{0, 0, 2, 1}},
+	}
+	for _, tt := range tests {
+		results := ResolveSuggestionConflicts(newResults(), tt.mode)
+		if len(results) != len(tt.want) {
+			t.Fatalf("%s: got %d results, want %d", tt.mode.String(), len(results), len(tt.want))
+		}
+		for i, d := range results {
+			if got := len(d.GetSuggestions()); got != tt.want[i] {
+				t.Errorf("%s: results[%d] has %d suggestions, want %d", tt.mode.String(), i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestDropAppliedSuggestions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	content := `package main
+
+func main() {
+	fmt.Println("fixed")
+	x := 1
+}
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	suggestion := func(startLine, startCol, endLine, endCol int32, text string) *rdf.Suggestion {
+		return &rdf.Suggestion{
+			Range: &rdf.Range{
+				Start: &rdf.Position{Line: startLine, Column: startCol},
+				End:   &rdf.Position{Line: endLine, Column: endCol},
+			},
+			Text: text,
+		}
+	}
+	tests := []struct {
+		name       string
+		path       string
+		suggestion *rdf.Suggestion
+		applied    bool
+	}{
+		{name: "applied line", path: path, suggestion: suggestion(4, 0, 4, 0, "\tfmt.Println(\"fixed\")"), applied: true},
+		{name: "applied lines", path: path, suggestion: suggestion(3, 0, 4, 0, "func main() {\n\tfmt.Println(\"fixed\")"), applied: true},
+		{name: "applied columns", path: path, suggestion: suggestion(4, 14, 4, 21, `"fixed"`), applied: true},
+		{name: "applied multiline columns", path: path, suggestion: suggestion(4, 14, 5, 4, "\"fixed\")\n\tx "), applied: true},
+		{name: "not applied", path: path, suggestion: suggestion(4, 14, 4, 21, `"fix"`)},
+		{name: "deletion", path: path, suggestion: suggestion(2, 0, 2, 0, "")},
+		{name: "out of file", path: path, suggestion: suggestion(100, 0, 100, 0, "x")},
+		{name: "out of line", path: path, suggestion: suggestion(4, 100, 4, 101, "")},
+		{name: "missing file", path: "missing.go", suggestion: suggestion(1, 0, 1, 0, "package main")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &rdf.Diagnostic{
+				Location:    &rdf.Location{Path: tt.path},
+				Suggestions: []*rdf.Suggestion{tt.suggestion},
+			}
+			got := DropAppliedSuggestions([]*rdf.Diagnostic{d})
+			if len(got) != 1 {
+				t.Fatalf("got %d results, want 1", len(got))
+			}
+			if dropped := len(got[0].GetSuggestions()) == 0; dropped != tt.applied {
+				t.Errorf("suggestion dropped = %v, want %v", dropped, tt.applied)
+			}
+		})
+	}
+}
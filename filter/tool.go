@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// ToolPatterns is a list of glob patterns (path.Match syntax) of tool names.
+type ToolPatterns []string
+
+// String implements the flag.Value interface
+func (ps *ToolPatterns) String() string {
+	return strings.Join(*ps, ",")
+}
+
+// Set implements the flag.Value interface. It accepts comma separated
+// patterns and can be called multiple times.
+func (ps *ToolPatterns) Set(value string) error {
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid tool name pattern %q: %w", p, err)
+		}
+		*ps = append(*ps, p)
+	}
+	return nil
+}
+
+// Match returns true if the name matches any of the patterns.
+func (ps ToolPatterns) Match(name string) bool {
+	for _, p := range ps {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterTools returns results whose tool name matches include patterns and
+// doesn't match exclude patterns. Empty include patterns match any tools. The
+// tool name of a result is its source name, or toolName if it has no source.
+func FilterTools(results []*rdf.Diagnostic, toolName string, include, exclude ToolPatterns) []*rdf.Diagnostic {
+	if len(include) == 0 && len(exclude) == 0 {
+		return results
+	}
+	filtered := make([]*rdf.Diagnostic, 0, len(results))
+	for _, d := range results {
+		name := toolName
+		if n := d.GetSource().GetName(); n != "" {
+			name = n
+		}
+		if len(include) > 0 && !include.Match(name) {
+			continue
+		}
+		if exclude.Match(name) {
+			continue
+		}
+		filtered = append(filtered, d)
+	}
+	return filtered
+}
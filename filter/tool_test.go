@@ -0,0 +1,77 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestFilterTools(t *testing.T) {
+	results := []*rdf.Diagnostic{
+		{Message: "golint", Source: &rdf.Source{Name: "golint"}},
+		{Message: "staticcheck", Source: &rdf.Source{Name: "staticcheck"}},
+		{Message: "stylecheck", Source: &rdf.Source{Name: "stylecheck"}},
+		{Message: "no source"},
+	}
+	tests := []struct {
+		name    string
+		include ToolPatterns
+		exclude ToolPatterns
+		want    []string
+	}{
+		{
+			name: "no patterns",
+			want: []string{"golint", "staticcheck", "stylecheck", "no source"},
+		},
+		{
+			name:    "include only",
+			include: ToolPatterns{"st*check"},
+			want:    []string{"staticcheck", "stylecheck"},
+		},
+		{
+			name:    "include the tool name",
+			include: ToolPatterns{"golangci"},
+			want:    []string{"no source"},
+		},
+		{
+			name:    "exclude",
+			exclude: ToolPatterns{"golint", "style*"},
+			want:    []string{"staticcheck", "no source"},
+		},
+		{
+			name:    "include and exclude",
+			include: ToolPatterns{"*check"},
+			exclude: ToolPatterns{"stylecheck"},
+			want:    []string{"staticcheck"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FilterTools(results, "golangci", tt.include, tt.exclude)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FilterTools() = %v, want %v", got, tt.want)
+			}
+			for i, d := range got {
+				if d.GetMessage() != tt.want[i] {
+					t.Errorf("FilterTools()[%d] = %q, want %q", i, d.GetMessage(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestToolPatterns_Set(t *testing.T) {
+	var ps ToolPatterns
+	if err := ps.Set("golint, stat*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.Set("eslint"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ps.String(), "golint,stat*,eslint"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := ps.Set("[bad"); err == nil {
+		t.Error("got no error for invalid pattern")
+	}
+}
@@ -0,0 +1,178 @@
+// Package metrics provides sinks of metrics of reviewdog runs such as
+// durations of stages, the number of API calls and the number of results.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink receives metrics. Implementations must be safe for concurrent use.
+type Sink interface {
+	// Timing records the duration of the named stage.
+	Timing(name string, d time.Duration, tags Tags)
+	// Count adds n to the named counter.
+	Count(name string, n int, tags Tags)
+}
+
+// Tags are dimensions of a metric (e.g. tool=golint).
+type Tags map[string]string
+
+// sortedKeys returns the keys of the tags in a stable order.
+func (t Tags) sortedKeys() []string {
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var _ Sink = &JSONSink{}
+
+// JSONSink writes metrics to w as JSON lines, e.g.
+//
+//	{"name":"diff","type":"timing","value":12.5,"unit":"ms","tags":{"tool":"golint"}}
+//	{"name":"results.reported","type":"count","value":3,"tags":{"tool":"golint"}}
+type JSONSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONSink returns a new JSONSink which writes to w.
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{enc: json.NewEncoder(w)}
+}
+
+type jsonMetric struct {
+	Name  string  `json:"name"`
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+	Tags  Tags    `json:"tags,omitempty"`
+}
+
+func (s *JSONSink) Timing(name string, d time.Duration, tags Tags) {
+	s.write(&jsonMetric{Name: name, Type: "timing", Value: float64(d) / float64(time.Millisecond), Unit: "ms", Tags: tags})
+}
+
+func (s *JSONSink) Count(name string, n int, tags Tags) {
+	s.write(&jsonMetric{Name: name, Type: "count", Value: float64(n), Tags: tags})
+}
+
+func (s *JSONSink) write(m *jsonMetric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Metrics are best effort and never fail runs.
+	_ = s.enc.Encode(m)
+}
+
+var _ Sink = &StatsdSink{}
+
+// StatsdSink sends metrics to a statsd server over UDP. Names are prefixed
+// with the prefix and a dot, and tags are sent in the DogStatsD format (e.g.
+// "reviewdog.diff:12|ms|#tool:golint"), which Datadog agents and Telegraf
+// accept.
+type StatsdSink struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	prefix string
+}
+
+// NewStatsdSink returns a new StatsdSink which sends metrics to addr (e.g.
+// "127.0.0.1:8125"). Close it after use.
+func NewStatsdSink(addr, prefix string) (*StatsdSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd %s: %w", addr, err)
+	}
+	return &StatsdSink{conn: conn, prefix: prefix}, nil
+}
+
+func (s *StatsdSink) Timing(name string, d time.Duration, tags Tags) {
+	s.send(name, fmt.Sprintf("%d|ms", d.Milliseconds()), tags)
+}
+
+func (s *StatsdSink) Count(name string, n int, tags Tags) {
+	s.send(name, fmt.Sprintf("%d|c", n), tags)
+}
+
+func (s *StatsdSink) send(name, value string, tags Tags) {
+	var sb strings.Builder
+	if s.prefix != "" {
+		sb.WriteString(s.prefix + ".")
+	}
+	sb.WriteString(name + ":" + value)
+	for i, k := range tags.sortedKeys() {
+		if i == 0 {
+			sb.WriteString("|#")
+		} else {
+			sb.WriteString(",")
+		}
+		sb.WriteString(k + ":" + tags[k])
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// UDP writes are best effort and never fail runs.
+	_, _ = s.conn.Write([]byte(sb.String()))
+}
+
+// Close closes the connection.
+func (s *StatsdSink) Close() error {
+	return s.conn.Close()
+}
+
+// Time records the duration since start as the named timing if sink is not
+// nil. It's handy with defer:
+//
+//	defer metrics.Time(sink, "diff", time.Now(), tags)
+func Time(sink Sink, name string, start time.Time, tags Tags) {
+	if sink != nil {
+		sink.Timing(name, time.Since(start), tags)
+	}
+}
+
+// Count adds n to the named counter if sink is not nil.
+func Count(sink Sink, name string, n int, tags Tags) {
+	if sink != nil {
+		sink.Count(name, n, tags)
+	}
+}
+
+// Transport returns an http.RoundTripper which counts requests as
+// "api.calls" with host, method and status code class (e.g. 2xx, or error) tags,
+// and times them as "api.call". It returns base as is if sink is nil.
+func Transport(base http.RoundTripper, sink Sink) http.RoundTripper {
+	if sink == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, sink: sink}
+}
+
+type transport struct {
+	base http.RoundTripper
+	sink Sink
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	status := "error"
+	if err == nil {
+		status = fmt.Sprintf("%dxx", resp.StatusCode/100)
+	}
+	tags := Tags{"host": req.URL.Host, "method": req.Method, "status": status}
+	t.sink.Timing("api.call", time.Since(start), tags)
+	t.sink.Count("api.calls", 1, tags)
+	return resp, err
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONSink(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewJSONSink(&buf)
+	s.Timing("diff", 1500*time.Microsecond, Tags{"tool": "golint"})
+	s.Count("results.reported", 3, nil)
+	want := `{"name":"diff","type":"timing","value":1.5,"unit":"ms","tags":{"tool":"golint"}}
+{"name":"results.reported","type":"count","value":3}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestStatsdSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	s, err := NewStatsdSink(conn.LocalAddr().String(), "reviewdog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.Timing("diff", 12*time.Millisecond, Tags{"tool": "golint", "a": "b"})
+	s.Count("results.reported", 3, nil)
+
+	for _, want := range []string{
+		"reviewdog.diff:12|ms|#a:b,tool:golint",
+		"reviewdog.results.reported:3|c",
+	} {
+		buf := make([]byte, 1024)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	cli := &http.Client{Transport: Transport(nil, NewJSONSink(&buf))}
+	resp, err := cli.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d metrics, want 2:\n%s", len(lines), buf.String())
+	}
+	if want := `"name":"api.calls","type":"count","value":1`; !strings.Contains(lines[1], want) {
+		t.Errorf("got %s, want %s", lines[1], want)
+	}
+	if want := `"method":"GET","status":"4xx"`; !strings.Contains(lines[1], want) {
+		t.Errorf("got %s, want tags %s", lines[1], want)
+	}
+
+	if tr := Transport(http.DefaultTransport, nil); tr != http.DefaultTransport {
+		t.Error("Transport with nil sink should return the base")
+	}
+}
@@ -11,13 +11,19 @@ import (
 var _ Parser = &CheckStyleParser{}
 
 // CheckStyleParser is checkstyle parser.
-type CheckStyleParser struct{}
+type CheckStyleParser struct {
+	severityMap SeverityMap
+}
 
 // NewCheckStyleParser returns a new CheckStyleParser.
 func NewCheckStyleParser() Parser {
 	return &CheckStyleParser{}
 }
 
+func (p *CheckStyleParser) setSeverityMap(m SeverityMap) {
+	p.severityMap = m
+}
+
 func (p *CheckStyleParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 	var cs = new(CheckStyleResult)
 	if err := xml.NewDecoder(r).Decode(cs); err != nil {
@@ -37,13 +43,16 @@ func (p *CheckStyleParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 					},
 				},
 				Message:  cerr.Message,
-				Severity: severity(cerr.Severity),
+				Severity: p.severityMap.Severity(cerr.Severity),
 				OriginalOutput: fmt.Sprintf("%v:%d:%d: %v: %v (%v)",
 					file.Name, cerr.Line, cerr.Column, cerr.Severity, cerr.Message, cerr.Source),
 			}
 			if s := cerr.Source; s != "" {
 				d.Code = &rdf.Code{Value: s}
 			}
+			for _, fix := range cerr.Fixes {
+				d.Suggestions = append(d.Suggestions, fix.suggestion(cerr))
+			}
 			ds = append(ds, d)
 		}
 	}
@@ -70,9 +79,48 @@ type CheckStyleFile struct {
 
 // CheckStyleError represents <error line="1" column="10" severity="error" message="msg" source="src" />
 type CheckStyleError struct {
-	Column   int    `xml:"column,attr,omitempty"`
-	Line     int    `xml:"line,attr"`
-	Message  string `xml:"message,attr"`
-	Severity string `xml:"severity,attr,omitempty"`
-	Source   string `xml:"source,attr,omitempty"`
+	Column   int              `xml:"column,attr,omitempty"`
+	Line     int              `xml:"line,attr"`
+	Message  string           `xml:"message,attr"`
+	Severity string           `xml:"severity,attr,omitempty"`
+	Source   string           `xml:"source,attr,omitempty"`
+	Fixes    []*CheckStyleFix `xml:"fix,omitempty"`
+}
+
+// CheckStyleFix represents a fix of an error, which is not a part of the
+// checkstyle format but an extension reviewdog supports.
+// <error ...><fix line="1" column="10" endLine="1" endColumn="16">replacement</fix></error>
+//
+// Attributes are optional. Without line, the fix replaces the line of the
+// error. Without columns, it replaces whole lines from line to endLine. With
+// columns, it replaces text from column to endColumn (exclusive), and
+// endLine and endColumn default to line and column (insertion).
+type CheckStyleFix struct {
+	Line      int    `xml:"line,attr,omitempty"`
+	Column    int    `xml:"column,attr,omitempty"`
+	EndLine   int    `xml:"endLine,attr,omitempty"`
+	EndColumn int    `xml:"endColumn,attr,omitempty"`
+	Text      string `xml:",chardata"`
+}
+
+func (fix *CheckStyleFix) suggestion(cerr *CheckStyleError) *rdf.Suggestion {
+	line := fix.Line
+	if line == 0 {
+		line = cerr.Line
+	}
+	endLine := fix.EndLine
+	if endLine == 0 {
+		endLine = line
+	}
+	endColumn := fix.EndColumn
+	if endColumn == 0 && endLine == line {
+		endColumn = fix.Column
+	}
+	return &rdf.Suggestion{
+		Range: &rdf.Range{
+			Start: &rdf.Position{Line: int32(line), Column: int32(fix.Column)},
+			End:   &rdf.Position{Line: int32(endLine), Column: int32(endColumn)},
+		},
+		Text: fix.Text,
+	}
 }
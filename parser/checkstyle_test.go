@@ -4,9 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
+	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
 )
 
 func ExampleCheckStyleParser() {
@@ -178,3 +184,35 @@ func ExampleCheckStyleParser() {
 	//   "originalOutput": "/path/to/file:7:2: error: Unnecessary semicolon. (no-extra-semi) (eslint.rules.no-extra-semi)"
 	// }
 }
+
+func TestCheckStyleParser_fix(t *testing.T) {
+	f, err := os.Open("testdata/checkstyle_fix.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	diagnostics, err := NewCheckStyleParser().Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := func(startLine, startCol, endLine, endCol int32) *rdf.Range {
+		return &rdf.Range{
+			Start: &rdf.Position{Line: startLine, Column: startCol},
+			End:   &rdf.Position{Line: endLine, Column: endCol},
+		}
+	}
+	want := [][]*rdf.Suggestion{
+		{{Range: rng(1, 1, 1, 4), Text: "let"}},
+		{{Range: rng(2, 12, 2, 12), Text: ";"}},
+		{{Range: rng(4, 0, 5, 0), Text: "if (a) {\n  b();"}, {Range: rng(7, 0, 7, 0), Text: ""}},
+		nil, // No fix data.
+	}
+	if len(diagnostics) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d", len(diagnostics), len(want))
+	}
+	for i, d := range diagnostics {
+		if diff := cmp.Diff(want[i], d.GetSuggestions(), protocmp.Transform()); diff != "" {
+			t.Errorf("suggestions of diagnostic %d (-want +got):\n%s", i, diff)
+		}
+	}
+}
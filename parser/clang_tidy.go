@@ -0,0 +1,266 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ Parser = &ClangTidyFixesParser{}
+
+// ClangTidyFixesParser is a parser for fixes YAML of clang-tidy
+// (`clang-tidy -export-fixes=fixes.yaml`). Replacements are converted to
+// suggestions.
+//
+// The YAML has byte offsets instead of lines and columns, so the parser reads
+// the source files to convert them. Relative file paths are resolved with
+// BuildDirectory of diagnostics.
+type ClangTidyFixesParser struct {
+	// readFile reads source files. os.ReadFile is used if nil.
+	readFile func(path string) ([]byte, error)
+}
+
+// NewClangTidyFixesParser returns a new ClangTidyFixesParser.
+func NewClangTidyFixesParser() Parser {
+	return &ClangTidyFixesParser{}
+}
+
+func (p *ClangTidyFixesParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
+	files := &clangTidySourceFiles{
+		readFile: p.readFile,
+		lines:    make(map[string][]int),
+	}
+	if files.readFile == nil {
+		files.readFile = os.ReadFile
+	}
+	var ds []*rdf.Diagnostic
+	dec := yaml.NewDecoder(r)
+	for {
+		var fixes ClangTidyFixes
+		if err := dec.Decode(&fixes); err != nil {
+			if errors.Is(err, io.EOF) {
+				return ds, nil
+			}
+			return nil, err
+		}
+		for _, d := range fixes.Diagnostics {
+			rds, err := d.diagnostics(files)
+			if err != nil {
+				return nil, err
+			}
+			ds = append(ds, rds...)
+		}
+	}
+}
+
+// ClangTidyFixes represents fixes YAML of clang-tidy.
+//
+// Reference: https://clang.llvm.org/doxygen/structclang_1_1tooling_1_1TranslationUnitDiagnostics.html
+type ClangTidyFixes struct {
+	MainSourceFile string                 `yaml:"MainSourceFile"`
+	Diagnostics    []*ClangTidyDiagnostic `yaml:"Diagnostics"`
+}
+
+// ClangTidyDiagnostic represents a diagnostic of clang-tidy. clang-tidy 8 and
+// older ones have fields of DiagnosticMessage in the diagnostic itself.
+type ClangTidyDiagnostic struct {
+	DiagnosticName    string                  `yaml:"DiagnosticName"`
+	DiagnosticMessage *ClangTidyMessage       `yaml:"DiagnosticMessage"`
+	Level             string                  `yaml:"Level"`
+	BuildDirectory    string                  `yaml:"BuildDirectory"`
+	Message           string                  `yaml:"Message"`
+	FilePath          string                  `yaml:"FilePath"`
+	FileOffset        int                     `yaml:"FileOffset"`
+	Replacements      []*ClangTidyReplacement `yaml:"Replacements"`
+}
+
+// ClangTidyMessage represents a message of a clang-tidy diagnostic.
+type ClangTidyMessage struct {
+	Message      string                  `yaml:"Message"`
+	FilePath     string                  `yaml:"FilePath"`
+	FileOffset   int                     `yaml:"FileOffset"`
+	Replacements []*ClangTidyReplacement `yaml:"Replacements"`
+}
+
+// ClangTidyReplacement replaces Length bytes from Offset (0-based) of the file
+// with ReplacementText.
+type ClangTidyReplacement struct {
+	FilePath        string `yaml:"FilePath"`
+	Offset          int    `yaml:"Offset"`
+	Length          int    `yaml:"Length"`
+	ReplacementText string `yaml:"ReplacementText"`
+}
+
+func (d *ClangTidyDiagnostic) message() *ClangTidyMessage {
+	if d.DiagnosticMessage != nil {
+		return d.DiagnosticMessage
+	}
+	return &ClangTidyMessage{
+		Message:      d.Message,
+		FilePath:     d.FilePath,
+		FileOffset:   d.FileOffset,
+		Replacements: d.Replacements,
+	}
+}
+
+// diagnostics returns the diagnostic and diagnostics for replacements in other
+// files than the diagnostic file, as suggestions apply to the file of their
+// diagnostic.
+func (d *ClangTidyDiagnostic) diagnostics(files *clangTidySourceFiles) ([]*rdf.Diagnostic, error) {
+	msg := d.message()
+	path := d.resolvePath(msg.FilePath)
+	var start *rdf.Position
+	if path != "" {
+		var err error
+		if start, err = files.position(path, msg.FileOffset); err != nil {
+			return nil, err
+		}
+	}
+	main := d.diagnostic(path, start, msg.Message)
+
+	ds := []*rdf.Diagnostic{main}
+	others := make(map[string]*rdf.Diagnostic)
+	for _, rep := range sortReplacements(msg.Replacements) {
+		rpath := d.resolvePath(rep.FilePath)
+		s, err := rep.suggestion(rpath, files)
+		if err != nil {
+			return nil, err
+		}
+		target := main
+		if rpath != path {
+			target = others[rpath]
+			if target == nil {
+				target = d.diagnostic(rpath, s.GetRange().GetStart(), msg.Message)
+				others[rpath] = target
+				ds = append(ds, target)
+			}
+		}
+		if overlapsLastSuggestion(target, s) {
+			continue
+		}
+		target.Suggestions = append(target.Suggestions, s)
+	}
+	return ds, nil
+}
+
+func (d *ClangTidyDiagnostic) diagnostic(path string, start *rdf.Position, message string) *rdf.Diagnostic {
+	rd := &rdf.Diagnostic{
+		Message:  message,
+		Severity: clangTidySeverity(d.Level),
+		Source:   &rdf.Source{Name: "clang-tidy", Url: "https://clang.llvm.org/extra/clang-tidy/"},
+		OriginalOutput: fmt.Sprintf("%s:%d:%d: %s [%s]",
+			path, start.GetLine(), start.GetColumn(), message, d.DiagnosticName),
+	}
+	if path != "" {
+		// Diagnostics without file (e.g. of command line errors) have no
+		// location.
+		rd.Location = &rdf.Location{
+			Path:  path,
+			Range: &rdf.Range{Start: start},
+		}
+	}
+	if d.DiagnosticName != "" {
+		rd.Code = &rdf.Code{Value: d.DiagnosticName}
+	}
+	return rd
+}
+
+func (d *ClangTidyDiagnostic) resolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) || d.BuildDirectory == "" {
+		return path
+	}
+	return filepath.Join(d.BuildDirectory, path)
+}
+
+func clangTidySeverity(level string) rdf.Severity {
+	if level == "Remark" {
+		return rdf.Severity_INFO
+	}
+	return severity(level)
+}
+
+// sortReplacements returns replacements sorted by file and offset so that
+// overlapping ones are next to each other.
+func sortReplacements(reps []*ClangTidyReplacement) []*ClangTidyReplacement {
+	sorted := make([]*ClangTidyReplacement, len(reps))
+	copy(sorted, reps)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].FilePath != sorted[j].FilePath {
+			return sorted[i].FilePath < sorted[j].FilePath
+		}
+		return sorted[i].Offset < sorted[j].Offset
+	})
+	return sorted
+}
+
+// overlapsLastSuggestion returns true if s overlaps the last suggestion of d.
+// Suggestions of a diagnostic cannot overlap, so the first one of overlapping
+// replacements wins. Insertions at the end of the last one don't overlap.
+func overlapsLastSuggestion(d *rdf.Diagnostic, s *rdf.Suggestion) bool {
+	if len(d.Suggestions) == 0 {
+		return false
+	}
+	last := d.Suggestions[len(d.Suggestions)-1].GetRange().GetEnd()
+	start := s.GetRange().GetStart()
+	if start.GetLine() != last.GetLine() {
+		return start.GetLine() < last.GetLine()
+	}
+	return start.GetColumn() < last.GetColumn()
+}
+
+func (rep *ClangTidyReplacement) suggestion(path string, files *clangTidySourceFiles) (*rdf.Suggestion, error) {
+	start, err := files.position(path, rep.Offset)
+	if err != nil {
+		return nil, err
+	}
+	end, err := files.position(path, rep.Offset+rep.Length)
+	if err != nil {
+		return nil, err
+	}
+	return &rdf.Suggestion{
+		Range: &rdf.Range{Start: start, End: end},
+		Text:  rep.ReplacementText,
+	}, nil
+}
+
+// clangTidySourceFiles converts byte offsets of source files to positions.
+type clangTidySourceFiles struct {
+	readFile func(path string) ([]byte, error)
+	// lines has byte offsets of line starts per file.
+	lines map[string][]int
+}
+
+// position returns the 1-based line and column (in bytes) of the 0-based byte
+// offset in the file.
+func (fs *clangTidySourceFiles) position(path string, offset int) (*rdf.Position, error) {
+	starts, ok := fs.lines[path]
+	if !ok {
+		b, err := fs.readFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source file to convert offsets: %w", err)
+		}
+		starts = []int{0}
+		for i := bytes.IndexByte(b, '\n'); i >= 0; {
+			next := starts[len(starts)-1] + i + 1
+			starts = append(starts, next)
+			i = bytes.IndexByte(b[next:], '\n')
+		}
+		// Keep the file size as the end of the last line.
+		starts = append(starts, len(b)+1)
+		fs.lines[path] = starts
+	}
+	if offset < 0 || offset >= starts[len(starts)-1] {
+		return nil, fmt.Errorf("offset %d is out of %s", offset, path)
+	}
+	// Index of the first line start after the offset.
+	i := sort.SearchInts(starts, offset+1)
+	return &rdf.Position{Line: int32(i), Column: int32(offset - starts[i-1] + 1)}, nil
+}
@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestClangTidyFixesParser(t *testing.T) {
+	f, err := os.Open("testdata/clang-tidy-fixes.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := NewClangTidyFixesParser().Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &rdf.Source{Name: "clang-tidy", Url: "https://clang.llvm.org/extra/clang-tidy/"}
+	pos := func(line, col int32) *rdf.Position {
+		return &rdf.Position{Line: line, Column: col}
+	}
+	suggestion := func(start, end *rdf.Position, text string) *rdf.Suggestion {
+		return &rdf.Suggestion{Range: &rdf.Range{Start: start, End: end}, Text: text}
+	}
+	want := []*rdf.Diagnostic{
+		{
+			Message: "use nullptr",
+			Location: &rdf.Location{
+				Path:  "testdata/clang-tidy/main.cpp",
+				Range: &rdf.Range{Start: pos(3, 12)},
+			},
+			Severity: rdf.Severity_WARNING,
+			Source:   source,
+			Code:     &rdf.Code{Value: "modernize-use-nullptr"},
+			Suggestions: []*rdf.Suggestion{
+				suggestion(pos(3, 12), pos(3, 16), "nullptr"),
+			},
+			OriginalOutput: "testdata/clang-tidy/main.cpp:3:12: use nullptr [modernize-use-nullptr]",
+		},
+		{
+			Message: "invalid case style for parameter 'p'",
+			Location: &rdf.Location{
+				Path:  "testdata/clang-tidy/main.cpp",
+				Range: &rdf.Range{Start: pos(2, 12)},
+			},
+			Severity: rdf.Severity_WARNING,
+			Source:   source,
+			Code:     &rdf.Code{Value: "readability-identifier-naming"},
+			Suggestions: []*rdf.Suggestion{
+				suggestion(pos(2, 12), pos(2, 13), "ptr"),
+				suggestion(pos(3, 7), pos(3, 8), "ptr"),
+				suggestion(pos(4, 11), pos(4, 12), "ptr"),
+			},
+			OriginalOutput: "testdata/clang-tidy/main.cpp:2:12: invalid case style for parameter 'p' [readability-identifier-naming]",
+		},
+		{
+			Message: "invalid case style for parameter 'p'",
+			Location: &rdf.Location{
+				Path:  "testdata/clang-tidy/a.h",
+				Range: &rdf.Range{Start: pos(1, 12)},
+			},
+			Severity: rdf.Severity_WARNING,
+			Source:   source,
+			Code:     &rdf.Code{Value: "readability-identifier-naming"},
+			Suggestions: []*rdf.Suggestion{
+				suggestion(pos(1, 12), pos(1, 13), "ptr"),
+			},
+			OriginalOutput: "testdata/clang-tidy/a.h:1:12: invalid case style for parameter 'p' [readability-identifier-naming]",
+		},
+		{
+			Message: "unknown type name 'foo'",
+			Location: &rdf.Location{
+				Path:  "testdata/clang-tidy/main.cpp",
+				Range: &rdf.Range{Start: pos(1, 1)},
+			},
+			Severity:       rdf.Severity_ERROR,
+			Source:         source,
+			Code:           &rdf.Code{Value: "clang-diagnostic-error"},
+			OriginalOutput: "testdata/clang-tidy/main.cpp:1:1: unknown type name 'foo' [clang-diagnostic-error]",
+		},
+		{
+			Message:        "no input files",
+			Severity:       rdf.Severity_ERROR,
+			Source:         source,
+			Code:           &rdf.Code{Value: "clang-diagnostic-error"},
+			OriginalOutput: ":0:0: no input files [clang-diagnostic-error]",
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestClangTidyFixesParser_position(t *testing.T) {
+	p := &ClangTidyFixesParser{readFile: func(string) ([]byte, error) {
+		return []byte("ab\n\ncd"), nil
+	}}
+	in := `
+Diagnostics:
+  - DiagnosticName: check
+    DiagnosticMessage:
+      Message: msg
+      FilePath: /src/a.cpp
+      FileOffset: %d
+`
+	tests := []struct {
+		offset int
+		want   *rdf.Position
+	}{
+		{offset: 0, want: &rdf.Position{Line: 1, Column: 1}},
+		{offset: 2, want: &rdf.Position{Line: 1, Column: 3}},
+		{offset: 3, want: &rdf.Position{Line: 2, Column: 1}},
+		{offset: 5, want: &rdf.Position{Line: 3, Column: 2}},
+		{offset: 6, want: &rdf.Position{Line: 3, Column: 3}}, // EOF
+	}
+	for _, tt := range tests {
+		ds, err := p.Parse(strings.NewReader(fmt.Sprintf(in, tt.offset)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(tt.want, ds[0].GetLocation().GetRange().GetStart(), protocmp.Transform()); diff != "" {
+			t.Errorf("offset %d: diff (-want +got):\n%s", tt.offset, diff)
+		}
+	}
+	if _, err := p.Parse(strings.NewReader(fmt.Sprintf(in, 7))); err == nil {
+		t.Error("out of range offset should fail")
+	}
+}
@@ -11,52 +11,123 @@ import (
 )
 
 var _ Parser = &ErrorformatParser{}
+var _ UnmatchedCounter = &ErrorformatParser{}
+
+// ErrorformatSetSeparator separates errorformat patterns into ordered sets.
+// Lines which no pattern of a set matches are parsed with the next set, so
+// that output mixing different formats (e.g. summary lines and detail lines)
+// can be parsed.
+const ErrorformatSetSeparator = "--"
 
 // ErrorformatParser is errorformat parser.
 type ErrorformatParser struct {
 	efm *errorformat.Errorformat
+
+	// fallbacks are errorformats to parse lines which efm doesn't match, in
+	// order.
+	fallbacks []*errorformat.Errorformat
+
+	// unmatched is the number of lines which any errorformat didn't match in
+	// the last Parse.
+	unmatched int
+
+	severityMap SeverityMap
 }
 
-// NewErrorformatParser returns a new ErrorformatParser.
-func NewErrorformatParser(efm *errorformat.Errorformat) *ErrorformatParser {
-	return &ErrorformatParser{efm: efm}
+// NewErrorformatParser returns a new ErrorformatParser. Lines which efm
+// doesn't match are parsed with fallbacks in order.
+func NewErrorformatParser(efm *errorformat.Errorformat, fallbacks ...*errorformat.Errorformat) *ErrorformatParser {
+	return &ErrorformatParser{efm: efm, fallbacks: fallbacks}
 }
 
 // NewErrorformatParserString returns a new ErrorformatParser from errorformat
-// in string representation.
+// in string representation. Patterns can be separated into fallback sets by
+// ErrorformatSetSeparator.
 func NewErrorformatParserString(efms []string) (*ErrorformatParser, error) {
-	efm, err := errorformat.NewErrorformat(efms)
-	if err != nil {
-		return nil, err
+	var sets []*errorformat.Errorformat
+	for _, set := range splitErrorformatSets(efms) {
+		efm, err := errorformat.NewErrorformat(set)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, efm)
+	}
+	return NewErrorformatParser(sets[0], sets[1:]...), nil
+}
+
+func splitErrorformatSets(efms []string) [][]string {
+	sets := [][]string{{}}
+	for _, efm := range efms {
+		if efm == ErrorformatSetSeparator {
+			sets = append(sets, []string{})
+			continue
+		}
+		sets[len(sets)-1] = append(sets[len(sets)-1], efm)
+	}
+	nonEmpty := sets[:0]
+	for _, set := range sets {
+		if len(set) > 0 {
+			nonEmpty = append(nonEmpty, set)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return [][]string{{}}
 	}
-	return NewErrorformatParser(efm), nil
+	return nonEmpty
 }
 
 func (p *ErrorformatParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
-	s := p.efm.NewScanner(r)
 	var ds []*rdf.Diagnostic
-	for s.Scan() {
-		e := s.Entry()
-		if e.Valid {
-			d := &rdf.Diagnostic{
-				Location: &rdf.Location{
-					Path: e.Filename,
-					Range: &rdf.Range{
-						Start: &rdf.Position{
-							Line:   int32(e.Lnum),
-							Column: int32(e.Col),
-						},
-					},
-				},
-				Message:        e.Text,
-				Severity:       severity(string(e.Type)),
-				OriginalOutput: strings.Join(e.Lines, "\n"),
+	var unmatched []string
+	for i, efm := range append([]*errorformat.Errorformat{p.efm}, p.fallbacks...) {
+		if i > 0 {
+			if len(unmatched) == 0 {
+				break
 			}
-			if e.Nr != 0 {
-				d.Code = &rdf.Code{Value: fmt.Sprintf("%d", e.Nr)}
+			r = strings.NewReader(strings.Join(unmatched, "\n"))
+			unmatched = nil
+		}
+		s := efm.NewScanner(r)
+		for s.Scan() {
+			e := s.Entry()
+			if !e.Valid {
+				unmatched = append(unmatched, e.Lines...)
+				continue
 			}
-			ds = append(ds, d)
+			ds = append(ds, entryDiagnostic(e, p.severityMap))
 		}
 	}
+	p.unmatched = len(unmatched)
 	return ds, nil
 }
+
+// Unmatched returns the number of lines which no errorformat matched in the
+// last Parse.
+func (p *ErrorformatParser) Unmatched() int {
+	return p.unmatched
+}
+
+func (p *ErrorformatParser) setSeverityMap(m SeverityMap) {
+	p.severityMap = m
+}
+
+func entryDiagnostic(e *errorformat.Entry, m SeverityMap) *rdf.Diagnostic {
+	d := &rdf.Diagnostic{
+		Location: &rdf.Location{
+			Path: e.Filename,
+			Range: &rdf.Range{
+				Start: &rdf.Position{
+					Line:   int32(e.Lnum),
+					Column: int32(e.Col),
+				},
+			},
+		},
+		Message:        e.Text,
+		Severity:       m.Severity(string(e.Type)),
+		OriginalOutput: strings.Join(e.Lines, "\n"),
+	}
+	if e.Nr != 0 {
+		d.Code = &rdf.Code{Value: fmt.Sprintf("%d", e.Nr)}
+	}
+	return d
+}
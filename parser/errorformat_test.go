@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -76,3 +78,47 @@ func ExampleErrorformatParser() {
 	//   "originalOutput": "/path/to/file2.txt:2:14: [N][RULE:7] message 2"
 	// }
 }
+
+func TestErrorformatParser_fallback(t *testing.T) {
+	f, err := os.Open("testdata/errorformat_fallback.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	p, err := NewErrorformatParserString([]string{
+		`%f:%l:%c: %t%*[a-z]: %m`,
+		ErrorformatSetSeparator,
+		`%EError in %f line %l:`,
+		`%Z  %m`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := p.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range ds {
+		got = append(got, fmt.Sprintf("%s:%d: %s", d.GetLocation().GetPath(), d.GetLocation().GetRange().GetStart().GetLine(), d.GetMessage()))
+	}
+	want := []string{
+		"src/a.c:10: unused variable 'x'",
+		"src/c.c:1: unknown type name 'foo'",
+		"src/b.py:3: invalid syntax",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("diff:\n%s", diff)
+	}
+	if p.Unmatched() != 1 {
+		t.Errorf("got %d unmatched lines, want 1", p.Unmatched())
+	}
+}
+
+func TestSplitErrorformatSets(t *testing.T) {
+	got := splitErrorformatSets([]string{"a", "--", "b", "c", "--", "--"})
+	want := [][]string{{"a"}, {"b", "c"}}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("diff:\n%s", diff)
+	}
+}
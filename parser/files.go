@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ Parser = &FilesParser{}
+var _ UnmatchedCounter = &FilesParser{}
+
+// FilesParser is a composite parser which parses input files in sequence with
+// the same parser instead of the given input, so that outputs of tools which
+// land in separate files (e.g. in fan-out CI jobs) can be reported at once.
+//
+// Each file is parsed separately, so formats with one document per input such
+// as rdjson and checkstyle work as well. Diagnostics are returned in file
+// order.
+type FilesParser struct {
+	p     Parser
+	paths []string
+
+	// unmatched is the total number of unmatched lines of the files in the
+	// last Parse.
+	unmatched int
+}
+
+// NewFilesParser returns a new FilesParser which parses the files with p.
+func NewFilesParser(p Parser, paths ...string) *FilesParser {
+	return &FilesParser{p: p, paths: paths}
+}
+
+// Parse parses the files and returns the merged diagnostics. The input r is
+// ignored.
+func (p *FilesParser) Parse(_ io.Reader) ([]*rdf.Diagnostic, error) {
+	var ds []*rdf.Diagnostic
+	p.unmatched = 0
+	for _, path := range p.paths {
+		d, err := p.parseFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if c, ok := p.p.(UnmatchedCounter); ok {
+			p.unmatched += c.Unmatched()
+		}
+		ds = append(ds, d...)
+	}
+	return ds, nil
+}
+
+// Unmatched returns the total number of lines of the files which the parser
+// didn't match in the last Parse. It's always 0 if the parser doesn't
+// implement UnmatchedCounter.
+func (p *FilesParser) Unmatched() int {
+	return p.unmatched
+}
+
+func (p *FilesParser) parseFile(path string) ([]*rdf.Diagnostic, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer f.Close()
+	d, err := p.p.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse input file %s: %w", path, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFilesParser(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.json")
+	if err := os.WriteFile(a, []byte(`{"diagnostics": [{"message": "a1", "location": {"path": "a.go"}}, {"message": "a2", "location": {"path": "a.go"}}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte(`{"diagnostics": [{"message": "b1", "location": {"path": "b.go"}}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	diagnostics, err := NewFilesParser(NewRDJSONParser(), a, b).Parse(strings.NewReader("ignored"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.GetMessage())
+	}
+	if want := "a1,a2,b1"; strings.Join(got, ",") != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	missing := filepath.Join(dir, "missing.json")
+	if _, err := NewFilesParser(NewRDJSONParser(), a, missing).Parse(nil); err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("got %v, want an error about %s", err, missing)
+	}
+}
+
+func TestFilesParser_unmatched(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	if err := os.WriteFile(a, []byte("a.go:1:1: a1\nnoise\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("noise\nb.go:1:1: b1\nnoise\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	efmp, err := NewErrorformatParserString([]string{"%f:%l:%c: %m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p Parser = NewFilesParser(efmp, a, b)
+	if _, err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	c, ok := p.(UnmatchedCounter)
+	if !ok {
+		t.Fatal("FilesParser doesn't implement UnmatchedCounter")
+	}
+	if got, want := c.Unmatched(), 3; got != want {
+		t.Errorf("Unmatched() = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ Parser = &GolangCILintParser{}
+
+// GolangCILintParser is a parser for golangci-lint JSON output
+// (`golangci-lint run --out-format json`). Unlike errorformat, it keeps
+// linter names, severities and replacements as codes and suggestions.
+type GolangCILintParser struct {
+	severityMap SeverityMap
+}
+
+// NewGolangCILintParser returns a new GolangCILintParser.
+func NewGolangCILintParser() Parser {
+	return &GolangCILintParser{}
+}
+
+func (p *GolangCILintParser) setSeverityMap(m SeverityMap) {
+	p.severityMap = m
+}
+
+func (p *GolangCILintParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
+	var result GolangCILintResult
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return nil, err
+	}
+	ds := make([]*rdf.Diagnostic, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		ds = append(ds, issue.diagnostic(p.severityMap))
+	}
+	return ds, nil
+}
+
+// GolangCILintResult represents golangci-lint JSON output.
+//
+// Reference: https://golangci-lint.run/usage/configuration/#output-configuration
+type GolangCILintResult struct {
+	Issues []*GolangCILintIssue `json:"Issues"`
+}
+
+// GolangCILintIssue represents an issue of golangci-lint JSON output.
+type GolangCILintIssue struct {
+	FromLinter  string                   `json:"FromLinter"`
+	Text        string                   `json:"Text"`
+	Severity    string                   `json:"Severity"`
+	Pos         GolangCILintPosition     `json:"Pos"`
+	LineRange   *GolangCILintLineRange   `json:"LineRange,omitempty"`
+	Replacement *GolangCILintReplacement `json:"Replacement,omitempty"`
+}
+
+// GolangCILintPosition represents a position of golangci-lint issue.
+type GolangCILintPosition struct {
+	Filename string `json:"Filename"`
+	Line     int    `json:"Line"`
+	Column   int    `json:"Column"`
+}
+
+// GolangCILintLineRange represents a line range of golangci-lint issue.
+type GolangCILintLineRange struct {
+	From int `json:"From"`
+	To   int `json:"To"`
+}
+
+// GolangCILintReplacement represents a fix of golangci-lint issue. Either
+// NeedOnlyDelete, NewLines or Inline is set.
+type GolangCILintReplacement struct {
+	NeedOnlyDelete bool                           `json:"NeedOnlyDelete"`
+	NewLines       []string                       `json:"NewLines"`
+	Inline         *GolangCILintInlineReplacement `json:"Inline"`
+}
+
+// GolangCILintInlineReplacement replaces Length bytes from StartCol (0-based)
+// of the issue line with NewString.
+type GolangCILintInlineReplacement struct {
+	StartCol  int    `json:"StartCol"`
+	Length    int    `json:"Length"`
+	NewString string `json:"NewString"`
+}
+
+func (issue *GolangCILintIssue) diagnostic(m SeverityMap) *rdf.Diagnostic {
+	start, end := issue.lineRange()
+	rng := &rdf.Range{
+		Start: &rdf.Position{
+			Line:   int32(issue.Pos.Line),
+			Column: int32(issue.Pos.Column),
+		},
+	}
+	if end > start {
+		rng.End = &rdf.Position{Line: int32(end)}
+	}
+	d := &rdf.Diagnostic{
+		Location: &rdf.Location{
+			Path:  issue.Pos.Filename,
+			Range: rng,
+		},
+		Message:  issue.Text,
+		Severity: m.Severity(issue.Severity),
+		Source:   &rdf.Source{Name: "golangci-lint", Url: "https://golangci-lint.run/"},
+		OriginalOutput: fmt.Sprintf("%s:%d:%d: %s (%s)",
+			issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column, issue.Text, issue.FromLinter),
+	}
+	if issue.FromLinter != "" {
+		d.Code = &rdf.Code{Value: issue.FromLinter}
+	}
+	if s := issue.suggestion(); s != nil {
+		d.Suggestions = []*rdf.Suggestion{s}
+	}
+	return d
+}
+
+// lineRange returns the line range of the issue. It's the issue line if
+// LineRange is not available.
+func (issue *GolangCILintIssue) lineRange() (start, end int) {
+	if lr := issue.LineRange; lr != nil && lr.From > 0 {
+		if lr.To < lr.From {
+			return lr.From, lr.From
+		}
+		return lr.From, lr.To
+	}
+	return issue.Pos.Line, issue.Pos.Line
+}
+
+func (issue *GolangCILintIssue) suggestion() *rdf.Suggestion {
+	r := issue.Replacement
+	if r == nil {
+		return nil
+	}
+	if r.Inline != nil {
+		startCol := int32(r.Inline.StartCol + 1)
+		return &rdf.Suggestion{
+			Range: &rdf.Range{
+				Start: &rdf.Position{Line: int32(issue.Pos.Line), Column: startCol},
+				End:   &rdf.Position{Line: int32(issue.Pos.Line), Column: startCol + int32(r.Inline.Length)},
+			},
+			Text: r.Inline.NewString,
+		}
+	}
+	if !r.NeedOnlyDelete && r.NewLines == nil {
+		return nil
+	}
+	start, end := issue.lineRange()
+	// Line-wise range. It includes the end line.
+	return &rdf.Suggestion{
+		Range: &rdf.Range{
+			Start: &rdf.Position{Line: int32(start)},
+			End:   &rdf.Position{Line: int32(end)},
+		},
+		Text: strings.Join(r.NewLines, "\n"),
+	}
+}
@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestGolangCILintParser(t *testing.T) {
+	f, err := os.Open("testdata/golangci-lint.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := NewGolangCILintParser().Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &rdf.Source{Name: "golangci-lint", Url: "https://golangci-lint.run/"}
+	want := []*rdf.Diagnostic{
+		{
+			Message: "Error return value of `f.Close` is not checked",
+			Location: &rdf.Location{
+				Path:  "main.go",
+				Range: &rdf.Range{Start: &rdf.Position{Line: 10, Column: 9}},
+			},
+			Severity:       rdf.Severity_ERROR,
+			Source:         source,
+			Code:           &rdf.Code{Value: "errcheck"},
+			OriginalOutput: "main.go:10:9: Error return value of `f.Close` is not checked (errcheck)",
+		},
+		{
+			Message: "File is not `gofmt`-ed with `-s`",
+			Location: &rdf.Location{
+				Path: "main.go",
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 14},
+					End:   &rdf.Position{Line: 15},
+				},
+			},
+			Source: source,
+			Code:   &rdf.Code{Value: "gofmt"},
+			Suggestions: []*rdf.Suggestion{
+				{
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 14},
+						End:   &rdf.Position{Line: 15},
+					},
+					Text: "\tx := []int{1, 2}\n\ty := 1",
+				},
+			},
+			OriginalOutput: "main.go:14:0: File is not `gofmt`-ed with `-s` (gofmt)",
+		},
+		{
+			Message: "`recieve` is a misspelling of `receive`",
+			Location: &rdf.Location{
+				Path:  "sub/util.go",
+				Range: &rdf.Range{Start: &rdf.Position{Line: 3, Column: 4}},
+			},
+			Severity: rdf.Severity_WARNING,
+			Source:   source,
+			Code:     &rdf.Code{Value: "misspell"},
+			Suggestions: []*rdf.Suggestion{
+				{
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 3, Column: 4},
+						End:   &rdf.Position{Line: 3, Column: 11},
+					},
+					Text: "receive",
+				},
+			},
+			OriginalOutput: "sub/util.go:3:4: `recieve` is a misspelling of `receive` (misspell)",
+		},
+		{
+			Message: "unnecessary trailing newline",
+			Location: &rdf.Location{
+				Path:  "sub/util.go",
+				Range: &rdf.Range{Start: &rdf.Position{Line: 8, Column: 1}},
+			},
+			Source: source,
+			Code:   &rdf.Code{Value: "whitespace"},
+			Suggestions: []*rdf.Suggestion{
+				{
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 8},
+						End:   &rdf.Position{Line: 8},
+					},
+				},
+			},
+			OriginalOutput: "sub/util.go:8:1: unnecessary trailing newline (whitespace)",
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("result has diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGolangCILintParser_invalid(t *testing.T) {
+	if _, err := NewGolangCILintParser().Parse(strings.NewReader("main.go:1:1: not json")); err == nil {
+		t.Error("got no error for invalid JSON")
+	}
+}
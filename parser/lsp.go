@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ Parser = &LSPParser{}
+
+// LSPParser is a parser for diagnostics of Language Server Protocol
+// (textDocument/publishDiagnostics). Input is a sequence of JSON values each of
+// which is PublishDiagnosticsParams, an array of them, or a publishDiagnostics
+// notification message which has them as params.
+//
+// LSP positions are 0-based and converted to 1-based lines and columns. Note
+// that LSP characters are UTF-16 code units by default while reviewdog columns
+// are bytes, so columns after non-ASCII characters may be off.
+//
+// Reference: https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_publishDiagnostics
+type LSPParser struct{}
+
+// NewLSPParser returns a new LSPParser.
+func NewLSPParser() Parser {
+	return &LSPParser{}
+}
+
+func (p *LSPParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
+	var ds []*rdf.Diagnostic
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return ds, nil
+			}
+			return nil, err
+		}
+		params, err := lspPublishDiagnosticsParams(raw)
+		if err != nil {
+			return nil, err
+		}
+		for _, param := range params {
+			path := lspURIToPath(param.URI)
+			for _, d := range param.Diagnostics {
+				ds = append(ds, d.diagnostic(path))
+			}
+		}
+	}
+}
+
+// lspPublishDiagnosticsParams decodes a JSON value of the input.
+func lspPublishDiagnosticsParams(raw json.RawMessage) ([]*LSPPublishDiagnosticsParams, error) {
+	if len(raw) > 0 && raw[0] == '[' {
+		var params []*LSPPublishDiagnosticsParams
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, err
+		}
+		return params, nil
+	}
+	var msg struct {
+		LSPPublishDiagnosticsParams
+		Params *LSPPublishDiagnosticsParams `json:"params"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Params != nil {
+		return []*LSPPublishDiagnosticsParams{msg.Params}, nil
+	}
+	if msg.URI == "" {
+		return nil, fmt.Errorf("LSP diagnostics must have uri: %s", raw)
+	}
+	return []*LSPPublishDiagnosticsParams{&msg.LSPPublishDiagnosticsParams}, nil
+}
+
+// LSPPublishDiagnosticsParams represents diagnostics of a file.
+type LSPPublishDiagnosticsParams struct {
+	URI         string           `json:"uri"`
+	Diagnostics []*LSPDiagnostic `json:"diagnostics"`
+}
+
+// LSPDiagnostic represents a diagnostic of LSP.
+type LSPDiagnostic struct {
+	Range           LSPRange            `json:"range"`
+	Severity        int                 `json:"severity,omitempty"`
+	Code            json.RawMessage     `json:"code,omitempty"` // integer or string
+	CodeDescription *LSPCodeDescription `json:"codeDescription,omitempty"`
+	Source          string              `json:"source,omitempty"`
+	Message         string              `json:"message"`
+}
+
+// LSPRange represents a range of LSP. The end position is exclusive.
+type LSPRange struct {
+	Start LSPPosition `json:"start"`
+	End   LSPPosition `json:"end"`
+}
+
+// LSPPosition represents a 0-based position of LSP.
+type LSPPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// LSPCodeDescription represents a description of a diagnostic code.
+type LSPCodeDescription struct {
+	Href string `json:"href"`
+}
+
+func (d *LSPDiagnostic) diagnostic(path string) *rdf.Diagnostic {
+	start, end := d.Range.Start, d.Range.End
+	rd := &rdf.Diagnostic{
+		Location: &rdf.Location{
+			Path: path,
+			Range: &rdf.Range{
+				Start: &rdf.Position{Line: int32(start.Line + 1), Column: int32(start.Character + 1)},
+				End:   &rdf.Position{Line: int32(end.Line + 1), Column: int32(end.Character + 1)},
+			},
+		},
+		Message:  d.Message,
+		Severity: lspSeverity(d.Severity),
+		OriginalOutput: fmt.Sprintf("%s:%d:%d: %s",
+			path, start.Line+1, start.Character+1, d.Message),
+	}
+	if d.Source != "" {
+		rd.Source = &rdf.Source{Name: d.Source}
+	}
+	if code := lspCode(d.Code); code != "" {
+		rd.Code = &rdf.Code{Value: code}
+		if d.CodeDescription != nil {
+			rd.Code.Url = d.CodeDescription.Href
+		}
+	}
+	return rd
+}
+
+// lspSeverity converts DiagnosticSeverity of LSP. Hint is treated as info.
+func lspSeverity(s int) rdf.Severity {
+	switch s {
+	case 1:
+		return rdf.Severity_ERROR
+	case 2:
+		return rdf.Severity_WARNING
+	case 3, 4:
+		return rdf.Severity_INFO
+	}
+	return rdf.Severity_UNKNOWN_SEVERITY
+}
+
+// lspCode returns the code, which is an integer or a string, as a string.
+func lspCode(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
+
+var windowsDrivePath = regexp.MustCompile(`^/[A-Za-z]:/`)
+
+// lspURIToPath returns the local path of file URIs. Other URIs are returned as
+// they are.
+func lspURIToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	if windowsDrivePath.MatchString(u.Path) {
+		// file:///C:/path -> C:/path
+		return u.Path[1:]
+	}
+	return u.Path
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestLSPParser(t *testing.T) {
+	f, err := os.Open("testdata/lsp.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := NewLSPParser().Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*rdf.Diagnostic{
+		{
+			Message: "this value of err is never used",
+			Location: &rdf.Location{
+				Path: "/home/user/repo/main.go",
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 10, Column: 9},
+					End:   &rdf.Position{Line: 10, Column: 16},
+				},
+			},
+			Severity:       rdf.Severity_ERROR,
+			Source:         &rdf.Source{Name: "staticcheck"},
+			Code:           &rdf.Code{Value: "SA4006", Url: "https://staticcheck.dev/docs/checks#SA4006"},
+			OriginalOutput: "/home/user/repo/main.go:10:9: this value of err is never used",
+		},
+		{
+			Message: "hint",
+			Location: &rdf.Location{
+				Path: "/home/user/repo/main.go",
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 1, Column: 1},
+					End:   &rdf.Position{Line: 2, Column: 1},
+				},
+			},
+			Severity:       rdf.Severity_INFO,
+			OriginalOutput: "/home/user/repo/main.go:1:1: hint",
+		},
+		{
+			Message: "'x' is declared but its value is never read.",
+			Location: &rdf.Location{
+				Path: "C:/repo/a.ts",
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 3, Column: 5},
+					End:   &rdf.Position{Line: 3, Column: 6},
+				},
+			},
+			Severity:       rdf.Severity_WARNING,
+			Source:         &rdf.Source{Name: "ts"},
+			Code:           &rdf.Code{Value: "6133"},
+			OriginalOutput: "C:/repo/a.ts:3:5: 'x' is declared but its value is never read.",
+		},
+		{
+			Message: "no severity",
+			Location: &rdf.Location{
+				Path: "src/b.py",
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 5, Column: 1},
+					End:   &rdf.Position{Line: 5, Column: 4},
+				},
+			},
+			OriginalOutput: "src/b.py:5:1: no severity",
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestLSPParser_invalid(t *testing.T) {
+	for _, in := range []string{`{"diagnostics": []}`, `{"uri": 1}`, `[`} {
+		if _, err := NewLSPParser().Parse(strings.NewReader(in)); err == nil {
+			t.Errorf("Parse(%q) should fail", in)
+		}
+	}
+}
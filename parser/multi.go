@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ Parser = &MultiParser{}
+
+// MultiDelimiterPrefix is the prefix of delimiter lines which route the
+// following input lines to the parser of the given format name.
+//
+//	##reviewdog -f=<format name>
+const MultiDelimiterPrefix = "##reviewdog -f="
+
+// MultiParser is a composite parser which routes segments of input to
+// one of multiple parsers.
+//
+// Input is split into segments by delimiter lines (see MultiDelimiterPrefix)
+// and each segment is parsed by the parser of the format name in the
+// preceding delimiter. Diagnostics are returned in input order, i.e. ordered
+// by segment and then by the order each parser returns.
+type MultiParser struct {
+	parsers map[string]Parser
+}
+
+// NewMultiParser returns a new MultiParser. parsers is a map from format name
+// in delimiter lines to its parser.
+func NewMultiParser(parsers map[string]Parser) *MultiParser {
+	return &MultiParser{parsers: parsers}
+}
+
+// Parse parses input segments with corresponding parsers and returns the
+// merged diagnostics. Non-empty input before the first delimiter line is an
+// error.
+func (p *MultiParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
+	var ds []*rdf.Diagnostic
+	var name string
+	var segment bytes.Buffer
+	flush := func() error {
+		defer segment.Reset()
+		if name == "" {
+			if len(bytes.TrimSpace(segment.Bytes())) > 0 {
+				return errors.New("input found before the first delimiter line")
+			}
+			return nil
+		}
+		d, err := p.parsers[name].Parse(&segment)
+		if err != nil {
+			return fmt.Errorf("failed to parse %q segment: %w", name, err)
+		}
+		ds = append(ds, d...)
+		return nil
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if strings.HasPrefix(line, MultiDelimiterPrefix) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			name = strings.TrimSpace(strings.TrimPrefix(line, MultiDelimiterPrefix))
+			if _, ok := p.parsers[name]; !ok {
+				return nil, fmt.Errorf("unknown format name in delimiter line: %q", name)
+			}
+		} else {
+			segment.WriteString(line)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMultiParser(t *testing.T) {
+	golint, err := New(&Option{FormatName: "golint"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewMultiParser(map[string]Parser{
+		"golint":  golint,
+		"rdjsonl": NewRDJSONLParser(),
+	})
+	const sample = `
+##reviewdog -f=golint
+a.go:1:1: golint 1
+##reviewdog -f=rdjsonl
+{"message":"rdjsonl","location":{"path":"b.go","range":{"start":{"line":2}}}}
+##reviewdog -f=golint
+c.go:3:1: golint 2`
+	diagnostics, err := p.Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diagnostics {
+		got = append(got, d.GetLocation().GetPath()+":"+d.GetMessage())
+	}
+	if want := "a.go:golint 1,b.go:rdjsonl,c.go:golint 2"; strings.Join(got, ",") != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestMultiParser_error(t *testing.T) {
+	p := NewMultiParser(map[string]Parser{"rdjsonl": NewRDJSONLParser()})
+	for _, in := range []string{
+		"a.go:1:1: no delimiter\n",
+		"##reviewdog -f=golint\na.go:1:1: unknown format\n",
+	} {
+		if _, err := p.Parse(strings.NewReader(in)); err == nil {
+			t.Errorf("Parse(%q) got no error", in)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/reviewdog/errorformat/fmts"
 
@@ -16,22 +17,59 @@ type Parser interface {
 	Parse(r io.Reader) ([]*rdf.Diagnostic, error)
 }
 
+// UnmatchedCounter is implemented by parsers which count lines of input that
+// they couldn't parse.
+type UnmatchedCounter interface {
+	// Unmatched returns the number of lines which didn't match in the last
+	// Parse.
+	Unmatched() int
+}
+
 // Option represents option to create Parser. Either FormatName or
 // Errorformat should be specified.
 type Option struct {
 	FormatName  string
 	Errorformat []string
 	DiffStrip   int
+
+	// SeverityMap maps tool-specific severity levels to rdf.Severity in
+	// addition to the built-in mapping.
+	SeverityMap SeverityMap
 }
 
 // New returns Parser based on Option.
+//
+// FormatName can be comma separated format names to create MultiParser.
 func New(opt *Option) (Parser, error) {
+	p, err := newParser(opt)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := p.(severityMapSetter); ok {
+		s.setSeverityMap(opt.SeverityMap)
+	}
+	return p, nil
+}
+
+func newParser(opt *Option) (Parser, error) {
 	name := opt.FormatName
 
 	if name != "" && len(opt.Errorformat) > 0 {
 		return nil, errors.New("you cannot specify both format name and errorformat at the same time")
 	}
 
+	if strings.Contains(name, ",") {
+		parsers := make(map[string]Parser)
+		for _, n := range strings.Split(name, ",") {
+			p, err := New(&Option{FormatName: n, DiffStrip: opt.DiffStrip, SeverityMap: opt.SeverityMap})
+			if err != nil {
+				return nil, err
+			}
+			parsers[n] = p
+		}
+		return NewMultiParser(parsers), nil
+	}
+
 	switch name {
 	case "checkstyle":
 		return NewCheckStyleParser(), nil
@@ -41,6 +79,12 @@ func New(opt *Option) (Parser, error) {
 		return NewRDJSONParser(), nil
 	case "diff":
 		return NewDiffParser(opt.DiffStrip), nil
+	case "golangci-lint-json":
+		return NewGolangCILintParser(), nil
+	case "lsp":
+		return NewLSPParser(), nil
+	case "clang-tidy-fixes":
+		return NewClangTidyFixesParser(), nil
 	}
 
 	// use defined errorformat
@@ -56,17 +100,3 @@ func New(opt *Option) (Parser, error) {
 	}
 	return NewErrorformatParserString(opt.Errorformat)
 }
-
-func severity(s string) rdf.Severity {
-	switch s {
-	case "error", "ERROR", "Error", "e", "E":
-		return rdf.Severity_ERROR
-	case "warning", "WARNING", "Warning", "w", "W":
-		return rdf.Severity_WARNING
-	case "info", "INFO", "Info", "i", "I",
-		"note", "NOTE", "Note", "n", "N": // Treat note as info.
-		return rdf.Severity_INFO
-	default:
-		return rdf.Severity_UNKNOWN_SEVERITY
-	}
-}
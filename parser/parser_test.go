@@ -23,6 +23,24 @@ func TestNewParser(t *testing.T) {
 			},
 			typ: &RDJSONLParser{},
 		},
+		{
+			in: &Option{
+				FormatName: "golangci-lint-json",
+			},
+			typ: &GolangCILintParser{},
+		},
+		{
+			in: &Option{
+				FormatName: "lsp",
+			},
+			typ: &LSPParser{},
+		},
+		{
+			in: &Option{
+				FormatName: "clang-tidy-fixes",
+			},
+			typ: &ClangTidyFixesParser{},
+		},
 		{
 			in: &Option{
 				FormatName: "golint",
@@ -35,6 +53,12 @@ func TestNewParser(t *testing.T) {
 			},
 			typ: &ErrorformatParser{},
 		},
+		{
+			in: &Option{
+				FormatName: "golint,checkstyle",
+			},
+			typ: &MultiParser{},
+		},
 		{ // empty
 			in:      &Option{},
 			wantErr: true,
@@ -52,6 +76,12 @@ func TestNewParser(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{ // unsupported in multiple format names
+			in: &Option{
+				FormatName: "golint,unsupported format",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		p, err := New(tt.in)
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"io"
 
@@ -12,13 +13,19 @@ import (
 var _ Parser = &RDJSONParser{}
 
 // RDJSONParser is parser for rdjsonl format.
-type RDJSONParser struct{}
+type RDJSONParser struct {
+	severityMap SeverityMap
+}
 
 // NewRDJSONParser returns a new RDJSONParser.
 func NewRDJSONParser() *RDJSONParser {
 	return &RDJSONParser{}
 }
 
+func (p *RDJSONParser) setSeverityMap(m SeverityMap) {
+	p.severityMap = m
+}
+
 // Parse parses rdjson (JSON of DiagnosticResult).
 func (p *RDJSONParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 	b, err := io.ReadAll(r)
@@ -26,9 +33,14 @@ func (p *RDJSONParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 		return nil, err
 	}
 	var dr rdf.DiagnosticResult
-	if err := protojson.Unmarshal(b, &dr); err != nil {
+	if err := protojson.Unmarshal(mapRDJSONSeverities(b, p.severityMap), &dr); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal rdjson (DiagnosticResult): %w", err)
 	}
+	for i, d := range dr.Diagnostics {
+		if err := validateDiagnostic(d); err != nil {
+			return nil, fmt.Errorf("invalid rdjson diagnostic at index %d: %w", i, err)
+		}
+	}
 	for _, d := range dr.Diagnostics {
 		// Fill in default severity and source for each diagnostic.
 		if d.Severity == rdf.Severity_UNKNOWN_SEVERITY {
@@ -44,3 +56,14 @@ func (p *RDJSONParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 	}
 	return dr.Diagnostics, nil
 }
+
+// validateDiagnostic checks required fields of the diagnostic.
+func validateDiagnostic(d *rdf.Diagnostic) error {
+	if d.GetMessage() == "" {
+		return errors.New("message is empty")
+	}
+	if d.GetLocation().GetPath() == "" {
+		return errors.New("location.path is empty")
+	}
+	return nil
+}
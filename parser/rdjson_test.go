@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"testing"
 
 	"google.golang.org/protobuf/encoding/protojson"
 )
@@ -158,3 +159,29 @@ func ExampleRDJSONParser() {
 	//   }
 	// }
 }
+
+func TestRDJSONParser_invalid(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   `{"diagnostics": [{"message": "ok", "location": {"path": "a.go"}}, {"location": {"path": "a.go"}}]}`,
+			want: "invalid rdjson diagnostic at index 1: message is empty",
+		},
+		{
+			in:   `{"diagnostics": [{"message": "no location"}]}`,
+			want: "invalid rdjson diagnostic at index 0: location.path is empty",
+		},
+	}
+	for _, tt := range tests {
+		_, err := NewRDJSONParser().Parse(strings.NewReader(tt.in))
+		if err == nil {
+			t.Errorf("Parse(%s) got no error", tt.in)
+			continue
+		}
+		if got := err.Error(); got != tt.want {
+			t.Errorf("Parse(%s) error = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
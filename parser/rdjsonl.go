@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 
@@ -13,27 +14,113 @@ import (
 var _ Parser = &RDJSONLParser{}
 
 // RDJSONLParser is parser for rdjsonl format.
-type RDJSONLParser struct{}
+type RDJSONLParser struct {
+	severityMap SeverityMap
+}
 
 // NewRDJSONLParser returns a new RDJSONParser.
 func NewRDJSONLParser() *RDJSONLParser {
 	return &RDJSONLParser{}
 }
 
+func (p *RDJSONLParser) setSeverityMap(m SeverityMap) {
+	p.severityMap = m
+}
+
 // Parse parses rdjson (JSONL of Diagnostic).
 func (p *RDJSONLParser) Parse(r io.Reader) ([]*rdf.Diagnostic, error) {
 	var results []*rdf.Diagnostic
-	s := bufio.NewScanner(r)
+	s := newRDJSONLScanner(r)
 	for s.Scan() {
-		d := new(rdf.Diagnostic)
-		if err := protojson.Unmarshal(s.Bytes(), d); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal rdjsonl (Diagnostic): %w", err)
-		}
-		if d.GetOriginalOutput() == "" {
-			// TODO(haya14busa): Refactor not to fill in original output.
-			d.OriginalOutput = s.Text()
+		d, err := parseRDJSONLine(s.Bytes(), p.severityMap)
+		if err != nil {
+			return nil, err
 		}
 		results = append(results, d)
 	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rdjsonl: %w", err)
+	}
 	return results, nil
 }
+
+// RDJSONLStream is a stream of Diagnostics returned by
+// RDJSONLParser.ParseStream.
+type RDJSONLStream struct {
+	// Diagnostics receives parsed Diagnostics in input order. It's closed when
+	// the parser reaches the end of input or fails to read it.
+	Diagnostics <-chan *rdf.Diagnostic
+
+	skipped int
+	err     error
+}
+
+// Skipped returns the number of malformed lines which are skipped. It must be
+// called after Diagnostics is closed.
+func (s *RDJSONLStream) Skipped() int {
+	return s.skipped
+}
+
+// Err returns the error of reading input or the error of the context if it's
+// done before the end of input, if any. It must be called after Diagnostics is
+// closed.
+func (s *RDJSONLStream) Err() error {
+	return s.err
+}
+
+// ParseStream parses rdjsonl (JSONL of Diagnostic) line by line and sends
+// Diagnostics to the returned stream as soon as each line is parsed, so that
+// library users can start processing before the input ends. Reviewdog.Run
+// still uses Parse as reporting needs all the results. The channel is
+// unbuffered, so the parser doesn't read ahead of the consumer. Malformed
+// lines are skipped and counted instead of failing the whole stream.
+//
+// The stream is closed when ctx is done, so consumers which stop receiving
+// must cancel ctx not to leak the parser goroutine.
+func (p *RDJSONLParser) ParseStream(ctx context.Context, r io.Reader) *RDJSONLStream {
+	ch := make(chan *rdf.Diagnostic)
+	stream := &RDJSONLStream{Diagnostics: ch}
+	go func() {
+		defer close(ch)
+		s := newRDJSONLScanner(r)
+		for s.Scan() {
+			if err := ctx.Err(); err != nil {
+				stream.err = err
+				return
+			}
+			d, err := parseRDJSONLine(s.Bytes(), p.severityMap)
+			if err != nil {
+				stream.skipped++
+				continue
+			}
+			select {
+			case ch <- d:
+			case <-ctx.Done():
+				stream.err = ctx.Err()
+				return
+			}
+		}
+		stream.err = s.Err()
+	}()
+	return stream
+}
+
+// newRDJSONLScanner returns a scanner of rdjsonl lines which accepts lines
+// longer than the default limit, e.g. diagnostics with large suggestions.
+func newRDJSONLScanner(r io.Reader) *bufio.Scanner {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1024*1024)
+	return s
+}
+
+func parseRDJSONLine(line []byte, m SeverityMap) (*rdf.Diagnostic, error) {
+	d := new(rdf.Diagnostic)
+	if err := protojson.Unmarshal(mapRDJSONSeverities(line, m), d); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rdjsonl (Diagnostic): %w", err)
+	}
+	if d.GetOriginalOutput() == "" {
+		// TODO(haya14busa): Refactor not to fill in original output.
+		d.OriginalOutput = string(line)
+	}
+	return d, nil
+}
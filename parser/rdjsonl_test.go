@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -25,3 +28,59 @@ func TestRDJSONLParser(t *testing.T) {
 		}
 	}
 }
+
+func TestRDJSONLParser_ParseStream(t *testing.T) {
+	const sample = `{"message":"first","location":{"path":"a.go","range":{"start":{"line":1}}}}
+malformed line
+{"message":"second","location":{"path":"b.go","range":{"start":{"line":2}}}}
+{"message": 14}`
+	stream := NewRDJSONLParser().ParseStream(context.Background(), strings.NewReader(sample))
+	var got []string
+	for d := range stream.Diagnostics {
+		got = append(got, d.GetMessage())
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"first", "second"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := stream.Skipped(), 2; got != want {
+		t.Errorf("got %d skipped lines, want %d", got, want)
+	}
+}
+
+func TestRDJSONLParser_tooLongLine(t *testing.T) {
+	line := `{"message":"` + strings.Repeat("x", 2*1024*1024) + `"}`
+	if _, err := NewRDJSONLParser().Parse(strings.NewReader(line)); !errors.Is(err, bufio.ErrTooLong) {
+		t.Errorf("Parse() error = %v, want %v", err, bufio.ErrTooLong)
+	}
+	long := `{"message":"` + strings.Repeat("x", 100*1024) + `"}`
+	ds, err := NewRDJSONLParser().Parse(strings.NewReader(long))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 {
+		t.Errorf("got %d diagnostics, want 1", len(ds))
+	}
+}
+
+func TestRDJSONLParser_ParseStream_canceled(t *testing.T) {
+	sample := strings.Repeat(`{"message":"diagnostic"}`+"\n", 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := NewRDJSONLParser().ParseStream(ctx, strings.NewReader(sample))
+	<-stream.Diagnostics
+	// Stop receiving. The parser may be sending the second diagnostic, but
+	// must stop after that.
+	cancel()
+	n := 0
+	for range stream.Diagnostics {
+		n++
+	}
+	if n > 1 {
+		t.Errorf("got %d diagnostics after cancel, want at most 1", n)
+	}
+	if err := stream.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Err() = %v, want %v", err, context.Canceled)
+	}
+}
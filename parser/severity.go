@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// SeverityMap maps tool-specific severity levels (e.g. "fatal", "style") to
+// rdf.Severity. Levels which it doesn't contain are mapped with the built-in
+// mapping.
+type SeverityMap map[string]rdf.Severity
+
+// String implements the flag.Value interface
+func (m *SeverityMap) String() string {
+	ss := make([]string, 0, len(*m))
+	for level, s := range *m {
+		ss = append(ss, level+"="+s.String())
+	}
+	sort.Strings(ss)
+	return strings.Join(ss, ",")
+}
+
+// Set implements the flag.Value interface. It accepts a mapping in
+// "LEVEL=SEVERITY" format where SEVERITY is one of error, warning and info,
+// and can be called multiple times. e.g. "fatal=error" or "style=info".
+func (m *SeverityMap) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("invalid severity mapping %q: want LEVEL=SEVERITY", value)
+	}
+	s := severity(parts[1])
+	if s == rdf.Severity_UNKNOWN_SEVERITY {
+		return fmt.Errorf("invalid severity %q in severity mapping %q: want error, warning or info", parts[1], value)
+	}
+	if *m == nil {
+		*m = make(SeverityMap)
+	}
+	(*m)[parts[0]] = s
+	return nil
+}
+
+// Severity returns rdf.Severity of the tool-specific level. The level is
+// looked up as it is and then case-insensitively before falling back to the
+// built-in mapping.
+func (m SeverityMap) Severity(level string) rdf.Severity {
+	if s, ok := m.lookup(level); ok {
+		return s
+	}
+	return severity(level)
+}
+
+func (m SeverityMap) lookup(level string) (rdf.Severity, bool) {
+	if s, ok := m[level]; ok {
+		return s, true
+	}
+	for l, s := range m {
+		if strings.EqualFold(l, level) {
+			return s, true
+		}
+	}
+	return rdf.Severity_UNKNOWN_SEVERITY, false
+}
+
+// severity is the built-in mapping of tool-specific levels to rdf.Severity.
+func severity(s string) rdf.Severity {
+	switch strings.ToLower(s) {
+	case "error", "e", "fatal", "critical":
+		return rdf.Severity_ERROR
+	case "warning", "warn", "w":
+		return rdf.Severity_WARNING
+	case "info", "i",
+		"note", "n", // Treat note as info.
+		"hint", "style", "suggestion", "convention", "refactor":
+		return rdf.Severity_INFO
+	default:
+		return rdf.Severity_UNKNOWN_SEVERITY
+	}
+}
+
+// severityMapSetter is implemented by parsers which parse tool-specific
+// severity levels.
+type severityMapSetter interface {
+	setSeverityMap(m SeverityMap)
+}
+
+// mapRDJSONSeverities rewrites severity strings of rdjson (DiagnosticResult)
+// or rdjsonl (Diagnostic) which aren't rdf.Severity names with the mapping,
+// so that protojson can unmarshal them. It returns b as it is if b isn't a
+// JSON object so that protojson reports the error.
+func mapRDJSONSeverities(b []byte, m SeverityMap) []byte {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return b
+	}
+	changed := mapSeverityField(obj, m)
+	if raw, ok := obj["diagnostics"]; ok {
+		var ds []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &ds); err == nil {
+			dsChanged := false
+			for _, d := range ds {
+				if mapSeverityField(d, m) {
+					dsChanged = true
+				}
+			}
+			if dsChanged {
+				if nb, err := json.Marshal(ds); err == nil {
+					obj["diagnostics"] = nb
+					changed = true
+				}
+			}
+		}
+	}
+	if !changed {
+		return b
+	}
+	nb, err := json.Marshal(obj)
+	if err != nil {
+		return b
+	}
+	return nb
+}
+
+// mapSeverityField rewrites the "severity" field of obj with the mapping and
+// reports whether it's changed.
+func mapSeverityField(obj map[string]json.RawMessage, m SeverityMap) bool {
+	raw, ok := obj["severity"]
+	if !ok {
+		return false
+	}
+	var level string
+	if err := json.Unmarshal(raw, &level); err != nil {
+		return false // Not a string (e.g. enum number).
+	}
+	if _, ok := m.lookup(level); !ok {
+		if _, ok := rdf.Severity_value[level]; ok {
+			return false
+		}
+	}
+	nb, err := json.Marshal(m.Severity(level).String())
+	if err != nil {
+		return false
+	}
+	obj["severity"] = nb
+	return true
+}
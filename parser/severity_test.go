@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestSeverityMap_Set(t *testing.T) {
+	var m SeverityMap
+	for _, v := range []string{"blocker=error", "minor=Warning", "style=info"} {
+		if err := m.Set(v); err != nil {
+			t.Fatalf("Set(%q) got unexpected error: %v", v, err)
+		}
+	}
+	want := SeverityMap{
+		"blocker": rdf.Severity_ERROR,
+		"minor":   rdf.Severity_WARNING,
+		"style":   rdf.Severity_INFO,
+	}
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Errorf("mapping diff (-want +got):\n%s", diff)
+	}
+	if got, want := m.String(), "blocker=ERROR,minor=WARNING,style=INFO"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	for _, v := range []string{"blocker", "=error", "blocker=unknown"} {
+		if err := m.Set(v); err == nil {
+			t.Errorf("Set(%q) got no error", v)
+		}
+	}
+}
+
+func TestSeverityMap_Severity(t *testing.T) {
+	m := SeverityMap{"Blocker": rdf.Severity_ERROR, "note": rdf.Severity_WARNING}
+	tests := []struct {
+		level string
+		want  rdf.Severity
+	}{
+		{level: "Blocker", want: rdf.Severity_ERROR},
+		{level: "blocker", want: rdf.Severity_ERROR},
+		{level: "NOTE", want: rdf.Severity_WARNING},
+		{level: "fatal", want: rdf.Severity_ERROR},
+		{level: "W", want: rdf.Severity_WARNING},
+		{level: "style", want: rdf.Severity_INFO},
+		{level: "unknown", want: rdf.Severity_UNKNOWN_SEVERITY},
+		{level: "", want: rdf.Severity_UNKNOWN_SEVERITY},
+	}
+	for _, tt := range tests {
+		if got := m.Severity(tt.level); got != tt.want {
+			t.Errorf("Severity(%q) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestNew_severityMap_rdjson(t *testing.T) {
+	f, err := os.Open("testdata/severity_map.rdjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	p, err := New(&Option{
+		FormatName: "rdjson",
+		SeverityMap: SeverityMap{
+			"blocker": rdf.Severity_ERROR,
+			"INFO":    rdf.Severity_WARNING,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := p.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rdf.Severity{
+		rdf.Severity_ERROR,   // fatal (built-in)
+		rdf.Severity_ERROR,   // blocker (custom)
+		rdf.Severity_WARNING, // INFO (custom)
+		rdf.Severity_INFO,    // note (built-in) of the result
+	}
+	if diff := cmp.Diff(want, severities(ds)); diff != "" {
+		t.Errorf("severities diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestNew_severityMap_rdjsonl(t *testing.T) {
+	const in = `{"message": "m1", "location": {"path": "a.go"}, "severity": "blocker"}
+{"message": "m2", "location": {"path": "a.go"}, "severity": "warn"}
+{"message": "m3", "location": {"path": "a.go"}, "severity": "ERROR"}
+`
+	p, err := New(&Option{
+		FormatName:  "rdjsonl",
+		SeverityMap: SeverityMap{"blocker": rdf.Severity_ERROR},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := p.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rdf.Severity{rdf.Severity_ERROR, rdf.Severity_WARNING, rdf.Severity_ERROR}
+	if diff := cmp.Diff(want, severities(ds)); diff != "" {
+		t.Errorf("severities diff (-want +got):\n%s", diff)
+	}
+	if got := ds[0].GetOriginalOutput(); !strings.Contains(got, `"blocker"`) {
+		t.Errorf("original output should keep the tool level: %q", got)
+	}
+}
+
+func TestNew_severityMap_errorformat(t *testing.T) {
+	f, err := os.Open("testdata/severity_map.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	p, err := New(&Option{
+		// %t takes the first character of the level.
+		Errorformat: []string{`%f:%l:%c: %t%*[a-z]: %m`},
+		SeverityMap: SeverityMap{"s": rdf.Severity_INFO, "b": rdf.Severity_ERROR},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := p.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rdf.Severity{
+		rdf.Severity_UNKNOWN_SEVERITY, // f
+		rdf.Severity_INFO,             // s (custom)
+		rdf.Severity_ERROR,            // b (custom)
+		rdf.Severity_UNKNOWN_SEVERITY, // u
+	}
+	if diff := cmp.Diff(want, severities(ds)); diff != "" {
+		t.Errorf("severities diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestNew_severityMap_multi(t *testing.T) {
+	const in = `##reviewdog -f=checkstyle
+<?xml version="1.0" encoding="utf-8"?><checkstyle version="4.3"><file name="a.js"><error line="1" column="1" severity="blocker" message="m1" /></file></checkstyle>
+##reviewdog -f=rdjsonl
+{"message": "m2", "location": {"path": "a.go"}, "severity": "blocker"}
+`
+	p, err := New(&Option{
+		FormatName:  "checkstyle,rdjsonl",
+		SeverityMap: SeverityMap{"blocker": rdf.Severity_ERROR},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := p.Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rdf.Severity{rdf.Severity_ERROR, rdf.Severity_ERROR}
+	if diff := cmp.Diff(want, severities(ds)); diff != "" {
+		t.Errorf("severities diff (-want +got):\n%s", diff)
+	}
+}
+
+func severities(ds []*rdf.Diagnostic) []rdf.Severity {
+	ss := make([]rdf.Severity, 0, len(ds))
+	for _, d := range ds {
+		ss = append(ss, d.GetSeverity())
+	}
+	return ss
+}
@@ -0,0 +1 @@
+int f(int *p);
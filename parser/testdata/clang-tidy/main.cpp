@@ -0,0 +1,5 @@
+#include "a.h"
+int f(int *p) {
+  if (p == NULL) return 0;
+  return *p;
+}
@@ -21,6 +21,8 @@ type Runner struct {
 	Errorformat []string
 	// Report Level for this runner. ("info", "warning", "error")
 	Level string
+	// Labels of results of this runner. (e.g. `team: backend`)
+	Labels map[string]string
 }
 
 // Parse parses reviewdog config in yaml format.
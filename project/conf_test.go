@@ -20,6 +20,8 @@ runner:
     cmd: go tool vet -all -shadowstrict .
     format: govet
     level: warning
+    labels:
+      team: backend
   namekey:
     cmd: echo 'name'
     name: nameoverwritten
@@ -40,6 +42,7 @@ runner:
 				Format: "govet",
 				Name:   "govet",
 				Level:  "warning",
+				Labels: map[string]string{"team": "backend"},
 			},
 			"namekey": {
 				Cmd:    "echo 'name'",
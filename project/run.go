@@ -3,12 +3,14 @@ package project
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"runtime"
 	"strings"
+	"sync/atomic"
 
 	"golang.org/x/sync/errgroup"
 
@@ -91,7 +93,7 @@ func RunAndParse(ctx context.Context, conf *Config, runners map[string]bool, def
 }
 
 // Run runs reviewdog tasks based on Config.
-func Run(ctx context.Context, conf *Config, runners map[string]bool, c reviewdog.CommentService, d reviewdog.DiffService, teeMode bool, filterMode filter.Mode, failOnError bool) error {
+func Run(ctx context.Context, conf *Config, runners map[string]bool, c reviewdog.CommentService, d reviewdog.DiffService, teeMode bool, filterMode filter.Mode, failOnError bool, opts ...reviewdog.Option) error {
 	results, err := RunAndParse(ctx, conf, runners, "", teeMode) // Level is not used.
 	if err != nil {
 		return err
@@ -108,17 +110,43 @@ func Run(ctx context.Context, conf *Config, runners map[string]bool, c reviewdog
 	if err != nil {
 		return err
 	}
+	labels := make(map[string]map[string]string)
+	for key, runner := range conf.Runner {
+		if len(runner.Labels) > 0 {
+			labels[getRunnerName(key, runner)] = runner.Labels
+		}
+	}
 	var g errgroup.Group
+	// incomplete is set if any runners fail before reporting all the results.
+	var incomplete int32
 	results.Range(func(toolname string, result *reviewdog.Result) {
 		ds := result.Diagnostics
+		ropts := opts
+		if l, ok := labels[toolname]; ok {
+			ropts = append(opts[:len(opts):len(opts)], reviewdog.WithLabeler(filter.StaticLabels(l)))
+		}
 		g.Go(func() error {
 			if err := result.CheckUnexpectedFailure(); err != nil {
+				atomic.StoreInt32(&incomplete, 1)
 				return err
 			}
-			return reviewdog.RunFromResult(ctx, c, ds, filediffs, d.Strip(), toolname, filterMode, failOnError)
+			err := reviewdog.RunFromResult(ctx, c, ds, filediffs, d.Strip(), toolname, filterMode, failOnError, ropts...)
+			if err != nil && !errors.Is(err, reviewdog.ErrViolations) {
+				atomic.StoreInt32(&incomplete, 1)
+			}
+			return err
 		})
 	})
-	return g.Wait()
+	err = g.Wait()
+	// Finish the comment service once after all the runners flush it. It's
+	// skipped if results of any runners are missing, which would be regarded
+	// as stale otherwise.
+	if fc, ok := c.(reviewdog.FinishingCommentService); ok && atomic.LoadInt32(&incomplete) == 0 {
+		if ferr := fc.Finish(ctx); ferr != nil {
+			return ferr
+		}
+	}
+	return err
 }
 
 var secretEnvs = [...]string{
@@ -5,7 +5,9 @@ import (
 	"context"
 	"errors"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/reviewdog/reviewdog"
@@ -34,6 +36,30 @@ func (f *fakeCommentService) Post(_ context.Context, c *reviewdog.Comment) error
 	return f.FakePost(c)
 }
 
+type fakeFinishingCommentService struct {
+	mu       sync.Mutex
+	flushed  int
+	finished []int // The number of flushes when Finish is called.
+}
+
+func (f *fakeFinishingCommentService) Post(context.Context, *reviewdog.Comment) error {
+	return nil
+}
+
+func (f *fakeFinishingCommentService) Flush(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushed++
+	return nil
+}
+
+func (f *fakeFinishingCommentService) Finish(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.finished = append(f.finished, f.flushed)
+	return nil
+}
+
 func TestRun(t *testing.T) {
 	ctx := context.Background()
 
@@ -286,6 +312,43 @@ func TestRun(t *testing.T) {
 			t.Error("got no error but want runner not found error")
 		}
 	})
+
+	t.Run("finish after all runners flush", func(t *testing.T) {
+		ds := &fakeDiffService{
+			FakeDiff: func() ([]byte, error) {
+				return []byte(""), nil
+			},
+		}
+		conf := &Config{
+			Runner: map[string]*Runner{
+				"test1": {
+					Cmd:         "echo 'file:14:14:message'",
+					Errorformat: []string{`%f:%l:%c:%m`},
+				},
+				"test2": {
+					Cmd:         "echo 'file:15:15:message'",
+					Errorformat: []string{`%f:%l:%c:%m`},
+				},
+			},
+		}
+		cs := &fakeFinishingCommentService{}
+		// Results which violate the fail policy don't skip Finish.
+		if err := Run(ctx, conf, nil, cs, ds, false, filter.ModeNoFilter, true); !errors.Is(err, reviewdog.ErrViolations) {
+			t.Errorf("got error %v, want %v", err, reviewdog.ErrViolations)
+		}
+		if want := []int{2}; !reflect.DeepEqual(cs.finished, want) {
+			t.Errorf("Finish called after %v flushes, want %v", cs.finished, want)
+		}
+
+		conf.Runner["test2"].Cmd = "not found"
+		cs = &fakeFinishingCommentService{}
+		if err := Run(ctx, conf, nil, cs, ds, false, filter.ModeNoFilter, false); err == nil {
+			t.Error("want error, got nil")
+		}
+		if len(cs.finished) != 0 {
+			t.Errorf("Finish should not be called if a runner fails, but called after %v flushes", cs.finished)
+		}
+	})
 }
 
 func TestFilteredEnviron(t *testing.T) {
@@ -3,12 +3,16 @@ package reviewdog
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"time"
 
 	"github.com/reviewdog/reviewdog/diff"
 	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/metrics"
 	"github.com/reviewdog/reviewdog/parser"
 	"github.com/reviewdog/reviewdog/proto/rdf"
 )
@@ -23,17 +27,247 @@ type Reviewdog struct {
 	d           DiffService
 	filterMode  filter.Mode
 	failOnError bool
+
+	// severityLevel is the lowest severity of results to report.
+	severityLevel filter.SeverityLevel
+
+	// suggestionConflictMode is how to resolve overlapping suggestions.
+	suggestionConflictMode filter.SuggestionConflictMode
+
+	// failLevel is the lowest severity of reported results counted to fail.
+	// failThreshold is the max number of counted results which doesn't fail.
+	failLevel     filter.SeverityLevel
+	failThreshold int
+
+	// diffContextExpansion is the number of lines around changed lines which
+	// are also reported with diff_context filter mode.
+	diffContextExpansion int
+
+	// tabWidth is the tab width to translate columns with. 0 means no
+	// translation.
+	tabWidth int
+
+	// ignoreAnnotations drops results on lines with reviewdog:ignore
+	// annotations.
+	ignoreAnnotations bool
+
+	// keepEmptyMessages reports results with empty messages instead of
+	// dropping them.
+	keepEmptyMessages bool
+
+	// includeTools and excludeTools are glob patterns of tool names of results
+	// to report and to drop respectively.
+	includeTools filter.ToolPatterns
+	excludeTools filter.ToolPatterns
+
+	// includeFiles and excludeFiles are gitignore-style patterns of file paths
+	// of results to report and to drop respectively.
+	includeFiles filter.FilePatterns
+	excludeFiles filter.FilePatterns
+
+	// baseline has known results to drop. baselineRecorder records results to
+	// generate a baseline.
+	baseline         *filter.Baseline
+	baselineRecorder *filter.Baseline
+
+	// labelers set labels of reported results.
+	labelers []filter.Labeler
+
+	// collapseThreshold is the max number of reported results with the same
+	// code and message which are reported separately. 0 disables collapsing.
+	collapseThreshold int
+
+	// maxComments is the max number of reported results posted as individual
+	// comments. 0 means no limit.
+	maxComments int
+
+	// skipEmptyDiff skips reporting when the diff is empty.
+	skipEmptyDiff bool
+
+	// pathRewrites rewrite paths of results before filtering them.
+	pathRewrites filter.PathRewrites
+
+	// summary accumulates reported results if not nil.
+	summary *RunSummary
+
+	// metrics receives durations of stages and result counts if not nil.
+	metrics metrics.Sink
+}
+
+// Option is an option for Reviewdog.
+type Option func(*Reviewdog)
+
+// WithSeverityLevel makes Reviewdog drop results whose severity is lower than
+// the level before reporting them.
+func WithSeverityLevel(level filter.SeverityLevel) Option {
+	return func(w *Reviewdog) {
+		w.severityLevel = level
+	}
+}
+
+// WithSuggestionConflictMode sets how Reviewdog resolves overlapping
+// suggestions proposed by different results.
+func WithSuggestionConflictMode(mode filter.SuggestionConflictMode) Option {
+	return func(w *Reviewdog) {
+		w.suggestionConflictMode = mode
+	}
+}
+
+// WithFailPolicy makes Reviewdog fail only when the number of reported results
+// whose severity is the same or higher than the level exceeds the threshold.
+// Threshold 0 means failing on any such results. It takes effect only if
+// failOnError is true.
+func WithFailPolicy(level filter.SeverityLevel, threshold int) Option {
+	return func(w *Reviewdog) {
+		w.failLevel = level
+		w.failThreshold = threshold
+	}
+}
+
+// WithDiffContextExpansion makes Reviewdog with diff_context filter mode also
+// report results within n lines of changed lines.
+func WithDiffContextExpansion(n int) Option {
+	return func(w *Reviewdog) {
+		w.diffContextExpansion = n
+	}
+}
+
+// WithTabWidth makes Reviewdog translate columns of results, which count a
+// tab as one column, into columns rendered with the tab width before posting
+// them. Width 0 disables the translation.
+func WithTabWidth(width int) Option {
+	return func(w *Reviewdog) {
+		w.tabWidth = width
+	}
+}
+
+// WithIgnoreAnnotations makes Reviewdog drop results whose line has a
+// `reviewdog:ignore` annotation, optionally followed by comma separated codes
+// of results to ignore.
+func WithIgnoreAnnotations(enabled bool) Option {
+	return func(w *Reviewdog) {
+		w.ignoreAnnotations = enabled
+	}
+}
+
+// WithKeepEmptyMessages makes Reviewdog report results whose message is empty
+// or whitespace only. They are dropped by default.
+func WithKeepEmptyMessages(enabled bool) Option {
+	return func(w *Reviewdog) {
+		w.keepEmptyMessages = enabled
+	}
+}
+
+// WithToolFilter makes Reviewdog report only results whose tool name matches
+// include patterns and doesn't match exclude patterns. Empty include patterns
+// match any tools.
+func WithToolFilter(include, exclude filter.ToolPatterns) Option {
+	return func(w *Reviewdog) {
+		w.includeTools = include
+		w.excludeTools = exclude
+	}
+}
+
+// WithFileFilter makes Reviewdog report only results whose path matches
+// include patterns and doesn't match exclude patterns. Empty include patterns
+// match any files.
+func WithFileFilter(include, exclude filter.FilePatterns) Option {
+	return func(w *Reviewdog) {
+		w.includeFiles = include
+		w.excludeFiles = exclude
+	}
+}
+
+// WithPathRewrites makes Reviewdog rewrite paths of results with the rules
+// before filtering and reporting them. It's useful when tools report paths
+// relative to another directory than the current one (e.g. a subproject of a
+// monorepo).
+func WithPathRewrites(rules filter.PathRewrites) Option {
+	return func(w *Reviewdog) {
+		w.pathRewrites = rules
+	}
+}
+
+// WithBaseline makes Reviewdog drop results in the baseline and report only
+// new results.
+func WithBaseline(b *filter.Baseline) Option {
+	return func(w *Reviewdog) {
+		w.baseline = b
+	}
+}
+
+// WithBaselineRecorder makes Reviewdog add results to b before filtering them
+// by diff, so that b can be written as a baseline file of the current
+// results.
+func WithBaselineRecorder(b *filter.Baseline) Option {
+	return func(w *Reviewdog) {
+		w.baselineRecorder = b
+	}
+}
+
+// WithLabeler makes Reviewdog set labels returned by l to results, so that
+// comment services and templates can refer to them. It can be given multiple
+// times and later labelers override labels of earlier ones.
+func WithLabeler(l filter.Labeler) Option {
+	return func(w *Reviewdog) {
+		w.labelers = append(w.labelers, l)
+	}
+}
+
+// WithDuplicateCollapse makes Reviewdog report results with the same tool,
+// code and message as one comment listing all the locations if there are more
+// than threshold of them. threshold <= 0 disables it.
+func WithDuplicateCollapse(threshold int) Option {
+	return func(w *Reviewdog) {
+		w.collapseThreshold = threshold
+	}
+}
+
+// WithMaxComments makes Reviewdog post at most max results as individual
+// comments, preferring results with higher severity, and summarize the others
+// in one comment. max <= 0 means no limit.
+func WithMaxComments(max int) Option {
+	return func(w *Reviewdog) {
+		w.maxComments = max
+	}
+}
+
+// WithSkipEmptyDiff makes Reviewdog skip reporting, including Flush of bulk
+// comment services, when the diff is empty, since there is nothing to review
+// and reporters may update stale comments or statuses otherwise. It doesn't
+// skip reporting with filter.ModeNoFilter, which doesn't use diff.
+func WithSkipEmptyDiff(enabled bool) Option {
+	return func(w *Reviewdog) {
+		w.skipEmptyDiff = enabled
+	}
+}
+
+// WithMetrics makes Reviewdog send durations of the parse, diff, filter and
+// report stages and the number of parsed and reported results to the sink
+// with the tool name as the tool tag.
+func WithMetrics(sink metrics.Sink) Option {
+	return func(w *Reviewdog) {
+		w.metrics = sink
+	}
 }
 
 // NewReviewdog returns a new Reviewdog.
-func NewReviewdog(toolname string, p parser.Parser, c CommentService, d DiffService, filterMode filter.Mode, failOnError bool) *Reviewdog {
-	return &Reviewdog{p: p, c: c, d: d, toolname: toolname, filterMode: filterMode, failOnError: failOnError}
+func NewReviewdog(toolname string, p parser.Parser, c CommentService, d DiffService, filterMode filter.Mode, failOnError bool, opts ...Option) *Reviewdog {
+	w := &Reviewdog{p: p, c: c, d: d, toolname: toolname, filterMode: filterMode, failOnError: failOnError}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
 }
 
 // RunFromResult creates a new Reviewdog and runs it with check results.
 func RunFromResult(ctx context.Context, c CommentService, results []*rdf.Diagnostic,
-	filediffs []*diff.FileDiff, strip int, toolname string, filterMode filter.Mode, failOnError bool) error {
-	return (&Reviewdog{c: c, toolname: toolname, filterMode: filterMode, failOnError: failOnError}).runFromResult(ctx, results, filediffs, strip, failOnError)
+	filediffs []*diff.FileDiff, strip int, toolname string, filterMode filter.Mode, failOnError bool, opts ...Option) error {
+	w := &Reviewdog{c: c, toolname: toolname, filterMode: filterMode, failOnError: failOnError}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w.runFromResult(ctx, results, filediffs, strip)
 }
 
 // Comment represents a reported result as a comment.
@@ -54,6 +288,27 @@ type BulkCommentService interface {
 	Flush(context.Context) error
 }
 
+// GroupingCommentService is a CommentService which renders grouped results
+// in one comment, i.e. locations of collapsed duplicates
+// (filter.FilteredDiagnostic.Duplicates) and the summary of results omitted
+// by the max number of comments (filter.FilteredDiagnostic.Omitted). Other
+// services receive each of the grouped results as a separate comment instead,
+// so that no results are lost.
+type GroupingCommentService interface {
+	CommentService
+	// GroupsResults is a marker method of GroupingCommentService.
+	GroupsResults()
+}
+
+// FinishingCommentService is a BulkCommentService which has work to do once
+// after all the runs sharing it (e.g. runners of a project config) flush it,
+// such as resolving stale comments. project.Run calls Finish after all the
+// runners finish.
+type FinishingCommentService interface {
+	BulkCommentService
+	Finish(context.Context) error
+}
+
 // DiffService is an interface which get diff.
 type DiffService interface {
 	Diff(context.Context) ([]byte, error)
@@ -61,15 +316,82 @@ type DiffService interface {
 }
 
 func (w *Reviewdog) runFromResult(ctx context.Context, results []*rdf.Diagnostic,
-	filediffs []*diff.FileDiff, strip int, failOnError bool) error {
+	filediffs []*diff.FileDiff, strip int) error {
+	res, err := w.report(ctx, results, filediffs, strip)
+	if err != nil {
+		return progressError(ctx, res, err)
+	}
+	return res.err()
+}
+
+// report filters results and posts them.
+func (w *Reviewdog) report(ctx context.Context, results []*rdf.Diagnostic,
+	filediffs []*diff.FileDiff, strip int) (*RunResult, error) {
 	wd, err := os.Getwd()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	filterStart := time.Now()
+	filter.RewritePaths(results, w.pathRewrites)
+	if !w.keepEmptyMessages {
+		n := len(results)
+		results = filter.FilterEmptyMessages(results)
+		if dropped := n - len(results); dropped > 0 {
+			log.Printf("reviewdog: [%s] dropped %d results with empty messages", w.toolname, dropped)
+		}
 	}
+	results = filter.FilterSeverity(results, w.severityLevel)
+	results = filter.FilterTools(results, w.toolname, w.includeTools, w.excludeTools)
+	results = filter.FilterFiles(results, wd, w.includeFiles, w.excludeFiles)
+	if w.ignoreAnnotations {
+		results = filter.FilterIgnoreAnnotations(results)
+	}
+	if w.baselineRecorder != nil {
+		for _, d := range results {
+			w.baselineRecorder.Add(d, w.toolname, wd)
+		}
+	}
+	// Check it after recording the baseline, which doesn't depend on diff.
+	if w.skipEmptyDiff && w.filterMode != filter.ModeNoFilter && len(filediffs) == 0 {
+		log.Printf("reviewdog: [%s] diff is empty. Nothing to review", w.toolname)
+		return &RunResult{}, nil
+	}
+	results = filter.FilterBaseline(results, w.toolname, wd, w.baseline)
+	results = filter.DropAppliedSuggestions(results)
+	results = filter.ResolveSuggestionConflicts(results, w.suggestionConflictMode)
+	checks := filter.FilterCheck(results, filediffs, strip, wd, w.filterMode,
+		filter.WithContextExpansion(w.diffContextExpansion))
+	filter.ExpandTabColumns(checks, w.tabWidth)
+	filter.SetLabels(checks, w.labelers...)
+	filter.CollapseDuplicates(checks, w.toolname, w.collapseThreshold)
+	if omitted := filter.LimitChecks(checks, w.maxComments); omitted != nil {
+		log.Printf("reviewdog: [%s] %d results are summarized in one comment due to the max number of comments (%d)", w.toolname, len(omitted.Omitted), w.maxComments)
+		checks = append(checks, omitted)
+	}
+	metrics.Time(w.metrics, "filter", filterStart, w.metricsTags())
 
-	checks := filter.FilterCheck(results, filediffs, strip, wd, w.filterMode)
-	hasViolations := false
+	res := &RunResult{}
+	counted, err := w.post(ctx, checks, res)
+	if err != nil {
+		return res, err
+	}
 
+	if counted > w.failThreshold && w.summary != nil {
+		w.summary.SetFailLevelExceeded()
+	}
+	res.Failed = w.failOnError && counted > w.failThreshold
+	return res, nil
+}
+
+// post posts checks to report and flushes them. It returns the number of
+// posted results counted for the fail policy.
+func (w *Reviewdog) post(ctx context.Context, checks []*filter.FilteredDiagnostic, res *RunResult) (int, error) {
+	defer func(start time.Time) {
+		metrics.Time(w.metrics, "report", start, w.metricsTags())
+		metrics.Count(w.metrics, "results.reported", res.Reported, w.metricsTags())
+	}(time.Now())
+	counted := 0
 	for _, check := range checks {
 		if !check.ShouldReport {
 			continue
@@ -78,41 +400,171 @@ func (w *Reviewdog) runFromResult(ctx context.Context, results []*rdf.Diagnostic
 			Result:   check,
 			ToolName: w.toolname,
 		}
-		if err := w.c.Post(ctx, comment); err != nil {
-			return err
+		if err := postComment(ctx, w.c, comment); err != nil {
+			return counted, err
+		}
+		res.Reported++
+		w.addSummary(check)
+		// Collapsed duplicates and omitted results are counted as reported
+		// results for the fail policy.
+		for _, fd := range check.ReportedChecks() {
+			if w.failLevel.Match(fd.Diagnostic.GetSeverity()) {
+				counted++
+			}
 		}
-		hasViolations = true
 	}
 
 	if bulk, ok := w.c.(BulkCommentService); ok {
 		if err := bulk.Flush(ctx); err != nil {
-			return err
+			return counted, err
 		}
 	}
+	return counted, nil
+}
 
-	if failOnError && hasViolations {
-		return fmt.Errorf("input data has violations")
-	}
-
-	return nil
+func (w *Reviewdog) metricsTags() metrics.Tags {
+	return metrics.Tags{"tool": w.toolname}
 }
 
 // Run runs Reviewdog application.
 func (w *Reviewdog) Run(ctx context.Context, r io.Reader) error {
-	results, err := w.p.Parse(r)
+	res, err := w.parseAndReport(ctx, r)
+	if err != nil {
+		return progressError(ctx, res, err)
+	}
+	return res.err()
+}
+
+// progressError adds the number of processed results to err if ctx is done
+// (e.g. by timeout), so that users can see how far the cancelled run went.
+func progressError(ctx context.Context, res *RunResult, err error) error {
+	if ctx.Err() == nil || res == nil {
+		return err
+	}
+	return fmt.Errorf("%w (processed %d result(s) before %v)", err, res.Reported, ctx.Err())
+}
+
+func (w *Reviewdog) parseAndReport(ctx context.Context, r io.Reader) (*RunResult, error) {
+	results, err := w.parse(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	filediffs, err := w.diff(ctx)
 	if err != nil {
-		return fmt.Errorf("parse error: %w", err)
+		return nil, err
 	}
+	return w.report(ctx, results, filediffs, w.d.Strip())
+}
 
+func (w *Reviewdog) parse(ctx context.Context, r io.Reader) ([]*rdf.Diagnostic, error) {
+	defer metrics.Time(w.metrics, "parse", time.Now(), w.metricsTags())
+	results, err := w.p.Parse(newContextReader(ctx, r))
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+	// Some parsers stop at read errors without returning them, so check ctx
+	// not to report partial input.
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+	metrics.Count(w.metrics, "results.parsed", len(results), w.metricsTags())
+	return results, nil
+}
+
+func (w *Reviewdog) diff(ctx context.Context) ([]*diff.FileDiff, error) {
+	defer metrics.Time(w.metrics, "diff", time.Now(), w.metricsTags())
 	d, err := w.d.Diff(ctx)
 	if err != nil {
-		return fmt.Errorf("fail to get diff: %w", err)
+		return nil, fmt.Errorf("fail to get diff: %w", err)
 	}
 
 	filediffs, err := diff.ParseMultiFile(bytes.NewReader(d))
 	if err != nil {
-		return fmt.Errorf("fail to parse diff: %w", err)
+		return nil, fmt.Errorf("fail to parse diff: %w", err)
+	}
+	return filediffs, nil
+}
+
+// RunConfig is a configuration of Run.
+type RunConfig struct {
+	// Input is the result of compilers or linters.
+	Input io.Reader
+	// ToolName is the tool name shown in comments.
+	ToolName       string
+	Parser         parser.Parser
+	CommentService CommentService
+	DiffService    DiffService
+	FilterMode     filter.Mode
+	// FailOnError makes RunResult.Failed true if any results are reported.
+	// Use WithFailPolicy option to fail only on results with high severity.
+	FailOnError bool
+	Options     []Option
+}
+
+// RunResult is a result of Run.
+type RunResult struct {
+	// Reported is the number of reported results.
+	Reported int
+	// Failed is true if FailOnError is set and reported results violate the
+	// fail policy (any results by default).
+	Failed bool
+}
+
+// ErrViolations is returned by Reviewdog.Run and RunFromResult when reported
+// results violate the fail policy with failOnError.
+var ErrViolations = errors.New("input data has violations")
+
+func (r *RunResult) err() error {
+	if r.Failed {
+		return ErrViolations
 	}
+	return nil
+}
+
+// Run runs the whole reviewdog pipeline (parse, get diff, filter and report)
+// with the config. Unlike Reviewdog.Run, it doesn't return an error for
+// reported results but sets RunResult.Failed instead.
+func Run(ctx context.Context, cfg RunConfig) (*RunResult, error) {
+	w := NewReviewdog(cfg.ToolName, cfg.Parser, cfg.CommentService, cfg.DiffService,
+		cfg.FilterMode, cfg.FailOnError, cfg.Options...)
+	return w.parseAndReport(ctx, cfg.Input)
+}
 
-	return w.runFromResult(ctx, results, filediffs, w.d.Strip(), w.failOnError)
+// contextReader is an io.Reader which stops reading when ctx is done, even if
+// the underlying Read blocks (e.g. stdin of a stuck linter).
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// newContextReader returns r as is if ctx is never done.
+func newContextReader(ctx context.Context, r io.Reader) io.Reader {
+	if ctx.Done() == nil {
+		return r
+	}
+	return &contextReader{ctx: ctx, r: r}
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	type result struct {
+		n   int
+		err error
+	}
+	// Read into a separate buffer so that the abandoned Read doesn't write to
+	// p after returning.
+	buf := make([]byte, len(p))
+	ch := make(chan result, 1)
+	go func() {
+		n, err := cr.r.Read(buf)
+		ch <- result{n: n, err: err}
+	}()
+	select {
+	case <-cr.ctx.Done():
+		return 0, cr.ctx.Err()
+	case res := <-ch:
+		return copy(p, buf[:res.n]), res.err
+	}
 }
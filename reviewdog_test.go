@@ -2,10 +2,14 @@ package reviewdog
 
 import (
 	"context"
+	"errors"
+	"io"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/reviewdog/errorformat"
 
 	"github.com/reviewdog/reviewdog/filter"
@@ -172,3 +176,238 @@ golint.new.go:11:1: comment on exported function F2 should be of the form "F2 ..
 		t.Errorf("'input data has violations' expected, but got %v", err)
 	}
 }
+
+func TestReviewdog_Run_severityLevel(t *testing.T) {
+	difftext := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,1 +1,3 @@
+ package a
++var A int
++var B int
+`
+	lintresult := `{"message":"error","severity":"ERROR","location":{"path":"a.go","range":{"start":{"line":2}}}}
+{"message":"warning","severity":"WARNING","location":{"path":"a.go","range":{"start":{"line":3}}}}
+{"message":"unknown","location":{"path":"a.go","range":{"start":{"line":3}}}}
+`
+	var got []string
+	c := &testWriter{
+		FakePost: func(c *Comment) error {
+			got = append(got, c.Result.Diagnostic.GetMessage())
+			return nil
+		},
+	}
+	d := NewDiffString(difftext, 1)
+	app := NewReviewdog("tool name", parser.NewRDJSONLParser(), c, d, filter.ModeAdded, false,
+		WithSeverityLevel(filter.SeverityLevelError))
+	if err := app.Run(context.Background(), strings.NewReader(lintresult)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "error,unknown"; strings.Join(got, ",") != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestReviewdog_Run_failPolicy(t *testing.T) {
+	difftext := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,1 +1,3 @@
+ package a
++var A int
++var B int
+`
+	lintresult := `{"message":"error1","severity":"ERROR","location":{"path":"a.go","range":{"start":{"line":2}}}}
+{"message":"error2","severity":"ERROR","location":{"path":"a.go","range":{"start":{"line":3}}}}
+{"message":"warning","severity":"WARNING","location":{"path":"a.go","range":{"start":{"line":3}}}}
+`
+	tests := []struct {
+		level     filter.SeverityLevel
+		threshold int
+		wantFail  bool
+	}{
+		{level: filter.SeverityLevelAny, threshold: 0, wantFail: true},
+		{level: filter.SeverityLevelAny, threshold: 2, wantFail: true},
+		{level: filter.SeverityLevelAny, threshold: 3, wantFail: false},
+		{level: filter.SeverityLevelError, threshold: 0, wantFail: true},
+		{level: filter.SeverityLevelError, threshold: 1, wantFail: true},
+		{level: filter.SeverityLevelError, threshold: 2, wantFail: false},
+	}
+	for _, tt := range tests {
+		c := &testWriter{FakePost: func(*Comment) error { return nil }}
+		d := NewDiffString(difftext, 1)
+		app := NewReviewdog("tool name", parser.NewRDJSONLParser(), c, d, filter.ModeAdded, true,
+			WithFailPolicy(tt.level, tt.threshold))
+		err := app.Run(context.Background(), strings.NewReader(lintresult))
+		if gotFail := err != nil; gotFail != tt.wantFail {
+			t.Errorf("level=%v, threshold=%d: got error %v, want fail=%v", tt.level.String(), tt.threshold, err, tt.wantFail)
+		}
+	}
+}
+
+type testBulkWriter struct {
+	posted  int
+	flushed int
+}
+
+func (s *testBulkWriter) Post(_ context.Context, _ *Comment) error {
+	s.posted++
+	return nil
+}
+
+func (s *testBulkWriter) Flush(_ context.Context) error {
+	s.flushed++
+	return nil
+}
+
+func TestReviewdog_Run_skipEmptyDiff(t *testing.T) {
+	lintresult := `{"message":"error","location":{"path":"a.go","range":{"start":{"line":1}}}}
+`
+	tests := []struct {
+		name        string
+		mode        filter.Mode
+		skip        bool
+		wantPosted  int
+		wantFlushed int
+	}{
+		{name: "skip", mode: filter.ModeAdded, skip: true, wantPosted: 0, wantFlushed: 0},
+		{name: "no skip by default", mode: filter.ModeAdded, skip: false, wantPosted: 0, wantFlushed: 1},
+		{name: "nofilter", mode: filter.ModeNoFilter, skip: true, wantPosted: 1, wantFlushed: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &testBulkWriter{}
+			app := NewReviewdog("tool name", parser.NewRDJSONLParser(), c, NewDiffString("", 1), tt.mode, true,
+				WithSkipEmptyDiff(tt.skip))
+			err := app.Run(context.Background(), strings.NewReader(lintresult))
+			if wantErr := tt.wantPosted > 0; (err != nil) != wantErr {
+				t.Errorf("got error %v, want error: %v", err, wantErr)
+			}
+			if c.posted != tt.wantPosted || c.flushed != tt.wantFlushed {
+				t.Errorf("posted %d, flushed %d; want posted %d, flushed %d", c.posted, c.flushed, tt.wantPosted, tt.wantFlushed)
+			}
+		})
+	}
+}
+
+func TestReviewdog_Run_cancelled(t *testing.T) {
+	difftext := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,1 +1,3 @@
+ package a
++var A int
++var B int
+`
+	lintresult := `{"message":"error1","location":{"path":"a.go","range":{"start":{"line":2}}}}
+{"message":"error2","location":{"path":"a.go","range":{"start":{"line":3}}}}
+`
+	t.Run("stuck input", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		r, w := io.Pipe() // Nothing is written to the pipe.
+		defer w.Close()
+		c := &testWriter{FakePost: func(*Comment) error { return nil }}
+		app := NewReviewdog("tool name", parser.NewRDJSONLParser(), c, NewDiffString(difftext, 1), filter.ModeAdded, false)
+		if err := app.Run(ctx, r); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("stuck reporter", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		c := &testWriter{FakePost: func(c *Comment) error {
+			if c.Result.Diagnostic.GetMessage() == "error2" {
+				cancel()
+				return ctx.Err()
+			}
+			return nil
+		}}
+		app := NewReviewdog("tool name", parser.NewRDJSONLParser(), c, NewDiffString(difftext, 1), filter.ModeAdded, false)
+		err := app.Run(ctx, strings.NewReader(lintresult))
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want %v", err, context.Canceled)
+		}
+		if want := "processed 1 result(s)"; !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't contain %q", err, want)
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	difftext := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,1 +1,2 @@
+ package a
++var A int
+`
+	lintresult := `a.go:1:1: outside diff
+a.go:2:5: exported var A should have comment or be unexported
+`
+	efm, _ := errorformat.NewErrorformat([]string{`%f:%l:%c: %m`})
+	for _, failOnError := range []bool{false, true} {
+		var got []string
+		c := &testWriter{
+			FakePost: func(c *Comment) error {
+				got = append(got, c.Result.Diagnostic.GetMessage())
+				return nil
+			},
+		}
+		res, err := Run(context.Background(), RunConfig{
+			Input:          strings.NewReader(lintresult),
+			ToolName:       "tool name",
+			Parser:         parser.NewErrorformatParser(efm),
+			CommentService: c,
+			DiffService:    NewDiffString(difftext, 1),
+			FilterMode:     filter.ModeAdded,
+			FailOnError:    failOnError,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (RunResult{Reported: 1, Failed: failOnError}); *res != want {
+			t.Errorf("failOnError=%v: got %+v, want %+v", failOnError, *res, want)
+		}
+		if want := "exported var A should have comment or be unexported"; strings.Join(got, ",") != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestReviewdog_maxComments(t *testing.T) {
+	c := &fakeGroupingCommentService{}
+	var individual []string
+	raw := &testWriter{FakePost: func(c *Comment) error {
+		individual = append(individual, c.Result.Diagnostic.GetMessage())
+		return nil
+	}}
+	input := `{"message":"a","location":{"path":"a.go","range":{"start":{"line":1}}},"severity":"INFO"}
+{"message":"b","location":{"path":"b.go","range":{"start":{"line":2}}},"severity":"ERROR"}
+{"message":"c","location":{"path":"c.go","range":{"start":{"line":3}}},"severity":"WARNING"}
+`
+	app := NewReviewdog("tool", parser.NewRDJSONLParser(), MultiCommentService(c, raw), NewDiffString("", 1), filter.ModeNoFilter, true,
+		WithMaxComments(1), WithFailPolicy(filter.SeverityLevelAny, 2))
+	err := app.Run(context.Background(), strings.NewReader(input))
+	if err == nil {
+		t.Error("want an error as omitted results are counted by the fail policy")
+	}
+	// Services which don't group results get all the results.
+	if diff := cmp.Diff([]string{"b", "c", "a"}, individual); diff != "" {
+		t.Errorf("results of non-grouping service (-want +got):\n%s", diff)
+	}
+	posted := c.posted
+	if len(posted) != 2 {
+		t.Fatalf("got %d comments, want 2", len(posted))
+	}
+	if got := posted[0].Result.Diagnostic.GetMessage(); got != "b" {
+		t.Errorf("got individual comment %q, want the error b", got)
+	}
+	want := "2 more issue(s) are not posted as individual comments to limit the number of comments:\n" +
+		"\n- `c.go:3`: c" +
+		"\n- `a.go:1`: a"
+	if got := posted[1].Result.Diagnostic.GetMessage(); got != want {
+		t.Errorf("got summary comment:\n%s\nwant:\n%s", got, want)
+	}
+}
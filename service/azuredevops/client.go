@@ -0,0 +1,152 @@
+package azuredevops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const apiVersion = "6.0"
+
+// Client is a minimal Azure DevOps REST API client for pull requests.
+//
+// API:
+//  https://learn.microsoft.com/en-us/rest/api/azure/devops/git/pull-request-threads
+type Client struct {
+	cli *http.Client
+	// baseURL is the organization (collection) URL. e.g.
+	// https://dev.azure.com/{organization}/
+	baseURL *url.URL
+	token   string
+}
+
+// NewClient returns a new Client. baseURL is the organization (collection) URL
+// and token is a personal access token (PAT) or System.AccessToken of Azure
+// Pipelines. http.DefaultClient is used if cli is nil.
+func NewClient(cli *http.Client, baseURL, token string) (*Client, error) {
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("Azure DevOps base URL is invalid: %v, %w", baseURL, err)
+	}
+	return &Client{cli: cli, baseURL: u, token: token}, nil
+}
+
+// PullRequest represents an Azure DevOps pull request.
+type PullRequest struct {
+	PullRequestID         int        `json:"pullRequestId"`
+	TargetRefName         string     `json:"targetRefName"`
+	LastMergeSourceCommit *CommitRef `json:"lastMergeSourceCommit,omitempty"`
+	LastMergeTargetCommit *CommitRef `json:"lastMergeTargetCommit,omitempty"`
+}
+
+// CommitRef represents a commit reference.
+type CommitRef struct {
+	CommitID string `json:"commitId"`
+}
+
+// Thread represents a pull request comment thread.
+type Thread struct {
+	ID            int            `json:"id,omitempty"`
+	Comments      []*Comment     `json:"comments"`
+	Status        string         `json:"status,omitempty"`
+	ThreadContext *ThreadContext `json:"threadContext,omitempty"`
+}
+
+// Comment represents a comment of a thread.
+type Comment struct {
+	ParentCommentID int    `json:"parentCommentId,omitempty"`
+	Content         string `json:"content"`
+	CommentType     string `json:"commentType,omitempty"`
+}
+
+// ThreadContext represents the file and the range of the right (new) side
+// which a thread is anchored to.
+type ThreadContext struct {
+	FilePath       string        `json:"filePath"`
+	RightFileStart *FilePosition `json:"rightFileStart,omitempty"`
+	RightFileEnd   *FilePosition `json:"rightFileEnd,omitempty"`
+}
+
+// FilePosition represents a 1-based position of a file.
+type FilePosition struct {
+	Line   int `json:"line"`
+	Offset int `json:"offset"`
+}
+
+// GetPullRequest gets the pull request.
+func (c *Client) GetPullRequest(ctx context.Context, project, repo string, pr int) (*PullRequest, error) {
+	var res PullRequest
+	if err := c.do(ctx, http.MethodGet, c.pullRequestPath(project, repo, pr), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListThreads lists all the threads of the pull request.
+func (c *Client) ListThreads(ctx context.Context, project, repo string, pr int) ([]*Thread, error) {
+	var res struct {
+		Value []*Thread `json:"value"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.pullRequestPath(project, repo, pr)+"/threads", nil, &res); err != nil {
+		return nil, err
+	}
+	return res.Value, nil
+}
+
+// CreateThread creates a thread on the pull request.
+func (c *Client) CreateThread(ctx context.Context, project, repo string, pr int, thread *Thread) error {
+	return c.do(ctx, http.MethodPost, c.pullRequestPath(project, repo, pr)+"/threads", thread, nil)
+}
+
+func (c *Client) pullRequestPath(project, repo string, pr int) string {
+	return fmt.Sprintf("%s/_apis/git/repositories/%s/pullRequests/%d",
+		url.PathEscape(project), url.PathEscape(repo), pr)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	u, err := c.baseURL.Parse(path + "?api-version=" + apiVersion)
+	if err != nil {
+		return err
+	}
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// PAT is used as the password of basic authentication with empty user.
+	req.SetBasicAuth("", c.token)
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Azure DevOps API %s %s failed: %s: %s", method, u.Path, resp.Status, strings.TrimSpace(string(b)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Azure DevOps API response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/reviewdog/reviewdog"
+)
+
+var _ reviewdog.DiffService = &PullRequestDiff{}
+
+// PullRequestDiff is a diff service for Azure DevOps pull requests.
+type PullRequestDiff struct {
+	cli     *Client
+	project string
+	repo    string
+	pr      int
+}
+
+// NewPullRequestDiff returns a new PullRequestDiff service. It needs git
+// command in $PATH.
+func NewPullRequestDiff(cli *Client, project, repo string, pr int) *PullRequestDiff {
+	return &PullRequestDiff{cli: cli, project: project, repo: repo, pr: pr}
+}
+
+// Diff returns a diff of the pull request. It runs `git diff` locally between
+// the merge-base of the last merged target and source commits and the source
+// commit, which is equivalent to the diff of the pull request with
+// `--find-renames`.
+func (g *PullRequestDiff) Diff(ctx context.Context) ([]byte, error) {
+	pr, err := g.cli.GetPullRequest(ctx, g.project, g.repo, g.pr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+	if pr.LastMergeSourceCommit == nil || pr.LastMergeTargetCommit == nil {
+		return nil, errors.New("pull request doesn't have the last merge source and target commits")
+	}
+	return gitDiff(ctx, pr.LastMergeSourceCommit.CommitID, pr.LastMergeTargetCommit.CommitID)
+}
+
+func gitDiff(ctx context.Context, baseSha, targetSha string) ([]byte, error) {
+	b, err := exec.CommandContext(ctx, "git", "merge-base", targetSha, baseSha).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merge-base commit: %w", err)
+	}
+	mergeBase := strings.Trim(string(b), "\n")
+	bytes, err := exec.CommandContext(ctx, "git", "diff", "--find-renames", mergeBase, baseSha).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git diff: %w", err)
+	}
+	return bytes, nil
+}
+
+// Strip returns 1 as a strip of git diff.
+func (g *PullRequestDiff) Strip() int {
+	return 1
+}
@@ -0,0 +1,48 @@
+package azuredevops
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPullRequestDiff_Diff(t *testing.T) {
+	getPRAPICall := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/org/proj/_apis/git/repositories/repo/pullRequests/14", func(w http.ResponseWriter, r *http.Request) {
+		getPRAPICall++
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		w.Write([]byte(`{"pullRequestId": 14, "lastMergeSourceCommit": {"commitId": "HEAD"}, "lastMergeTargetCommit": {"commitId": "HEAD~"}}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := NewClient(ts.Client(), ts.URL+"/org/", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPullRequestDiff(cli, "proj", "repo", 14).Diff(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if getPRAPICall != 1 {
+		t.Errorf("Get pull request API called %v times, want once", getPRAPICall)
+	}
+}
+
+func TestPullRequestDiff_Diff_error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	cli, err := NewClient(ts.Client(), ts.URL+"/org", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPullRequestDiff(cli, "proj", "repo", 14).Diff(context.Background()); err == nil {
+		t.Error("got no error for the failed API call")
+	}
+}
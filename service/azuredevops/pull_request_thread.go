@@ -0,0 +1,178 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ reviewdog.CommentService = &PullRequestThreadCommenter{}
+var _ reviewdog.GroupingCommentService = &PullRequestThreadCommenter{}
+
+// DefaultMaxMessageLength is the default max length of diagnostic messages in
+// thread comments. Azure DevOps rejects comments longer than 150,000
+// characters.
+const DefaultMaxMessageLength = 140000
+
+// PullRequestThreadCommenter is a comment service for Azure DevOps pull
+// requests. It posts results as pull request threads.
+//
+// API:
+//  https://learn.microsoft.com/en-us/rest/api/azure/devops/git/pull-request-threads/create
+//  POST {organization}/{project}/_apis/git/repositories/{repositoryId}/pullRequests/{pullRequestId}/threads
+type PullRequestThreadCommenter struct {
+	cli     *Client
+	project string
+	repo    string
+	pr      int
+
+	muComments   sync.Mutex
+	postComments []*reviewdog.Comment
+
+	// wd is working directory relative to root of repository.
+	wd string
+
+	// tmpl is the comment body template. MarkdownComment is used if nil.
+	tmpl *commentutil.Template
+
+	// maxMessageLength is the max length of diagnostic messages in comment
+	// bodies. Longer messages are truncated.
+	maxMessageLength int
+}
+
+// PullRequestThreadCommenterOption is an option for
+// NewPullRequestThreadCommenter.
+type PullRequestThreadCommenterOption func(*PullRequestThreadCommenter)
+
+// WithThreadCommentTemplate makes PullRequestThreadCommenter build comment
+// bodies with tmpl.
+func WithThreadCommentTemplate(tmpl *commentutil.Template) PullRequestThreadCommenterOption {
+	return func(g *PullRequestThreadCommenter) {
+		g.tmpl = tmpl
+	}
+}
+
+// WithThreadMaxMessageLength sets the max length of diagnostic messages in
+// comment bodies in bytes. Longer messages are truncated with a note.
+// DefaultMaxMessageLength is used by default.
+func WithThreadMaxMessageLength(n int) PullRequestThreadCommenterOption {
+	return func(g *PullRequestThreadCommenter) {
+		g.maxMessageLength = n
+	}
+}
+
+// NewPullRequestThreadCommenter returns a new PullRequestThreadCommenter
+// service. repo is the name or the ID of the repository.
+func NewPullRequestThreadCommenter(cli *Client, project, repo string, pr int, opts ...PullRequestThreadCommenterOption) (*PullRequestThreadCommenter, error) {
+	workDir, err := serviceutil.GitRelWorkdir()
+	if err != nil {
+		return nil, fmt.Errorf("PullRequestThreadCommenter needs 'git' command: %w", err)
+	}
+	g := &PullRequestThreadCommenter{
+		cli:     cli,
+		project: project,
+		repo:    repo,
+		pr:      pr,
+		wd:      workDir,
+
+		maxMessageLength: DefaultMaxMessageLength,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.tmpl = g.tmpl.WithMaxMessageLength(g.maxMessageLength)
+	return g, nil
+}
+
+// Post accepts a comment and holds it. Flush method actually posts comments to
+// Azure DevOps in parallel.
+func (g *PullRequestThreadCommenter) Post(_ context.Context, c *reviewdog.Comment) error {
+	c.Result.Diagnostic.GetLocation().Path = filepath.ToSlash(
+		filepath.Join(g.wd, c.Result.Diagnostic.GetLocation().GetPath()))
+	g.muComments.Lock()
+	defer g.muComments.Unlock()
+	g.postComments = append(g.postComments, c)
+	return nil
+}
+
+// GroupsResults implements reviewdog.GroupingCommentService. Comment bodies
+// list locations of collapsed duplicates.
+func (g *PullRequestThreadCommenter) GroupsResults() {}
+
+// Flush posts comments which has not been posted yet.
+func (g *PullRequestThreadCommenter) Flush(ctx context.Context) error {
+	g.muComments.Lock()
+	defer g.muComments.Unlock()
+	threads, err := g.cli.ListThreads(ctx, g.project, g.repo, g.pr)
+	if err != nil {
+		return fmt.Errorf("failed to list pull request threads: %w", err)
+	}
+	postedcs := createPostedComments(threads)
+
+	var eg errgroup.Group
+	for _, c := range g.postComments {
+		c := c
+		lnum := int(c.Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine())
+		body := g.tmpl.Body(c)
+		if !c.Result.InDiffFile || lnum == 0 || postedcs.IsPosted(c, lnum, body) {
+			continue
+		}
+		eg.Go(func() error {
+			thread := &Thread{
+				Comments:      []*Comment{{ParentCommentID: 0, Content: body, CommentType: "text"}},
+				Status:        "active",
+				ThreadContext: threadContext(c),
+			}
+			if err := g.cli.CreateThread(ctx, g.project, g.repo, g.pr, thread); err != nil {
+				return fmt.Errorf("failed to create pull request thread: %w", err)
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+func createPostedComments(threads []*Thread) commentutil.PostedComments {
+	postedcs := make(commentutil.PostedComments)
+	for _, t := range threads {
+		tc := t.ThreadContext
+		if tc == nil || tc.RightFileStart == nil || len(t.Comments) == 0 {
+			continue
+		}
+		postedcs.AddPostedComment(strings.TrimPrefix(tc.FilePath, "/"), tc.RightFileStart.Line, t.Comments[0].Content)
+	}
+	return postedcs
+}
+
+// threadContext maps the location of the diagnostic to the right (new) file
+// range of a thread. Offsets are 1-based columns, and the whole line is used
+// if the diagnostic doesn't have columns.
+func threadContext(c *reviewdog.Comment) *ThreadContext {
+	loc := c.Result.Diagnostic.GetLocation()
+	start := loc.GetRange().GetStart()
+	end := loc.GetRange().GetEnd()
+	tc := &ThreadContext{
+		FilePath:       "/" + loc.GetPath(),
+		RightFileStart: &FilePosition{Line: int(start.GetLine()), Offset: max(int(start.GetColumn()), 1)},
+		RightFileEnd:   &FilePosition{Line: int(start.GetLine()), Offset: max(int(start.GetColumn()), 1)},
+	}
+	if end.GetLine() > 0 {
+		tc.RightFileEnd = &FilePosition{Line: int(end.GetLine()), Offset: max(int(end.GetColumn()), 1)}
+	}
+	return tc
+}
+
+func max(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
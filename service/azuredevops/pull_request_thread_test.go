@@ -0,0 +1,153 @@
+package azuredevops
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+)
+
+func TestPullRequestThreadCommenter_Post_Flush(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	alreadyCommented := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+				},
+				Message: "already commented",
+			},
+			InDiffFile: true,
+		},
+	}
+	newComment := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path: "dir/file.go",
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 14, Column: 3},
+						End:   &rdf.Position{Line: 15, Column: 7},
+					},
+				},
+				Message: "new comment",
+			},
+			InDiffFile: true,
+		},
+	}
+	outsideDiff := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+				},
+				Message: "outside diff",
+			},
+		},
+	}
+
+	var created []*Thread
+	mux := http.NewServeMux()
+	mux.HandleFunc("/org/proj/_apis/git/repositories/repo/pullRequests/14/threads", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("api-version"); got != apiVersion {
+			t.Errorf("got api-version %q, want %q", got, apiVersion)
+		}
+		if _, pass, ok := r.BasicAuth(); !ok || pass != "token" {
+			t.Errorf("want basic auth with the token")
+		}
+		switch r.Method {
+		case http.MethodGet:
+			threads := []*Thread{
+				{
+					Comments: []*Comment{{Content: commentutil.MarkdownComment(alreadyCommented)}},
+					ThreadContext: &ThreadContext{
+						FilePath:       "/file.go",
+						RightFileStart: &FilePosition{Line: 1, Offset: 1},
+					},
+				},
+				// Threads without file context (e.g. system messages).
+				{Comments: []*Comment{{Content: "status changed"}}},
+			}
+			if err := json.NewEncoder(w).Encode(map[string]interface{}{"value": threads, "count": len(threads)}); err != nil {
+				t.Fatal(err)
+			}
+		case http.MethodPost:
+			var thread Thread
+			if err := json.NewDecoder(r.Body).Decode(&thread); err != nil {
+				t.Error(err)
+			}
+			created = append(created, &thread)
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := NewClient(ts.Client(), ts.URL+"/org", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewPullRequestThreadCommenter(cli, "proj", "repo", 14)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*reviewdog.Comment{alreadyCommented, newComment, outsideDiff} {
+		if err := g.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []*Thread{
+		{
+			Comments: []*Comment{{Content: commentutil.MarkdownComment(newComment), CommentType: "text"}},
+			Status:   "active",
+			ThreadContext: &ThreadContext{
+				FilePath:       "/dir/file.go",
+				RightFileStart: &FilePosition{Line: 14, Offset: 3},
+				RightFileEnd:   &FilePosition{Line: 15, Offset: 7},
+			},
+		},
+	}
+	if diff := cmp.Diff(created, want); diff != "" {
+		t.Errorf("created threads diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestThreadContext_line(t *testing.T) {
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+				},
+			},
+		},
+	}
+	want := &ThreadContext{
+		FilePath:       "/file.go",
+		RightFileStart: &FilePosition{Line: 14, Offset: 1},
+		RightFileEnd:   &FilePosition{Line: 14, Offset: 1},
+	}
+	if diff := cmp.Diff(threadContext(c), want); diff != "" {
+		t.Errorf("threadContext diff (-got +want):\n%s", diff)
+	}
+}
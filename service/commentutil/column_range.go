@@ -0,0 +1,107 @@
+package commentutil
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/reviewdog/reviewdog"
+)
+
+// maxColumnRangeLines is the max number of lines of ranges which ColumnRange
+// renders. Columns of longer ranges are not helpful to see the span.
+const maxColumnRangeLines = 10
+
+// ColumnRange returns a fenced code block of the source lines of the comment
+// with carets under the columns of the diagnostic range, e.g.
+//
+//	x := foo(bar)
+//	     ^^^
+//
+// It's for reporters which cannot show columns natively. Columns are 1-based
+// byte offsets and the end column is exclusive. It returns empty string if the
+// range has no column, covers whole lines or the lines cannot be read.
+func ColumnRange(c *reviewdog.Comment) string {
+	rng := c.Result.Diagnostic.GetLocation().GetRange()
+	start, end := rng.GetStart(), rng.GetEnd()
+	startLine, startCol := int(start.GetLine()), int(start.GetColumn())
+	if startLine <= 0 || startCol <= 0 {
+		return ""
+	}
+	endLine, endCol := int(end.GetLine()), int(end.GetColumn())
+	if endLine < startLine {
+		// No end. Mark the start column.
+		endLine, endCol = startLine, startCol
+	}
+	if endLine > startLine && endCol == 1 {
+		// The range ends with the line break of the previous line.
+		endLine, endCol = endLine-1, 0
+	}
+	if startCol == 1 && endCol == 0 {
+		// The range covers whole lines, which comments already show.
+		return ""
+	}
+	if endLine-startLine+1 > maxColumnRangeLines {
+		return ""
+	}
+	lines := sourceLines(c, startLine, endLine)
+	if len(lines) == 0 {
+		return ""
+	}
+	var code strings.Builder
+	for i, line := range lines {
+		from, to := 0, len(line) // Byte offsets of the span in the line.
+		if i == 0 {
+			from = startCol - 1
+		}
+		if i == len(lines)-1 && endCol > 0 {
+			to = endCol - 1
+		}
+		if from > len(line) {
+			from = len(line)
+		}
+		if to > len(line) {
+			to = len(line)
+		}
+		if to <= from {
+			// Mark at least one column, e.g. for ranges without end.
+			to = from + 1
+		}
+		if i > 0 {
+			code.WriteString("\n")
+		}
+		code.WriteString(line)
+		code.WriteString("\n")
+		code.WriteString(caretLine(line, from, to))
+	}
+	var sb strings.Builder
+	fence := GetCodeFenceLength(code.String())
+	WriteCodeFence(&sb, fence)
+	sb.WriteString("\n")
+	sb.WriteString(code.String())
+	sb.WriteString("\n")
+	WriteCodeFence(&sb, fence)
+	return sb.String()
+}
+
+// caretLine returns the line which has carets under bytes [from, to) of line.
+// It keeps tabs before the span so that carets align with the line. A span
+// at the end of the line (e.g. a missing semicolon) is marked with one caret.
+func caretLine(line string, from, to int) string {
+	var sb strings.Builder
+	for i, r := range line {
+		if i >= from {
+			break
+		}
+		if r == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+	if from >= len(line) {
+		sb.WriteRune('^')
+		return sb.String()
+	}
+	sb.WriteString(strings.Repeat("^", utf8.RuneCountInString(line[from:to])))
+	return sb.String()
+}
@@ -0,0 +1,84 @@
+package commentutil
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestColumnRange(t *testing.T) {
+	sourceLines := map[int]string{
+		1: "x := foo(bar)",
+		2: "\tif héllo {",
+		3: "\t\treturn",
+		4: "\t}",
+	}
+	newComment := func(startLine, startCol, endLine, endCol int32) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path: "not-exist.go",
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: startLine, Column: startCol},
+							End:   &rdf.Position{Line: endLine, Column: endCol},
+						},
+					},
+				},
+				SourceLines: sourceLines,
+			},
+		}
+	}
+	tests := []struct {
+		name string
+		c    *reviewdog.Comment
+		want string
+	}{
+		{
+			name: "no columns",
+			c:    newComment(1, 0, 1, 0),
+		},
+		{
+			name: "whole lines",
+			c:    newComment(1, 1, 3, 1),
+		},
+		{
+			name: "no source lines",
+			c:    newComment(10, 2, 10, 4),
+		},
+		{
+			name: "single line range",
+			c:    newComment(1, 6, 1, 9),
+			want: "```\nx := foo(bar)\n     ^^^\n```",
+		},
+		{
+			name: "start column only",
+			c:    newComment(1, 10, 0, 0),
+			want: "```\nx := foo(bar)\n         ^\n```",
+		},
+		{
+			name: "end of line",
+			c:    newComment(1, 14, 1, 14),
+			want: "```\nx := foo(bar)\n             ^\n```",
+		},
+		{
+			name: "tabs and multibyte characters",
+			c:    newComment(2, 5, 2, 11),
+			want: "```\n\tif héllo {\n\t   ^^^^^\n```",
+		},
+		{
+			name: "multi-line range",
+			c:    newComment(2, 2, 4, 3),
+			want: "```\n\tif héllo {\n\t^^^^^^^^^^\n\t\treturn\n^^^^^^^^\n\t}\n^^\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ColumnRange(tt.c); got != tt.want {
+				t.Errorf("ColumnRange() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
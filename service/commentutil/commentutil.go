@@ -16,7 +16,12 @@ type PostedComments map[string]map[int][]string
 // otherwise returns false. It sees comments with same path, same position,
 // and same body as same comments.
 func (p PostedComments) IsPosted(c *reviewdog.Comment, lineNum int, body string) bool {
-	path := c.Result.Diagnostic.GetLocation().GetPath()
+	return p.Contains(c.Result.Diagnostic.GetLocation().GetPath(), lineNum, body)
+}
+
+// Contains returns true if a comment with given path, position and body has
+// been added.
+func (p PostedComments) Contains(path string, lineNum int, body string) bool {
 	if _, ok := p[path]; !ok {
 		return false
 	}
@@ -57,8 +62,12 @@ const BodyPrefix = `<sub>reported by [reviewdog](https://github.com/reviewdog/re
 
 // MarkdownComment creates comment body markdown.
 func MarkdownComment(c *reviewdog.Comment) string {
+	return markdownComment(c, c.Result.Diagnostic.GetMessage(), true)
+}
+
+func markdownComment(c *reviewdog.Comment, message string, withSeverity bool) string {
 	var sb strings.Builder
-	if s := severity(c); s != "" {
+	if s := severity(c); withSeverity && s != "" {
 		sb.WriteString(s)
 		sb.WriteString(" ")
 	}
@@ -73,7 +82,7 @@ func MarkdownComment(c *reviewdog.Comment) string {
 		}
 	}
 	sb.WriteString(BodyPrefix)
-	sb.WriteString(c.Result.Diagnostic.GetMessage())
+	sb.WriteString(message)
 	return sb.String()
 }
 
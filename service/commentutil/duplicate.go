@@ -0,0 +1,37 @@
+package commentutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/reviewdog/reviewdog"
+)
+
+// maxDuplicateLocations is the max number of locations listed by
+// DuplicateLocations.
+const maxDuplicateLocations = 50
+
+// DuplicateLocations returns a markdown list of locations of results which
+// are collapsed into the comment (see filter.CollapseDuplicates). It lists at
+// most 50 locations and returns empty string if there are no duplicates.
+func DuplicateLocations(c *reviewdog.Comment) string {
+	dups := c.Result.Duplicates
+	if len(dups) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Also found at %d other location(s):", len(dups))
+	for i, d := range dups {
+		if i == maxDuplicateLocations {
+			fmt.Fprintf(&sb, "\n- and %d more", len(dups)-i)
+			break
+		}
+		loc := d.Diagnostic.GetLocation()
+		pos := loc.GetPath()
+		if line := loc.GetRange().GetStart().GetLine(); line > 0 {
+			pos = fmt.Sprintf("%s:%d", pos, line)
+		}
+		fmt.Fprintf(&sb, "\n- `%s`", pos)
+	}
+	return sb.String()
+}
@@ -0,0 +1,45 @@
+package commentutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestDuplicateLocations(t *testing.T) {
+	newResult := func(path string, line int32) *filter.FilteredDiagnostic {
+		return &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{
+			Message: "msg",
+			Location: &rdf.Location{
+				Path:  path,
+				Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+			},
+		}}
+	}
+	c := &reviewdog.Comment{Result: newResult("a.go", 1)}
+	if got := DuplicateLocations(c); got != "" {
+		t.Errorf("no duplicates: got %q", got)
+	}
+
+	c.Result.Duplicates = []*filter.FilteredDiagnostic{newResult("b.go", 2), newResult("c.go", 0)}
+	want := "Also found at 2 other location(s):\n- `b.go:2`\n- `c.go`"
+	if got := DuplicateLocations(c); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := (*Template)(nil).Body(c); !strings.HasSuffix(got, "msg\n\n"+want) {
+		t.Errorf("Body() = %q, want suffix %q", got, want)
+	}
+
+	c.Result.Duplicates = nil
+	for i := 0; i < maxDuplicateLocations+2; i++ {
+		c.Result.Duplicates = append(c.Result.Duplicates, newResult(fmt.Sprintf("%d.go", i), 1))
+	}
+	got := DuplicateLocations(c)
+	if !strings.HasSuffix(got, "\n- `49.go:1`\n- and 2 more") {
+		t.Errorf("got %q, want truncated list", got)
+	}
+}
@@ -0,0 +1,55 @@
+package commentutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/reviewdog/reviewdog"
+)
+
+const fingerprintPrefix = "<!-- reviewdog-fingerprint: "
+
+var fingerprintRe = regexp.MustCompile(`<!-- reviewdog-fingerprint: ([0-9a-f]+) -->`)
+
+// Fingerprint returns a stable fingerprint of the comment. It's calculated
+// from the path, message and code of the diagnostic, so it doesn't change
+// even if the result moves to another line.
+func Fingerprint(c *reviewdog.Comment) string {
+	d := c.Result.Diagnostic
+	h := sha256.New()
+	for _, s := range []string{d.GetLocation().GetPath(), d.GetMessage(), d.GetCode().GetValue()} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// AppendFingerprint appends the fingerprint of c to body as an HTML comment,
+// which is invisible in rendered markdown.
+func AppendFingerprint(body string, c *reviewdog.Comment) string {
+	return body + "\n" + fingerprintPrefix + Fingerprint(c) + " -->"
+}
+
+// Fingerprints is a set of fingerprints of posted comments.
+type Fingerprints map[string]bool
+
+// Add adds the fingerprint embedded in the comment body if any.
+func (fps Fingerprints) Add(body string) {
+	if m := fingerprintRe.FindStringSubmatch(body); m != nil {
+		fps[m[1]] = true
+	}
+}
+
+// Contains returns true if a comment with the same fingerprint as c has been
+// added.
+func (fps Fingerprints) Contains(c *reviewdog.Comment) bool {
+	return fps[Fingerprint(c)]
+}
+
+// ContainsBody returns true if the fingerprint embedded in the comment body
+// has been added.
+func (fps Fingerprints) ContainsBody(body string) bool {
+	m := fingerprintRe.FindStringSubmatch(body)
+	return m != nil && fps[m[1]]
+}
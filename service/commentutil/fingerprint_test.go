@@ -0,0 +1,60 @@
+package commentutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestFingerprint(t *testing.T) {
+	newComment := func(path string, line int32, msg, code string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  path,
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: msg,
+					Code:    &rdf.Code{Value: code},
+				},
+			},
+		}
+	}
+	c := newComment("a.go", 1, "msg", "code")
+	if Fingerprint(c) != Fingerprint(newComment("a.go", 14, "msg", "code")) {
+		t.Error("fingerprint should not depend on the line")
+	}
+	for _, other := range []*reviewdog.Comment{
+		newComment("b.go", 1, "msg", "code"),
+		newComment("a.go", 1, "other msg", "code"),
+		newComment("a.go", 1, "msg", "other code"),
+	} {
+		if Fingerprint(c) == Fingerprint(other) {
+			t.Errorf("fingerprint of %v should differ from %v", other.Result.Diagnostic, c.Result.Diagnostic)
+		}
+	}
+
+	body := AppendFingerprint("body", c)
+	if !strings.HasPrefix(body, "body\n<!-- reviewdog-fingerprint: ") {
+		t.Errorf("AppendFingerprint() = %q", body)
+	}
+	fps := make(Fingerprints)
+	fps.Add("no fingerprint")
+	if fps.Contains(c) {
+		t.Error("Contains() = true before adding the fingerprint")
+	}
+	fps.Add(body)
+	if !fps.Contains(newComment("a.go", 2, "msg", "code")) {
+		t.Error("Contains() = false for a moved comment")
+	}
+	if !fps.ContainsBody(AppendFingerprint("other body", c)) {
+		t.Error("ContainsBody() = false for the same fingerprint")
+	}
+	if fps.ContainsBody("body") {
+		t.Error("ContainsBody() = true for a body without fingerprint")
+	}
+}
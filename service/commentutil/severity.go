@@ -0,0 +1,70 @@
+package commentutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// SeverityPrefixes are markers (e.g. "❌" or "[ERROR]") prefixed to diagnostic
+// messages per severity. Results without severity use the marker of
+// rdf.Severity_UNKNOWN_SEVERITY.
+type SeverityPrefixes map[rdf.Severity]string
+
+var severityPrefixPresets = map[string]SeverityPrefixes{
+	"emoji": {
+		rdf.Severity_ERROR:   "❌",
+		rdf.Severity_WARNING: "⚠️",
+		rdf.Severity_INFO:    "ℹ️",
+	},
+	"text": {
+		rdf.Severity_ERROR:   "[ERROR]",
+		rdf.Severity_WARNING: "[WARNING]",
+		rdf.Severity_INFO:    "[INFO]",
+	},
+}
+
+// ParseSeverityPrefixes parses severity prefixes. s is a preset name ("emoji"
+// or "text") or comma separated severity=marker pairs where severity is one
+// of error, warning, info and other (results without severity). e.g.
+// "error=[E],warning=[W]". Empty s returns nil, which means no prefixes.
+func ParseSeverityPrefixes(s string) (SeverityPrefixes, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if ps, ok := severityPrefixPresets[s]; ok {
+		return ps, nil
+	}
+	ps := make(SeverityPrefixes)
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid severity prefix %q: want severity=marker", kv)
+		}
+		var severity rdf.Severity
+		switch strings.TrimSpace(parts[0]) {
+		case "error":
+			severity = rdf.Severity_ERROR
+		case "warning":
+			severity = rdf.Severity_WARNING
+		case "info":
+			severity = rdf.Severity_INFO
+		case "other":
+			severity = rdf.Severity_UNKNOWN_SEVERITY
+		default:
+			return nil, fmt.Errorf("invalid severity of severity prefix %q: want error, warning, info or other", kv)
+		}
+		ps[severity] = parts[1]
+	}
+	return ps, nil
+}
+
+// Prefix returns message prefixed with the marker of the severity and a space.
+// It returns message as is if there is no marker for the severity.
+func (ps SeverityPrefixes) Prefix(message string, severity rdf.Severity) string {
+	if p := ps[severity]; p != "" {
+		return p + " " + message
+	}
+	return message
+}
@@ -0,0 +1,103 @@
+package commentutil
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestParseSeverityPrefixes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want map[rdf.Severity]string // message prefixed with the marker.
+	}{
+		{
+			in: "",
+			want: map[rdf.Severity]string{
+				rdf.Severity_ERROR:            "msg",
+				rdf.Severity_WARNING:          "msg",
+				rdf.Severity_INFO:             "msg",
+				rdf.Severity_UNKNOWN_SEVERITY: "msg",
+			},
+		},
+		{
+			in: "emoji",
+			want: map[rdf.Severity]string{
+				rdf.Severity_ERROR:            "❌ msg",
+				rdf.Severity_WARNING:          "⚠️ msg",
+				rdf.Severity_INFO:             "ℹ️ msg",
+				rdf.Severity_UNKNOWN_SEVERITY: "msg",
+			},
+		},
+		{
+			in: "text",
+			want: map[rdf.Severity]string{
+				rdf.Severity_ERROR:            "[ERROR] msg",
+				rdf.Severity_WARNING:          "[WARNING] msg",
+				rdf.Severity_INFO:             "[INFO] msg",
+				rdf.Severity_UNKNOWN_SEVERITY: "msg",
+			},
+		},
+		{
+			in: "error=[E],warning=[W],other=[?]",
+			want: map[rdf.Severity]string{
+				rdf.Severity_ERROR:            "[E] msg",
+				rdf.Severity_WARNING:          "[W] msg",
+				rdf.Severity_INFO:             "msg",
+				rdf.Severity_UNKNOWN_SEVERITY: "[?] msg",
+			},
+		},
+	}
+	for _, tt := range tests {
+		ps, err := ParseSeverityPrefixes(tt.in)
+		if err != nil {
+			t.Fatalf("ParseSeverityPrefixes(%q) failed: %v", tt.in, err)
+		}
+		for severity, want := range tt.want {
+			if got := ps.Prefix("msg", severity); got != want {
+				t.Errorf("ParseSeverityPrefixes(%q).Prefix(%v) = %q, want %q", tt.in, severity, got, want)
+			}
+		}
+	}
+}
+
+func TestParseSeverityPrefixes_invalid(t *testing.T) {
+	for _, in := range []string{"unknown", "fatal=[F]", "error=[E],warning"} {
+		if _, err := ParseSeverityPrefixes(in); err == nil {
+			t.Errorf("ParseSeverityPrefixes(%q) should fail", in)
+		}
+	}
+}
+
+func TestTemplate_WithSeverityPrefixes(t *testing.T) {
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{Message: "msg", Severity: rdf.Severity_WARNING},
+		},
+		ToolName: "tool",
+	}
+	ps, err := ParseSeverityPrefixes("text")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var nilTmpl *Template
+	tmpl := nilTmpl.WithSeverityPrefixes(ps)
+	if got, want := tmpl.Message(c), "[WARNING] msg"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+	// The prefix replaces the default severity emoji.
+	if got, want := tmpl.Body(c), "**[tool]** "+BodyPrefix+"[WARNING] msg"; got != want {
+		t.Errorf("Body() = %q, want %q", got, want)
+	}
+
+	custom, err := ParseTemplate(`{{.ToolName}}: {{.Message}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := custom.WithSeverityPrefixes(ps).Body(c), "tool: [WARNING] msg"; got != want {
+		t.Errorf("Body() with template = %q, want %q", got, want)
+	}
+}
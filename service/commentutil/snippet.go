@@ -0,0 +1,89 @@
+package commentutil
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+// Snippet returns a fenced code block of the source lines of the comment. It
+// includes at most maxLines lines from the start line of the diagnostic. The
+// lines are taken from the diff if available, or read from the local checkout.
+// It returns empty string if the lines cannot be read.
+func Snippet(c *reviewdog.Comment, maxLines int) string {
+	rng := c.Result.Diagnostic.GetLocation().GetRange()
+	start := int(rng.GetStart().GetLine())
+	if maxLines <= 0 || start <= 0 {
+		return ""
+	}
+	end := int(rng.GetEnd().GetLine())
+	if end < start {
+		end = start
+	}
+	if end-start+1 > maxLines {
+		end = start + maxLines - 1
+	}
+	lines := sourceLines(c, start, end)
+	if len(lines) == 0 {
+		return ""
+	}
+	code := strings.Join(lines, "\n")
+	var sb strings.Builder
+	fence := GetCodeFenceLength(code)
+	WriteCodeFence(&sb, fence)
+	sb.WriteString("\n")
+	sb.WriteString(code)
+	sb.WriteString("\n")
+	WriteCodeFence(&sb, fence)
+	return sb.String()
+}
+
+// sourceLines returns lines from start to end (inclusive) of the file of the
+// comment. It returns nil if any of the lines cannot be read.
+func sourceLines(c *reviewdog.Comment, start, end int) []string {
+	lines := make([]string, 0, end-start+1)
+	for l := start; l <= end; l++ {
+		line, ok := c.Result.SourceLines[l]
+		if !ok {
+			break
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == end-start+1 {
+		return lines
+	}
+	f, err := openSource(c.Result.Diagnostic.GetLocation().GetPath())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	lines = lines[:0]
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1024*1024)
+	for lnum := 1; lnum <= end && s.Scan(); lnum++ {
+		if lnum >= start {
+			lines = append(lines, s.Text())
+		}
+	}
+	if len(lines) != end-start+1 {
+		return nil
+	}
+	return lines
+}
+
+// openSource opens the file of the path. Services may have converted the
+// path to be relative to the repository root, so it also tries the path
+// relative to the current directory.
+func openSource(path string) (*os.File, error) {
+	f, err := os.Open(path)
+	if err == nil {
+		return f, nil
+	}
+	if wd, werr := serviceutil.GitRelWorkdir(); werr == nil && wd != "" && strings.HasPrefix(path, wd) {
+		return os.Open(strings.TrimPrefix(path, wd))
+	}
+	return nil, err
+}
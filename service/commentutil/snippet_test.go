@@ -0,0 +1,117 @@
+package commentutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestSnippet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.go")
+	if err := os.WriteFile(path, []byte("line1\nline2\nline3\n```\nline5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	newComment := func(path string, start, end int32, sourceLines map[int]string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path: path,
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: start},
+							End:   &rdf.Position{Line: end},
+						},
+					},
+				},
+				SourceLines: sourceLines,
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		c        *reviewdog.Comment
+		maxLines int
+		want     string
+	}{
+		{
+			name:     "single line",
+			c:        newComment(path, 2, 0, nil),
+			maxLines: 5,
+			want:     "```\nline2\n```",
+		},
+		{
+			name:     "bounded lines",
+			c:        newComment(path, 1, 5, nil),
+			maxLines: 2,
+			want:     "```\nline1\nline2\n```",
+		},
+		{
+			name:     "code fence in lines",
+			c:        newComment(path, 3, 4, nil),
+			maxLines: 5,
+			want:     "````\nline3\n```\n````",
+		},
+		{
+			name:     "source lines of diff",
+			c:        newComment("not-found.go", 1, 1, map[int]string{1: "from diff"}),
+			maxLines: 5,
+			want:     "```\nfrom diff\n```",
+		},
+		{
+			name:     "unreadable file",
+			c:        newComment("not-found.go", 1, 1, nil),
+			maxLines: 5,
+			want:     "",
+		},
+		{
+			name:     "out of range",
+			c:        newComment(path, 10, 10, nil),
+			maxLines: 5,
+			want:     "",
+		},
+		{
+			name:     "no line",
+			c:        newComment(path, 0, 0, nil),
+			maxLines: 5,
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		if got := Snippet(tt.c, tt.maxLines); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTemplate_WithSnippet(t *testing.T) {
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Message: "msg",
+				Location: &rdf.Location{
+					Path:  "a.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+				},
+			},
+			SourceLines: map[int]string{1: "code"},
+		},
+	}
+	var nilTmpl *Template
+	if got, want := nilTmpl.WithSnippet(3).Body(c), MarkdownComment(c)+"\n\n```\ncode\n```"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	tmpl, err := ParseTemplate(`{{.Message}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tmpl.WithSnippet(3).Body(c), "msg\n\n```\ncode\n```"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := tmpl.Body(c), "msg"; got != want {
+		t.Errorf("WithSnippet should not modify the original template: got %q, want %q", got, want)
+	}
+}
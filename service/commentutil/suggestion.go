@@ -0,0 +1,49 @@
+package commentutil
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// LineBasedSuggestionText returns the text which replaces whole lines of the
+// suggestion range, for code review services whose suggestions replace whole
+// lines. Suggestions with columns are expanded with the source lines of the
+// comment, and it returns an error if they are not available.
+func LineBasedSuggestionText(c *reviewdog.Comment, s *rdf.Suggestion) (string, error) {
+	start := s.GetRange().GetStart()
+	end := s.GetRange().GetEnd()
+	if start.GetColumn() <= 0 && end.GetColumn() <= 0 {
+		return s.GetText(), nil
+	}
+	sourceLines := c.Result.SourceLines
+	if len(sourceLines) == 0 {
+		return "", errors.New("source lines are not available")
+	}
+	startLineContent, err := getSourceLine(sourceLines, int(start.GetLine()))
+	if err != nil {
+		return "", err
+	}
+	endLineContent, err := getSourceLine(sourceLines, int(end.GetLine()))
+	if err != nil {
+		return "", err
+	}
+	return startLineContent[:max(start.GetColumn()-1, 0)] + s.GetText() + endLineContent[max(end.GetColumn()-1, 0):], nil
+}
+
+func getSourceLine(sourceLines map[int]string, line int) (string, error) {
+	lineContent, ok := sourceLines[line]
+	if !ok {
+		return "", fmt.Errorf("source line (L=%d) is not available for this suggestion", line)
+	}
+	return lineContent, nil
+}
+
+func max(x, y int32) int32 {
+	if x < y {
+		return y
+	}
+	return x
+}
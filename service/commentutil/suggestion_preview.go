@@ -0,0 +1,74 @@
+package commentutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// SuggestionPreview returns a fenced diff code block which shows the source
+// lines replaced by the suggestions of the comment and their replacements, so
+// that reviewers can see the change on services where suggestions aren't
+// shown natively or cannot be applied. Each side of each suggestion includes
+// at most maxLines lines. Suggestions whose source lines cannot be read are
+// skipped, and it returns empty string if no suggestions can be shown.
+func SuggestionPreview(c *reviewdog.Comment, maxLines int) string {
+	if maxLines <= 0 {
+		return ""
+	}
+	var hunks []string
+	for _, s := range c.Result.Diagnostic.GetSuggestions() {
+		if hunk, ok := suggestionHunk(c, s, maxLines); ok {
+			hunks = append(hunks, hunk)
+		}
+	}
+	if len(hunks) == 0 {
+		return ""
+	}
+	// All the lines of hunks are prefixed, so they cannot close the fence.
+	return "```diff\n" + strings.Join(hunks, "\n") + "\n```"
+}
+
+// suggestionHunk returns a unified diff hunk of the suggestion. It returns
+// false if the source lines of the suggestion range are not available.
+func suggestionHunk(c *reviewdog.Comment, s *rdf.Suggestion, maxLines int) (string, bool) {
+	start := int(s.GetRange().GetStart().GetLine())
+	end := int(s.GetRange().GetEnd().GetLine())
+	if start <= 0 {
+		return "", false
+	}
+	if end < start {
+		end = start
+	}
+	text, err := LineBasedSuggestionText(c, s)
+	if err != nil {
+		return "", false
+	}
+	old := sourceLines(c, start, end)
+	if old == nil {
+		return "", false
+	}
+	var replacement []string
+	if text != "" {
+		replacement = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@", start, len(old), start, len(replacement))
+	writeHunkLines(&sb, "-", old, maxLines)
+	writeHunkLines(&sb, "+", replacement, maxLines)
+	return sb.String(), true
+}
+
+// writeHunkLines writes at most maxLines lines with the prefix and notes the
+// number of the other lines.
+func writeHunkLines(sb *strings.Builder, prefix string, lines []string, maxLines int) {
+	for i, line := range lines {
+		if i == maxLines {
+			fmt.Fprintf(sb, "\n ... %d more line(s)", len(lines)-i)
+			return
+		}
+		sb.WriteString("\n" + prefix + line)
+	}
+}
@@ -0,0 +1,92 @@
+package commentutil
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestSuggestionPreview(t *testing.T) {
+	sourceLines := map[int]string{1: "line1", 2: "line2", 3: "line3"}
+	newComment := func(suggestions ...*rdf.Suggestion) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location:    &rdf.Location{Path: "not-found.go"},
+					Suggestions: suggestions,
+				},
+				SourceLines: sourceLines,
+			},
+		}
+	}
+	newSuggestion := func(start, end int32, text string) *rdf.Suggestion {
+		return &rdf.Suggestion{
+			Range: &rdf.Range{Start: &rdf.Position{Line: start}, End: &rdf.Position{Line: end}},
+			Text:  text,
+		}
+	}
+	tests := []struct {
+		name     string
+		c        *reviewdog.Comment
+		maxLines int
+		want     string
+	}{
+		{
+			name:     "single line",
+			c:        newComment(newSuggestion(2, 2, "line 2")),
+			maxLines: 5,
+			want:     "```diff\n@@ -2,1 +2,1 @@\n-line2\n+line 2\n```",
+		},
+		{
+			name:     "multiline",
+			c:        newComment(newSuggestion(1, 3, "a\nb\n")),
+			maxLines: 5,
+			want:     "```diff\n@@ -1,3 +1,2 @@\n-line1\n-line2\n-line3\n+a\n+b\n```",
+		},
+		{
+			name:     "bounded lines",
+			c:        newComment(newSuggestion(1, 3, "a\nb\nc")),
+			maxLines: 1,
+			want:     "```diff\n@@ -1,3 +1,3 @@\n-line1\n ... 2 more line(s)\n+a\n ... 2 more line(s)\n```",
+		},
+		{
+			name: "columns",
+			c: newComment(&rdf.Suggestion{
+				Range: &rdf.Range{Start: &rdf.Position{Line: 1, Column: 5}, End: &rdf.Position{Line: 1, Column: 6}},
+				Text:  "_one",
+			}),
+			maxLines: 5,
+			want:     "```diff\n@@ -1,1 +1,1 @@\n-line1\n+line_one\n```",
+		},
+		{
+			name:     "deletion and multiple suggestions",
+			c:        newComment(newSuggestion(1, 1, ""), newSuggestion(3, 3, "```")),
+			maxLines: 5,
+			// Prefixed lines cannot close the code fence.
+			want: "```diff\n@@ -1,1 +1,0 @@\n-line1\n@@ -3,1 +3,1 @@\n-line3\n+```\n```",
+		},
+		{
+			name:     "source lines are not available",
+			c:        newComment(newSuggestion(5, 5, "x")),
+			maxLines: 5,
+		},
+		{
+			name: "no suggestions",
+			c:    newComment(),
+		},
+		{
+			name:     "disabled",
+			c:        newComment(newSuggestion(2, 2, "line 2")),
+			maxLines: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SuggestionPreview(tt.c, tt.maxLines); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package commentutil
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestLineBasedSuggestionText(t *testing.T) {
+	c := &reviewdog.Comment{Result: &filter.FilteredDiagnostic{
+		Diagnostic:  &rdf.Diagnostic{},
+		SourceLines: map[int]string{1: "x := foo(bar)", 2: "y := 1"},
+	}}
+	lines := func(start, end int32) *rdf.Range {
+		return &rdf.Range{Start: &rdf.Position{Line: start}, End: &rdf.Position{Line: end}}
+	}
+	cols := func(startLine, startCol, endLine, endCol int32) *rdf.Range {
+		return &rdf.Range{
+			Start: &rdf.Position{Line: startLine, Column: startCol},
+			End:   &rdf.Position{Line: endLine, Column: endCol},
+		}
+	}
+	tests := []struct {
+		s       *rdf.Suggestion
+		want    string
+		wantErr bool
+	}{
+		{s: &rdf.Suggestion{Range: lines(1, 2), Text: "z"}, want: "z"},
+		{s: &rdf.Suggestion{Range: cols(1, 6, 1, 9), Text: "baz"}, want: "x := baz(bar)"},
+		{s: &rdf.Suggestion{Range: cols(1, 9, 2, 2), Text: "()\nz"}, want: "x := foo()\nz := 1"},
+		{s: &rdf.Suggestion{Range: cols(3, 1, 3, 2), Text: "z"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := LineBasedSuggestionText(c, tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LineBasedSuggestionText(%v) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("LineBasedSuggestionText(%v) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
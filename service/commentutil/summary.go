@@ -0,0 +1,161 @@
+package commentutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// CommentMode represents how comment services report results.
+type CommentMode int
+
+const (
+	// CommentModeInline posts results as inline comments.
+	CommentModeInline CommentMode = iota
+	// CommentModeSummary posts one summary comment of results instead of
+	// inline comments.
+	CommentModeSummary
+	// CommentModeBoth posts both inline comments and a summary comment.
+	CommentModeBoth
+)
+
+// String implements the flag.Value interface
+func (mode *CommentMode) String() string {
+	names := [...]string{
+		"inline",
+		"summary",
+		"both",
+	}
+	if *mode < CommentModeInline || *mode > CommentModeBoth {
+		return "Unknown comment mode"
+	}
+	return names[*mode]
+}
+
+// Set implements the flag.Value interface
+func (mode *CommentMode) Set(value string) error {
+	switch value {
+	case "inline", "":
+		*mode = CommentModeInline
+	case "summary":
+		*mode = CommentModeSummary
+	case "both":
+		*mode = CommentModeBoth
+	default:
+		return fmt.Errorf("invalid comment mode: %s", value)
+	}
+	return nil
+}
+
+// Inline returns true if inline comments should be posted.
+func (mode CommentMode) Inline() bool {
+	return mode == CommentModeInline || mode == CommentModeBoth
+}
+
+// Summary returns true if a summary comment should be posted.
+func (mode CommentMode) Summary() bool {
+	return mode == CommentModeSummary || mode == CommentModeBoth
+}
+
+// NoResultsMessage is the message which tells that reviewdog ran and found no
+// results. Comment services post it if they are configured to report runs
+// without results, so that reviewers can tell them from runs which didn't
+// happen. NoResultsComment is the markdown comment of it.
+const (
+	NoResultsMessage = "reviewdog found no results."
+	NoResultsComment = BodyPrefix + NoResultsMessage + "\n"
+)
+
+// SummaryComment creates markdown of a summary comment which groups results by
+// file and severity. It returns empty string if there are no comments.
+func SummaryComment(comments []*reviewdog.Comment) string {
+	if len(comments) == 0 {
+		return ""
+	}
+	perFile := make(map[string][]*reviewdog.Comment)
+	var files []string
+	for _, c := range comments {
+		path := c.Result.Diagnostic.GetLocation().GetPath()
+		if _, ok := perFile[path]; !ok {
+			files = append(files, path)
+		}
+		perFile[path] = append(perFile[path], c)
+	}
+	sort.Strings(files)
+
+	var sb strings.Builder
+	sb.WriteString(BodyPrefix)
+	sb.WriteString(fmt.Sprintf("**%d result(s)** in %d file(s): %s\n", len(comments), len(files), SeverityCounts(comments)))
+	for _, path := range files {
+		cs := perFile[path]
+		sort.SliceStable(cs, func(i, j int) bool {
+			si, sj := severityOrder(cs[i]), severityOrder(cs[j])
+			if si != sj {
+				return si < sj
+			}
+			return cs[i].Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine() <
+				cs[j].Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine()
+		})
+		sb.WriteString("\n<details>\n")
+		sb.WriteString(fmt.Sprintf("<summary>%s (%s)</summary>\n\n", path, SeverityCounts(cs)))
+		for _, c := range cs {
+			sb.WriteString("- ")
+			if s := severity(c); s != "" {
+				sb.WriteString(s)
+				sb.WriteString(" ")
+			}
+			if line := c.Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine(); line > 0 {
+				sb.WriteString(fmt.Sprintf("L%d: ", line))
+			}
+			if tool := toolName(c); tool != "" {
+				sb.WriteString(fmt.Sprintf("**[%s]** ", tool))
+			}
+			// Keep each result in one list item.
+			sb.WriteString(strings.ReplaceAll(c.Result.Diagnostic.GetMessage(), "\n", " "))
+			sb.WriteString("\n")
+		}
+		sb.WriteString("</details>\n")
+	}
+	return sb.String()
+}
+
+// SeverityCounts returns the numbers of comments per severity. e.g.
+// "1 error, 2 warning".
+func SeverityCounts(comments []*reviewdog.Comment) string {
+	counts := make(map[rdf.Severity]int)
+	for _, c := range comments {
+		counts[c.Result.Diagnostic.GetSeverity()]++
+	}
+	var parts []string
+	for _, s := range []struct {
+		severity rdf.Severity
+		name     string
+	}{
+		{rdf.Severity_ERROR, "error"},
+		{rdf.Severity_WARNING, "warning"},
+		{rdf.Severity_INFO, "info"},
+		{rdf.Severity_UNKNOWN_SEVERITY, "other"},
+	} {
+		if n := counts[s.severity]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, s.name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// severityOrder returns sort order of comments from high severity.
+func severityOrder(c *reviewdog.Comment) int {
+	switch c.Result.Diagnostic.GetSeverity() {
+	case rdf.Severity_ERROR:
+		return 0
+	case rdf.Severity_WARNING:
+		return 1
+	case rdf.Severity_INFO:
+		return 2
+	default:
+		return 3
+	}
+}
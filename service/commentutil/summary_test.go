@@ -0,0 +1,81 @@
+package commentutil
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestSummaryComment(t *testing.T) {
+	newComment := func(path string, line int32, severity rdf.Severity, msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: "tool",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  path,
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Severity: severity,
+					Message:  msg,
+				},
+			},
+		}
+	}
+	comments := []*reviewdog.Comment{
+		newComment("b.go", 3, rdf.Severity_WARNING, "warning"),
+		newComment("a.go", 10, rdf.Severity_INFO, "info"),
+		newComment("a.go", 20, rdf.Severity_ERROR, "multiline\nerror"),
+		newComment("b.go", 1, rdf.Severity_UNKNOWN_SEVERITY, "unknown"),
+	}
+	want := BodyPrefix + `**4 result(s)** in 2 file(s): 1 error, 1 warning, 1 info, 1 other
+
+<details>
+<summary>a.go (1 error, 1 info)</summary>
+
+- 🚫 L20: **[tool]** multiline error
+- 📝 L10: **[tool]** info
+</details>
+
+<details>
+<summary>b.go (1 warning, 1 other)</summary>
+
+- ⚠️ L3: **[tool]** warning
+- L1: **[tool]** unknown
+</details>
+`
+	if got := SummaryComment(comments); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got := SummaryComment(nil); got != "" {
+		t.Errorf("got %q for no comments, want empty", got)
+	}
+}
+
+func TestCommentMode(t *testing.T) {
+	tests := []struct {
+		in          string
+		wantInline  bool
+		wantSummary bool
+	}{
+		{in: "", wantInline: true},
+		{in: "inline", wantInline: true},
+		{in: "summary", wantSummary: true},
+		{in: "both", wantInline: true, wantSummary: true},
+	}
+	for _, tt := range tests {
+		var mode CommentMode
+		if err := mode.Set(tt.in); err != nil {
+			t.Fatal(err)
+		}
+		if mode.Inline() != tt.wantInline || mode.Summary() != tt.wantSummary {
+			t.Errorf("%q: Inline()=%v, Summary()=%v, want %v, %v", tt.in, mode.Inline(), mode.Summary(), tt.wantInline, tt.wantSummary)
+		}
+	}
+	var mode CommentMode
+	if err := mode.Set("unknown"); err == nil {
+		t.Error("got no error for unknown comment mode")
+	}
+}
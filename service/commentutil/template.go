@@ -0,0 +1,199 @@
+package commentutil
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// Template is a text/template based comment body template.
+type Template struct {
+	tmpl *template.Template
+
+	// snippetLines is the max number of source lines appended to comment
+	// bodies. 0 means no snippets.
+	snippetLines int
+	// suggestionPreviewLines is the max number of lines of each side of
+	// suggestion previews appended to comment bodies. 0 means no previews.
+	suggestionPreviewLines int
+	// maxMessageLength is the max length of diagnostic messages in bytes.
+	// Longer messages are truncated. 0 means no limit.
+	maxMessageLength int
+	// severityPrefixes are prefixed to diagnostic messages. They replace the
+	// severity emoji of MarkdownComment.
+	severityPrefixes SeverityPrefixes
+}
+
+// TemplateData is data which comment templates are executed with.
+type TemplateData struct {
+	Comment    *reviewdog.Comment
+	Diagnostic *rdf.Diagnostic
+
+	// ToolName is the source name of the diagnostic, or the tool name if the
+	// diagnostic doesn't have one.
+	ToolName string
+	// Severity is the severity emoji (e.g. 🚫) or empty.
+	Severity string
+	Message  string
+	Path     string
+	Line     int32
+	Code     string
+	CodeURL  string
+	// Labels are labels of the result (e.g. {{.Labels.team}}). Missing labels
+	// are empty strings.
+	Labels map[string]string
+	// BodyPrefix is the "reported by reviewdog" text. Include it to let
+	// reviewdog recognize its own comments (e.g. to resolve stale ones).
+	BodyPrefix string
+}
+
+// ParseTemplate parses text as a comment template. It also executes the
+// template with an empty comment so that templates referring to unknown
+// fields fail here instead of on posting comments.
+func ParseTemplate(text string) (*Template, error) {
+	tmpl, err := template.New("comment").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse comment template: %w", err)
+	}
+	t := &Template{tmpl: tmpl}
+	empty := &reviewdog.Comment{Result: &filter.FilteredDiagnostic{Diagnostic: &rdf.Diagnostic{}}}
+	if _, err := t.Execute(empty); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Execute executes the template with the comment.
+func (t *Template) Execute(c *reviewdog.Comment) (string, error) {
+	d := c.Result.Diagnostic
+	data := &TemplateData{
+		Comment:    c,
+		Diagnostic: d,
+		ToolName:   toolName(c),
+		Severity:   severity(c),
+		Message:    t.Message(c),
+		Path:       d.GetLocation().GetPath(),
+		Line:       d.GetLocation().GetRange().GetStart().GetLine(),
+		Code:       d.GetCode().GetValue(),
+		CodeURL:    d.GetCode().GetUrl(),
+		Labels:     c.Result.Labels,
+		BodyPrefix: BodyPrefix,
+	}
+	var sb strings.Builder
+	if err := t.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to execute comment template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// WithSnippet returns a copy of t which appends at most maxLines source lines
+// of the diagnostic to comment bodies as a code block. t can be nil to build
+// bodies with MarkdownComment.
+func (t *Template) WithSnippet(maxLines int) *Template {
+	nt := t.clone()
+	nt.snippetLines = maxLines
+	return nt
+}
+
+// WithSuggestionPreview returns a copy of t which appends a diff of the source
+// lines and the replacements of suggestions of the diagnostic to comment bodies
+// with SuggestionPreview. It's in addition to native suggestions of services.
+// t can be nil to build bodies with MarkdownComment.
+func (t *Template) WithSuggestionPreview(maxLines int) *Template {
+	nt := t.clone()
+	nt.suggestionPreviewLines = maxLines
+	return nt
+}
+
+// WithMaxMessageLength returns a copy of t which truncates diagnostic
+// messages longer than maxLen bytes with TruncateMessage. t can be nil to
+// build bodies with MarkdownComment.
+func (t *Template) WithMaxMessageLength(maxLen int) *Template {
+	nt := t.clone()
+	nt.maxMessageLength = maxLen
+	return nt
+}
+
+// WithSeverityPrefixes returns a copy of t which prefixes diagnostic messages
+// with the markers of their severities. Bodies built with MarkdownComment use
+// the markers instead of the default severity emojis. t can be nil to build
+// bodies with MarkdownComment.
+func (t *Template) WithSeverityPrefixes(ps SeverityPrefixes) *Template {
+	nt := t.clone()
+	nt.severityPrefixes = ps
+	return nt
+}
+
+func (t *Template) clone() *Template {
+	if t == nil {
+		return &Template{}
+	}
+	nt := *t
+	return &nt
+}
+
+// Message returns the diagnostic message of c truncated to the max message
+// length of t and prefixed with the severity prefix of t if any.
+func (t *Template) Message(c *reviewdog.Comment) string {
+	msg := c.Result.Diagnostic.GetMessage()
+	if t == nil {
+		return msg
+	}
+	msg = TruncateMessage(msg, t.maxMessageLength)
+	return t.severityPrefixes.Prefix(msg, c.Result.Diagnostic.GetSeverity())
+}
+
+// IsDefault returns true if t doesn't have template text, which means
+// bodies are built with MarkdownComment.
+func (t *Template) IsDefault() bool {
+	return t == nil || t.tmpl == nil
+}
+
+// Body returns the comment body built with the template. It returns
+// MarkdownComment(c) if t doesn't have template text or the template fails.
+// Locations of collapsed duplicates are appended to the body.
+func (t *Template) Body(c *reviewdog.Comment) string {
+	if t.IsDefault() {
+		return t.AppendSnippet(appendDuplicates(t.markdownComment(c), c), c)
+	}
+	body, err := t.Execute(c)
+	if err != nil {
+		log.Printf("reviewdog: %v", err)
+		body = t.markdownComment(c)
+	}
+	return t.AppendSnippet(appendDuplicates(body, c), c)
+}
+
+func (t *Template) markdownComment(c *reviewdog.Comment) string {
+	// Severity prefixes replace the severity emoji not to show both.
+	withSeverity := t == nil || t.severityPrefixes == nil
+	return markdownComment(c, t.Message(c), withSeverity)
+}
+
+func appendDuplicates(body string, c *reviewdog.Comment) string {
+	if dups := DuplicateLocations(c); dups != "" {
+		return body + "\n\n" + dups
+	}
+	return body
+}
+
+// AppendSnippet appends the source code snippet of the comment to body if t
+// is configured with WithSnippet, and the suggestion preview if t is
+// configured with WithSuggestionPreview.
+func (t *Template) AppendSnippet(body string, c *reviewdog.Comment) string {
+	if t == nil {
+		return body
+	}
+	if snippet := Snippet(c, t.snippetLines); snippet != "" {
+		body += "\n\n" + snippet
+	}
+	if preview := SuggestionPreview(c, t.suggestionPreviewLines); preview != "" {
+		body += "\n\n" + preview
+	}
+	return body
+}
@@ -0,0 +1,65 @@
+package commentutil
+
+import (
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestTemplate_Body(t *testing.T) {
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Message: "msg",
+				Location: &rdf.Location{
+					Path:  "a.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+				},
+				Severity: rdf.Severity_ERROR,
+				Code:     &rdf.Code{Value: "SA1000", Url: "https://example.com/SA1000"},
+			},
+		},
+		ToolName: "tool",
+	}
+	tmpl, err := ParseTemplate(`{{.Severity}} [{{.ToolName}}] {{.Path}}:{{.Line}} {{.Message}} (see {{.CodeURL}})`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tmpl.Body(c), "🚫 [tool] a.go:14 msg (see https://example.com/SA1000)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	var nilTmpl *Template
+	if got, want := nilTmpl.Body(c), MarkdownComment(c); got != want {
+		t.Errorf("nil template: got %q, want %q", got, want)
+	}
+}
+
+func TestTemplate_Body_labels(t *testing.T) {
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{Message: "msg"},
+			Labels:     map[string]string{"team": "backend"},
+		},
+	}
+	tmpl, err := ParseTemplate(`{{.Message}} (owner: {{.Labels.team}}{{.Labels.missing}}{{with .Labels.env}}, env: {{.}}{{end}})`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tmpl.Body(c), "msg (owner: backend)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseTemplate_invalid(t *testing.T) {
+	for _, text := range []string{
+		`{{.Message`,
+		`{{.UnknownField}}`,
+	} {
+		if _, err := ParseTemplate(text); err == nil {
+			t.Errorf("ParseTemplate(%q) got no error", text)
+		}
+	}
+}
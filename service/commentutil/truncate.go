@@ -0,0 +1,36 @@
+package commentutil
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+const ellipsis = "…"
+
+// TruncateMessage caps msg at maxLen bytes. If msg is longer than that, it's
+// cut at a rune boundary and an ellipsis and a note about the original length
+// are appended, all within maxLen bytes. The note is omitted if maxLen is too
+// small for it. maxLen <= 0 means no limit.
+func TruncateMessage(msg string, maxLen int) string {
+	if maxLen <= 0 || len(msg) <= maxLen {
+		return msg
+	}
+	suffix := ellipsis + truncatedNote(len(msg))
+	if len(suffix) > maxLen {
+		return cutAtRune(msg, maxLen)
+	}
+	return cutAtRune(msg, maxLen-len(suffix)) + suffix
+}
+
+// cutAtRune returns the longest prefix of s which is at most n bytes and
+// doesn't split a rune.
+func cutAtRune(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func truncatedNote(origLen int) string {
+	return fmt.Sprintf("\n\n(message truncated by reviewdog: original length is %d bytes)", origLen)
+}
@@ -0,0 +1,100 @@
+package commentutil
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestTruncateMessage(t *testing.T) {
+	long := strings.Repeat("a", 10000)
+	tests := []struct {
+		name   string
+		msg    string
+		maxLen int
+		want   string
+	}{
+		{
+			name:   "short message",
+			msg:    "msg",
+			maxLen: 100,
+			want:   "msg",
+		},
+		{
+			name:   "no limit",
+			msg:    long,
+			maxLen: 0,
+			want:   long,
+		},
+		{
+			name:   "oversized message",
+			msg:    long,
+			maxLen: 1000,
+			want:   long[:1000-len(ellipsis+truncatedNote(10000))] + "…\n\n(message truncated by reviewdog: original length is 10000 bytes)",
+		},
+		{
+			name:   "limit smaller than note",
+			msg:    long,
+			maxLen: 10,
+			want:   "aaaaaaaaaa",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateMessage(tt.msg, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("TruncateMessage() = %q, want %q", got, tt.want)
+			}
+			if tt.maxLen > 0 && len(got) > tt.maxLen {
+				t.Errorf("len(TruncateMessage()) = %d, want <= %d", len(got), tt.maxLen)
+			}
+		})
+	}
+}
+
+func TestTruncateMessage_multibyte(t *testing.T) {
+	msg := strings.Repeat("犬", 1000)
+	got := TruncateMessage(msg, 500)
+	if len(got) > 500 {
+		t.Errorf("len(TruncateMessage()) = %d, want <= 500", len(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("TruncateMessage() splits a rune: %q", got)
+	}
+}
+
+func TestTemplate_WithMaxMessageLength(t *testing.T) {
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Message: strings.Repeat("x", 70000),
+			},
+		},
+		ToolName: "tool",
+	}
+	tmpl, err := ParseTemplate("{{.Message}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name string
+		tmpl *Template
+	}{
+		{name: "default", tmpl: (*Template)(nil).WithMaxMessageLength(60000)},
+		{name: "template", tmpl: tmpl.WithMaxMessageLength(60000)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.tmpl.Body(c)
+			if len(body) > 60000+len(BodyPrefix)+len("**[tool]** ") {
+				t.Errorf("body is not truncated: len = %d", len(body))
+			}
+			if !strings.Contains(body, "…\n\n(message truncated by reviewdog: original length is 70000 bytes)") {
+				t.Errorf("body doesn't have the truncation note: %q", body[len(body)-100:])
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package gerrit
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"golang.org/x/build/gerrit"
@@ -20,27 +22,81 @@ var _ reviewdog.DiffService = &ChangeDiff{}
 
 // ChangeDiff is a diff service for Gerrit changes.
 type ChangeDiff struct {
-	cli      *gerrit.Client
+	cli      Client
 	changeID string
 	branch   string
 
 	// wd is working directory relative to root of repository.
 	wd string
+
+	// baseRevision is a revision to diff the target revision against.
+	// Empty means the merge-base of the branch and the target revision.
+	baseRevision string
+
+	// targetRevision is a revision to diff. Empty means the current revision.
+	targetRevision string
+
+	// workdir is the directory to run git commands in. Empty means the
+	// current directory.
+	workdir string
+}
+
+// ChangeDiffOption is an option for NewChangeDiff.
+type ChangeDiffOption func(*ChangeDiff)
+
+// WithBaseRevision makes ChangeDiff diff the current revision against the
+// given revision instead of the merge-base of the branch and the current
+// revision. rev is a commit or a patchset number (e.g. "3") of the change.
+func WithBaseRevision(rev string) ChangeDiffOption {
+	return func(g *ChangeDiff) {
+		g.baseRevision = rev
+	}
+}
+
+// WithTargetRevision makes ChangeDiff diff the given revision instead of the
+// current revision. rev is a commit or a patchset number of the change. With
+// WithBaseRevision, it diffs between two patchsets so that only changes
+// between them are reviewed.
+func WithTargetRevision(rev string) ChangeDiffOption {
+	return func(g *ChangeDiff) {
+		g.targetRevision = rev
+	}
+}
+
+// WithDiffWorkdir makes ChangeDiff run git commands in dir instead of the
+// current directory. dir must be inside the git repository.
+func WithDiffWorkdir(dir string) ChangeDiffOption {
+	return func(g *ChangeDiff) {
+		g.workdir = dir
+	}
 }
 
 // NewChangeDiff returns a new ChangeDiff service,
 // it needs git command in $PATH.
-func NewChangeDiff(cli *gerrit.Client, branch, changeID string) (*ChangeDiff, error) {
-	workDir, err := serviceutil.GitRelWorkdir()
-	if err != nil {
-		return nil, fmt.Errorf("ChangeDiff needs 'git' command: %w", err)
-	}
-	return &ChangeDiff{
+func NewChangeDiff(cli Client, branch, changeID string, opts ...ChangeDiffOption) (*ChangeDiff, error) {
+	g := &ChangeDiff{
 		cli:      cli,
 		branch:   branch,
 		changeID: changeID,
-		wd:       workDir,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	workDir, err := gitRelWorkdir(g.workdir)
+	if err != nil {
+		return nil, fmt.Errorf("ChangeDiff needs 'git' command: %w", err)
+	}
+	g.wd = workDir
+	return g, nil
+}
+
+// gitRelWorkdir returns git relative path of dir, or of the current directory
+// if dir is empty.
+func gitRelWorkdir(dir string) (string, error) {
+	if dir == "" {
+		return serviceutil.GitRelWorkdir()
+	}
+	return serviceutil.GitRelDir(dir)
 }
 
 // Diff returns a diff of MergeRequest. It runs `git diff` locally instead of
@@ -49,28 +105,119 @@ func NewChangeDiff(cli *gerrit.Client, branch, changeID string) (*ChangeDiff, er
 // `git diff --no-renames`, we want diff which is equivalent to
 // `git diff --find-renames`.
 func (g *ChangeDiff) Diff(ctx context.Context) ([]byte, error) {
+	field := "CURRENT_REVISION"
+	if isPatchSetNumber(g.baseRevision) || isPatchSetNumber(g.targetRevision) {
+		field = "ALL_REVISIONS"
+	}
 	change, err := g.cli.GetChangeDetail(ctx, g.changeID, gerrit.QueryChangesOpt{
-		Fields: []string{"CURRENT_REVISION"},
+		Fields: []string{field},
 	})
 	if err != nil {
 		return nil, err
 	}
-	return g.gitDiff(ctx, change.CurrentRevision, g.branch)
+	target := change.CurrentRevision
+	if g.targetRevision != "" {
+		if target, err = g.resolveRevision(ctx, change, g.targetRevision); err != nil {
+			return nil, err
+		}
+	}
+	base := g.baseRevision
+	if base != "" {
+		if base, err = g.resolveRevision(ctx, change, base); err != nil {
+			return nil, err
+		}
+	}
+	return g.gitDiff(ctx, target, g.branch, base)
 }
 
-func (g *ChangeDiff) gitDiff(_ context.Context, baseSha, targetSha string) ([]byte, error) {
-	b, err := exec.Command("git", "merge-base", targetSha, baseSha).Output() // #nosec
+// resolveRevision returns the commit of the revision, which is a commit or a
+// patchset number of the change. It validates that the revision exists.
+func (g *ChangeDiff) resolveRevision(ctx context.Context, change *gerrit.ChangeInfo, rev string) (string, error) {
+	if isPatchSetNumber(rev) {
+		n, _ := strconv.Atoi(rev)
+		for commit, r := range change.Revisions {
+			if r.PatchSetNumber == n {
+				return commit, nil
+			}
+		}
+		return "", fmt.Errorf("patchset %d not found in change %s", n, g.changeID)
+	}
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--quiet", "--verify", rev+"^{commit}") // #nosec
+	cmd.Dir = g.workdir
+	b, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get merge-base commit: %w", err)
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", serviceutil.ErrGitNotFound
+		}
+		return "", fmt.Errorf("revision %q not found in the local repository. Fetch it first: %w", rev, err)
 	}
-	mergeBase := strings.Trim(string(b), "\n")
-	bytes, err := exec.Command("git", "diff", "--find-renames", mergeBase, baseSha).Output()
+	return strings.TrimSpace(string(b)), nil
+}
+
+// isPatchSetNumber returns true if rev is a patchset number rather than a
+// commit. Commits are given as at least 4 hex digits, so short numbers are
+// patchset numbers.
+func isPatchSetNumber(rev string) bool {
+	if rev == "" || len(rev) > 3 {
+		return false
+	}
+	n, err := strconv.Atoi(rev)
+	return err == nil && n > 0
+}
+
+// gitDiff returns the diff of the revision against base. Empty base means the
+// merge-base of the branch and the revision, or the empty tree if they have
+// no common ancestor (e.g. the revision is a root commit).
+func (g *ChangeDiff) gitDiff(ctx context.Context, revision, branch, base string) ([]byte, error) {
+	mergeBase := base
+	if mergeBase == "" {
+		var err error
+		if mergeBase, err = g.mergeBase(ctx, branch, revision); err != nil {
+			return nil, err
+		}
+	}
+	cmd := exec.CommandContext(ctx, "git", "diff", "--find-renames", mergeBase, revision)
+	cmd.Dir = g.workdir
+	bytes, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to run git diff: %w", err)
+		return nil, fmt.Errorf("failed to run git diff: %w", gitError(err))
 	}
 	return bytes, nil
 }
 
+// mergeBase returns the merge-base of the branch and the revision. It returns
+// the empty tree if they have no common ancestor so that the whole revision is
+// reviewed.
+func (g *ChangeDiff) mergeBase(ctx context.Context, branch, revision string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "merge-base", branch, revision) // #nosec
+	cmd.Dir = g.workdir
+	b, err := cmd.Output()
+	if err == nil {
+		return strings.Trim(string(b), "\n"), nil
+	}
+	// git merge-base exits with 1 without output if there is no common
+	// ancestor, and with 128 for unknown revisions.
+	var exitErr *exec.ExitError
+	if ctx.Err() != nil || !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		return "", fmt.Errorf("failed to get merge-base commit: %w", gitError(err))
+	}
+	cmd = exec.CommandContext(ctx, "git", "hash-object", "-t", "tree", "/dev/null")
+	cmd.Dir = g.workdir
+	if b, err = cmd.Output(); err != nil {
+		return "", fmt.Errorf("failed to get the empty tree: %w", gitError(err))
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// gitError converts an error of git command execution to
+// serviceutil.ErrGitNotFound if git command is not found.
+func gitError(err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return serviceutil.ErrGitNotFound
+	}
+	return err
+}
+
 // Strip returns 1 as a strip of git diff.
 func (g *ChangeDiff) Strip() int {
 	return stripDiffResult
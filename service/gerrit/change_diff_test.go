@@ -2,12 +2,20 @@ package gerrit
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/build/gerrit"
+
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 func TestChangeDiff_Diff(t *testing.T) {
@@ -39,3 +47,165 @@ func TestChangeDiff_Diff(t *testing.T) {
 		t.Errorf("Get GitLab MergeRequest API called %v times, want once", getChangeDetailAPICall)
 	}
 }
+
+func TestChangeDiff_Diff_baseRevision(t *testing.T) {
+	cli := &fakeClient{change: &gerrit.ChangeInfo{CurrentRevision: "HEAD"}}
+
+	// The branch doesn't exist, so merge-base must not be used.
+	g, err := NewChangeDiff(cli, "unknown-branch", "changeID", WithBaseRevision("HEAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := g.Diff(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d) != 0 {
+		t.Errorf("got non-empty diff between the same revisions: %s", d)
+	}
+}
+
+func TestChangeDiff_Diff_patchSets(t *testing.T) {
+	revParse := func(rev string) string {
+		b, err := exec.Command("git", "rev-parse", rev).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(b))
+	}
+	ps1, ps2 := revParse("HEAD^"), revParse("HEAD")
+	cli := &fakeClient{change: &gerrit.ChangeInfo{
+		CurrentRevision: ps2,
+		Revisions: map[string]gerrit.RevisionInfo{
+			ps1: {PatchSetNumber: 1},
+			ps2: {PatchSetNumber: 2},
+		},
+	}}
+	want, err := exec.Command("git", "diff", "--find-renames", ps1, ps2).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    []ChangeDiffOption
+		wantErr bool
+	}{
+		{name: "patchset numbers", opts: []ChangeDiffOption{WithBaseRevision("1"), WithTargetRevision("2")}},
+		{name: "base patchset against current", opts: []ChangeDiffOption{WithBaseRevision("1")}},
+		{name: "commits", opts: []ChangeDiffOption{WithBaseRevision(ps1), WithTargetRevision("HEAD")}},
+		{name: "unknown patchset", opts: []ChangeDiffOption{WithBaseRevision("3")}, wantErr: true},
+		{name: "unknown commit", opts: []ChangeDiffOption{WithTargetRevision("deadbeefdeadbeef")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewChangeDiff(cli, "unknown-branch", "changeID", tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := g.Diff(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Error("got no error for a revision which doesn't exist")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("got diff:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestIsPatchSetNumber(t *testing.T) {
+	for rev, want := range map[string]bool{"1": true, "12": true, "0": false, "": false, "abcd": false, "1234": false, "HEAD": false} {
+		if got := isPatchSetNumber(rev); got != want {
+			t.Errorf("isPatchSetNumber(%q) = %v, want %v", rev, got, want)
+		}
+	}
+}
+
+func TestChangeDiff_gitDiff_gitNotFound(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	g := &ChangeDiff{}
+	if _, err := g.gitDiff(context.Background(), "HEAD", "HEAD", ""); !errors.Is(err, serviceutil.ErrGitNotFound) {
+		t.Fatalf("gitDiff() error = %v, want %v", err, serviceutil.ErrGitNotFound)
+	}
+}
+
+func TestChangeDiff_gitDiff_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g := &ChangeDiff{}
+	done := make(chan error, 1)
+	go func() {
+		_, err := g.gitDiff(ctx, "HEAD", "HEAD", "")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("gitDiff() error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("gitDiff() didn't terminate after the context was canceled")
+	}
+}
+
+func TestChangeDiff_Diff_unrelatedHistories(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=reviewdog", "-c", "user.email=reviewdog@example.com"}, args...)...)
+		cmd.Dir = dir
+		b, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, b)
+		}
+		return strings.TrimSpace(string(b))
+	}
+	git("init", "--quiet")
+	git("commit", "--quiet", "--allow-empty", "-m", "base")
+	git("branch", "base")
+	git("checkout", "--quiet", "--orphan", "change")
+	if err := os.WriteFile(filepath.Join(dir, "file.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "file.go")
+	git("commit", "--quiet", "-m", "root")
+	root := git("rev-parse", "HEAD")
+
+	cli := &fakeClient{change: &gerrit.ChangeInfo{CurrentRevision: root}}
+	g, err := NewChangeDiff(cli, "base", "changeID", WithDiffWorkdir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := g.Diff(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "+++ b/file.go"; !strings.Contains(string(d), want) {
+		t.Errorf("got diff without %q:\n%s", want, d)
+	}
+
+	// Unknown branches are still errors.
+	g.branch = "unknown-branch"
+	if _, err := g.Diff(context.Background()); err == nil {
+		t.Error("got no error for a branch which doesn't exist")
+	}
+}
+
+func TestNewChangeDiff_workdir(t *testing.T) {
+	g, err := NewChangeDiff(nil, "master", "changeID", WithDiffWorkdir("../../cmd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "cmd/"; g.wd != want {
+		t.Errorf("wd = %q, want %q", g.wd, want)
+	}
+	if _, err := NewChangeDiff(nil, "master", "changeID", WithDiffWorkdir(t.TempDir())); !errors.Is(err, serviceutil.ErrNotGitRepo) {
+		t.Errorf("NewChangeDiff() error = %v, want %v", err, serviceutil.ErrNotGitRepo)
+	}
+}
@@ -2,24 +2,53 @@ package gerrit
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/build/gerrit"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/cienv"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
 	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 var _ reviewdog.CommentService = &ChangeReviewCommenter{}
+var _ reviewdog.GroupingCommentService = &ChangeReviewCommenter{}
+
+// DefaultBatchSize is the default max number of comments posted by a single
+// SetReview request.
+const DefaultBatchSize = 500
+
+// DefaultMaxMessageLength is the default max length of diagnostic messages in
+// comments. Gerrit rejects comments longer than change.commentSizeLimit,
+// which is 16 KiB by default.
+const DefaultMaxMessageLength = 15000
+
+// DefaultRetryCount and DefaultRetryDelay are the default retry settings for
+// transient SetReview failures.
+const (
+	DefaultRetryCount = 3
+	DefaultRetryDelay = time.Second
+)
 
 // ChangeReviewCommenter is a comment service for Gerrit Change Review
 // API:
 // 	https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#set-review
 // 	POST /changes/{change-id}/revisions/{revision-id}/review
 type ChangeReviewCommenter struct {
-	cli        *gerrit.Client
+	cli        Client
 	changeID   string
 	revisionID string
 
@@ -28,34 +57,239 @@ type ChangeReviewCommenter struct {
 
 	// wd is working directory relative to root of repository.
 	wd string
+
+	// workdir overrides the working directory used to compute wd. Empty
+	// means the current directory.
+	workdir string
+
+	// batchSize is the max number of comments posted by a single SetReview
+	// request.
+	batchSize int
+
+	// dryRunWriter is a writer to which review inputs are written instead of
+	// posting them to Gerrit. Nil means posting them.
+	dryRunWriter io.Writer
+
+	// label is a label name (e.g. Verified) to vote on. Empty means no vote.
+	label     string
+	labelPass int
+	labelFail int
+
+	// outsideDiffSummary reports comments outside diff files in the review
+	// message instead of dropping them.
+	outsideDiffSummary bool
+
+	// retryCount is the max number of retries on transient SetReview failures.
+	// retryDelay is the delay before the first retry, and it's doubled for each
+	// retry.
+	retryCount int
+	retryDelay time.Duration
+
+	logger serviceutil.Logger
+
+	// noDedup posts all the comments even if the same comments are reported
+	// at the same line.
+	noDedup bool
+
+	// tmpl is the comment message template. The plain diagnostic message is
+	// used if nil.
+	tmpl *commentutil.Template
+
+	// maxMessageLength is the max length of diagnostic messages in comment
+	// bodies. Longer messages are truncated.
+	maxMessageLength int
+
+	// hashtag is added to the change when comments are posted. Empty means no
+	// hashtag.
+	hashtag string
+
+	// reportNoResults posts a review message which tells no results are found
+	// if there are no results.
+	reportNoResults bool
 }
 
-// NewChangeReviewCommenter returns a new NewChangeReviewCommenter service.
-// ChangeReviewCommenter service needs git command in $PATH.
-func NewChangeReviewCommenter(cli *gerrit.Client, changeID, revisionID string) (*ChangeReviewCommenter, error) {
-	workDir, err := serviceutil.GitRelWorkdir()
-	if err != nil {
-		return nil, fmt.Errorf("ChangeReviewCommenter needs 'git' command: %w", err)
+// ChangeReviewCommenterOption is an option for NewChangeReviewCommenter.
+type ChangeReviewCommenterOption func(*ChangeReviewCommenter)
+
+// WithBatchSize sets the max number of comments posted by a single SetReview
+// request. Gerrit rejects too large request bodies, so comments are split into
+// multiple requests. Non-positive n is ignored.
+func WithBatchSize(n int) ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		if n > 0 {
+			g.batchSize = n
+		}
+	}
+}
+
+// WithDryRunWriter makes ChangeReviewCommenter write review inputs to w as
+// JSON instead of posting them to Gerrit. It's useful for debugging.
+func WithDryRunWriter(w io.Writer) ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		g.dryRunWriter = w
+	}
+}
+
+// WithLabel makes ChangeReviewCommenter vote on the given label (e.g.
+// Verified) with the review. It votes fail if any comments are posted,
+// otherwise it votes pass.
+func WithLabel(label string, pass, fail int) ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		g.label = label
+		g.labelPass = pass
+		g.labelFail = fail
+	}
+}
+
+// WithOutsideDiffSummary makes ChangeReviewCommenter list comments outside
+// diff files in the review message. Gerrit cannot post inline comments to
+// files which are not in the change, so they are dropped by default.
+func WithOutsideDiffSummary() ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		g.outsideDiffSummary = true
 	}
+}
+
+// WithRetry sets the max number of retries and the delay before the first
+// retry on transient SetReview failures (network errors and 5xx responses).
+// The delay is doubled for each retry.
+func WithRetry(count int, delay time.Duration) ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		g.retryCount = count
+		g.retryDelay = delay
+	}
+}
+
+// WithoutDeduplication makes ChangeReviewCommenter post all the comments.
+// By default, comments which have the same path, line, message and tool
+// name are posted only once.
+func WithoutDeduplication() ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		g.noDedup = true
+	}
+}
+
+// WithCommentTemplate makes ChangeReviewCommenter build comment messages with
+// tmpl instead of the plain diagnostic message.
+func WithCommentTemplate(tmpl *commentutil.Template) ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		g.tmpl = tmpl
+	}
+}
+
+// WithWorkdir makes ChangeReviewCommenter treat paths of results as relative to
+// dir instead of the current directory. dir must be inside the git repository.
+// It's useful when reviewdog runs outside the directory where linters ran.
+func WithWorkdir(dir string) ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		g.workdir = dir
+	}
+}
+
+// WithMaxMessageLength sets the max length of diagnostic messages in comments
+// in bytes. Longer messages are truncated with a note.
+// DefaultMaxMessageLength is used by default.
+func WithMaxMessageLength(n int) ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		g.maxMessageLength = n
+	}
+}
 
-	return &ChangeReviewCommenter{
+// WithHashtag makes ChangeReviewCommenter add the hashtag (e.g. reviewdog) to
+// the change when it posts any comments, so that changes which received
+// reviewdog feedback can be queried. Failures to add the hashtag are logged
+// and don't fail Flush.
+func WithHashtag(tag string) ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		g.hashtag = tag
+	}
+}
+
+// WithReportNoResults makes ChangeReviewCommenter post a comment-free review
+// with commentutil.NoResultsMessage if no results are found, so that reviewers
+// can tell reviewdog ran. Reviews without results are posted without message
+// by default.
+func WithReportNoResults() ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		g.reportNoResults = true
+	}
+}
+
+// WithLogger sets the logger of ChangeReviewCommenter.
+// serviceutil.DefaultLogger is used by default.
+func WithLogger(logger serviceutil.Logger) ChangeReviewCommenterOption {
+	return func(g *ChangeReviewCommenter) {
+		g.logger = logger
+	}
+}
+
+// NewChangeReviewCommenter returns a new NewChangeReviewCommenter service.
+// ChangeReviewCommenter service needs git command in $PATH.
+func NewChangeReviewCommenter(cli Client, changeID, revisionID string, opts ...ChangeReviewCommenterOption) (*ChangeReviewCommenter, error) {
+	g := &ChangeReviewCommenter{
 		cli:          cli,
 		changeID:     changeID,
 		revisionID:   revisionID,
 		postComments: []*reviewdog.Comment{},
-		wd:           workDir,
-	}, nil
+		batchSize:    DefaultBatchSize,
+		retryCount:   DefaultRetryCount,
+		retryDelay:   DefaultRetryDelay,
+		logger:       serviceutil.DefaultLogger(),
+
+		maxMessageLength: DefaultMaxMessageLength,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.tmpl = g.tmpl.WithMaxMessageLength(g.maxMessageLength)
+	workDir, err := gitRelWorkdir(g.workdir)
+	if err != nil {
+		return nil, fmt.Errorf("ChangeReviewCommenter needs 'git' command: %w", err)
+	}
+	g.wd = workDir
+	return g, nil
+}
+
+// NewChangeReviewCommenterFromEnv returns a new ChangeReviewCommenter for the
+// change and revision in environment variables of Gerrit CI (GERRIT_CHANGE_ID,
+// and GERRIT_REVISION_ID or GERRIT_PATCHSET_REVISION). Use
+// NewChangeReviewCommenter to pass them explicitly.
+func NewChangeReviewCommenterFromEnv(cli Client, opts ...ChangeReviewCommenterOption) (*ChangeReviewCommenter, error) {
+	changeID, revisionID, err := cienv.GetGerritChange()
+	if err != nil {
+		return nil, err
+	}
+	return NewChangeReviewCommenter(cli, changeID, revisionID, opts...)
 }
 
 // Post accepts a comment and holds it. Flush method actually posts comments to Gerrit
+//
+// It holds a copy of the comment with the path relative to the repository
+// root and doesn't modify c, so that the same comment can be posted to
+// multiple services concurrently.
 func (g *ChangeReviewCommenter) Post(_ context.Context, c *reviewdog.Comment) error {
-	c.Result.Diagnostic.GetLocation().Path = filepath.Join(g.wd, c.Result.Diagnostic.GetLocation().GetPath())
+	c = copyComment(c)
+	if loc := c.Result.Diagnostic.GetLocation(); loc != nil {
+		loc.Path = filepath.Join(g.wd, loc.GetPath())
+	}
 	g.muComments.Lock()
 	defer g.muComments.Unlock()
 	g.postComments = append(g.postComments, c)
 	return nil
 }
 
+// copyComment returns a copy of c whose diagnostic can be modified without
+// affecting c.
+func copyComment(c *reviewdog.Comment) *reviewdog.Comment {
+	result := *c.Result
+	result.Diagnostic = proto.Clone(c.Result.Diagnostic).(*rdf.Diagnostic)
+	return &reviewdog.Comment{Result: &result, ToolName: c.ToolName}
+}
+
+// GroupsResults implements reviewdog.GroupingCommentService. Comment bodies
+// list locations of collapsed duplicates.
+func (g *ChangeReviewCommenter) GroupsResults() {}
+
 // Flush posts comments which has not been posted yet.
 func (g *ChangeReviewCommenter) Flush(ctx context.Context) error {
 	g.muComments.Lock()
@@ -65,20 +299,211 @@ func (g *ChangeReviewCommenter) Flush(ctx context.Context) error {
 }
 
 func (g *ChangeReviewCommenter) postAllComments(ctx context.Context) error {
+	var errs []string
+	reviews := g.buildReviews()
+	g.logger.Debugf("gerrit-change-review: posting %d comments in %d review batches", len(g.postComments), len(reviews))
+	// posted reports whether at least one review with comments was posted.
+	posted := false
+	for _, review := range reviews {
+		if g.dryRunWriter != nil {
+			if err := g.writeReview(review); err != nil {
+				return err
+			}
+		} else if err := g.setReview(ctx, review); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if len(review.Comments) > 0 {
+			posted = true
+		}
+	}
+	if posted {
+		if err := g.addHashtag(ctx); err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to post %d review batches: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// addHashtag adds the hashtag to the change. It only logs API failures as the
+// hashtag is not essential.
+func (g *ChangeReviewCommenter) addHashtag(ctx context.Context) error {
+	if g.hashtag == "" {
+		return nil
+	}
+	if g.dryRunWriter != nil {
+		_, err := fmt.Fprintf(g.dryRunWriter, "[dry-run] POST /changes/%s/hashtags\n{\"add\": [%q]}\n", g.changeID, g.hashtag)
+		return err
+	}
+	if _, err := g.cli.AddHashtags(ctx, g.changeID, g.hashtag); err != nil {
+		g.logger.Warnf("gerrit-change-review: failed to add hashtag %q: %v", g.hashtag, err)
+	}
+	return nil
+}
+
+// setReview calls SetReview API and retries it on transient failures. It
+// returns the last error if all the retries fail.
+func (g *ChangeReviewCommenter) setReview(ctx context.Context, review gerrit.ReviewInput) error {
+	delay := g.retryDelay
+	for i := 0; ; i++ {
+		err := g.cli.SetReview(ctx, g.changeID, g.revisionID, review)
+		if err == nil || i >= g.retryCount || !isTransientError(err) {
+			return err
+		}
+		g.logger.Warnf("gerrit-change-review: failed to set review: %v. Retrying in %v", err, delay)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func isTransientError(err error) bool {
+	var httpErr *gerrit.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Res.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func (g *ChangeReviewCommenter) writeReview(review gerrit.ReviewInput) error {
+	b, err := json.MarshalIndent(review, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal review input: %w", err)
+	}
+	_, err = fmt.Fprintf(g.dryRunWriter, "[dry-run] POST /changes/%s/revisions/%s/review\n%s\n", g.changeID, g.revisionID, b)
+	return err
+}
+
+// buildReviews builds review inputs which have at most g.batchSize comments
+// each. It always returns at least one review input.
+func (g *ChangeReviewCommenter) buildReviews() []gerrit.ReviewInput {
+	var reviews []gerrit.ReviewInput
 	review := gerrit.ReviewInput{
 		Comments: map[string][]gerrit.CommentInput{},
 	}
-	for _, c := range g.postComments {
+	n := 0
+	total := 0
+	var outside []*reviewdog.Comment
+	seen := make(map[commentKey]bool)
+	for _, c := range sortComments(g.postComments) {
 		if !c.Result.InDiffFile {
+			outside = append(outside, c)
 			continue
 		}
+		if !g.noDedup {
+			key := newCommentKey(c)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		if n >= g.batchSize {
+			reviews = append(reviews, review)
+			review = gerrit.ReviewInput{
+				Comments: map[string][]gerrit.CommentInput{},
+			}
+			n = 0
+		}
 		loc := c.Result.Diagnostic.GetLocation()
 		path := loc.GetPath()
 		review.Comments[path] = append(review.Comments[path], gerrit.CommentInput{
 			Line:    int(loc.GetRange().GetStart().GetLine()),
-			Message: c.Result.Diagnostic.GetMessage(),
+			Message: g.message(c),
 		})
+		n++
+		total++
+	}
+	reviews = append(reviews, review)
+	if g.outsideDiffSummary && len(outside) > 0 {
+		reviews[len(reviews)-1].Message = outsideDiffMessage(outside)
+	}
+	if g.reportNoResults && len(g.postComments) == 0 {
+		reviews[len(reviews)-1].Message = commentutil.NoResultsMessage
+	}
+	if g.label != "" {
+		// Vote with the last review so that the vote reflects all the comments.
+		vote := g.labelPass
+		if total > 0 {
+			vote = g.labelFail
+		}
+		reviews[len(reviews)-1].Labels = map[string]int{g.label: vote}
 	}
+	return reviews
+}
 
-	return g.cli.SetReview(ctx, g.changeID, g.revisionID, review)
+// sortComments returns comments sorted by path, line and column so that
+// comments of each file are posted from top to bottom. Ties keep the order of
+// results.
+func sortComments(comments []*reviewdog.Comment) []*reviewdog.Comment {
+	sorted := make([]*reviewdog.Comment, len(comments))
+	copy(sorted, comments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		li, lj := sorted[i].Result.Diagnostic.GetLocation(), sorted[j].Result.Diagnostic.GetLocation()
+		if li.GetPath() != lj.GetPath() {
+			return li.GetPath() < lj.GetPath()
+		}
+		si, sj := li.GetRange().GetStart(), lj.GetRange().GetStart()
+		if si.GetLine() != sj.GetLine() {
+			return si.GetLine() < sj.GetLine()
+		}
+		return si.GetColumn() < sj.GetColumn()
+	})
+	return sorted
+}
+
+func (g *ChangeReviewCommenter) message(c *reviewdog.Comment) string {
+	if g.tmpl.IsDefault() {
+		msg := g.tmpl.Message(c)
+		if dups := commentutil.DuplicateLocations(c); dups != "" {
+			msg += "\n\n" + dups
+		}
+		return g.tmpl.AppendSnippet(msg, c)
+	}
+	return g.tmpl.Body(c)
+}
+
+// commentKey identifies the same comments reported by the same tool.
+type commentKey struct {
+	path    string
+	line    int32
+	message string
+	tool    string
+}
+
+func newCommentKey(c *reviewdog.Comment) commentKey {
+	tool := c.Result.Diagnostic.GetSource().GetName()
+	if tool == "" {
+		tool = c.ToolName
+	}
+	loc := c.Result.Diagnostic.GetLocation()
+	return commentKey{
+		path:    loc.GetPath(),
+		line:    loc.GetRange().GetStart().GetLine(),
+		message: c.Result.Diagnostic.GetMessage(),
+		tool:    tool,
+	}
+}
+
+func outsideDiffMessage(comments []*reviewdog.Comment) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("reviewdog: %d comment(s) outside the diff cannot be posted as inline comments.\n\n", len(comments)))
+	for _, c := range comments {
+		loc := c.Result.Diagnostic.GetLocation()
+		sb.WriteString("* ")
+		sb.WriteString(loc.GetPath())
+		if line := loc.GetRange().GetStart().GetLine(); line > 0 {
+			sb.WriteString(fmt.Sprintf(":%d", line))
+		}
+		sb.WriteString(": ")
+		sb.WriteString(c.Result.Diagnostic.GetMessage())
+		sb.WriteString("\n")
+	}
+	return sb.String()
 }
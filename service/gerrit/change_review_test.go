@@ -1,20 +1,28 @@
 package gerrit
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/build/gerrit"
 
 	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/diff"
 	"github.com/reviewdog/reviewdog/filter"
 	"github.com/reviewdog/reviewdog/proto/rdf"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
@@ -133,3 +141,598 @@ func TestChangeReviewCommenter_Post_Flush(t *testing.T) {
 		t.Errorf("%v", err)
 	}
 }
+
+func TestChangeReviewCommenter_Flush_batch(t *testing.T) {
+	ctx := context.Background()
+	var comments []*reviewdog.Comment
+	for i := 1; i <= 5; i++ {
+		comments = append(comments, &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: int32(i)}},
+					},
+					Message: fmt.Sprintf("comment %d", i),
+				},
+				InDiffFile: true,
+			},
+		})
+	}
+
+	cli := &fakeClient{}
+	g, err := NewChangeReviewCommenter(cli, "testChangeID", "testRevisionID", WithBatchSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range comments {
+		if err := g.Post(ctx, c); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := g.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][]gerrit.CommentInput
+	for _, review := range cli.reviews {
+		var cs []gerrit.CommentInput
+		for _, c := range review.Comments {
+			cs = append(cs, c...)
+		}
+		got = append(got, cs)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d requests, want 3", len(got))
+	}
+	var lines []int
+	for i, batch := range got {
+		if i < 2 && len(batch) != 2 {
+			t.Errorf("batch %d has %d comments, want 2", i, len(batch))
+		}
+		for _, c := range batch {
+			lines = append(lines, c.Line)
+		}
+	}
+	if diff := cmp.Diff(lines, []int{1, 2, 3, 4, 5}); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestChangeReviewCommenter_Flush_dryRun(t *testing.T) {
+	ctx := context.Background()
+	// The client must not be used in dry-run mode.
+	cli := &fakeClient{}
+	var buf strings.Builder
+	g, err := NewChangeReviewCommenter(cli, "testChangeID", "testRevisionID", WithDryRunWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+				},
+				Message: "dry-run comment",
+			},
+			InDiffFile: true,
+		},
+	}
+	if err := g.Post(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(cli.reviews) != 0 {
+		t.Errorf("SetReview called %d times in dry-run mode", len(cli.reviews))
+	}
+	got := buf.String()
+	if !strings.HasPrefix(got, "[dry-run] POST /changes/testChangeID/revisions/testRevisionID/review\n") {
+		t.Errorf("unexpected dry-run header: %q", got)
+	}
+	if !strings.Contains(got, `"message": "dry-run comment"`) {
+		t.Errorf("dry-run output doesn't contain the comment: %q", got)
+	}
+}
+
+func TestChangeReviewCommenter_buildReviews_label(t *testing.T) {
+	comment := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+				},
+				Message: "comment",
+			},
+			InDiffFile: true,
+		},
+	}
+	tests := []struct {
+		name     string
+		comments []*reviewdog.Comment
+		want     map[string]int
+	}{
+		{name: "pass", comments: nil, want: map[string]int{"Verified": 1}},
+		{name: "fail", comments: []*reviewdog.Comment{comment}, want: map[string]int{"Verified": -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &ChangeReviewCommenter{batchSize: DefaultBatchSize, postComments: tt.comments}
+			WithLabel("Verified", 1, -1)(g)
+			reviews := g.buildReviews()
+			if diff := cmp.Diff(reviews[len(reviews)-1].Labels, tt.want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestChangeReviewCommenter_buildReviews_reportNoResults(t *testing.T) {
+	comment := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+				},
+				Message: "comment",
+			},
+			InDiffFile: true,
+		},
+	}
+	tests := []struct {
+		name     string
+		comments []*reviewdog.Comment
+		want     string
+	}{
+		{name: "no results", comments: nil, want: commentutil.NoResultsMessage},
+		{name: "results", comments: []*reviewdog.Comment{comment}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &ChangeReviewCommenter{batchSize: DefaultBatchSize, postComments: tt.comments}
+			WithReportNoResults()(g)
+			reviews := g.buildReviews()
+			if got := reviews[len(reviews)-1].Message; got != tt.want {
+				t.Errorf("got message %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangeReviewCommenter_buildReviews_outsideDiffSummary(t *testing.T) {
+	g := &ChangeReviewCommenter{
+		batchSize: DefaultBatchSize,
+		postComments: []*reviewdog.Comment{
+			{
+				Result: &filter.FilteredDiagnostic{
+					Diagnostic: &rdf.Diagnostic{
+						Location: &rdf.Location{
+							Path:  "file.go",
+							Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+						},
+						Message: "in diff",
+					},
+					InDiffFile: true,
+				},
+			},
+			{
+				Result: &filter.FilteredDiagnostic{
+					Diagnostic: &rdf.Diagnostic{
+						Location: &rdf.Location{
+							Path:  "file2.go",
+							Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+						},
+						Message: "outside diff",
+					},
+				},
+			},
+			{
+				Result: &filter.FilteredDiagnostic{
+					Diagnostic: &rdf.Diagnostic{
+						Location: &rdf.Location{Path: "file3.go"},
+						Message:  "outside diff without line",
+					},
+				},
+			},
+		},
+	}
+
+	if got := g.buildReviews()[0].Message; got != "" {
+		t.Errorf("got message %q by default, want empty", got)
+	}
+
+	WithOutsideDiffSummary()(g)
+	reviews := g.buildReviews()
+	want := `reviewdog: 2 comment(s) outside the diff cannot be posted as inline comments.
+
+* file2.go:14: outside diff
+* file3.go: outside diff without line
+`
+	if diff := cmp.Diff(reviews[0].Message, want); diff != "" {
+		t.Error(diff)
+	}
+	if got := len(reviews[0].Comments); got != 1 {
+		t.Errorf("got %d inline comment paths, want 1", got)
+	}
+}
+
+func TestChangeReviewCommenter_renamedFile(t *testing.T) {
+	// Paths in the diff are relative to the repository root while the test
+	// runs in service/gerrit.
+	const renamedDiff = `diff --git a/service/gerrit/old.go b/service/gerrit/new.go
+similarity index 80%
+rename from service/gerrit/old.go
+rename to service/gerrit/new.go
+index 1111111..2222222 100644
+--- a/service/gerrit/old.go
++++ b/service/gerrit/new.go
+@@ -1,3 +1,3 @@
+ package gerrit
+-var x = 1
++var x = 2
+ // end
+`
+	filediffs, err := diff.ParseMultiFile(strings.NewReader(renamedDiff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := &fakeClient{}
+	var buf bytes.Buffer
+	g, err := NewChangeReviewCommenter(cli, "testChangeID", "testRevisionID", WithDryRunWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The tool reports the result with the old path.
+	results := []*rdf.Diagnostic{{
+		Location: &rdf.Location{
+			Path:  "old.go",
+			Range: &rdf.Range{Start: &rdf.Position{Line: 2}},
+		},
+		Message: "renamed",
+	}}
+	if err := reviewdog.RunFromResult(context.Background(), g, results, filediffs, 1, "tool", filter.ModeAdded, false); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	if !strings.Contains(got, `"service/gerrit/new.go"`) || strings.Contains(got, "old.go") {
+		t.Errorf("the comment should be posted on the new path:\n%s", got)
+	}
+}
+
+func TestChangeReviewCommenter_Flush_retry(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "success after 5xx", statuses: []int{503, 502, 200}, wantCalls: 3},
+		{name: "no retry on 4xx", statuses: []int{400}, wantCalls: 1, wantErr: true},
+		{name: "give up", statuses: []int{500, 500, 500}, wantCalls: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			mux := http.NewServeMux()
+			mux.HandleFunc(`/changes/testChangeID/revisions/testRevisionID/review`, func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[calls]
+				calls++
+				if status != http.StatusOK {
+					w.WriteHeader(status)
+					return
+				}
+				fmt.Fprintf(w, ")]}\n{}")
+			})
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			cli := gerrit.NewClient(ts.URL, gerrit.NoAuth)
+			var logs bytes.Buffer
+			g, err := NewChangeReviewCommenter(cli, "testChangeID", "testRevisionID", WithRetry(2, time.Millisecond),
+				WithLogger(serviceutil.NewLogger(&logs, serviceutil.LogLevelWarn)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = g.Flush(context.Background())
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", calls, tt.wantCalls)
+			}
+			if got := strings.Count(logs.String(), "Retrying"); got != tt.wantCalls-1 {
+				t.Errorf("got %d retry logs, want %d:\n%s", got, tt.wantCalls-1, logs.String())
+			}
+		})
+	}
+}
+
+func TestChangeReviewCommenter_buildReviews_dedup(t *testing.T) {
+	newComment := func(tool string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: tool,
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 14}},
+					},
+					Message: "duplicated",
+				},
+				InDiffFile: true,
+			},
+		}
+	}
+	comments := []*reviewdog.Comment{newComment("tool"), newComment("tool"), newComment("another-tool")}
+
+	tests := []struct {
+		name string
+		opts []ChangeReviewCommenterOption
+		want int
+	}{
+		{name: "dedup", want: 2},
+		{name: "no dedup", opts: []ChangeReviewCommenterOption{WithoutDeduplication()}, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &ChangeReviewCommenter{batchSize: DefaultBatchSize, postComments: comments}
+			for _, opt := range tt.opts {
+				opt(g)
+			}
+			if got := len(g.buildReviews()[0].Comments["file.go"]); got != tt.want {
+				t.Errorf("got %d comments, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangeReviewCommenter_buildReviews_duplicates(t *testing.T) {
+	newResult := func(path string, line int32) *filter.FilteredDiagnostic {
+		return &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  path,
+					Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+				},
+				Message: "duplicated",
+			},
+			InDiffFile: true,
+		}
+	}
+	c := &reviewdog.Comment{ToolName: "tool", Result: newResult("a.go", 1)}
+	c.Result.Duplicates = []*filter.FilteredDiagnostic{newResult("b.go", 2)}
+	g := &ChangeReviewCommenter{batchSize: DefaultBatchSize, postComments: []*reviewdog.Comment{c}}
+	want := "duplicated\n\nAlso found at 1 other location(s):\n- `b.go:2`"
+	if got := g.buildReviews()[0].Comments["a.go"][0].Message; got != want {
+		t.Errorf("got message:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestChangeReviewCommenter_buildReviews_sorted(t *testing.T) {
+	newComment := func(path string, line, col int32, msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  path,
+						Range: &rdf.Range{Start: &rdf.Position{Line: line, Column: col}},
+					},
+					Message: msg,
+				},
+				InDiffFile: true,
+			},
+		}
+	}
+	g := &ChangeReviewCommenter{batchSize: DefaultBatchSize, postComments: []*reviewdog.Comment{
+		newComment("a.go", 10, 1, "a10"),
+		newComment("b.go", 1, 0, "b1"),
+		newComment("a.go", 2, 5, "a2:5"),
+		newComment("a.go", 2, 3, "a2:3"),
+		newComment("a.go", 2, 3, "a2:3 tie"),
+		newComment("a.go", 1, 0, "a1"),
+	}}
+	reviews := g.buildReviews()
+	want := map[string][]gerrit.CommentInput{
+		"a.go": {
+			{Line: 1, Message: "a1"},
+			{Line: 2, Message: "a2:3"},
+			{Line: 2, Message: "a2:3 tie"},
+			{Line: 2, Message: "a2:5"},
+			{Line: 10, Message: "a10"},
+		},
+		"b.go": {
+			{Line: 1, Message: "b1"},
+		},
+	}
+	if diff := cmp.Diff(want, reviews[0].Comments); diff != "" {
+		t.Errorf("diff (-want +got):\n%s", diff)
+	}
+	if got := g.postComments[0].Result.Diagnostic.GetMessage(); got != "a10" {
+		t.Errorf("postComments must keep the order, got %q first", got)
+	}
+}
+
+func TestChangeReviewCommenter_Post_workdir(t *testing.T) {
+	g, err := NewChangeReviewCommenter(nil, "changeID", "revisionID", WithWorkdir("../../cmd/reviewdog"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: "main.go"},
+			},
+		},
+	}
+	if err := g.Post(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := g.postComments[0].Result.Diagnostic.GetLocation().GetPath(), "cmd/reviewdog/main.go"; got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+	if got, want := c.Result.Diagnostic.GetLocation().GetPath(), "main.go"; got != want {
+		t.Errorf("posted comment is modified: path = %q, want %q", got, want)
+	}
+	if _, err := NewChangeReviewCommenter(nil, "changeID", "revisionID", WithWorkdir(t.TempDir())); err == nil {
+		t.Error("got no error for workdir outside git repository")
+	}
+}
+
+func TestChangeReviewCommenter_Post_concurrent(t *testing.T) {
+	// Run with -race to detect data races of concurrent Posts which share
+	// comments like parallel reporters.
+	newCommenter := func() *ChangeReviewCommenter {
+		g, err := NewChangeReviewCommenter(nil, "changeID", "revisionID", WithWorkdir("../../cmd/reviewdog"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+	g1, g2 := newCommenter(), newCommenter()
+	const n = 50
+	var comments []*reviewdog.Comment
+	for i := 0; i < n; i++ {
+		comments = append(comments, &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{Path: "main.go"},
+					Message:  fmt.Sprintf("message %d", i),
+				},
+			},
+		})
+	}
+	var wg sync.WaitGroup
+	for _, g := range []*ChangeReviewCommenter{g1, g2} {
+		for _, c := range comments {
+			wg.Add(1)
+			go func(g *ChangeReviewCommenter, c *reviewdog.Comment) {
+				defer wg.Done()
+				if err := g.Post(context.Background(), c); err != nil {
+					t.Error(err)
+				}
+			}(g, c)
+		}
+	}
+	wg.Wait()
+	for _, g := range []*ChangeReviewCommenter{g1, g2} {
+		if len(g.postComments) != n {
+			t.Fatalf("got %d comments, want %d", len(g.postComments), n)
+		}
+		for _, c := range g.postComments {
+			if got, want := c.Result.Diagnostic.GetLocation().GetPath(), "cmd/reviewdog/main.go"; got != want {
+				t.Errorf("path = %q, want %q", got, want)
+			}
+		}
+	}
+	for _, c := range comments {
+		if got, want := c.Result.Diagnostic.GetLocation().GetPath(), "main.go"; got != want {
+			t.Errorf("posted comment is modified: path = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestChangeReviewCommenter_Flush_hashtag(t *testing.T) {
+	ctx := context.Background()
+	newComment := func() *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+					},
+					Message: "comment",
+				},
+				InDiffFile: true,
+			},
+		}
+	}
+	tests := []struct {
+		name         string
+		comments     []*reviewdog.Comment
+		hashtagsErr  error
+		reviewErr    error
+		wantHashtags []string
+		wantWarning  bool
+	}{
+		{
+			name:         "comments",
+			comments:     []*reviewdog.Comment{newComment()},
+			wantHashtags: []string{"reviewdog"},
+		},
+		{
+			name: "no comments",
+		},
+		{
+			name: "outside diff only",
+			comments: []*reviewdog.Comment{func() *reviewdog.Comment {
+				c := newComment()
+				c.Result.InDiffFile = false
+				return c
+			}()},
+		},
+		{
+			name:      "review failed",
+			comments:  []*reviewdog.Comment{newComment()},
+			reviewErr: errors.New("review rejected"),
+		},
+		{
+			name:        "best-effort",
+			comments:    []*reviewdog.Comment{newComment()},
+			hashtagsErr: errors.New("hashtags are disabled"),
+			wantWarning: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &fakeClient{hashtagsErr: tt.hashtagsErr, reviewErr: tt.reviewErr}
+			var logs bytes.Buffer
+			g, err := NewChangeReviewCommenter(cli, "testChangeID", "testRevisionID", WithHashtag("reviewdog"),
+				WithLogger(serviceutil.NewLogger(&logs, serviceutil.LogLevelWarn)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range tt.comments {
+				if err := g.Post(ctx, c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := g.Flush(ctx); (err != nil) != (tt.reviewErr != nil) {
+				t.Fatalf("Flush() error = %v, want error: %v", err, tt.reviewErr != nil)
+			}
+			if tt.reviewErr == nil && len(cli.reviews) != 1 {
+				t.Errorf("got %d reviews, want 1", len(cli.reviews))
+			}
+			if diff := cmp.Diff(cli.hashtags, tt.wantHashtags); diff != "" {
+				t.Errorf("hashtags diff:\n%s", diff)
+			}
+			if got := strings.Contains(logs.String(), "failed to add hashtag"); got != tt.wantWarning {
+				t.Errorf("warning logged = %v, want %v: %q", got, tt.wantWarning, logs.String())
+			}
+		})
+	}
+}
+
+func TestNewChangeReviewCommenterFromEnv(t *testing.T) {
+	t.Setenv("GERRIT_CHANGE_ID", "")
+	t.Setenv("GERRIT_REVISION_ID", "")
+	t.Setenv("GERRIT_PATCHSET_REVISION", "")
+	if _, err := NewChangeReviewCommenterFromEnv(nil); err == nil {
+		t.Error("got no error without GERRIT_CHANGE_ID")
+	}
+
+	t.Setenv("GERRIT_CHANGE_ID", "myproject~master~I1293efab014de2")
+	t.Setenv("GERRIT_PATCHSET_REVISION", "ed318bf9a3c")
+	g, err := NewChangeReviewCommenterFromEnv(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.changeID != "myproject~master~I1293efab014de2" || g.revisionID != "ed318bf9a3c" {
+		t.Errorf("got change %q and revision %q", g.changeID, g.revisionID)
+	}
+}
@@ -0,0 +1,34 @@
+package gerrit
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/build/gerrit"
+)
+
+var _ Client = &gerrit.Client{}
+
+// Client is the Gerrit API used by ChangeReviewCommenter and ChangeDiff.
+// *gerrit.Client implements it, and tests can replace it with mocks.
+type Client interface {
+	// SetReview posts a review of the revision of the change.
+	SetReview(ctx context.Context, changeID, revision string, review gerrit.ReviewInput) error
+
+	// GetChangeDetail returns the change with its details such as the current
+	// revision.
+	GetChangeDetail(ctx context.Context, changeID string, opts ...gerrit.QueryChangesOpt) (*gerrit.ChangeInfo, error)
+
+	// AddHashtags adds hashtags to the change.
+	AddHashtags(ctx context.Context, changeID string, tags ...string) ([]string, error)
+}
+
+// NewClient returns a new Gerrit client which sends requests with httpClient,
+// so that callers can configure proxies, TLS (e.g. custom CA bundles) and
+// timeouts. ChangeReviewCommenter and ChangeDiff send all requests through the
+// given client. http.DefaultClient is used if httpClient is nil.
+func NewClient(url string, auth gerrit.Auth, httpClient *http.Client) *gerrit.Client {
+	cli := gerrit.NewClient(url, auth)
+	cli.HTTPClient = httpClient
+	return cli
+}
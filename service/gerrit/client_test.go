@@ -0,0 +1,112 @@
+package gerrit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/build/gerrit"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// fakeClient is a Client which records reviews instead of posting them.
+type fakeClient struct {
+	reviews  []gerrit.ReviewInput
+	change   *gerrit.ChangeInfo
+	hashtags []string
+
+	// hashtagsErr is returned by AddHashtags.
+	hashtagsErr error
+	// reviewErr is returned by SetReview.
+	reviewErr error
+}
+
+func (c *fakeClient) SetReview(_ context.Context, _, _ string, review gerrit.ReviewInput) error {
+	if c.reviewErr != nil {
+		return c.reviewErr
+	}
+	c.reviews = append(c.reviews, review)
+	return nil
+}
+
+func (c *fakeClient) GetChangeDetail(_ context.Context, changeID string, _ ...gerrit.QueryChangesOpt) (*gerrit.ChangeInfo, error) {
+	if c.change == nil {
+		return nil, fmt.Errorf("change %s not found", changeID)
+	}
+	return c.change, nil
+}
+
+func (c *fakeClient) AddHashtags(_ context.Context, _ string, tags ...string) ([]string, error) {
+	if c.hashtagsErr != nil {
+		return nil, c.hashtagsErr
+	}
+	c.hashtags = append(c.hashtags, tags...)
+	return c.hashtags, nil
+}
+
+type countingTransport struct {
+	count int
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.count++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestNewClient(t *testing.T) {
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/changes/changeID/detail", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, ")]}\n{\"current_revision\": \"HEAD\"}")
+	})
+	mux.HandleFunc("/changes/changeID/revisions/revisionID/review", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, ")]}\n{}")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	tr := &countingTransport{}
+	cli := NewClient(ts.URL, gerrit.NoAuth, &http.Client{Transport: tr})
+
+	d, err := NewChangeDiff(cli, "HEAD^", "changeID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Diff(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if tr.count != 1 {
+		t.Errorf("ChangeDiff sent %d requests with the client, want 1", tr.count)
+	}
+
+	g, err := NewChangeReviewCommenter(cli, "changeID", "revisionID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+				},
+				Message: "message",
+			},
+			InDiffFile: true,
+		},
+	}
+	if err := g.Post(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if tr.count != 2 {
+		t.Errorf("ChangeReviewCommenter sent %d requests with the client, want 1", tr.count-1)
+	}
+}
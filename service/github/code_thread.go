@@ -0,0 +1,152 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v39/github"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/github/githubutils"
+)
+
+// codeThread is a review comment thread of results with the same tool and
+// code. The first result is posted as the root review comment and the others
+// are posted as its replies.
+type codeThread struct {
+	root     *reviewdog.Comment
+	rootBody string
+	// rootID is the ID of the posted root comment. It's 0 until the root is
+	// posted.
+	rootID int64
+	// overflow is true if the root cannot be posted in the review due to
+	// maxCommentsPerRequest, and replies are listed in the review body as well.
+	overflow bool
+	replies  []*reviewdog.Comment
+}
+
+// codeThreadKey returns the key of the thread of the comment. Results without
+// code are not threaded.
+func codeThreadKey(c *reviewdog.Comment) string {
+	code := c.Result.Diagnostic.GetCode().GetValue()
+	if code == "" {
+		return ""
+	}
+	return c.ToolName + "\x00" + code
+}
+
+// commentIDKey returns the key of reviewCommentIDs.
+func commentIDKey(path string, line int, body string) string {
+	return fmt.Sprintf("%s:%d:%s", path, line, body)
+}
+
+// buildReplyBody returns the body of the reply of the comment. Replies are
+// shown under the root comment, so it links to the location of the result.
+// It has no suggestions as they would apply to the lines of the root comment.
+func (g *PullRequest) buildReplyBody(c *reviewdog.Comment) string {
+	d := c.Result.Diagnostic
+	link := githubutils.PathLink(g.owner, g.repo, g.sha, d.GetLocation().GetPath(), githubCommentLine(c))
+	body := fmt.Sprintf("[%s](%s)\n\n%s", githubutils.BasicLocationFormat(d), link, g.tmpl.Body(c))
+	if g.fingerprint {
+		body = commentutil.AppendFingerprint(body, c)
+	}
+	return body
+}
+
+// postThreadReplies posts replies of the threads to their root comments. Roots
+// posted in the review of reviewID are looked up by their path, line and body.
+// Replies whose root cannot be found or which cannot be posted fall back to
+// individual review comments.
+func (g *PullRequest) postThreadReplies(ctx context.Context, reviewID int64) error {
+	var hasNewRoot bool
+	for _, t := range g.threads {
+		if t.rootID == 0 && !t.overflow && len(t.replies) > 0 {
+			hasNewRoot = true
+		}
+	}
+	var rootIDs map[string]int64
+	if hasNewRoot && reviewID != 0 {
+		var err error
+		if rootIDs, err = g.reviewCommentIDs(ctx, reviewID); err != nil {
+			g.logger.Warnf("github-pr-review: failed to list review comments to reply to: %v", err)
+		}
+	}
+
+	var fallback []*github.DraftReviewComment
+	for _, t := range g.threads {
+		if t.overflow || len(t.replies) == 0 {
+			continue
+		}
+		if t.rootID == 0 {
+			t.rootID = rootIDs[commentIDKey(t.root.Result.Diagnostic.GetLocation().GetPath(), githubCommentLine(t.root), t.rootBody)]
+		}
+		for _, c := range t.replies {
+			if t.rootID == 0 {
+				fallback = append(fallback, buildDraftReviewComment(c, g.buildBody(c)))
+				continue
+			}
+			body := g.buildReplyBody(c)
+			err := withRateLimitRetry(ctx, g.logger, g.rateLimitMaxWait, func() error {
+				_, _, err := g.cli.PullRequests.CreateCommentInReplyTo(ctx, g.owner, g.repo, g.pr, body, t.rootID)
+				return err
+			})
+			if err != nil {
+				g.logger.Warnf("github-pr-review: failed to reply to comment %d, posting a review comment instead: %v", t.rootID, err)
+				fallback = append(fallback, buildDraftReviewComment(c, g.buildBody(c)))
+			}
+		}
+	}
+	if len(fallback) == 0 {
+		return nil
+	}
+	g.logger.Warnf("github-pr-review: posting %d results of threads as individual review comments", len(fallback))
+	for len(fallback) > 0 {
+		n := len(fallback)
+		if n > maxCommentsPerRequest {
+			n = maxCommentsPerRequest
+		}
+		review := &github.PullRequestReviewRequest{
+			CommitID: &g.sha,
+			Event:    github.String(eventComment),
+			Comments: fallback[:n],
+		}
+		if err := withRateLimitRetry(ctx, g.logger, g.rateLimitMaxWait, func() error {
+			_, _, err := g.cli.PullRequests.CreateReview(ctx, g.owner, g.repo, g.pr, review)
+			return err
+		}); err != nil {
+			return err
+		}
+		fallback = fallback[n:]
+	}
+	return nil
+}
+
+// reviewCommentIDs returns IDs of comments of the review keyed by
+// commentIDKey.
+func (g *PullRequest) reviewCommentIDs(ctx context.Context, reviewID int64) (map[string]int64, error) {
+	var comments []*github.PullRequestComment
+	err := withRateLimitRetry(ctx, g.logger, g.rateLimitMaxWait, func() error {
+		var err error
+		// A review has at most maxCommentsPerRequest comments.
+		comments, _, err = g.cli.PullRequests.ListReviewComments(ctx, g.owner, g.repo, g.pr, reviewID, &github.ListOptions{PerPage: 100})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]int64, len(comments))
+	for _, c := range comments {
+		ids[commentIDKey(c.GetPath(), reviewCommentLine(c), c.GetBody())] = c.GetID()
+	}
+	return ids, nil
+}
+
+// reviewCommentLine returns the line of the comment. Comments listed by
+// review may have only the original line.
+func reviewCommentLine(c *github.PullRequestComment) int {
+	if c.Line != nil {
+		return c.GetLine()
+	}
+	return c.GetOriginalLine()
+}
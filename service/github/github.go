@@ -2,16 +2,17 @@ package github
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/go-github/v39/github"
 
 	"github.com/reviewdog/reviewdog"
 	"github.com/reviewdog/reviewdog/cienv"
+	"github.com/reviewdog/reviewdog/filter"
 	"github.com/reviewdog/reviewdog/proto/rdf"
 	"github.com/reviewdog/reviewdog/service/commentutil"
 	"github.com/reviewdog/reviewdog/service/github/githubutils"
@@ -20,9 +21,15 @@ import (
 
 var _ reviewdog.CommentService = &PullRequest{}
 var _ reviewdog.DiffService = &PullRequest{}
+var _ reviewdog.GroupingCommentService = &PullRequest{}
 
 const maxCommentsPerRequest = 30
 
+// DefaultMaxMessageLength is the default max length of diagnostic messages in
+// review comments. GitHub rejects comment bodies longer than 65536
+// characters, so it leaves room for the rest of the body (e.g. suggestions).
+const DefaultMaxMessageLength = 60000
+
 const (
 	invalidSuggestionPre  = "<details><summary>reviewdog suggestion error</summary>"
 	invalidSuggestionPost = "</details>"
@@ -45,25 +52,174 @@ type PullRequest struct {
 
 	postedcs commentutil.PostedComments
 
+	// fingerprint embeds fingerprints in comment bodies and skips comments
+	// whose fingerprint is found in existing comments.
+	fingerprint        bool
+	postedFingerprints commentutil.Fingerprints
+
 	// wd is working directory relative to root of repository.
 	wd string
+
+	// tmpl is the comment body template. MarkdownComment is used if nil.
+	tmpl *commentutil.Template
+
+	// maxMessageLength is the max length of diagnostic messages in comment
+	// bodies. Longer messages are truncated.
+	maxMessageLength int
+
+	// rateLimitMaxWait is the max total time to wait for rate limits per API
+	// call.
+	rateLimitMaxWait time.Duration
+
+	// commentMode is whether to post inline comments, a summary comment or
+	// both.
+	commentMode commentutil.CommentMode
+
+	// requestChanges submits reviews with REQUEST_CHANGES event if any results
+	// match requestChangesLevel.
+	requestChanges      bool
+	requestChangesLevel filter.SeverityLevel
+
+	// approve submits an APPROVE review if there are no results.
+	approve bool
+
+	// reportNoResults submits a review which tells no results are found if
+	// there are no results.
+	reportNoResults bool
+
+	// threadByCode posts results with the same tool and code as replies to one
+	// root review comment.
+	threadByCode bool
+	// threads are threads of results of the current Flush in the order of
+	// their roots.
+	threads     []*codeThread
+	threadByKey map[string]*codeThread
+	// postedCommentIDs has IDs of existing review comments keyed by
+	// commentIDKey, so that new results can reply to posted roots.
+	postedCommentIDs map[string]int64
+	// postedReplies has bodies of existing replies.
+	postedReplies map[string]bool
+
+	logger serviceutil.Logger
+}
+
+// PullRequestOption is an option for NewGitHubPullRequest.
+type PullRequestOption func(*PullRequest)
+
+// WithCommentTemplate makes PullRequest build comment bodies with tmpl.
+func WithCommentTemplate(tmpl *commentutil.Template) PullRequestOption {
+	return func(g *PullRequest) {
+		g.tmpl = tmpl
+	}
+}
+
+// WithMaxMessageLength sets the max length of diagnostic messages in comment
+// bodies in bytes. Longer messages are truncated with a note.
+// DefaultMaxMessageLength is used by default.
+func WithMaxMessageLength(n int) PullRequestOption {
+	return func(g *PullRequest) {
+		g.maxMessageLength = n
+	}
+}
+
+// WithRateLimitMaxWait sets the max total time to wait for GitHub rate limits
+// per API call. PullRequest waits for Retry-After of secondary rate limits or
+// the reset of the primary rate limit, and fails if it takes longer than d.
+func WithRateLimitMaxWait(d time.Duration) PullRequestOption {
+	return func(g *PullRequest) {
+		g.rateLimitMaxWait = d
+	}
+}
+
+// WithCommentMode makes PullRequest post a summary of all the results as the
+// review body instead of or in addition to inline comments.
+func WithCommentMode(mode commentutil.CommentMode) PullRequestOption {
+	return func(g *PullRequest) {
+		g.commentMode = mode
+	}
+}
+
+// WithFingerprint makes PullRequest embed a fingerprint of each result in
+// comment bodies and skip results whose fingerprint is found in existing
+// review comments, even if they are on other lines.
+func WithFingerprint() PullRequestOption {
+	return func(g *PullRequest) {
+		g.fingerprint = true
+	}
+}
+
+// WithRequestChanges makes PullRequest submit reviews with REQUEST_CHANGES
+// event if any results whose severity is the same or higher than the level are
+// found. Reviews are submitted with COMMENT event by default.
+func WithRequestChanges(level filter.SeverityLevel) PullRequestOption {
+	return func(g *PullRequest) {
+		g.requestChanges = true
+		g.requestChangesLevel = level
+	}
+}
+
+// WithApprove makes PullRequest submit an APPROVE review if no results are
+// found. It never approves Pull Requests with any results. Note that each
+// Flush decides the event, so runners of the project config flushed before
+// others may approve Pull Requests which later runners request changes of.
+func WithApprove() PullRequestOption {
+	return func(g *PullRequest) {
+		g.approve = true
+	}
+}
+
+// WithReportNoResults makes PullRequest submit a review with
+// commentutil.NoResultsComment if no results are found, so that reviewers can
+// tell reviewdog ran. Like WithApprove, each Flush decides it.
+func WithReportNoResults() PullRequestOption {
+	return func(g *PullRequest) {
+		g.reportNoResults = true
+	}
+}
+
+// WithThreadByCode makes PullRequest post results with the same tool and code
+// as replies to one root review comment instead of separate review comments,
+// so that frequent rules don't clutter the conversation. Results without code
+// are posted separately. Results fall back to separate review comments if the
+// root comment cannot be found or replies cannot be posted.
+func WithThreadByCode() PullRequestOption {
+	return func(g *PullRequest) {
+		g.threadByCode = true
+	}
+}
+
+// WithLogger sets the logger of PullRequest. serviceutil.DefaultLogger is used
+// by default.
+func WithLogger(logger serviceutil.Logger) PullRequestOption {
+	return func(g *PullRequest) {
+		g.logger = logger
+	}
 }
 
 // NewGitHubPullRequest returns a new PullRequest service.
 // PullRequest service needs git command in $PATH.
-func NewGitHubPullRequest(cli *github.Client, owner, repo string, pr int, sha string) (*PullRequest, error) {
+func NewGitHubPullRequest(cli *github.Client, owner, repo string, pr int, sha string, opts ...PullRequestOption) (*PullRequest, error) {
 	workDir, err := serviceutil.GitRelWorkdir()
 	if err != nil {
 		return nil, fmt.Errorf("PullRequest needs 'git' command: %w", err)
 	}
-	return &PullRequest{
+	g := &PullRequest{
 		cli:   cli,
 		owner: owner,
 		repo:  repo,
 		pr:    pr,
 		sha:   sha,
 		wd:    workDir,
-	}, nil
+
+		rateLimitMaxWait: DefaultRateLimitMaxWait,
+		maxMessageLength: DefaultMaxMessageLength,
+		logger:           serviceutil.DefaultLogger(),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.tmpl = g.tmpl.WithMaxMessageLength(g.maxMessageLength)
+	return g, nil
 }
 
 // Post accepts a comment and holds it. Flush method actually posts comments to
@@ -77,6 +233,10 @@ func (g *PullRequest) Post(_ context.Context, c *reviewdog.Comment) error {
 	return nil
 }
 
+// GroupsResults implements reviewdog.GroupingCommentService. Comment bodies
+// list locations of collapsed duplicates.
+func (g *PullRequest) GroupsResults() {}
+
 // Flush posts comments which has not been posted yet.
 func (g *PullRequest) Flush(ctx context.Context) error {
 	g.muComments.Lock()
@@ -89,8 +249,90 @@ func (g *PullRequest) Flush(ctx context.Context) error {
 }
 
 func (g *PullRequest) postAsReviewComment(ctx context.Context) error {
+	var comments []*github.DraftReviewComment
+	var remaining []*reviewdog.Comment
+	if g.commentMode.Inline() {
+		comments, remaining = g.buildReviewComments()
+	}
+	body := g.remainingCommentsSummary(remaining)
+	if g.commentMode.Summary() {
+		if summary := commentutil.SummaryComment(g.postComments); summary != "" && body != "" {
+			body = summary + "\n" + body
+		} else if summary != "" {
+			body = summary
+		}
+	}
+
+	if g.reportNoResults && len(g.postComments) == 0 {
+		body = commentutil.NoResultsComment
+	}
+
+	event := g.reviewEvent()
+	if len(comments) == 0 && body == "" && event != eventApprove {
+		g.logger.Debugf("github-pr-review: no new review comments to post")
+		// New results may reply to posted roots.
+		return g.postThreadReplies(ctx, 0)
+	}
+	if body == "" && event != eventComment {
+		// GitHub requires the body of REQUEST_CHANGES reviews.
+		body = reviewEventBody(g.postComments)
+	}
+	g.logger.Debugf("github-pr-review: posting a %s review with %d comments", event, len(comments))
+
+	review := &github.PullRequestReviewRequest{
+		CommitID: &g.sha,
+		Event:    github.String(event),
+		Comments: comments,
+		Body:     github.String(body),
+	}
+	var reviewID int64
+	if err := withRateLimitRetry(ctx, g.logger, g.rateLimitMaxWait, func() error {
+		r, _, err := g.cli.PullRequests.CreateReview(ctx, g.owner, g.repo, g.pr, review)
+		reviewID = r.GetID()
+		return err
+	}); err != nil {
+		return err
+	}
+	return g.postThreadReplies(ctx, reviewID)
+}
+
+// Events of reviews.
+const (
+	eventComment        = "COMMENT"
+	eventRequestChanges = "REQUEST_CHANGES"
+	eventApprove        = "APPROVE"
+)
+
+// reviewEvent returns the event of the review based on the highest severity of
+// all the results posted so far.
+func (g *PullRequest) reviewEvent() string {
+	if g.requestChanges {
+		for _, c := range g.postComments {
+			if g.requestChangesLevel.Match(c.Result.Diagnostic.GetSeverity()) {
+				return eventRequestChanges
+			}
+		}
+	}
+	if g.approve && len(g.postComments) == 0 {
+		return eventApprove
+	}
+	return eventComment
+}
+
+func reviewEventBody(comments []*reviewdog.Comment) string {
+	if len(comments) == 0 {
+		return commentutil.NoResultsMessage
+	}
+	return fmt.Sprintf("reviewdog found %d result(s): %s", len(comments), commentutil.SeverityCounts(comments))
+}
+
+// buildReviewComments returns review comments which have not been posted yet
+// and comments which cannot be posted in a review.
+func (g *PullRequest) buildReviewComments() ([]*github.DraftReviewComment, []*reviewdog.Comment) {
 	comments := make([]*github.DraftReviewComment, 0, len(g.postComments))
 	remaining := make([]*reviewdog.Comment, 0)
+	g.threads = nil
+	g.threadByKey = make(map[string]*codeThread)
 	for _, c := range g.postComments {
 		if !c.Result.InDiffContext {
 			// GitHub Review API cannot report results outside diff. If it's running
@@ -100,8 +342,30 @@ func (g *PullRequest) postAsReviewComment(ctx context.Context) error {
 			}
 			continue
 		}
-		body := buildBody(c)
+		if g.fingerprint && g.postedFingerprints.Contains(c) {
+			g.logger.Debugf("github-pr-review: skip a comment with the posted fingerprint: %s", c.Result.Diagnostic.GetLocation().GetPath())
+			continue
+		}
+		body := g.buildBody(c)
+		var thread *codeThread
+		if key := codeThreadKey(c); g.threadByCode && key != "" {
+			if t := g.threadByKey[key]; t != nil {
+				if t.overflow {
+					remaining = append(remaining, c)
+				} else if g.postedReplies[g.buildReplyBody(c)] {
+					g.logger.Debugf("github-pr-review: skip a posted reply: %s:%d", c.Result.Diagnostic.GetLocation().GetPath(), githubCommentLine(c))
+				} else {
+					t.replies = append(t.replies, c)
+				}
+				continue
+			}
+			thread = &codeThread{root: c, rootBody: body}
+			thread.rootID = g.postedCommentIDs[commentIDKey(c.Result.Diagnostic.GetLocation().GetPath(), githubCommentLine(c), body)]
+			g.threads = append(g.threads, thread)
+			g.threadByKey[key] = thread
+		}
 		if g.postedcs.IsPosted(c, githubCommentLine(c), body) {
+			g.logger.Debugf("github-pr-review: skip a posted comment: %s:%d", c.Result.Diagnostic.GetLocation().GetPath(), githubCommentLine(c))
 			continue
 		}
 		// Only posts maxCommentsPerRequest comments per 1 request to avoid spammy
@@ -113,24 +377,18 @@ func (g *PullRequest) postAsReviewComment(ctx context.Context) error {
 		// > again later.
 		// https://developer.github.com/v3/#abuse-rate-limits
 		if len(comments) >= maxCommentsPerRequest {
+			if len(remaining) == 0 {
+				g.logger.Warnf("github-pr-review: too many review comments. Comments over %d are listed in the review body", maxCommentsPerRequest)
+			}
 			remaining = append(remaining, c)
+			if thread != nil {
+				thread.overflow = true
+			}
 			continue
 		}
 		comments = append(comments, buildDraftReviewComment(c, body))
 	}
-
-	if len(comments) == 0 {
-		return nil
-	}
-
-	review := &github.PullRequestReviewRequest{
-		CommitID: &g.sha,
-		Event:    github.String("COMMENT"),
-		Comments: comments,
-		Body:     github.String(g.remainingCommentsSummary(remaining)),
-	}
-	_, _, err := g.cli.PullRequests.CreateReview(ctx, g.owner, g.repo, g.pr, review)
-	return err
+	return comments, remaining
 }
 
 // Document: https://docs.github.com/en/rest/reference/pulls#create-a-review-comment-for-a-pull-request
@@ -207,15 +465,25 @@ func (g *PullRequest) remainingCommentsSummary(remaining []*reviewdog.Comment) s
 
 func (g *PullRequest) setPostedComment(ctx context.Context) error {
 	g.postedcs = make(commentutil.PostedComments)
+	g.postedFingerprints = make(commentutil.Fingerprints)
+	g.postedCommentIDs = make(map[string]int64)
+	g.postedReplies = make(map[string]bool)
 	cs, err := g.comment(ctx)
 	if err != nil {
 		return err
 	}
+	g.logger.Debugf("github-pr-review: found %d existing review comments", len(cs))
 	for _, c := range cs {
+		// Outdated comments don't have line but still have fingerprints.
+		g.postedFingerprints.Add(c.GetBody())
+		if c.InReplyTo != nil {
+			g.postedReplies[c.GetBody()] = true
+		}
 		if c.Line == nil || c.Path == nil || c.Body == nil {
 			continue
 		}
 		g.postedcs.AddPostedComment(c.GetPath(), c.GetLine(), c.GetBody())
+		g.postedCommentIDs[commentIDKey(c.GetPath(), c.GetLine(), c.GetBody())] = c.GetID()
 	}
 	return nil
 }
@@ -223,7 +491,12 @@ func (g *PullRequest) setPostedComment(ctx context.Context) error {
 // Diff returns a diff of PullRequest.
 func (g *PullRequest) Diff(ctx context.Context) ([]byte, error) {
 	opt := github.RawOptions{Type: github.Diff}
-	d, _, err := g.cli.PullRequests.GetRaw(ctx, g.owner, g.repo, g.pr, opt)
+	var d string
+	err := withRateLimitRetry(ctx, g.logger, g.rateLimitMaxWait, func() error {
+		var err error
+		d, _, err = g.cli.PullRequests.GetRaw(ctx, g.owner, g.repo, g.pr, opt)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -242,7 +515,12 @@ func (g *PullRequest) comment(ctx context.Context) ([]*github.PullRequestComment
 			PerPage: 100,
 		},
 	}
-	comments, err := listAllPullRequestsComments(ctx, g.cli, g.owner, g.repo, g.pr, opts)
+	var comments []*github.PullRequestComment
+	err := withRateLimitRetry(ctx, g.logger, g.rateLimitMaxWait, func() error {
+		var err error
+		comments, err = listAllPullRequestsComments(ctx, g.cli, g.owner, g.repo, g.pr, opts)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -271,10 +549,17 @@ func listAllPullRequestsComments(ctx context.Context, cli *github.Client,
 	return append(comments, restComments...), nil
 }
 
-func buildBody(c *reviewdog.Comment) string {
-	cbody := commentutil.MarkdownComment(c)
+func (g *PullRequest) buildBody(c *reviewdog.Comment) string {
+	cbody := g.tmpl.Body(c)
 	if suggestion := buildSuggestions(c); suggestion != "" {
 		cbody += "\n" + suggestion
+	} else if rng := commentutil.ColumnRange(c); rng != "" {
+		// Review comments can't show columns, so render them under the lines
+		// unless suggestions already show the exact change.
+		cbody += "\n\n" + rng
+	}
+	if g.fingerprint {
+		cbody = commentutil.AppendFingerprint(cbody, c)
 	}
 	return cbody
 }
@@ -282,6 +567,12 @@ func buildBody(c *reviewdog.Comment) string {
 func buildSuggestions(c *reviewdog.Comment) string {
 	var sb strings.Builder
 	for _, s := range c.Result.Diagnostic.GetSuggestions() {
+		if !suggestionMatchesCommentRange(c, s) {
+			// GitHub can only apply a suggestion which replaces exactly the
+			// commented lines (e.g. it's outside the diff context). Fall back to
+			// a plain comment for it.
+			continue
+		}
 		txt, err := buildSingleSuggestion(c, s)
 		if err != nil {
 			sb.WriteString(invalidSuggestionPre + err.Error() + invalidSuggestionPost + "\n")
@@ -293,19 +584,19 @@ func buildSuggestions(c *reviewdog.Comment) string {
 	return sb.String()
 }
 
-func buildSingleSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, error) {
-	start := s.GetRange().GetStart()
-	startLine := int(start.GetLine())
-	end := s.GetRange().GetEnd()
-	endLine := int(end.GetLine())
+func suggestionMatchesCommentRange(c *reviewdog.Comment, s *rdf.Suggestion) bool {
+	startLine := int(s.GetRange().GetStart().GetLine())
+	endLine := int(s.GetRange().GetEnd().GetLine())
 	if endLine == 0 {
 		endLine = startLine
 	}
 	gStart, gEnd := githubCommentLineRange(c)
-	if startLine != gStart || endLine != gEnd {
-		return "", fmt.Errorf("GitHub comment range and suggestion line range must be same. L%d-L%d v.s. L%d-L%d",
-			gStart, gEnd, startLine, endLine)
-	}
+	return startLine == gStart && endLine == gEnd
+}
+
+func buildSingleSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, error) {
+	start := s.GetRange().GetStart()
+	end := s.GetRange().GetEnd()
 	if start.GetColumn() > 0 || end.GetColumn() > 0 {
 		return buildNonLineBasedSuggestion(c, s)
 	}
@@ -326,22 +617,10 @@ func buildSingleSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, err
 }
 
 func buildNonLineBasedSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, error) {
-	sourceLines := c.Result.SourceLines
-	if len(sourceLines) == 0 {
-		return "", errors.New("source lines are not available")
-	}
-	start := s.GetRange().GetStart()
-	end := s.GetRange().GetEnd()
-	startLineContent, err := getSourceLine(sourceLines, int(start.GetLine()))
+	txt, err := commentutil.LineBasedSuggestionText(c, s)
 	if err != nil {
 		return "", err
 	}
-	endLineContent, err := getSourceLine(sourceLines, int(end.GetLine()))
-	if err != nil {
-		return "", err
-	}
-
-	txt := startLineContent[:max(start.GetColumn()-1, 0)] + s.GetText() + endLineContent[max(end.GetColumn()-1, 0):]
 	backticks := commentutil.GetCodeFenceLength(txt)
 
 	var sb strings.Builder
@@ -353,18 +632,3 @@ func buildNonLineBasedSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (strin
 	commentutil.WriteCodeFence(&sb, backticks)
 	return sb.String(), nil
 }
-
-func getSourceLine(sourceLines map[int]string, line int) (string, error) {
-	lineContent, ok := sourceLines[line]
-	if !ok {
-		return "", fmt.Errorf("source line (L=%d) is not available for this suggestion", line)
-	}
-	return lineContent, nil
-}
-
-func max(x, y int32) int32 {
-	if x < y {
-		return y
-	}
-	return x
-}
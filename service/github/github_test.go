@@ -7,8 +7,10 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v39/github"
 	"github.com/kylelemons/godebug/pretty"
@@ -18,6 +20,7 @@ import (
 	"github.com/reviewdog/reviewdog/filter"
 	"github.com/reviewdog/reviewdog/proto/rdf"
 	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
 )
 
 const notokenSkipTestMes = "skipping test (requires actual Personal access tokens. export REVIEWDOG_TEST_GITHUB_API_TOKEN=<GitHub Personal Access Token>)"
@@ -311,10 +314,7 @@ func TestGitHubPullRequest_Post_Flush_review_api(t *testing.T) {
 				StartSide: github.String("RIGHT"),
 				StartLine: github.Int(15),
 				Line:      github.Int(16),
-				Body: github.String(commentutil.BodyPrefix + strings.Join([]string{
-					"invalid lines suggestion comment",
-					invalidSuggestionPre + "GitHub comment range and suggestion line range must be same. L15-L16 v.s. L16-L17" + invalidSuggestionPost,
-				}, "\n") + "\n"),
+				Body:      github.String(commentutil.BodyPrefix + "suggestion outside diff context falls back to plain comment"),
 			},
 			{
 				Path:      github.String("reviewdog.go"),
@@ -338,13 +338,12 @@ func TestGitHubPullRequest_Post_Flush_review_api(t *testing.T) {
 				StartLine: github.Int(14),
 				Line:      github.Int(16),
 				Body: github.String(commentutil.BodyPrefix + strings.Join([]string{
-					"Partially invalid suggestions",
+					"Suggestions not matching the comment range fall back to plain comment",
 					"```suggestion",
 					"line1",
 					"line2",
 					"line3",
 					"```",
-					invalidSuggestionPre + "GitHub comment range and suggestion line range must be same. L14-L16 v.s. L14-L14" + invalidSuggestionPost,
 				}, "\n") + "\n"),
 			},
 			{
@@ -694,7 +693,7 @@ func TestGitHubPullRequest_Post_Flush_review_api(t *testing.T) {
 							Text: "line1\nline2\nline3",
 						},
 					},
-					Message: "invalid lines suggestion comment",
+					Message: "suggestion outside diff context falls back to plain comment",
 				},
 				InDiffContext:                true,
 				FirstSuggestionInDiffContext: false,
@@ -775,7 +774,7 @@ func TestGitHubPullRequest_Post_Flush_review_api(t *testing.T) {
 							Text: "line1\nline2",
 						},
 					},
-					Message: "Partially invalid suggestions",
+					Message: "Suggestions not matching the comment range fall back to plain comment",
 				},
 				InDiffContext:                true,
 				FirstSuggestionInDiffContext: true,
@@ -1230,3 +1229,590 @@ func TestGitHubPullRequest_Diff_fake(t *testing.T) {
 		t.Errorf("GitHub API should be called once; called %v times", apiCalled)
 	}
 }
+
+func TestBuildSuggestions(t *testing.T) {
+	lineRange := func(start, end int32) *rdf.Range {
+		return &rdf.Range{
+			Start: &rdf.Position{Line: start},
+			End:   &rdf.Position{Line: end},
+		}
+	}
+	tests := []struct {
+		name                 string
+		location             *rdf.Range
+		suggestions          []*rdf.Suggestion
+		suggestionInDiffCtx  bool
+		wantStart, wantEnd   int
+		wantSuggestionBlocks string
+	}{
+		{
+			name:                 "single-line",
+			location:             lineRange(14, 0),
+			suggestions:          []*rdf.Suggestion{&rdf.Suggestion{Range: lineRange(14, 14), Text: "line1"}},
+			suggestionInDiffCtx:  true,
+			wantStart:            14,
+			wantEnd:              14,
+			wantSuggestionBlocks: "```suggestion\nline1\n```\n",
+		},
+		{
+			name:                 "multi-line",
+			location:             lineRange(14, 0),
+			suggestions:          []*rdf.Suggestion{&rdf.Suggestion{Range: lineRange(14, 16), Text: "line1\nline2"}},
+			suggestionInDiffCtx:  true,
+			wantStart:            14,
+			wantEnd:              16,
+			wantSuggestionBlocks: "```suggestion\nline1\nline2\n```\n",
+		},
+		{
+			name:                 "multi-line outside diff context",
+			location:             lineRange(14, 0),
+			suggestions:          []*rdf.Suggestion{&rdf.Suggestion{Range: lineRange(14, 30), Text: "line1\nline2"}},
+			suggestionInDiffCtx:  false,
+			wantStart:            14,
+			wantEnd:              14,
+			wantSuggestionBlocks: "",
+		},
+		{
+			name:                 "inside but not same as comment range",
+			location:             lineRange(14, 16),
+			suggestions:          []*rdf.Suggestion{{Range: lineRange(15, 15), Text: "line1"}},
+			suggestionInDiffCtx:  false,
+			wantStart:            14,
+			wantEnd:              16,
+			wantSuggestionBlocks: "",
+		},
+		{
+			name:     "later suggestion not matching comment range",
+			location: lineRange(14, 0),
+			suggestions: []*rdf.Suggestion{
+				{Range: lineRange(14, 14), Text: "line1"},
+				{Range: lineRange(14, 16), Text: "line1\nline2"},
+			},
+			suggestionInDiffCtx:  true,
+			wantStart:            14,
+			wantEnd:              14,
+			wantSuggestionBlocks: "```suggestion\nline1\n```\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &reviewdog.Comment{
+				Result: &filter.FilteredDiagnostic{
+					Diagnostic: &rdf.Diagnostic{
+						Location:    &rdf.Location{Path: "reviewdog.go", Range: tt.location},
+						Suggestions: tt.suggestions,
+					},
+					InDiffContext:                true,
+					FirstSuggestionInDiffContext: tt.suggestionInDiffCtx,
+				},
+			}
+			if start, end := githubCommentLineRange(c); start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("githubCommentLineRange() = L%d-L%d, want L%d-L%d", start, end, tt.wantStart, tt.wantEnd)
+			}
+			if got := buildSuggestions(c); got != tt.wantSuggestionBlocks {
+				t.Errorf("buildSuggestions() = %q, want %q", got, tt.wantSuggestionBlocks)
+			}
+		})
+	}
+}
+
+func TestGitHubPullRequest_Diff_rateLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        map[string]string
+		maxWait       time.Duration
+		wantAPICalled int
+		wantErr       string
+	}{
+		{
+			name:          "retry after secondary rate limit",
+			header:        map[string]string{"Retry-After": "1"},
+			maxWait:       time.Minute,
+			wantAPICalled: 2,
+		},
+		{
+			name:          "Retry-After exceeds max wait",
+			header:        map[string]string{"Retry-After": "120"},
+			maxWait:       time.Minute,
+			wantAPICalled: 1,
+			wantErr:       "GitHub API secondary rate limit exceeded",
+		},
+		{
+			name: "primary rate limit exhausted",
+			header: map[string]string{
+				"X-RateLimit-Limit":     "5000",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10),
+			},
+			maxWait:       time.Minute,
+			wantAPICalled: 1,
+			wantErr:       "GitHub API rate limit exceeded (limit: 5000)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiCalled := 0
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/o/r/pulls/14", func(w http.ResponseWriter, r *http.Request) {
+				apiCalled++
+				if apiCalled == 1 {
+					for k, v := range tt.header {
+						w.Header().Set(k, v)
+					}
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"message":"You have exceeded a secondary rate limit.","documentation_url":"https://docs.github.com/rest/overview/resources-in-the-rest-api#secondary-rate-limits"}`))
+					return
+				}
+				w.Write([]byte("Pull Request diff"))
+			})
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			cli := github.NewClient(nil)
+			cli.BaseURL, _ = url.Parse(ts.URL + "/")
+			g, err := NewGitHubPullRequest(cli, "o", "r", 14, "sha", WithRateLimitMaxWait(tt.maxWait))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = g.Diff(context.Background())
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("got error %v, want error containing %q", err, tt.wantErr)
+			}
+			if apiCalled != tt.wantAPICalled {
+				t.Errorf("GitHub API is called %d times, want %d times", apiCalled, tt.wantAPICalled)
+			}
+		})
+	}
+}
+
+func TestRateLimitWait_pastReset(t *testing.T) {
+	err := &github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: time.Now().Add(-time.Minute)}}}
+	wait, ok := rateLimitWait(err)
+	if !ok || wait != defaultSecondaryRateLimitWait {
+		t.Errorf("rateLimitWait() = %v, %v, want %v, true", wait, ok, defaultSecondaryRateLimitWait)
+	}
+}
+
+func TestWithRateLimitRetry_maxRetries(t *testing.T) {
+	called := 0
+	zero := time.Duration(0)
+	err := withRateLimitRetry(context.Background(), serviceutil.DefaultLogger(), time.Minute, func() error {
+		called++
+		return &github.AbuseRateLimitError{RetryAfter: &zero}
+	})
+	if err == nil {
+		t.Error("got no error")
+	}
+	if want := maxRateLimitRetries + 1; called != want {
+		t.Errorf("called %d times, want %d times", called, want)
+	}
+}
+
+func TestGitHubPullRequest_Flush_commentMode(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	moveToRootDir()
+	defer setupEnvs()()
+
+	tests := []struct {
+		mode         commentutil.CommentMode
+		wantComments int
+		wantSummary  bool
+	}{
+		{mode: commentutil.CommentModeInline, wantComments: 2},
+		{mode: commentutil.CommentModeSummary, wantComments: 0, wantSummary: true},
+		{mode: commentutil.CommentModeBoth, wantComments: 2, wantSummary: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			var req github.PullRequestReviewRequest
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/o/r/pulls/14/comments", func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewEncoder(w).Encode([]*github.PullRequestComment{}); err != nil {
+					t.Fatal(err)
+				}
+			})
+			mux.HandleFunc("/repos/o/r/pulls/14/reviews", func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Error(err)
+				}
+			})
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			cli := github.NewClient(nil)
+			cli.BaseURL, _ = url.Parse(ts.URL + "/")
+			g, err := NewGitHubPullRequest(cli, "o", "r", 14, "sha", WithCommentMode(tt.mode))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 1; i <= 2; i++ {
+				c := &reviewdog.Comment{
+					Result: &filter.FilteredDiagnostic{
+						Diagnostic: &rdf.Diagnostic{
+							Location: &rdf.Location{
+								Path:  "reviewdog.go",
+								Range: &rdf.Range{Start: &rdf.Position{Line: int32(i)}},
+							},
+							Message: "comment",
+						},
+						InDiffContext: true,
+					},
+					ToolName: "tool",
+				}
+				if err := g.Post(context.Background(), c); err != nil {
+					t.Error(err)
+				}
+			}
+			if err := g.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := len(req.Comments); got != tt.wantComments {
+				t.Errorf("got %d review comments, want %d", got, tt.wantComments)
+			}
+			if got := strings.Contains(req.GetBody(), "**2 result(s)**"); got != tt.wantSummary {
+				t.Errorf("review body contains summary = %v, want %v: %q", got, tt.wantSummary, req.GetBody())
+			}
+		})
+	}
+}
+
+func TestGitHubPullRequest_Flush_fingerprint(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	moveToRootDir()
+	defer setupEnvs()()
+
+	newComment := func(line int32, msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "reviewdog.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: msg,
+				},
+				InDiffContext: true,
+			},
+			ToolName: "tool",
+		}
+	}
+	// The result was posted on line 1 in the previous run, and the comment is
+	// outdated now.
+	posted := commentutil.AppendFingerprint(commentutil.MarkdownComment(newComment(1, "moved")), newComment(1, "moved"))
+
+	var req github.PullRequestReviewRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/pulls/14/comments", func(w http.ResponseWriter, r *http.Request) {
+		cs := []*github.PullRequestComment{
+			{Path: github.String("reviewdog.go"), Body: github.String(posted)},
+		}
+		if err := json.NewEncoder(w).Encode(cs); err != nil {
+			t.Fatal(err)
+		}
+	})
+	mux.HandleFunc("/repos/o/r/pulls/14/reviews", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli := github.NewClient(nil)
+	cli.BaseURL, _ = url.Parse(ts.URL + "/")
+	g, err := NewGitHubPullRequest(cli, "o", "r", 14, "sha", WithFingerprint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*reviewdog.Comment{newComment(2, "moved"), newComment(3, "new")} {
+		if err := g.Post(context.Background(), c); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Comments) != 1 {
+		t.Fatalf("got %d review comments, want 1: %v", len(req.Comments), req.Comments)
+	}
+	want := commentutil.AppendFingerprint(commentutil.MarkdownComment(newComment(3, "new")), newComment(3, "new"))
+	if got := req.Comments[0].GetBody(); got != want {
+		t.Errorf("got body %q, want %q", got, want)
+	}
+}
+
+func TestGitHubPullRequest_Flush_reviewEvent(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	moveToRootDir()
+	defer setupEnvs()()
+
+	tests := []struct {
+		name       string
+		opts       []PullRequestOption
+		severities []rdf.Severity
+		wantEvent  string // empty if no review is submitted
+		wantBody   string
+	}{
+		{
+			name:       "comment by default",
+			severities: []rdf.Severity{rdf.Severity_ERROR},
+			wantEvent:  "COMMENT",
+		},
+		{
+			name:       "request changes on error",
+			opts:       []PullRequestOption{WithRequestChanges(filter.SeverityLevelError)},
+			severities: []rdf.Severity{rdf.Severity_WARNING, rdf.Severity_ERROR},
+			wantEvent:  "REQUEST_CHANGES",
+		},
+		{
+			name:       "comment on results under the level",
+			opts:       []PullRequestOption{WithRequestChanges(filter.SeverityLevelError)},
+			severities: []rdf.Severity{rdf.Severity_WARNING, rdf.Severity_INFO},
+			wantEvent:  "COMMENT",
+		},
+		{
+			name:      "approve without results",
+			opts:      []PullRequestOption{WithRequestChanges(filter.SeverityLevelError), WithApprove()},
+			wantEvent: "APPROVE",
+		},
+		{
+			name:       "never approve with results",
+			opts:       []PullRequestOption{WithApprove()},
+			severities: []rdf.Severity{rdf.Severity_INFO},
+			wantEvent:  "COMMENT",
+		},
+		{
+			name: "no review without results nor approval",
+		},
+		{
+			name:      "report no results",
+			opts:      []PullRequestOption{WithReportNoResults()},
+			wantEvent: "COMMENT",
+			wantBody:  commentutil.NoResultsComment,
+		},
+		{
+			name:       "report no results only without results",
+			opts:       []PullRequestOption{WithReportNoResults()},
+			severities: []rdf.Severity{rdf.Severity_ERROR},
+			wantEvent:  "COMMENT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *github.PullRequestReviewRequest
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/o/r/pulls/14/comments", func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewEncoder(w).Encode([]*github.PullRequestComment{}); err != nil {
+					t.Fatal(err)
+				}
+			})
+			mux.HandleFunc("/repos/o/r/pulls/14/reviews", func(w http.ResponseWriter, r *http.Request) {
+				req = new(github.PullRequestReviewRequest)
+				if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+					t.Error(err)
+				}
+			})
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			cli := github.NewClient(nil)
+			cli.BaseURL, _ = url.Parse(ts.URL + "/")
+			g, err := NewGitHubPullRequest(cli, "o", "r", 14, "sha", tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, severity := range tt.severities {
+				c := &reviewdog.Comment{
+					Result: &filter.FilteredDiagnostic{
+						Diagnostic: &rdf.Diagnostic{
+							Location: &rdf.Location{
+								Path:  "reviewdog.go",
+								Range: &rdf.Range{Start: &rdf.Position{Line: int32(i + 1)}},
+							},
+							Message:  "comment",
+							Severity: severity,
+						},
+						InDiffContext: true,
+					},
+					ToolName: "tool",
+				}
+				if err := g.Post(context.Background(), c); err != nil {
+					t.Error(err)
+				}
+			}
+			if err := g.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantEvent == "" {
+				if req != nil {
+					t.Errorf("got a %s review, want no reviews", req.GetEvent())
+				}
+				return
+			}
+			if req == nil {
+				t.Fatalf("got no reviews, want a %s review", tt.wantEvent)
+			}
+			if req.GetEvent() != tt.wantEvent {
+				t.Errorf("got a %s review, want %s", req.GetEvent(), tt.wantEvent)
+			}
+			if tt.wantEvent != "COMMENT" && req.GetBody() == "" {
+				t.Errorf("%s review must have a body", req.GetEvent())
+			}
+			if tt.wantBody != "" && req.GetBody() != tt.wantBody {
+				t.Errorf("got review body %q, want %q", req.GetBody(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestPullRequest_buildBody_columnRange(t *testing.T) {
+	newComment := func(startCol, endCol int32, suggestions ...*rdf.Suggestion) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path: "reviewdog.go",
+						Range: &rdf.Range{
+							Start: &rdf.Position{Line: 1, Column: startCol},
+							End:   &rdf.Position{Line: 1, Column: endCol},
+						},
+					},
+					Message:     "msg",
+					Suggestions: suggestions,
+				},
+				SourceLines:                  map[int]string{1: "x := foo(bar)"},
+				InDiffContext:                true,
+				FirstSuggestionInDiffContext: true,
+			},
+		}
+	}
+	g := &PullRequest{}
+	if got, want := g.buildBody(newComment(6, 9)), commentutil.BodyPrefix+"msg\n\n```\nx := foo(bar)\n     ^^^\n```"; got != want {
+		t.Errorf("with columns: got %q, want %q", got, want)
+	}
+	if got, want := g.buildBody(newComment(0, 0)), commentutil.BodyPrefix+"msg"; got != want {
+		t.Errorf("without columns: got %q, want %q", got, want)
+	}
+	// Suggestions show the change, so the columns are not rendered.
+	s := &rdf.Suggestion{Range: &rdf.Range{Start: &rdf.Position{Line: 1}, End: &rdf.Position{Line: 1}}, Text: "x := baz(bar)"}
+	if got := g.buildBody(newComment(6, 9, s)); strings.Contains(got, "^^^") {
+		t.Errorf("with suggestions: got %q, want no column range", got)
+	}
+}
+
+func TestGitHubPullRequest_Flush_threadByCode(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	moveToRootDir()
+	defer setupEnvs()()
+
+	newComment := func(line int32, code string) *reviewdog.Comment {
+		d := &rdf.Diagnostic{
+			Location: &rdf.Location{
+				Path:  "reviewdog.go",
+				Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+			},
+			Message: "msg",
+		}
+		if code != "" {
+			d.Code = &rdf.Code{Value: code}
+		}
+		return &reviewdog.Comment{
+			Result:   &filter.FilteredDiagnostic{Diagnostic: d, InDiffContext: true},
+			ToolName: "tool",
+		}
+	}
+
+	tests := []struct {
+		name string
+		// listRoot lists the root comment in the review.
+		listRoot        bool
+		wantReviews     []int // # of comments of each review
+		wantRepliesToID []int64
+	}{
+		{name: "reply", listRoot: true, wantReviews: []int{2}, wantRepliesToID: []int64{100}},
+		{name: "fallback", listRoot: false, wantReviews: []int{2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reviews []*github.PullRequestReviewRequest
+			var repliesToID []int64
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/o/r/pulls/14/comments", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte("[]"))
+					return
+				}
+				var req struct {
+					Body      string `json:"body"`
+					InReplyTo int64  `json:"in_reply_to"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Error(err)
+				}
+				if !strings.Contains(req.Body, "reviewdog.go|2|") {
+					t.Errorf("reply body doesn't have the location: %q", req.Body)
+				}
+				repliesToID = append(repliesToID, req.InReplyTo)
+				w.Write([]byte("{}"))
+			})
+			mux.HandleFunc("/repos/o/r/pulls/14/reviews", func(w http.ResponseWriter, r *http.Request) {
+				var req github.PullRequestReviewRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Error(err)
+				}
+				reviews = append(reviews, &req)
+				w.Write([]byte(`{"id": 10}`))
+			})
+			mux.HandleFunc("/repos/o/r/pulls/14/reviews/10/comments", func(w http.ResponseWriter, r *http.Request) {
+				var cs []*github.PullRequestComment
+				if tt.listRoot {
+					for i, c := range reviews[0].Comments {
+						cs = append(cs, &github.PullRequestComment{
+							ID:   github.Int64(int64(100 + i)),
+							Path: c.Path,
+							Line: c.Line,
+							Body: c.Body,
+						})
+					}
+				}
+				if err := json.NewEncoder(w).Encode(cs); err != nil {
+					t.Fatal(err)
+				}
+			})
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			cli := github.NewClient(nil)
+			cli.BaseURL, _ = url.Parse(ts.URL + "/")
+			g, err := NewGitHubPullRequest(cli, "o", "r", 14, "sha", WithThreadByCode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range []*reviewdog.Comment{newComment(1, "c1"), newComment(2, "c1"), newComment(3, "")} {
+				if err := g.Post(context.Background(), c); err != nil {
+					t.Error(err)
+				}
+			}
+			if err := g.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			var gotReviews []int
+			for _, r := range reviews {
+				gotReviews = append(gotReviews, len(r.Comments))
+			}
+			if diff := pretty.Compare(gotReviews, tt.wantReviews); diff != "" {
+				t.Errorf("# of review comments diff: (-got +want)\n%s", diff)
+			}
+			if diff := pretty.Compare(repliesToID, tt.wantRepliesToID); diff != "" {
+				t.Errorf("replies diff: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package githubutils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+var _ reviewdog.CommentService = &GitHubAnnotationWriter{}
+
+// GitHubAnnotationWriter reports results as workflow commands (e.g.
+// `::error file=a.go,line=1::message`) so that GitHub Actions creates
+// annotations of them. Unlike GitHubActionLogWriter, it works without Pull
+// Requests and writes commands to the given writer.
+//
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-error-message
+type GitHubAnnotationWriter struct {
+	w io.Writer
+	// level is the command used for results without severity.
+	level string
+}
+
+// NewGitHubAnnotationWriter returns new GitHubAnnotationWriter. level is the
+// level ("error", "warning" or "info") of results without severity.
+func NewGitHubAnnotationWriter(w io.Writer, level string) *GitHubAnnotationWriter {
+	return &GitHubAnnotationWriter{w: w, level: level}
+}
+
+func (aw *GitHubAnnotationWriter) Post(_ context.Context, c *reviewdog.Comment) error {
+	_, err := fmt.Fprintln(aw.w, WorkflowCommand(c.ToolName, aw.level, c.Result.Diagnostic))
+	return err
+}
+
+// WorkflowCommand returns the workflow command which creates an annotation of
+// the diagnostic. ERROR, WARNING and INFO severities are reported as
+// `::error`, `::warning` and `::notice` commands respectively, and
+// defaultLevel is used for diagnostics without severity.
+func WorkflowCommand(toolName, defaultLevel string, d *rdf.Diagnostic) string {
+	var props []string
+	addProp := func(key, value string) {
+		props = append(props, key+"="+escapeProperty(value))
+	}
+	rng := d.GetLocation().GetRange()
+	start, end := rng.GetStart(), rng.GetEnd()
+	if path := d.GetLocation().GetPath(); path != "" {
+		addProp("file", path)
+	}
+	if start.GetLine() > 0 {
+		addProp("line", strconv.Itoa(int(start.GetLine())))
+		if end.GetLine() > start.GetLine() {
+			addProp("endLine", strconv.Itoa(int(end.GetLine())))
+		}
+		if start.GetColumn() > 0 {
+			addProp("col", strconv.Itoa(int(start.GetColumn())))
+			// endColumn is valid only for single line annotations.
+			if (end.GetLine() == 0 || end.GetLine() == start.GetLine()) && end.GetColumn() > start.GetColumn() {
+				addProp("endColumn", strconv.Itoa(int(end.GetColumn())))
+			}
+		}
+	}
+	if title := annotationTitle(toolName, d); title != "" {
+		addProp("title", title)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("::")
+	sb.WriteString(workflowCommandName(defaultLevel, d.GetSeverity()))
+	if len(props) > 0 {
+		sb.WriteString(" ")
+		sb.WriteString(strings.Join(props, ","))
+	}
+	sb.WriteString("::")
+	sb.WriteString(escapeData(d.GetMessage()))
+	return sb.String()
+}
+
+func workflowCommandName(defaultLevel string, s rdf.Severity) string {
+	switch s {
+	case rdf.Severity_ERROR:
+		return "error"
+	case rdf.Severity_WARNING:
+		return "warning"
+	case rdf.Severity_INFO:
+		return "notice"
+	}
+	switch defaultLevel {
+	case "warning":
+		return "warning"
+	case "info":
+		return "notice"
+	default:
+		return "error"
+	}
+}
+
+// annotationTitle returns the title of the annotation. e.g. "[golint] <code>".
+func annotationTitle(toolName string, d *rdf.Diagnostic) string {
+	if name := d.GetSource().GetName(); name != "" {
+		toolName = name
+	}
+	var parts []string
+	if toolName != "" {
+		parts = append(parts, "["+toolName+"]")
+	}
+	if code := d.GetCode().GetValue(); code != "" {
+		parts = append(parts, "<"+code+">")
+	}
+	return strings.Join(parts, " ")
+}
+
+// escapeData escapes the message of workflow commands.
+//
+// https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+func escapeData(s string) string {
+	return strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+	).Replace(s)
+}
+
+// escapeProperty escapes property values of workflow commands.
+func escapeProperty(s string) string {
+	return strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	).Replace(s)
+}
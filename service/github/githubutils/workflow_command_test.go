@@ -0,0 +1,118 @@
+package githubutils
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestWorkflowCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		toolName     string
+		defaultLevel string
+		d            *rdf.Diagnostic
+		want         string
+	}{
+		{
+			name:     "error with column",
+			toolName: "golint",
+			d: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "path/to/file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 14, Column: 3}},
+				},
+				Message:  "msg",
+				Severity: rdf.Severity_ERROR,
+			},
+			want: "::error file=path/to/file.go,line=14,col=3,title=[golint]::msg",
+		},
+		{
+			name: "warning with range and code",
+			d: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path: "file.go",
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 1, Column: 2},
+						End:   &rdf.Position{Line: 1, Column: 5},
+					},
+				},
+				Message:  "msg",
+				Severity: rdf.Severity_WARNING,
+				Source:   &rdf.Source{Name: "staticcheck"},
+				Code:     &rdf.Code{Value: "SA1000"},
+			},
+			want: "::warning file=file.go,line=1,col=2,endColumn=5,title=[staticcheck] <SA1000>::msg",
+		},
+		{
+			name: "notice with multiline range",
+			d: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path: "file.go",
+					Range: &rdf.Range{
+						Start: &rdf.Position{Line: 1, Column: 2},
+						End:   &rdf.Position{Line: 3, Column: 5},
+					},
+				},
+				Message:  "msg",
+				Severity: rdf.Severity_INFO,
+			},
+			want: "::notice file=file.go,line=1,endLine=3,col=2::msg",
+		},
+		{
+			name:         "default level",
+			defaultLevel: "warning",
+			d: &rdf.Diagnostic{
+				Location: &rdf.Location{Path: "file.go"},
+				Message:  "msg",
+			},
+			want: "::warning file=file.go::msg",
+		},
+		{
+			name: "escape",
+			d: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "a,b:c%.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+				},
+				Message: "100% wrong\r\nsecond line: a,b",
+				Code:    &rdf.Code{Value: "a:b"},
+			},
+			want: "::error file=a%2Cb%3Ac%25.go,line=1,title=<a%3Ab>::100%25 wrong%0D%0Asecond line: a,b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WorkflowCommand(tt.toolName, tt.defaultLevel, tt.d); got != tt.want {
+				t.Errorf("WorkflowCommand() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubAnnotationWriter_Post(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewGitHubAnnotationWriter(&buf, "error")
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+				},
+				Message: "msg",
+			},
+		},
+		ToolName: "tool",
+	}
+	if err := w.Post(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if want := "::error file=file.go,line=1,title=[tool]::msg\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,96 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v39/github"
+
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+// DefaultRateLimitMaxWait is the default max total time to wait for GitHub
+// rate limits per API call.
+const DefaultRateLimitMaxWait = time.Minute
+
+// defaultSecondaryRateLimitWait is the time to wait for secondary rate limits
+// without Retry-After header.
+// https://docs.github.com/en/rest/guides/best-practices-for-integrators#dealing-with-secondary-rate-limits
+const defaultSecondaryRateLimitWait = time.Minute
+
+// maxRateLimitRetries is the max number of retries per API call, which bounds
+// retries with short waits (e.g. Retry-After: 0).
+const maxRateLimitRetries = 5
+
+// withRateLimitRetry calls f and retries it after waiting when it fails due to
+// GitHub rate limits. It gives up and returns an error if the total wait
+// exceeds maxWait or it retries maxRateLimitRetries times.
+func withRateLimitRetry(ctx context.Context, logger serviceutil.Logger, maxWait time.Duration, f func() error) error {
+	var waited time.Duration
+	for retries := 0; ; retries++ {
+		err := f()
+		if err == nil {
+			return nil
+		}
+		wait, ok := rateLimitWait(err)
+		if !ok {
+			return err
+		}
+		if retries >= maxRateLimitRetries {
+			return fmt.Errorf("GitHub API rate limit exceeded after %d retries: %w", retries, err)
+		}
+		if waited+wait > maxWait {
+			var rateErr *github.RateLimitError
+			if errors.As(err, &rateErr) {
+				return fmt.Errorf("GitHub API rate limit exceeded (limit: %d). It resets at %v: %w",
+					rateErr.Rate.Limit, rateErr.Rate.Reset.Time, err)
+			}
+			return fmt.Errorf("GitHub API secondary rate limit exceeded. Retry-After %v exceeds max wait %v: %w",
+				wait, maxWait, err)
+		}
+		logger.Infof("github-pr-review: GitHub API rate limit exceeded. Retrying in %v", wait)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		waited += wait
+	}
+}
+
+// rateLimitWait returns the time to wait before retrying a request failed with
+// err. It returns false if err isn't caused by rate limits.
+func rateLimitWait(err error) (time.Duration, bool) {
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		// X-RateLimit-Remaining is 0. Wait until the primary rate limit resets.
+		if wait := time.Until(rateErr.Rate.Reset.Time); wait > 0 {
+			return wait, true
+		}
+		// The reset time is already past (e.g. clock skew or a stale header).
+		// Back off as for secondary rate limits instead of retrying at once.
+		return defaultSecondaryRateLimitWait, true
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return *abuseErr.RetryAfter, true
+		}
+		return defaultSecondaryRateLimitWait, true
+	}
+	// go-github doesn't detect secondary rate limits whose documentation_url
+	// is "#secondary-rate-limits", so check Retry-After header as well.
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusForbidden {
+		if s := errResp.Response.Header.Get("Retry-After"); s != "" {
+			if sec, err := strconv.Atoi(s); err == nil {
+				return time.Duration(sec) * time.Second, true
+			}
+		}
+	}
+	return 0, false
+}
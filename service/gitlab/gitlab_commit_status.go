@@ -0,0 +1,140 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ reviewdog.BulkCommentService = &CommitStatusReporter{}
+
+// DefaultCommitStatusName is the default name (context) of commit statuses.
+const DefaultCommitStatusName = "reviewdog"
+
+// CommitStatusReporter is a comment service which sets a commit status of
+// GitLab to success or failed based on the number of results instead of
+// posting comments.
+//
+// API:
+//  https://docs.gitlab.com/ee/api/commits.html#set-the-pipeline-status-of-a-commit
+//  POST /projects/:id/statuses/:sha
+type CommitStatusReporter struct {
+	cli      *gitlab.Client
+	sha      string
+	projects string
+
+	muComments sync.Mutex
+	comments   []*reviewdog.Comment
+
+	// name is the name (context) of the commit status.
+	name string
+
+	// failLevel is the lowest severity of results counted to fail.
+	// failThreshold is the max number of counted results which doesn't fail.
+	failLevel     filter.SeverityLevel
+	failThreshold int
+
+	logger serviceutil.Logger
+}
+
+// CommitStatusReporterOption is an option for NewGitLabCommitStatusReporter.
+type CommitStatusReporterOption func(*CommitStatusReporter)
+
+// WithCommitStatusName sets the name (context) of the commit status.
+// DefaultCommitStatusName is used by default.
+func WithCommitStatusName(name string) CommitStatusReporterOption {
+	return func(g *CommitStatusReporter) {
+		g.name = name
+	}
+}
+
+// WithCommitStatusFailPolicy makes CommitStatusReporter set failed status only
+// when the number of results whose severity is the same or higher than the
+// level exceeds the threshold. By default, any result fails the status.
+func WithCommitStatusFailPolicy(level filter.SeverityLevel, threshold int) CommitStatusReporterOption {
+	return func(g *CommitStatusReporter) {
+		g.failLevel = level
+		g.failThreshold = threshold
+	}
+}
+
+// WithCommitStatusLogger sets the logger of CommitStatusReporter.
+// serviceutil.DefaultLogger is used by default.
+func WithCommitStatusLogger(logger serviceutil.Logger) CommitStatusReporterOption {
+	return func(g *CommitStatusReporter) {
+		g.logger = logger
+	}
+}
+
+// NewGitLabCommitStatusReporter returns a new CommitStatusReporter service.
+func NewGitLabCommitStatusReporter(cli *gitlab.Client, owner, repo string, sha string, opts ...CommitStatusReporterOption) *CommitStatusReporter {
+	g := &CommitStatusReporter{
+		cli:      cli,
+		sha:      sha,
+		projects: owner + "/" + repo,
+		name:     DefaultCommitStatusName,
+		logger:   serviceutil.DefaultLogger(),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Post accepts a comment and holds it. Flush method sets the commit status.
+func (g *CommitStatusReporter) Post(_ context.Context, c *reviewdog.Comment) error {
+	g.muComments.Lock()
+	defer g.muComments.Unlock()
+	g.comments = append(g.comments, c)
+	return nil
+}
+
+// Flush sets the commit status based on all the comments posted so far. It
+// keeps the comments, so the last Flush reflects results of all the tools
+// when Flush is called per tool.
+func (g *CommitStatusReporter) Flush(ctx context.Context) error {
+	g.muComments.Lock()
+	defer g.muComments.Unlock()
+
+	state := gitlab.Success
+	if failedResults(g.comments, g.failLevel, g.failThreshold) {
+		state = gitlab.Failed
+	}
+	desc := statusDescription(g.comments)
+	g.logger.Debugf("gitlab-commit-status: setting %s status of %s: %s", state, g.sha, desc)
+	_, _, err := g.cli.Commits.SetCommitStatus(g.projects, g.sha, &gitlab.SetCommitStatusOptions{
+		State:       state,
+		Name:        gitlab.String(g.name),
+		Description: gitlab.String(desc),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to set commit status: %w", err)
+	}
+	return nil
+}
+
+// failedResults returns true if the number of comments whose severity matches
+// the level exceeds the threshold.
+func failedResults(comments []*reviewdog.Comment, level filter.SeverityLevel, threshold int) bool {
+	counted := 0
+	for _, c := range comments {
+		if level.Match(c.Result.Diagnostic.GetSeverity()) {
+			counted++
+		}
+	}
+	return counted > threshold
+}
+
+func statusDescription(comments []*reviewdog.Comment) string {
+	if len(comments) == 0 {
+		return "No results found"
+	}
+	return fmt.Sprintf("%d result(s) found: %s", len(comments), commentutil.SeverityCounts(comments))
+}
@@ -0,0 +1,126 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestCommitStatusReporter_Flush(t *testing.T) {
+	newComment := func(severity rdf.Severity) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+					},
+					Message:  "message",
+					Severity: severity,
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name      string
+		opts      []CommitStatusReporterOption
+		comments  []*reviewdog.Comment
+		wantState string
+		wantName  string
+		wantDesc  string
+	}{
+		{
+			name:      "no results",
+			wantState: "success",
+			wantName:  "reviewdog",
+			wantDesc:  "No results found",
+		},
+		{
+			name:      "any result fails",
+			comments:  []*reviewdog.Comment{newComment(rdf.Severity_WARNING)},
+			wantState: "failed",
+			wantName:  "reviewdog",
+			wantDesc:  "1 result(s) found: 1 warning",
+		},
+		{
+			name: "results under fail level",
+			opts: []CommitStatusReporterOption{
+				WithCommitStatusName("lint"),
+				WithCommitStatusFailPolicy(filter.SeverityLevelError, 0),
+			},
+			comments:  []*reviewdog.Comment{newComment(rdf.Severity_WARNING), newComment(rdf.Severity_INFO)},
+			wantState: "success",
+			wantName:  "lint",
+			wantDesc:  "2 result(s) found: 1 warning, 1 info",
+		},
+		{
+			name: "results exceed threshold",
+			opts: []CommitStatusReporterOption{
+				WithCommitStatusFailPolicy(filter.SeverityLevelWarning, 1),
+			},
+			comments:  []*reviewdog.Comment{newComment(rdf.Severity_ERROR), newComment(rdf.Severity_WARNING)},
+			wantState: "failed",
+			wantName:  "reviewdog",
+			wantDesc:  "2 result(s) found: 1 error, 1 warning",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiCalled := 0
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v4/projects/o/r/statuses/sha", func(w http.ResponseWriter, r *http.Request) {
+				apiCalled++
+				if r.Method != http.MethodPost {
+					t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+				}
+				var req struct {
+					State       string `json:"state"`
+					Name        string `json:"name"`
+					Description string `json:"description"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Error(err)
+				}
+				if req.State != tt.wantState {
+					t.Errorf("state = %q, want %q", req.State, tt.wantState)
+				}
+				if req.Name != tt.wantName {
+					t.Errorf("name = %q, want %q", req.Name, tt.wantName)
+				}
+				if req.Description != tt.wantDesc {
+					t.Errorf("description = %q, want %q", req.Description, tt.wantDesc)
+				}
+				if err := json.NewEncoder(w).Encode(&gitlab.CommitStatus{}); err != nil {
+					t.Fatal(err)
+				}
+			})
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			g := NewGitLabCommitStatusReporter(cli, "o", "r", "sha", tt.opts...)
+			for _, c := range tt.comments {
+				if err := g.Post(context.Background(), c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := g.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if apiCalled != 1 {
+				t.Errorf("API should be called once; called %v times", apiCalled)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/service/serviceutil"
+)
+
+var _ reviewdog.BulkCommentService = &MergeRequestApprover{}
+
+// MergeRequestApprover is a comment service which records the verdict of
+// reviewdog as an approval of the token user on a GitLab MergeRequest instead
+// of posting comments. It approves the MergeRequest if there are no failing
+// results and revokes the approval otherwise, so that approval rules which
+// require the user block merging MergeRequests with failing results.
+//
+// The token needs the api scope and the user needs to be eligible to approve
+// MergeRequests of the project (e.g. Developer role).
+//
+// API:
+//  https://docs.gitlab.com/ee/api/merge_request_approvals.html#approve-merge-request
+//  POST /projects/:id/merge_requests/:merge_request_iid/approve
+//  POST /projects/:id/merge_requests/:merge_request_iid/unapprove
+type MergeRequestApprover struct {
+	cli      *gitlab.Client
+	sha      string
+	pr       int
+	projects string
+
+	muComments sync.Mutex
+	comments   []*reviewdog.Comment
+
+	// failLevel is the lowest severity of results counted to fail.
+	// failThreshold is the max number of counted results which doesn't fail.
+	failLevel     filter.SeverityLevel
+	failThreshold int
+
+	logger serviceutil.Logger
+}
+
+// MergeRequestApproverOption is an option for
+// NewGitLabMergeRequestApprover.
+type MergeRequestApproverOption func(*MergeRequestApprover)
+
+// WithApprovalFailPolicy makes MergeRequestApprover revoke the approval only
+// when the number of results whose severity is the same or higher than the
+// level exceeds the threshold. By default, any result revokes the approval.
+func WithApprovalFailPolicy(level filter.SeverityLevel, threshold int) MergeRequestApproverOption {
+	return func(g *MergeRequestApprover) {
+		g.failLevel = level
+		g.failThreshold = threshold
+	}
+}
+
+// WithApprovalLogger sets the logger of MergeRequestApprover.
+// serviceutil.DefaultLogger is used by default.
+func WithApprovalLogger(logger serviceutil.Logger) MergeRequestApproverOption {
+	return func(g *MergeRequestApprover) {
+		g.logger = logger
+	}
+}
+
+// NewGitLabMergeRequestApprover returns a new MergeRequestApprover service.
+func NewGitLabMergeRequestApprover(cli *gitlab.Client, owner, repo string, pr int, sha string, opts ...MergeRequestApproverOption) *MergeRequestApprover {
+	g := &MergeRequestApprover{
+		cli:      cli,
+		sha:      sha,
+		pr:       pr,
+		projects: owner + "/" + repo,
+		logger:   serviceutil.DefaultLogger(),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Post accepts a comment and holds it. Flush method approves or unapproves
+// the MergeRequest.
+func (g *MergeRequestApprover) Post(_ context.Context, c *reviewdog.Comment) error {
+	g.muComments.Lock()
+	defer g.muComments.Unlock()
+	g.comments = append(g.comments, c)
+	return nil
+}
+
+// Flush approves or unapproves the MergeRequest based on all the comments
+// posted so far. Like CommitStatusReporter, it keeps the comments, so the last
+// Flush reflects results of all the tools when Flush is called per tool. It
+// does nothing if the approval of the user is already in the wanted state.
+func (g *MergeRequestApprover) Flush(ctx context.Context) error {
+	g.muComments.Lock()
+	defer g.muComments.Unlock()
+
+	approvals, _, err := g.cli.MergeRequestApprovals.GetConfiguration(g.projects, g.pr, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to get MergeRequest approvals: %w", err)
+	}
+	failed := failedResults(g.comments, g.failLevel, g.failThreshold)
+	switch {
+	case failed && approvals.UserHasApproved:
+		g.logger.Debugf("gitlab-mr-approval: revoking the approval of !%d: %s", g.pr, statusDescription(g.comments))
+		if _, err := g.cli.MergeRequestApprovals.UnapproveMergeRequest(g.projects, g.pr, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("failed to unapprove MergeRequest: %w", err)
+		}
+	case !failed && !approvals.UserHasApproved:
+		g.logger.Debugf("gitlab-mr-approval: approving !%d at %s: %s", g.pr, g.sha, statusDescription(g.comments))
+		// The SHA makes GitLab reject the approval if the MergeRequest has new
+		// commits which reviewdog didn't check.
+		opt := &gitlab.ApproveMergeRequestOptions{SHA: gitlab.String(g.sha)}
+		if _, _, err := g.cli.MergeRequestApprovals.ApproveMergeRequest(g.projects, g.pr, opt, gitlab.WithContext(ctx)); err != nil {
+			return fmt.Errorf("failed to approve MergeRequest: %w", err)
+		}
+	default:
+		g.logger.Debugf("gitlab-mr-approval: the approval of !%d is up to date", g.pr)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+func TestMergeRequestApprover_Flush(t *testing.T) {
+	newComment := func(severity rdf.Severity) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+					},
+					Message:  "message",
+					Severity: severity,
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		opts     []MergeRequestApproverOption
+		approved bool
+		comments []*reviewdog.Comment
+		// wantCall is "approve", "unapprove" or "" for no call.
+		wantCall string
+	}{
+		{
+			name:     "no results",
+			wantCall: "approve",
+		},
+		{
+			name:     "no results and approved",
+			approved: true,
+		},
+		{
+			name:     "results revoke the approval",
+			approved: true,
+			comments: []*reviewdog.Comment{newComment(rdf.Severity_WARNING)},
+			wantCall: "unapprove",
+		},
+		{
+			name:     "results and not approved",
+			comments: []*reviewdog.Comment{newComment(rdf.Severity_WARNING)},
+		},
+		{
+			name: "results under fail level",
+			opts: []MergeRequestApproverOption{
+				WithApprovalFailPolicy(filter.SeverityLevelError, 0),
+			},
+			comments: []*reviewdog.Comment{newComment(rdf.Severity_WARNING)},
+			wantCall: "approve",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCall string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/approvals", func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewEncoder(w).Encode(&gitlab.MergeRequestApprovals{UserHasApproved: tt.approved}); err != nil {
+					t.Fatal(err)
+				}
+			})
+			mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/approve", func(w http.ResponseWriter, r *http.Request) {
+				gotCall = "approve"
+				var req gitlab.ApproveMergeRequestOptions
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Error(err)
+				}
+				if got := req.SHA; got == nil || *got != "sha" {
+					t.Errorf("approved sha = %v, want sha", got)
+				}
+				if err := json.NewEncoder(w).Encode(&gitlab.MergeRequestApprovals{}); err != nil {
+					t.Fatal(err)
+				}
+			})
+			mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/unapprove", func(w http.ResponseWriter, r *http.Request) {
+				gotCall = "unapprove"
+			})
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			g := NewGitLabMergeRequestApprover(cli, "o", "r", 14, "sha", tt.opts...)
+			for _, c := range tt.comments {
+				if err := g.Post(context.Background(), c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := g.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if gotCall != tt.wantCall {
+				t.Errorf("got call %q, want %q", gotCall, tt.wantCall)
+			}
+		})
+	}
+}
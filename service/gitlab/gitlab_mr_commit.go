@@ -17,6 +17,7 @@ import (
 )
 
 var _ reviewdog.CommentService = &MergeRequestCommitCommenter{}
+var _ reviewdog.GroupingCommentService = &MergeRequestCommitCommenter{}
 
 // MergeRequestCommitCommenter is a comment service for GitLab MergeRequest.
 //
@@ -34,24 +35,109 @@ type MergeRequestCommitCommenter struct {
 
 	postedcs commentutil.PostedComments
 
+	// fingerprint embeds fingerprints in comment bodies and skips comments
+	// whose fingerprint is found in existing commit comments.
+	fingerprint        bool
+	postedFingerprints commentutil.Fingerprints
+
+	logger serviceutil.Logger
+
 	// wd is working directory relative to root of repository.
 	wd string
+
+	// tmpl is the comment body template. MarkdownComment is used if nil.
+	tmpl *commentutil.Template
+
+	// maxMessageLength is the max length of diagnostic messages in comment
+	// bodies. Longer messages are truncated.
+	maxMessageLength int
+
+	// commentMode is whether to post commit comments, a summary note or both.
+	commentMode commentutil.CommentMode
+
+	// reportNoResults posts a note which tells no results are found if there
+	// are no results.
+	reportNoResults bool
+}
+
+// MergeRequestCommitCommenterOption is an option for
+// NewGitLabMergeRequestCommitCommenter.
+type MergeRequestCommitCommenterOption func(*MergeRequestCommitCommenter)
+
+// WithCommitCommentTemplate makes MergeRequestCommitCommenter build comment
+// bodies with tmpl.
+func WithCommitCommentTemplate(tmpl *commentutil.Template) MergeRequestCommitCommenterOption {
+	return func(g *MergeRequestCommitCommenter) {
+		g.tmpl = tmpl
+	}
+}
+
+// WithCommitCommentMode makes MergeRequestCommitCommenter post a summary of
+// all the results as a merge request note instead of or in addition to commit
+// comments.
+func WithCommitCommentMode(mode commentutil.CommentMode) MergeRequestCommitCommenterOption {
+	return func(g *MergeRequestCommitCommenter) {
+		g.commentMode = mode
+	}
+}
+
+// WithCommitFingerprint makes MergeRequestCommitCommenter embed a fingerprint
+// of each result in comment bodies and skip results whose fingerprint is
+// found in existing commit comments, even if they are on other lines.
+func WithCommitFingerprint() MergeRequestCommitCommenterOption {
+	return func(g *MergeRequestCommitCommenter) {
+		g.fingerprint = true
+	}
+}
+
+// WithCommitMaxMessageLength sets the max length of diagnostic messages in
+// comment bodies in bytes. Longer messages are truncated with a note.
+// DefaultMaxMessageLength is used by default.
+func WithCommitMaxMessageLength(n int) MergeRequestCommitCommenterOption {
+	return func(g *MergeRequestCommitCommenter) {
+		g.maxMessageLength = n
+	}
+}
+
+// WithCommitReportNoResults makes MergeRequestCommitCommenter post a merge
+// request note with commentutil.NoResultsComment if no results are found, so
+// that reviewers can tell reviewdog ran.
+func WithCommitReportNoResults() MergeRequestCommitCommenterOption {
+	return func(g *MergeRequestCommitCommenter) {
+		g.reportNoResults = true
+	}
+}
+
+// WithCommitLogger sets the logger of MergeRequestCommitCommenter.
+// serviceutil.DefaultLogger is used by default.
+func WithCommitLogger(logger serviceutil.Logger) MergeRequestCommitCommenterOption {
+	return func(g *MergeRequestCommitCommenter) {
+		g.logger = logger
+	}
 }
 
 // NewGitLabMergeRequestCommitCommenter returns a new MergeRequestCommitCommenter service.
 // MergeRequestCommitCommenter service needs git command in $PATH.
-func NewGitLabMergeRequestCommitCommenter(cli *gitlab.Client, owner, repo string, pr int, sha string) (*MergeRequestCommitCommenter, error) {
+func NewGitLabMergeRequestCommitCommenter(cli *gitlab.Client, owner, repo string, pr int, sha string, opts ...MergeRequestCommitCommenterOption) (*MergeRequestCommitCommenter, error) {
 	workDir, err := serviceutil.GitRelWorkdir()
 	if err != nil {
 		return nil, fmt.Errorf("MergeRequestCommitCommenter needs 'git' command: %w", err)
 	}
-	return &MergeRequestCommitCommenter{
+	g := &MergeRequestCommitCommenter{
 		cli:      cli,
 		pr:       pr,
 		sha:      sha,
 		projects: owner + "/" + repo,
 		wd:       workDir,
-	}, nil
+		logger:   serviceutil.DefaultLogger(),
+
+		maxMessageLength: DefaultMaxMessageLength,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.tmpl = g.tmpl.WithMaxMessageLength(g.maxMessageLength)
+	return g, nil
 }
 
 // Post accepts a comment and holds it. Flush method actually posts comments to
@@ -65,16 +151,32 @@ func (g *MergeRequestCommitCommenter) Post(_ context.Context, c *reviewdog.Comme
 	return nil
 }
 
+// GroupsResults implements reviewdog.GroupingCommentService. Comment bodies
+// list locations of collapsed duplicates.
+func (g *MergeRequestCommitCommenter) GroupsResults() {}
+
 // Flush posts comments which has not been posted yet.
 func (g *MergeRequestCommitCommenter) Flush(ctx context.Context) error {
 	g.muComments.Lock()
 	defer g.muComments.Unlock()
 
-	if err := g.setPostedComment(ctx); err != nil {
-		return err
+	if g.commentMode.Inline() {
+		if err := g.setPostedComment(ctx); err != nil {
+			return err
+		}
+		if err := g.postCommentsForEach(ctx); err != nil {
+			return err
+		}
 	}
-
-	return g.postCommentsForEach(ctx)
+	if g.commentMode.Summary() {
+		if err := postSummaryNote(ctx, g.cli, g.projects, g.pr, g.postComments); err != nil {
+			return err
+		}
+	}
+	if g.reportNoResults && len(g.postComments) == 0 {
+		return postNoResultsNote(ctx, g.cli, g.projects, g.pr)
+	}
+	return nil
 }
 
 func (g *MergeRequestCommitCommenter) postCommentsForEach(ctx context.Context) error {
@@ -83,13 +185,21 @@ func (g *MergeRequestCommitCommenter) postCommentsForEach(ctx context.Context) e
 		c := c
 		loc := c.Result.Diagnostic.GetLocation()
 		lnum := int(loc.GetRange().GetStart().GetLine())
-		body := commentutil.MarkdownComment(c)
-		if !c.Result.InDiffFile || lnum == 0 || g.postedcs.IsPosted(c, lnum, body) {
+		body := g.tmpl.Body(c)
+		if g.fingerprint {
+			body = commentutil.AppendFingerprint(body, c)
+		}
+		if !c.Result.InDiffFile || lnum == 0 {
+			continue
+		}
+		if g.postedcs.IsPosted(c, lnum, body) || (g.fingerprint && g.postedFingerprints.Contains(c)) {
+			g.logger.Debugf("gitlab-mr-commit: skip a posted comment: %s:%d", loc.GetPath(), lnum)
 			continue
 		}
 		eg.Go(func() error {
-			commitID, err := g.getLastCommitsID(loc.GetPath(), lnum)
+			commitID, err := g.getLastCommitsID(ctx, loc.GetPath(), lnum)
 			if err != nil {
+				g.logger.Infof("gitlab-mr-commit: %v. Posting to %s instead", err, g.sha)
 				commitID = g.sha
 			}
 			prcomment := &gitlab.PostCommitCommentOptions{
@@ -105,9 +215,9 @@ func (g *MergeRequestCommitCommenter) postCommentsForEach(ctx context.Context) e
 	return eg.Wait()
 }
 
-func (g *MergeRequestCommitCommenter) getLastCommitsID(path string, line int) (string, error) {
+func (g *MergeRequestCommitCommenter) getLastCommitsID(ctx context.Context, path string, line int) (string, error) {
 	lineFormat := fmt.Sprintf("%d,%d", line, line)
-	s, err := exec.Command("git", "blame", "-l", "-L", lineFormat, path).Output()
+	s, err := exec.CommandContext(ctx, "git", "blame", "-l", "-L", lineFormat, path).Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get commitID: %w", err)
 	}
@@ -117,11 +227,13 @@ func (g *MergeRequestCommitCommenter) getLastCommitsID(path string, line int) (s
 
 func (g *MergeRequestCommitCommenter) setPostedComment(ctx context.Context) error {
 	g.postedcs = make(commentutil.PostedComments)
+	g.postedFingerprints = make(commentutil.Fingerprints)
 	cs, err := g.comment(ctx)
 	if err != nil {
 		return err
 	}
 	for _, c := range cs {
+		g.postedFingerprints.Add(c.Note)
 		if c.Line == 0 || c.Path == "" || c.Note == "" {
 			// skip resolved comments. Or comments which do not have "path" nor
 			// "body".
@@ -133,19 +245,58 @@ func (g *MergeRequestCommitCommenter) setPostedComment(ctx context.Context) erro
 }
 
 func (g *MergeRequestCommitCommenter) comment(ctx context.Context) ([]*gitlab.CommitComment, error) {
-	commits, _, err := g.cli.MergeRequests.GetMergeRequestCommits(
-		g.projects, g.pr, nil, gitlab.WithContext(ctx))
+	commits, err := listAllMergeRequestCommits(ctx, g.cli, g.projects, g.pr,
+		&gitlab.GetMergeRequestCommitsOptions{PerPage: 100})
 	if err != nil {
 		return nil, err
 	}
 	comments := make([]*gitlab.CommitComment, 0)
 	for _, c := range commits {
-		tmpComments, _, err := g.cli.Commits.GetCommitComments(
-			g.projects, c.ID, nil, gitlab.WithContext(ctx))
+		tmpComments, err := listAllCommitComments(ctx, g.cli, g.projects, c.ID,
+			&gitlab.GetCommitCommentsOptions{PerPage: 100})
 		if err != nil {
+			g.logger.Warnf("gitlab-mr-commit: failed to list comments of commit %s: %v", c.ID, err)
 			continue
 		}
 		comments = append(comments, tmpComments...)
 	}
 	return comments, nil
 }
+
+func listAllMergeRequestCommits(ctx context.Context, cli *gitlab.Client, projectID string, mergeRequest int, opts *gitlab.GetMergeRequestCommitsOptions) ([]*gitlab.Commit, error) {
+	commits, resp, err := cli.MergeRequests.GetMergeRequestCommits(projectID, mergeRequest, opts, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.NextPage == 0 {
+		return commits, nil
+	}
+	newOpts := &gitlab.GetMergeRequestCommitsOptions{
+		Page:    resp.NextPage,
+		PerPage: opts.PerPage,
+	}
+	restCommits, err := listAllMergeRequestCommits(ctx, cli, projectID, mergeRequest, newOpts)
+	if err != nil {
+		return nil, err
+	}
+	return append(commits, restCommits...), nil
+}
+
+func listAllCommitComments(ctx context.Context, cli *gitlab.Client, projectID string, sha string, opts *gitlab.GetCommitCommentsOptions) ([]*gitlab.CommitComment, error) {
+	comments, resp, err := cli.Commits.GetCommitComments(projectID, sha, opts, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.NextPage == 0 {
+		return comments, nil
+	}
+	newOpts := &gitlab.GetCommitCommentsOptions{
+		Page:    resp.NextPage,
+		PerPage: opts.PerPage,
+	}
+	restComments, err := listAllCommitComments(ctx, cli, projectID, sha, newOpts)
+	if err != nil {
+		return nil, err
+	}
+	return append(comments, restComments...), nil
+}
@@ -44,12 +44,25 @@ func TestGitLabMergeRequestCommitCommenter_Post_Flush_review_api(t *testing.T) {
 		if r.Method != http.MethodGet {
 			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
 		}
-		cs := []*gitlab.CommitComment{
-			{
-				Path: "notExistFile.go",
-				Line: 1,
-				Note: commentutil.BodyPrefix + "already commented",
-			},
+		var cs []*gitlab.CommitComment
+		switch r.URL.Query().Get("page") {
+		default:
+			cs = []*gitlab.CommitComment{
+				{
+					Path: "notExistFile.go",
+					Line: 1,
+					Note: commentutil.BodyPrefix + "already commented",
+				},
+			}
+			w.Header().Add("X-Next-Page", "2")
+		case "2":
+			cs = []*gitlab.CommitComment{
+				{
+					Path: "notExistFile.go",
+					Line: 2,
+					Note: commentutil.BodyPrefix + "already commented 2",
+				},
+			}
 		}
 		if err := json.NewEncoder(w).Encode(cs); err != nil {
 			t.Fatal(err)
@@ -106,6 +119,20 @@ func TestGitLabMergeRequestCommitCommenter_Post_Flush_review_api(t *testing.T) {
 				InDiffFile: true,
 			},
 		},
+		{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path: "notExistFile.go",
+						Range: &rdf.Range{Start: &rdf.Position{
+							Line: 2,
+						}},
+					},
+					Message: "already commented 2",
+				},
+				InDiffFile: true,
+			},
+		},
 		{
 			Result: &filter.FilteredDiagnostic{
 				Diagnostic: &rdf.Diagnostic{
@@ -129,7 +156,7 @@ func TestGitLabMergeRequestCommitCommenter_Post_Flush_review_api(t *testing.T) {
 	if err := g.Flush(context.Background()); err != nil {
 		t.Error(err)
 	}
-	if want := 3; apiCalled != want {
+	if want := 4; apiCalled != want {
 		t.Errorf("GitLab API is called %d times, want %d times", apiCalled, want)
 	}
 }
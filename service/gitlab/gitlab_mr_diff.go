@@ -47,24 +47,24 @@ func NewGitLabMergeRequestDiff(cli *gitlab.Client, owner, repo string, pr int, s
 // `git diff --no-renames`, we want diff which is equivalent to
 // `git diff --find-renames`.
 func (g *MergeRequestDiff) Diff(ctx context.Context) ([]byte, error) {
-	mr, _, err := g.cli.MergeRequests.GetMergeRequest(g.projects, g.pr, nil)
+	mr, _, err := g.cli.MergeRequests.GetMergeRequest(g.projects, g.pr, nil, gitlab.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
-	targetBranch, _, err := g.cli.Branches.GetBranch(mr.TargetProjectID, mr.TargetBranch, nil)
+	targetBranch, _, err := g.cli.Branches.GetBranch(mr.TargetProjectID, mr.TargetBranch, nil, gitlab.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 	return g.gitDiff(ctx, g.sha, targetBranch.Commit.ID)
 }
 
-func (g *MergeRequestDiff) gitDiff(_ context.Context, baseSha, targetSha string) ([]byte, error) {
-	b, err := exec.Command("git", "merge-base", targetSha, baseSha).Output()
+func (g *MergeRequestDiff) gitDiff(ctx context.Context, baseSha, targetSha string) ([]byte, error) {
+	b, err := exec.CommandContext(ctx, "git", "merge-base", targetSha, baseSha).Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get merge-base commit: %w", err)
 	}
 	mergeBase := strings.Trim(string(b), "\n")
-	bytes, err := exec.Command("git", "diff", "--find-renames", mergeBase, baseSha).Output()
+	bytes, err := exec.CommandContext(ctx, "git", "diff", "--find-renames", mergeBase, baseSha).Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to run git diff: %w", err)
 	}
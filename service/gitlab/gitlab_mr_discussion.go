@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 
@@ -22,6 +21,11 @@ const (
 	invalidSuggestionPost = "</details>"
 )
 
+// DefaultMaxMessageLength is the default max length of diagnostic messages in
+// discussion and commit comments. GitLab rejects notes longer than 1,000,000
+// characters.
+const DefaultMaxMessageLength = 900000
+
 // MergeRequestDiscussionCommenter is a comment and diff service for GitLab MergeRequest.
 //
 // API:
@@ -38,22 +42,135 @@ type MergeRequestDiscussionCommenter struct {
 
 	// wd is working directory relative to root of repository.
 	wd string
+
+	// resolveStale resolves discussions created by reviewdog whose results
+	// are not reported anymore. deferStaleResolution resolves them in Finish
+	// instead of Flush.
+	resolveStale         bool
+	deferStaleResolution bool
+
+	// tmpl is the comment body template. MarkdownComment is used if nil.
+	tmpl *commentutil.Template
+
+	// maxMessageLength is the max length of diagnostic messages in comment
+	// bodies. Longer messages are truncated.
+	maxMessageLength int
+
+	// commentMode is whether to post inline discussions, a summary note or
+	// both.
+	commentMode commentutil.CommentMode
+
+	// fingerprint embeds fingerprints in comment bodies and skips comments
+	// whose fingerprint is found in existing discussions.
+	fingerprint bool
+
+	// reportNoResults posts a note which tells no results are found if there
+	// are no results.
+	reportNoResults bool
+
+	logger serviceutil.Logger
+}
+
+// MergeRequestDiscussionCommenterOption is an option for
+// NewGitLabMergeRequestDiscussionCommenter.
+type MergeRequestDiscussionCommenterOption func(*MergeRequestDiscussionCommenter)
+
+// WithResolveStaleDiscussions makes MergeRequestDiscussionCommenter resolve
+// discussions which were created by reviewdog with the same user but whose
+// results are not reported in the current run anymore. Discussions created by
+// other users are never resolved.
+func WithResolveStaleDiscussions() MergeRequestDiscussionCommenterOption {
+	return func(g *MergeRequestDiscussionCommenter) {
+		g.resolveStale = true
+	}
+}
+
+// WithDeferredStaleResolution makes MergeRequestDiscussionCommenter with
+// WithResolveStaleDiscussions resolve stale discussions once in Finish instead
+// of in each Flush. Use it if multiple runs share the commenter (e.g. runners
+// of a project config), since results of runs which haven't flushed yet would
+// be regarded as stale otherwise.
+func WithDeferredStaleResolution() MergeRequestDiscussionCommenterOption {
+	return func(g *MergeRequestDiscussionCommenter) {
+		g.deferStaleResolution = true
+	}
+}
+
+// WithDiscussionCommentTemplate makes MergeRequestDiscussionCommenter build
+// comment bodies with tmpl.
+func WithDiscussionCommentTemplate(tmpl *commentutil.Template) MergeRequestDiscussionCommenterOption {
+	return func(g *MergeRequestDiscussionCommenter) {
+		g.tmpl = tmpl
+	}
+}
+
+// WithDiscussionCommentMode makes MergeRequestDiscussionCommenter post a
+// summary of all the results as a merge request note instead of or in addition
+// to inline discussions.
+func WithDiscussionCommentMode(mode commentutil.CommentMode) MergeRequestDiscussionCommenterOption {
+	return func(g *MergeRequestDiscussionCommenter) {
+		g.commentMode = mode
+	}
+}
+
+// WithDiscussionFingerprint makes MergeRequestDiscussionCommenter embed a
+// fingerprint of each result in comment bodies and skip results whose
+// fingerprint is found in existing discussions, even if they are on other
+// lines.
+func WithDiscussionFingerprint() MergeRequestDiscussionCommenterOption {
+	return func(g *MergeRequestDiscussionCommenter) {
+		g.fingerprint = true
+	}
+}
+
+// WithDiscussionMaxMessageLength sets the max length of diagnostic messages
+// in comment bodies in bytes. Longer messages are truncated with a note.
+// DefaultMaxMessageLength is used by default.
+func WithDiscussionMaxMessageLength(n int) MergeRequestDiscussionCommenterOption {
+	return func(g *MergeRequestDiscussionCommenter) {
+		g.maxMessageLength = n
+	}
+}
+
+// WithDiscussionReportNoResults makes MergeRequestDiscussionCommenter post a
+// merge request note with commentutil.NoResultsComment if no results are
+// found, so that reviewers can tell reviewdog ran.
+func WithDiscussionReportNoResults() MergeRequestDiscussionCommenterOption {
+	return func(g *MergeRequestDiscussionCommenter) {
+		g.reportNoResults = true
+	}
+}
+
+// WithDiscussionLogger sets the logger of MergeRequestDiscussionCommenter.
+// serviceutil.DefaultLogger is used by default.
+func WithDiscussionLogger(logger serviceutil.Logger) MergeRequestDiscussionCommenterOption {
+	return func(g *MergeRequestDiscussionCommenter) {
+		g.logger = logger
+	}
 }
 
 // NewGitLabMergeRequestDiscussionCommenter returns a new MergeRequestDiscussionCommenter service.
 // MergeRequestDiscussionCommenter service needs git command in $PATH.
-func NewGitLabMergeRequestDiscussionCommenter(cli *gitlab.Client, owner, repo string, pr int, sha string) (*MergeRequestDiscussionCommenter, error) {
+func NewGitLabMergeRequestDiscussionCommenter(cli *gitlab.Client, owner, repo string, pr int, sha string, opts ...MergeRequestDiscussionCommenterOption) (*MergeRequestDiscussionCommenter, error) {
 	workDir, err := serviceutil.GitRelWorkdir()
 	if err != nil {
 		return nil, fmt.Errorf("MergeRequestDiscussionCommenter needs 'git' command: %w", err)
 	}
-	return &MergeRequestDiscussionCommenter{
+	g := &MergeRequestDiscussionCommenter{
 		cli:      cli,
 		pr:       pr,
 		sha:      sha,
 		projects: owner + "/" + repo,
 		wd:       workDir,
-	}, nil
+		logger:   serviceutil.DefaultLogger(),
+
+		maxMessageLength: DefaultMaxMessageLength,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.tmpl = g.tmpl.WithMaxMessageLength(g.maxMessageLength)
+	return g, nil
 }
 
 // Post accepts a comment and holds it. Flush method actually posts comments to
@@ -67,25 +184,63 @@ func (g *MergeRequestDiscussionCommenter) Post(_ context.Context, c *reviewdog.C
 	return nil
 }
 
+// GroupsResults implements reviewdog.GroupingCommentService. Comment bodies
+// list locations of collapsed duplicates.
+func (g *MergeRequestDiscussionCommenter) GroupsResults() {}
+
 // Flush posts comments which has not been posted yet.
 func (g *MergeRequestDiscussionCommenter) Flush(ctx context.Context) error {
 	g.muComments.Lock()
 	defer g.muComments.Unlock()
-	postedcs, err := g.createPostedComments()
+	discussions, err := listAllMergeRequestDiscussion(ctx, g.cli, g.projects, g.pr, &gitlab.ListMergeRequestDiscussionsOptions{PerPage: 100})
 	if err != nil {
-		return fmt.Errorf("failed to create posted comments: %w", err)
+		return fmt.Errorf("failed to create posted comments: failed to list all merge request discussions: %w", err)
 	}
-	return g.postCommentsForEach(ctx, postedcs)
+	g.logger.Debugf("gitlab-mr-discussion: found %d existing discussions", len(discussions))
+	if g.commentMode.Inline() {
+		postedcs, fps := createPostedComments(discussions)
+		if err := g.postCommentsForEach(ctx, postedcs, fps); err != nil {
+			return err
+		}
+	}
+	if g.commentMode.Summary() {
+		if err := postSummaryNote(ctx, g.cli, g.projects, g.pr, g.postComments); err != nil {
+			return err
+		}
+	}
+	if g.reportNoResults && len(g.postComments) == 0 {
+		if err := postNoResultsNote(ctx, g.cli, g.projects, g.pr); err != nil {
+			return err
+		}
+	}
+	if g.resolveStale && !g.deferStaleResolution {
+		return g.resolveStaleDiscussions(ctx, discussions)
+	}
+	return nil
 }
 
-func (g *MergeRequestDiscussionCommenter) createPostedComments() (commentutil.PostedComments, error) {
-	postedcs := make(commentutil.PostedComments)
-	discussions, err := listAllMergeRequestDiscussion(g.cli, g.projects, g.pr, &gitlab.ListMergeRequestDiscussionsOptions{PerPage: 100})
+// Finish implements reviewdog.FinishingCommentService. It resolves stale
+// discussions with the results of all the runs with
+// WithDeferredStaleResolution.
+func (g *MergeRequestDiscussionCommenter) Finish(ctx context.Context) error {
+	if !g.resolveStale || !g.deferStaleResolution {
+		return nil
+	}
+	g.muComments.Lock()
+	defer g.muComments.Unlock()
+	discussions, err := listAllMergeRequestDiscussion(ctx, g.cli, g.projects, g.pr, &gitlab.ListMergeRequestDiscussionsOptions{PerPage: 100})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list all merge request discussions: %w", err)
+		return fmt.Errorf("failed to list all merge request discussions: %w", err)
 	}
+	return g.resolveStaleDiscussions(ctx, discussions)
+}
+
+func createPostedComments(discussions []*gitlab.Discussion) (commentutil.PostedComments, commentutil.Fingerprints) {
+	postedcs := make(commentutil.PostedComments)
+	fps := make(commentutil.Fingerprints)
 	for _, d := range discussions {
 		for _, note := range d.Notes {
+			fps.Add(note.Body)
 			pos := note.Position
 			if pos == nil || pos.NewPath == "" || pos.NewLine == 0 || note.Body == "" {
 				continue
@@ -93,15 +248,62 @@ func (g *MergeRequestDiscussionCommenter) createPostedComments() (commentutil.Po
 			postedcs.AddPostedComment(pos.NewPath, pos.NewLine, note.Body)
 		}
 	}
-	return postedcs, nil
+	return postedcs, fps
+}
+
+// resolveStaleDiscussions resolves unresolved discussions created by reviewdog
+// with the current user if the current run doesn't report the same result.
+func (g *MergeRequestDiscussionCommenter) resolveStaleDiscussions(ctx context.Context, discussions []*gitlab.Discussion) error {
+	user, _, err := g.cli.Users.CurrentUser(gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
+	current := make(commentutil.PostedComments)
+	currentFps := make(commentutil.Fingerprints)
+	for _, c := range g.postComments {
+		loc := c.Result.Diagnostic.GetLocation()
+		body := g.buildBody(c)
+		current.AddPostedComment(loc.GetPath(), int(loc.GetRange().GetStart().GetLine()), body)
+		currentFps.Add(body)
+	}
+
+	var eg errgroup.Group
+	for _, d := range discussions {
+		d := d
+		if len(d.Notes) == 0 {
+			continue
+		}
+		note := d.Notes[0]
+		pos := note.Position
+		if !note.Resolvable || note.Resolved || note.Author.ID != user.ID ||
+			!strings.Contains(note.Body, commentutil.BodyPrefix) || pos == nil {
+			continue
+		}
+		if current.Contains(pos.NewPath, pos.NewLine, note.Body) {
+			continue
+		}
+		// The result moved to another line but it's not re-posted.
+		if g.fingerprint && currentFps.ContainsBody(note.Body) {
+			continue
+		}
+		g.logger.Infof("gitlab-mr-discussion: resolving a stale discussion at %s:%d", pos.NewPath, pos.NewLine)
+		eg.Go(func() error {
+			opt := &gitlab.ResolveMergeRequestDiscussionOptions{Resolved: gitlab.Bool(true)}
+			if _, _, err := g.cli.Discussions.ResolveMergeRequestDiscussion(g.projects, g.pr, d.ID, opt, gitlab.WithContext(ctx)); err != nil {
+				return fmt.Errorf("failed to resolve merge request discussion: %w", err)
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
 }
 
-func (g *MergeRequestDiscussionCommenter) postCommentsForEach(ctx context.Context, postedcs commentutil.PostedComments) error {
+func (g *MergeRequestDiscussionCommenter) postCommentsForEach(ctx context.Context, postedcs commentutil.PostedComments, fps commentutil.Fingerprints) error {
 	mr, _, err := g.cli.MergeRequests.GetMergeRequest(g.projects, g.pr, nil, gitlab.WithContext(ctx))
 	if err != nil {
 		return fmt.Errorf("failed to get merge request: %w", err)
 	}
-	targetBranch, _, err := g.cli.Branches.GetBranch(mr.TargetProjectID, mr.TargetBranch, nil)
+	targetBranch, _, err := g.cli.Branches.GetBranch(mr.TargetProjectID, mr.TargetBranch, nil, gitlab.WithContext(ctx))
 	if err != nil {
 		return err
 	}
@@ -111,15 +313,16 @@ func (g *MergeRequestDiscussionCommenter) postCommentsForEach(ctx context.Contex
 		c := c
 		loc := c.Result.Diagnostic.GetLocation()
 		lnum := int(loc.GetRange().GetStart().GetLine())
-		body := commentutil.MarkdownComment(c)
+		body := g.buildBody(c)
 
-		if suggestion := buildSuggestions(c); suggestion != "" {
-			body = body + "\n\n" + suggestion
+		if !c.Result.InDiffFile || lnum == 0 {
+			continue
 		}
-
-		if !c.Result.InDiffFile || lnum == 0 || postedcs.IsPosted(c, lnum, body) {
+		if postedcs.IsPosted(c, lnum, body) || (g.fingerprint && fps.Contains(c)) {
+			g.logger.Debugf("gitlab-mr-discussion: skip a posted comment: %s:%d", loc.GetPath(), lnum)
 			continue
 		}
+		g.logger.Debugf("gitlab-mr-discussion: posting a discussion at %s:%d", loc.GetPath(), lnum)
 		eg.Go(func() error {
 			pos := &gitlab.NotePosition{
 				StartSHA:     targetBranch.Commit.ID,
@@ -137,7 +340,7 @@ func (g *MergeRequestDiscussionCommenter) postCommentsForEach(ctx context.Contex
 				Body:     gitlab.String(body),
 				Position: pos,
 			}
-			_, _, err := g.cli.Discussions.CreateMergeRequestDiscussion(g.projects, g.pr, discussion)
+			_, _, err := g.cli.Discussions.CreateMergeRequestDiscussion(g.projects, g.pr, discussion, gitlab.WithContext(ctx))
 			if err != nil {
 				return fmt.Errorf("failed to create merge request discussion: %w", err)
 			}
@@ -147,8 +350,8 @@ func (g *MergeRequestDiscussionCommenter) postCommentsForEach(ctx context.Contex
 	return eg.Wait()
 }
 
-func listAllMergeRequestDiscussion(cli *gitlab.Client, projectID string, mergeRequest int, opts *gitlab.ListMergeRequestDiscussionsOptions) ([]*gitlab.Discussion, error) {
-	discussions, resp, err := cli.Discussions.ListMergeRequestDiscussions(projectID, mergeRequest, opts)
+func listAllMergeRequestDiscussion(ctx context.Context, cli *gitlab.Client, projectID string, mergeRequest int, opts *gitlab.ListMergeRequestDiscussionsOptions) ([]*gitlab.Discussion, error) {
+	discussions, resp, err := cli.Discussions.ListMergeRequestDiscussions(projectID, mergeRequest, opts, gitlab.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -159,13 +362,24 @@ func listAllMergeRequestDiscussion(cli *gitlab.Client, projectID string, mergeRe
 		Page:    resp.NextPage,
 		PerPage: opts.PerPage,
 	}
-	restDiscussions, err := listAllMergeRequestDiscussion(cli, projectID, mergeRequest, newOpts)
+	restDiscussions, err := listAllMergeRequestDiscussion(ctx, cli, projectID, mergeRequest, newOpts)
 	if err != nil {
 		return nil, err
 	}
 	return append(discussions, restDiscussions...), nil
 }
 
+func (g *MergeRequestDiscussionCommenter) buildBody(c *reviewdog.Comment) string {
+	body := g.tmpl.Body(c)
+	if suggestion := buildSuggestions(c); suggestion != "" {
+		body = body + "\n\n" + suggestion
+	}
+	if g.fingerprint {
+		body = commentutil.AppendFingerprint(body, c)
+	}
+	return body
+}
+
 // creates diff in markdown for suggested changes
 // Ref gitlab suggestion: https://docs.gitlab.com/ee/user/project/merge_requests/reviews/suggestions.html
 func buildSuggestions(c *reviewdog.Comment) string {
@@ -187,8 +401,28 @@ func buildSuggestions(c *reviewdog.Comment) string {
 	return sb.String()
 }
 
+// buildSingleSuggestion builds a suggestion block whose "-N+M" offsets are
+// the number of lines above and below the comment line, since GitLab applies
+// suggestions relatively to the line of the discussion. Suggestions with
+// columns are expanded to whole lines with the source lines.
 func buildSingleSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, error) {
-	var sb strings.Builder
+	startLine := int(s.GetRange().GetStart().GetLine())
+	endLine := int(s.GetRange().GetEnd().GetLine())
+	if endLine < startLine {
+		endLine = startLine
+	}
+	commentLine := int(c.Result.Diagnostic.GetLocation().GetRange().GetStart().GetLine())
+	if commentLine == 0 {
+		commentLine = startLine
+	}
+	if startLine > commentLine || endLine < commentLine {
+		return "", fmt.Errorf("GitLab suggestion line range must contain the comment line. L%d v.s. L%d-L%d",
+			commentLine, startLine, endLine)
+	}
+	txt, err := commentutil.LineBasedSuggestionText(c, s)
+	if err != nil {
+		return "", err
+	}
 
 	// we might need to use 4 or more backticks
 	//
@@ -197,14 +431,14 @@ func buildSingleSuggestion(c *reviewdog.Comment, s *rdf.Suggestion) (string, err
 	//
 	// The documentation doesn't explicitly say anything about cases more than 4 backticks,
 	// however it seems to be handled as intended.
-	txt := s.GetText()
 	backticks := commentutil.GetCodeFenceLength(txt)
 
-	lines := strconv.Itoa(int(s.Range.End.Line - s.Range.Start.Line))
-	sb.Grow(backticks + len("suggestion:-0+\n") + len(lines) + len(txt) + len("\n") + backticks)
+	offsets := fmt.Sprintf("-%d+%d", commentLine-startLine, endLine-commentLine)
+	var sb strings.Builder
+	sb.Grow(backticks + len("suggestion:\n") + len(offsets) + len(txt) + len("\n") + backticks)
 	commentutil.WriteCodeFence(&sb, backticks)
-	sb.WriteString("suggestion:-0+")
-	sb.WriteString(lines)
+	sb.WriteString("suggestion:")
+	sb.WriteString(offsets)
 	sb.WriteString("\n")
 	if txt != "" {
 		sb.WriteString(txt)
@@ -309,6 +309,393 @@ func TestGitLabMergeRequestDiscussionCommenter_Post_Flush_review_api(t *testing.
 	}
 }
 
+func TestGitLabMergeRequestDiscussionCommenter_Flush_resolveStaleDiscussions(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	newComment := func(path string, line int32, msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  path,
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: msg,
+				},
+				InDiffFile: true,
+			},
+		}
+	}
+	current := newComment("file.go", 1, "still reported")
+	stale := newComment("file.go", 2, "fixed")
+	note := func(c *reviewdog.Comment, authorID int, resolved bool) *gitlab.Note {
+		loc := c.Result.Diagnostic.GetLocation()
+		n := &gitlab.Note{
+			Body:       commentutil.MarkdownComment(c),
+			Resolvable: true,
+			Resolved:   resolved,
+			Position: &gitlab.NotePosition{
+				NewPath: loc.GetPath(),
+				NewLine: int(loc.GetRange().GetStart().GetLine()),
+			},
+		}
+		n.Author.ID = authorID
+		return n
+	}
+
+	var resolved []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 1}`))
+	})
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/discussions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		dls := []*gitlab.Discussion{
+			{ID: "current", Notes: []*gitlab.Note{note(current, 1, false)}},
+			{ID: "stale", Notes: []*gitlab.Note{note(stale, 1, false)}},
+			{ID: "stale-other-user", Notes: []*gitlab.Note{note(stale, 2, false)}},
+			{ID: "stale-resolved", Notes: []*gitlab.Note{note(stale, 1, true)}},
+			{ID: "human", Notes: []*gitlab.Note{{Body: "LGTM", Resolvable: true}}},
+		}
+		if err := json.NewEncoder(w).Encode(dls); err != nil {
+			t.Fatal(err)
+		}
+	})
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/discussions/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		got := new(gitlab.ResolveMergeRequestDiscussionOptions)
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Error(err)
+		}
+		if got.Resolved == nil || !*got.Resolved {
+			t.Errorf("got unexpected resolve option: %#v", got)
+		}
+		resolved = append(resolved, strings.TrimPrefix(r.URL.Path, "/api/v4/projects/o/r/merge_requests/14/discussions/"))
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"target_project_id": 14, "target_branch": "test-branch"}`))
+	})
+	mux.HandleFunc("/api/v4/projects/14/repository/branches/test-branch", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"commit": {"id": "xxx"}}`))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := NewGitLabMergeRequestDiscussionCommenter(cli, "o", "r", 14, "sha", WithResolveStaleDiscussions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Post(context.Background(), current); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(resolved, []string{"stale"}); diff != "" {
+		t.Errorf("resolved discussions diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestGitLabMergeRequestDiscussionCommenter_Finish_resolveStaleDiscussions(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	newComment := func(tool string, line int32, msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: msg,
+				},
+				InDiffFile: true,
+			},
+			ToolName: tool,
+		}
+	}
+	// Results of two runners of a project config.
+	current1 := newComment("tool1", 1, "still reported by tool1")
+	current2 := newComment("tool2", 2, "still reported by tool2")
+	stale := newComment("tool2", 3, "fixed")
+	note := func(c *reviewdog.Comment) *gitlab.Note {
+		loc := c.Result.Diagnostic.GetLocation()
+		n := &gitlab.Note{
+			Body:       commentutil.MarkdownComment(c),
+			Resolvable: true,
+			Position: &gitlab.NotePosition{
+				NewPath: loc.GetPath(),
+				NewLine: int(loc.GetRange().GetStart().GetLine()),
+			},
+		}
+		n.Author.ID = 1
+		return n
+	}
+
+	var resolved []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 1}`))
+	})
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/discussions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		dls := []*gitlab.Discussion{
+			{ID: "current1", Notes: []*gitlab.Note{note(current1)}},
+			{ID: "current2", Notes: []*gitlab.Note{note(current2)}},
+			{ID: "stale", Notes: []*gitlab.Note{note(stale)}},
+		}
+		if err := json.NewEncoder(w).Encode(dls); err != nil {
+			t.Fatal(err)
+		}
+	})
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/discussions/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		resolved = append(resolved, strings.TrimPrefix(r.URL.Path, "/api/v4/projects/o/r/merge_requests/14/discussions/"))
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"target_project_id": 14, "target_branch": "test-branch"}`))
+	})
+	mux.HandleFunc("/api/v4/projects/14/repository/branches/test-branch", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"commit": {"id": "xxx"}}`))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := NewGitLabMergeRequestDiscussionCommenter(cli, "o", "r", 14, "sha",
+		WithResolveStaleDiscussions(), WithDeferredStaleResolution())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each runner posts its results and flushes the commenter.
+	for _, c := range []*reviewdog.Comment{current1, current2} {
+		if err := g.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Flush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if len(resolved) != 0 {
+			t.Fatalf("discussions should not be resolved before Finish, but resolved %v", resolved)
+		}
+	}
+	if err := g.Finish(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(resolved, []string{"stale"}); diff != "" {
+		t.Errorf("resolved discussions diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestGitLabMergeRequestDiscussionCommenter_Flush_summary(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	var notes []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/discussions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/notes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		got := new(gitlab.CreateMergeRequestNoteOptions)
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil || got.Body == nil {
+			t.Errorf("invalid note request: %v", err)
+			return
+		}
+		notes = append(notes, *got.Body)
+		w.Write([]byte(`{}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGitLabMergeRequestDiscussionCommenter(cli, "o", "r", 14, "sha", WithDiscussionCommentMode(commentutil.CommentModeSummary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &reviewdog.Comment{
+		Result: &filter.FilteredDiagnostic{
+			Diagnostic: &rdf.Diagnostic{
+				Location: &rdf.Location{
+					Path:  "file.go",
+					Range: &rdf.Range{Start: &rdf.Position{Line: 1}},
+				},
+				Message: "summarized",
+			},
+			InDiffFile: true,
+		},
+	}
+	if err := g.Post(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "summarized") {
+		t.Errorf("got summary notes %q, want one note containing the result", notes)
+	}
+}
+
+func TestGitLabMergeRequestDiscussionCommenter_Flush_reportNoResults(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	var notes []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/discussions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/notes", func(w http.ResponseWriter, r *http.Request) {
+		got := new(gitlab.CreateMergeRequestNoteOptions)
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil || got.Body == nil {
+			t.Errorf("invalid note request: %v", err)
+			return
+		}
+		notes = append(notes, *got.Body)
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"target_project_id": 14, "target_branch": "test-branch"}`))
+	})
+	mux.HandleFunc("/api/v4/projects/14/repository/branches/test-branch", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"commit": {"id": "xxx"}}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGitLabMergeRequestDiscussionCommenter(cli, "o", "r", 14, "sha", WithDiscussionReportNoResults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes[0] != commentutil.NoResultsComment {
+		t.Errorf("got notes %q, want one no results note", notes)
+	}
+}
+
+func TestGitLabMergeRequestDiscussionCommenter_Flush_fingerprint(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir("../..")
+
+	newComment := func(line int32, msg string) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Location: &rdf.Location{
+						Path:  "file.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Message: msg,
+				},
+				InDiffFile: true,
+			},
+		}
+	}
+	posted := commentutil.AppendFingerprint(commentutil.MarkdownComment(newComment(1, "moved")), newComment(1, "moved"))
+
+	var bodies []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14/discussions", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			ds := []*gitlab.Discussion{{
+				Notes: []*gitlab.Note{{
+					Body:     posted,
+					Position: &gitlab.NotePosition{NewPath: "file.go", NewLine: 1},
+				}},
+			}}
+			if err := json.NewEncoder(w).Encode(ds); err != nil {
+				t.Fatal(err)
+			}
+		case http.MethodPost:
+			got := new(gitlab.CreateMergeRequestDiscussionOptions)
+			if err := json.NewDecoder(r.Body).Decode(got); err != nil || got.Body == nil {
+				t.Errorf("invalid discussion request: %v", err)
+				return
+			}
+			bodies = append(bodies, *got.Body)
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected access: %v %v", r.Method, r.URL)
+		}
+	})
+	mux.HandleFunc("/api/v4/projects/o/r/merge_requests/14", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"target_project_id": 14, "target_branch": "test-branch"}`))
+	})
+	mux.HandleFunc("/api/v4/projects/14/repository/branches/test-branch", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"commit": {"id": "xxx"}}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cli, err := gitlab.NewClient("", gitlab.WithBaseURL(ts.URL+"/api/v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGitLabMergeRequestDiscussionCommenter(cli, "o", "r", 14, "sha", WithDiscussionFingerprint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*reviewdog.Comment{newComment(2, "moved"), newComment(3, "new")} {
+		if err := g.Post(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{commentutil.AppendFingerprint(commentutil.MarkdownComment(newComment(3, "new")), newComment(3, "new"))}
+	if diff := cmp.Diff(bodies, want); diff != "" {
+		t.Errorf("posted discussions diff (-got +want):\n%s", diff)
+	}
+}
+
 func TestBuildSuggestions(t *testing.T) {
 	tests := []struct {
 		in   *reviewdog.Comment
@@ -439,6 +826,62 @@ func TestBuildSuggestionsInvalid(t *testing.T) {
 	}
 }
 
+func TestBuildSuggestions_offsets(t *testing.T) {
+	newComment := func(line int32, sourceLines map[int]string, s *rdf.Suggestion) *reviewdog.Comment {
+		return &reviewdog.Comment{
+			ToolName: "tool-name",
+			Result: &filter.FilteredDiagnostic{
+				Diagnostic: &rdf.Diagnostic{
+					Message: "msg",
+					Location: &rdf.Location{
+						Path:  "a.go",
+						Range: &rdf.Range{Start: &rdf.Position{Line: line}},
+					},
+					Suggestions: []*rdf.Suggestion{s},
+				},
+				SourceLines: sourceLines,
+			},
+		}
+	}
+	tests := []struct {
+		name string
+		in   *reviewdog.Comment
+		want string
+	}{
+		{
+			name: "single line",
+			in:   newComment(10, nil, buildTestsSuggestion("fixed", 10, 10)),
+			want: "```suggestion:-0+0\nfixed\n```\n",
+		},
+		{
+			name: "multi lines around the comment line",
+			in:   newComment(11, nil, buildTestsSuggestion("line1\nline2\nline3", 10, 13)),
+			want: "```suggestion:-1+2\nline1\nline2\nline3\n```\n",
+		},
+		{
+			name: "columns",
+			in: newComment(10, map[int]string{10: "x := foo(bar)"}, &rdf.Suggestion{
+				Text: "baz",
+				Range: &rdf.Range{
+					Start: &rdf.Position{Line: 10, Column: 6},
+					End:   &rdf.Position{Line: 10, Column: 9},
+				},
+			}),
+			want: "```suggestion:-0+0\nx := baz(bar)\n```\n",
+		},
+		{
+			name: "outside the comment line",
+			in:   newComment(10, nil, buildTestsSuggestion("fixed", 11, 12)),
+			want: invalidSuggestionPre + "GitLab suggestion line range must contain the comment line. L10 v.s. L11-L12" + invalidSuggestionPost + "\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := buildSuggestions(tt.in); got != tt.want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
 func buildTestsSuggestion(text string, start int32, end int32) *rdf.Suggestion {
 	return &rdf.Suggestion{
 		Text: text,
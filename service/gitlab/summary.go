@@ -0,0 +1,39 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+
+	"github.com/reviewdog/reviewdog"
+	"github.com/reviewdog/reviewdog/service/commentutil"
+)
+
+// postSummaryNote posts a summary of comments as a merge request note. It
+// does nothing if there are no comments.
+func postSummaryNote(ctx context.Context, cli *gitlab.Client, projectID string, mergeRequest int, comments []*reviewdog.Comment) error {
+	summary := commentutil.SummaryComment(comments)
+	if summary == "" {
+		return nil
+	}
+	_, _, err := cli.Notes.CreateMergeRequestNote(projectID, mergeRequest, &gitlab.CreateMergeRequestNoteOptions{
+		Body: gitlab.String(summary),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to post a summary note: %w", err)
+	}
+	return nil
+}
+
+// postNoResultsNote posts a merge request note which tells no results are
+// found.
+func postNoResultsNote(ctx context.Context, cli *gitlab.Client, projectID string, mergeRequest int) error {
+	_, _, err := cli.Notes.CreateMergeRequestNote(projectID, mergeRequest, &gitlab.CreateMergeRequestNoteOptions{
+		Body: gitlab.String(commentutil.NoResultsComment),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to post a no results note: %w", err)
+	}
+	return nil
+}
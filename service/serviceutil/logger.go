@@ -0,0 +1,99 @@
+package serviceutil
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// Logger is a leveled logger used by services.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+// LogLevel represents the lowest level of logs to output.
+type LogLevel int
+
+const (
+	// LogLevelWarn outputs only warnings. It's the default level.
+	LogLevelWarn LogLevel = iota
+	// LogLevelInfo outputs info logs and warnings.
+	LogLevelInfo
+	// LogLevelDebug outputs all the logs.
+	LogLevelDebug
+	// LogLevelNone outputs nothing.
+	LogLevelNone
+)
+
+// String implements the flag.Value interface
+func (level *LogLevel) String() string {
+	names := [...]string{
+		"warn",
+		"info",
+		"debug",
+		"none",
+	}
+	if *level < LogLevelWarn || *level > LogLevelNone {
+		return "Unknown log level"
+	}
+	return names[*level]
+}
+
+// Set implements the flag.Value interface
+func (level *LogLevel) Set(value string) error {
+	switch value {
+	case "warn", "":
+		*level = LogLevelWarn
+	case "info":
+		*level = LogLevelInfo
+	case "debug":
+		*level = LogLevelDebug
+	case "none":
+		*level = LogLevelNone
+	default:
+		return fmt.Errorf("invalid log level: %s", value)
+	}
+	return nil
+}
+
+// enabled returns true if logs of the given level should be output.
+func (level LogLevel) enabled(l LogLevel) bool {
+	return level != LogLevelNone && l <= level
+}
+
+type logger struct {
+	l     *log.Logger
+	level LogLevel
+}
+
+// NewLogger returns a Logger which writes logs of the level or the higher
+// levels to w.
+func NewLogger(w io.Writer, level LogLevel) Logger {
+	return &logger{l: log.New(w, "reviewdog: ", log.LstdFlags), level: level}
+}
+
+// DefaultLogger returns a Logger which writes warnings to stderr.
+func DefaultLogger() Logger {
+	return NewLogger(os.Stderr, LogLevelWarn)
+}
+
+func (l *logger) Debugf(format string, args ...interface{}) {
+	l.logf(LogLevelDebug, "[debug] ", format, args...)
+}
+
+func (l *logger) Infof(format string, args ...interface{}) {
+	l.logf(LogLevelInfo, "[info] ", format, args...)
+}
+
+func (l *logger) Warnf(format string, args ...interface{}) {
+	l.logf(LogLevelWarn, "[warn] ", format, args...)
+}
+
+func (l *logger) logf(level LogLevel, prefix, format string, args ...interface{}) {
+	if l.level.enabled(level) {
+		l.l.Printf(prefix+format, args...)
+	}
+}
@@ -0,0 +1,62 @@
+package serviceutil
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLogLevel_Set(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    LogLevel
+		wantErr bool
+	}{
+		{value: "", want: LogLevelWarn},
+		{value: "warn", want: LogLevelWarn},
+		{value: "info", want: LogLevelInfo},
+		{value: "debug", want: LogLevelDebug},
+		{value: "none", want: LogLevelNone},
+		{value: "unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		var level LogLevel
+		err := (&level).Set(tt.value)
+		if err != nil && !tt.wantErr {
+			t.Errorf("got error for %q: %v", tt.value, err)
+		} else if err == nil && tt.wantErr {
+			t.Errorf("want error, but got nil for %q", tt.value)
+		}
+		if level != tt.want {
+			t.Errorf("[value=%s] got %q, want %q", tt.value, level.String(), tt.want.String())
+		}
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		level LogLevel
+		want  []string
+	}{
+		{level: LogLevelDebug, want: []string{"[debug] d", "[info] i", "[warn] w"}},
+		{level: LogLevelInfo, want: []string{"[info] i", "[warn] w"}},
+		{level: LogLevelWarn, want: []string{"[warn] w"}},
+		{level: LogLevelNone, want: nil},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		l := NewLogger(&buf, tt.level)
+		l.Debugf("d")
+		l.Infof("i")
+		l.Warnf("w")
+		var got []string
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if i := strings.Index(line, "["); i >= 0 {
+				got = append(got, line[i:])
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("[%s] got logs %q, want %q", tt.level.String(), got, tt.want)
+		}
+	}
+}
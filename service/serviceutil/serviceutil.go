@@ -1,16 +1,36 @@
 package serviceutil
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
+var (
+	// ErrGitNotFound is returned when git command is required but not found in
+	// $PATH.
+	ErrGitNotFound = errors.New("git command not found")
+	// ErrNotGitRepo is returned when current directory is not inside a git
+	// repository.
+	ErrNotGitRepo = errors.New("not a git repository")
+)
+
+// relWorkdirCache memoizes GitRelWorkdir for the current directory.
+var relWorkdirCache struct {
+	sync.Mutex
+	cwd string
+	wd  string
+}
+
 // GitRelWorkdir returns git relative workdir of current directory.
 //
 // It should return the same output as `git rev-parse --show-prefix`.
 // It does not execute `git` command to avoid needless git binary dependency.
+// The result is cached until the current directory changes as services and
+// comment bodies call it repeatedly in one run. Errors are not cached.
 //
 // An example problem due to `git` command dependency: https://github.com/reviewdog/reviewdog/issues/1158
 func GitRelWorkdir() (string, error) {
@@ -18,21 +38,48 @@ func GitRelWorkdir() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	root, err := findGitRoot(cwd)
+	relWorkdirCache.Lock()
+	defer relWorkdirCache.Unlock()
+	if relWorkdirCache.cwd == cwd {
+		return relWorkdirCache.wd, nil
+	}
+	wd, err := GitRelDir(cwd)
 	if err != nil {
 		return "", err
 	}
-	if !strings.HasPrefix(cwd, root) {
-		return "", fmt.Errorf("cannot get GitRelWorkdir: cwd=%q, root=%q", cwd, root)
+	relWorkdirCache.cwd, relWorkdirCache.wd = cwd, wd
+	return wd, nil
+}
+
+// GitRelDir returns git relative path of the given directory, which must be
+// inside a git repository. It's the same as GitRelWorkdir but for dir instead
+// of current directory.
+func GitRelDir(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	root, err := findGitRoot(dir)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(dir, root) {
+		return "", fmt.Errorf("cannot get GitRelDir: dir=%q, root=%q", dir, root)
 	}
 	const separator = string(filepath.Separator)
-	path := strings.Trim(strings.TrimPrefix(cwd, root), separator)
+	path := strings.Trim(strings.TrimPrefix(dir, root), separator)
 	if path != "" {
 		path += separator
 	}
 	return path, nil
 }
 
+// GitRoot returns the root directory of the git repository which the given
+// directory is inside.
+func GitRoot(dir string) (string, error) {
+	return findGitRoot(dir)
+}
+
 func findGitRoot(path string) (string, error) {
 	gitPath, err := findDotGitPath(path)
 	if err != nil {
@@ -72,7 +119,7 @@ func findDotGitPath(path string) (string, error) {
 
 		parent := filepath.Dir(path)
 		if parent == path {
-			return "", fmt.Errorf(".git not found")
+			return "", fmt.Errorf("%w: .git not found", ErrNotGitRepo)
 		}
 		path = parent
 	}
@@ -1,7 +1,9 @@
 package serviceutil
 
 import (
+	"errors"
 	"os"
+	"sync"
 	"testing"
 )
 
@@ -29,3 +31,59 @@ func TestGitRelWorkdir(t *testing.T) {
 		t.Fatalf("gitRelWorkdir() = %q, want %q", wd, subDir)
 	}
 }
+
+func TestGitRelWorkdir_notGitRepo(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GitRelWorkdir(); !errors.Is(err, ErrNotGitRepo) {
+		t.Fatalf("GitRelWorkdir() error = %v, want %v", err, ErrNotGitRepo)
+	}
+}
+
+func TestGitRelDir(t *testing.T) {
+	wd, err := GitRelDir("../../cmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "cmd/"; wd != want {
+		t.Fatalf("GitRelDir() = %q, want %q", wd, want)
+	}
+	if _, err := GitRelDir(t.TempDir()); !errors.Is(err, ErrNotGitRepo) {
+		t.Fatalf("GitRelDir() error = %v, want %v", err, ErrNotGitRepo)
+	}
+}
+
+func TestGitRelWorkdir_cache(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	if wd, _ := GitRelWorkdir(); wd != "" {
+		t.Fatalf("GitRelWorkdir() = %q, want empty", wd)
+	}
+	if relWorkdirCache.cwd == "" {
+		t.Fatal("GitRelWorkdir() result is not cached")
+	}
+
+	// Changing directory invalidates the cache.
+	if err := os.Chdir("cmd"); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if wd, _ := GitRelWorkdir(); wd != "cmd/" {
+				t.Errorf("GitRelWorkdir() = %q, want %q", wd, "cmd/")
+			}
+		}()
+	}
+	wg.Wait()
+}
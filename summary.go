@@ -0,0 +1,100 @@
+package reviewdog
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/proto/rdf"
+)
+
+// RunSummary accumulates reported results of one or more runs (e.g. runners
+// of a project config) so that a machine-readable summary can be written
+// after all the reporters flush. Pass it to runs with WithRunSummary.
+type RunSummary struct {
+	mu sync.Mutex
+
+	// Total is the number of reported results including collapsed duplicates.
+	Total int `json:"total"`
+	// Severities is the number of reported results per severity (error,
+	// warning, info and other).
+	Severities map[string]int `json:"severities"`
+	// Tools is the number of reported results per tool name.
+	Tools map[string]int `json:"tools"`
+	// FailLevelExceeded is true if results of the fail level (see
+	// WithFailPolicy) exceed the threshold in any run, regardless of
+	// -fail-on-error.
+	FailLevelExceeded bool `json:"fail_level_exceeded"`
+}
+
+// NewRunSummary returns a new empty RunSummary.
+func NewRunSummary() *RunSummary {
+	return &RunSummary{
+		Severities: make(map[string]int),
+		Tools:      make(map[string]int),
+	}
+}
+
+func (s *RunSummary) add(toolName string, d *rdf.Diagnostic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Total++
+	s.Severities[severityName(d.GetSeverity())]++
+	s.Tools[toolName]++
+}
+
+// AddCheck adds the results reported by the check of the tool, including
+// collapsed duplicates and omitted results, to the summary. It's for
+// reporters which don't run Reviewdog (e.g. GitHub Checks via doghouse).
+func (s *RunSummary) AddCheck(toolName string, check *filter.FilteredDiagnostic) {
+	for _, d := range check.ReportedChecks() {
+		s.add(toolName, d.Diagnostic)
+	}
+}
+
+// SetFailLevelExceeded marks that results of the fail level exceed the
+// threshold. It's for reporters which don't run Reviewdog.
+func (s *RunSummary) SetFailLevelExceeded() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.FailLevelExceeded = true
+}
+
+// WriteJSON writes the summary as an indented JSON object.
+func (s *RunSummary) WriteJSON(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+func severityName(s rdf.Severity) string {
+	switch s {
+	case rdf.Severity_ERROR:
+		return "error"
+	case rdf.Severity_WARNING:
+		return "warning"
+	case rdf.Severity_INFO:
+		return "info"
+	}
+	return "other"
+}
+
+// WithRunSummary makes Reviewdog add reported results to the summary. The same
+// summary can be shared by multiple runs.
+func WithRunSummary(s *RunSummary) Option {
+	return func(w *Reviewdog) {
+		w.summary = s
+	}
+}
+
+// addSummary adds the results reported by the check, including collapsed
+// duplicates and omitted results, to the summary if any.
+func (w *Reviewdog) addSummary(check *filter.FilteredDiagnostic) {
+	if w.summary == nil {
+		return
+	}
+	w.summary.AddCheck(w.toolname, check)
+}
@@ -0,0 +1,59 @@
+package reviewdog
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/reviewdog/reviewdog/filter"
+	"github.com/reviewdog/reviewdog/parser"
+)
+
+func TestRunSummary(t *testing.T) {
+	summary := NewRunSummary()
+	c := &testWriter{FakePost: func(*Comment) error { return nil }}
+	run := func(toolName, input string, opts ...Option) {
+		t.Helper()
+		opts = append(opts, WithRunSummary(summary))
+		app := NewReviewdog(toolName, parser.NewRDJSONLParser(), c, NewDiffString("", 1), filter.ModeNoFilter, false, opts...)
+		if err := app.Run(context.Background(), strings.NewReader(input)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("golint", `{"message":"a","location":{"path":"a.go"},"severity":"WARNING"}
+{"message":"b","location":{"path":"a.go"},"severity":"INFO"}
+`, WithFailPolicy(filter.SeverityLevelError, 0))
+	if summary.FailLevelExceeded {
+		t.Error("fail level should not be exceeded by warnings")
+	}
+	run("vet", `{"message":"c","location":{"path":"a.go"},"severity":"ERROR"}
+{"message":"c","location":{"path":"b.go"},"severity":"ERROR"}
+{"message":"d","location":{"path":"b.go"}}
+`, WithDuplicateCollapse(1))
+
+	var buf bytes.Buffer
+	if err := summary.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "total": 5,
+  "severities": {
+    "error": 2,
+    "info": 1,
+    "other": 1,
+    "warning": 1
+  },
+  "tools": {
+    "golint": 2,
+    "vet": 3
+  },
+  "fail_level_exceeded": true
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("summary (-want +got):\n%s", diff)
+	}
+}